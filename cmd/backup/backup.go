@@ -0,0 +1,79 @@
+// backup.go backup command code
+package backup
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/backup/sources"
+	"github.com/tphakala/birdnet-go/internal/backup/targets"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// Command creates the backup parent command
+func Command(settings *conf.Settings) *cobra.Command {
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Commands related to backup operations in BirdNET-Go",
+	}
+
+	// Add subcommands here
+	backupCmd.AddCommand(ListCommand(settings))
+	backupCmd.AddCommand(RestoreCommand(settings))
+
+	return backupCmd
+}
+
+// newManager creates a backup manager with the configured targets and the
+// restorers of the configured data sources
+func newManager(settings *conf.Settings) (*backup.Manager, error) {
+	level := slog.LevelWarn
+	if settings.Debug || settings.Backup.Debug {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	stateManager, err := backup.NewStateManager(logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup state manager: %w", err)
+	}
+
+	manager, err := backup.NewManager(settings, logger, stateManager, settings.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup manager: %w", err)
+	}
+
+	// Restorers are registered without validation so that a missing or
+	// damaged database can still be restored
+	if settings.Output.SQLite.Enabled {
+		manager.RegisterRestorer(sources.NewSQLiteSource(settings, logger))
+	}
+
+	registered := 0
+	for i := range settings.Backup.Targets {
+		targetConfig := &settings.Backup.Targets[i]
+		if !targetConfig.Enabled {
+			continue
+		}
+
+		target, err := targets.NewTargetFromConfig(targetConfig, settings.Backup.Debug, logger)
+		if err != nil {
+			fmt.Printf("Skipping %s backup target: %v\n", targetConfig.Type, err)
+			continue
+		}
+		if err := manager.RegisterTarget(target); err != nil {
+			fmt.Printf("Skipping %s backup target: %v\n", targetConfig.Type, err)
+			continue
+		}
+		registered++
+	}
+
+	if registered == 0 {
+		return nil, fmt.Errorf("no usable backup targets configured")
+	}
+
+	return manager, nil
+}
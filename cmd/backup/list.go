@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// ListCommand creates the list subcommand
+func ListCommand(settings *conf.Settings) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List backups stored in the configured backup targets",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := newManager(settings)
+			if err != nil {
+				return err
+			}
+
+			backups, err := manager.ListBackups(context.Background())
+			if err != nil && len(backups) == 0 {
+				return fmt.Errorf("failed to list backups: %w", err)
+			}
+			if err != nil {
+				fmt.Printf("Warning: some targets could not be listed: %v\n", err)
+			}
+
+			if len(backups) == 0 {
+				fmt.Println("No backups found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTARGET\tCREATED\tSIZE\tENCRYPTED")
+			for i := range backups {
+				b := &backups[i]
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%t\n", b.ID, b.Target, b.Timestamp.Local().Format(time.DateTime), b.Size, b.Encrypted)
+			}
+			return w.Flush()
+		},
+	}
+}
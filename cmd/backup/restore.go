@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// RestoreCommand creates the restore subcommand
+func RestoreCommand(settings *conf.Settings) *cobra.Command {
+	var opts backup.RestoreOptions
+	var assumeYes bool
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore <backup-id>",
+		Short: "Restore the database and optionally the configuration from a backup",
+		Long: `Restore the database and optionally the configuration from a backup.
+
+The backup archive is fetched from a configured backup target, its checksum is
+verified and it is decrypted with the local encryption key. The restored
+database is checked for integrity before it replaces the current database,
+which is kept next to it with a .pre-restore-<timestamp> suffix.
+
+BirdNET-Go must not be running while restoring from the command line. To
+restore a running instance use the web interface instead.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backupID := args[0]
+
+			if !assumeYes && !confirmRestore(backupID, &opts) {
+				fmt.Println("Restore cancelled")
+				return nil
+			}
+
+			manager, err := newManager(settings)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Restoring backup %s...\n", backupID)
+			result, err := manager.Restore(context.Background(), backupID, &opts)
+			if err != nil {
+				return fmt.Errorf("restore failed: %w", err)
+			}
+
+			fmt.Printf("Restored backup %s (created %s) from target %s\n",
+				result.BackupID, result.Timestamp.Local().Format(time.DateTime), result.Target)
+			if result.PreviousDataPath != "" {
+				fmt.Printf("Previous database saved to: %s\n", result.PreviousDataPath)
+			}
+			if result.ConfigRestored {
+				fmt.Printf("Configuration restored to: %s\n", result.ConfigPath)
+				if result.PreviousConfigPath != "" {
+					fmt.Printf("Previous configuration saved to: %s\n", result.PreviousConfigPath)
+				}
+			}
+			return nil
+		},
+	}
+
+	restoreCmd.Flags().StringVar(&opts.Target, "target", "", "Restore from this backup target only (e.g. local, s3, sftp)")
+	restoreCmd.Flags().BoolVar(&opts.RestoreConfig, "config", false, "Also restore the configuration stored in the backup, secrets are kept from the current configuration")
+	restoreCmd.Flags().StringVar(&opts.ConfigPath, "config-path", "", "Write the restored configuration to this file instead of the active config file")
+	restoreCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation")
+
+	return restoreCmd
+}
+
+// confirmRestore asks the user to confirm replacing the current data
+func confirmRestore(backupID string, opts *backup.RestoreOptions) bool {
+	what := "the database"
+	if opts.RestoreConfig {
+		what = "the database and configuration"
+	}
+	fmt.Printf("This will replace %s with backup %s. Make sure BirdNET-Go is not running.\n", what, backupID)
+	fmt.Print("Continue? [y/N]: ")
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
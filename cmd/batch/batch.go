@@ -0,0 +1,71 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/tphakala/birdnet-go/internal/analysis"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// Command creates a new cobra.Command for resumable batch analysis.
+func Command(settings *conf.Settings) *cobra.Command {
+	opts := &analysis.BatchOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "batch [path]",
+		Short: "Analyze a large set of audio files with resumable progress",
+		Long: `Analyze all audio files (WAV, FLAC, MP3, Ogg and M4A) in a directory with a pool of workers sized by --threads.
+Progress is stored in the output directory so an interrupted run resumes where it stopped,
+files already analyzed are skipped by content hash, and detections of all files are merged
+into a single report.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Create a context that can be cancelled
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// Set up signal handling
+			sigChan := make(chan os.Signal, 1)
+			signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+
+			// Handle shutdown in a separate goroutine
+			go func() {
+				sig := <-sigChan
+				fmt.Print("\n") // Add newline before the interrupt message
+				fmt.Printf("Received signal %v, stopping batch analysis, run the command again to resume...\n", sig)
+				cancel()
+			}()
+
+			// Ensure cleanup on exit
+			defer func() {
+				signal.Stop(sigChan)
+			}()
+
+			// The directory to analyze is passed as the first argument
+			settings.Input.Path = args[0]
+			err := analysis.BatchAnalysis(settings, opts, ctx)
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		},
+	}
+
+	// Disable printing usage on error
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	cmd.Flags().BoolVarP(&opts.Recursive, "recursive", "r", false, "Recursively analyze subdirectories")
+	cmd.Flags().StringVarP(&opts.OutputDir, "output", "o", ".", "Directory for the progress state and merged report")
+	cmd.Flags().StringSliceVar(&opts.Formats, "format", []string{analysis.BatchFormatCSV, analysis.BatchFormatJSONL}, "Merged report formats: csv, jsonl")
+	cmd.Flags().BoolVar(&opts.Datastore, "datastore", false, "Insert detections into the configured datastore")
+	cmd.Flags().BoolVar(&opts.Restart, "restart", false, "Discard the progress of a previous run and start over")
+
+	return cmd
+}
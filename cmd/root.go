@@ -8,6 +8,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/tphakala/birdnet-go/cmd/authors"
+	"github.com/tphakala/birdnet-go/cmd/backup"
+	"github.com/tphakala/birdnet-go/cmd/batch"
 	"github.com/tphakala/birdnet-go/cmd/benchmark"
 	"github.com/tphakala/birdnet-go/cmd/directory"
 	"github.com/tphakala/birdnet-go/cmd/file"
@@ -35,22 +37,26 @@ func RootCommand(settings *conf.Settings) *cobra.Command {
 	// Add sub-commands to the root command.
 	fileCmd := file.Command(settings)
 	directoryCmd := directory.Command(settings)
+	batchCmd := batch.Command(settings)
 	realtimeCmd := realtime.Command(settings)
 	authorsCmd := authors.Command()
 	licenseCmd := license.Command()
 	rangeCmd := rangefilter.Command(settings)
 	supportCmd := support.Command(settings)
 	benchmarkCmd := benchmark.Command(settings)
+	backupCmd := backup.Command(settings)
 
 	subcommands := []*cobra.Command{
 		fileCmd,
 		directoryCmd,
+		batchCmd,
 		realtimeCmd,
 		authorsCmd,
 		licenseCmd,
 		rangeCmd,
 		supportCmd,
 		benchmarkCmd,
+		backupCmd,
 	}
 
 	rootCmd.AddCommand(subcommands...)
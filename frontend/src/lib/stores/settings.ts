@@ -307,10 +307,13 @@ export interface SpeciesConfig {
 }
 
 export interface Action {
-  type: 'ExecuteCommand';
+  type: 'ExecuteCommand' | 'SendNotification';
   command: string;
   parameters: string[];
   executeDefaults: boolean;
+  title?: string; // Notification title template (SendNotification only)
+  message?: string; // Notification message template (SendNotification only)
+  priority?: 'low' | 'medium' | 'high' | 'critical'; // Notification priority (SendNotification only)
 }
 
 export interface SupportSettings {
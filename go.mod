@@ -33,6 +33,7 @@ require (
 	go.uber.org/goleak v1.3.0
 	go.uber.org/mock v0.6.0
 	golang.org/x/crypto v0.41.0
+	golang.org/x/image v0.25.0
 	golang.org/x/net v0.43.0
 	golang.org/x/term v0.34.0
 	golang.org/x/text v0.28.0
@@ -0,0 +1,648 @@
+// batch.go: resumable batch analysis of audio files with a merged report
+package analysis
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// Batch output file names, relative to the output directory
+const (
+	batchStateFileName  = "batch-state.json"
+	batchResultsDirName = "batch-results"
+	batchReportBaseName = "batch-report"
+)
+
+// Batch report formats
+const (
+	BatchFormatCSV   = "csv"
+	BatchFormatJSONL = "jsonl"
+)
+
+// audioMothTimestamp matches the recording start time in AudioMoth file names, e.g. 20240501_053000.WAV
+var audioMothTimestamp = regexp.MustCompile(`(\d{8}_\d{6})`)
+
+// BatchOptions contains the options of a batch analysis run
+type BatchOptions struct {
+	Recursive bool     // true to include subdirectories
+	OutputDir string   // directory for the state file, per-file results and the merged report
+	Formats   []string // merged report formats, "csv" and/or "jsonl"
+	Datastore bool     // true to insert detections into the configured datastore
+	Restart   bool     // true to discard the progress of a previous run
+}
+
+// BatchDetection is a single detection in the batch report
+type BatchDetection struct {
+	File           string    `json:"file"`
+	FileHash       string    `json:"fileHash"`
+	Begin          float64   `json:"begin"` // seconds from the start of the file
+	End            float64   `json:"end"`   // seconds from the start of the file
+	Timestamp      time.Time `json:"timestamp"`
+	ScientificName string    `json:"scientificName"`
+	CommonName     string    `json:"commonName"`
+	SpeciesCode    string    `json:"speciesCode"`
+	Confidence     float64   `json:"confidence"`
+	Model          string    `json:"model,omitempty"`
+}
+
+// batchAnalyzeFunc analyzes a single audio file and returns its detections
+type batchAnalyzeFunc func(ctx context.Context, path string) ([]datastore.Note, error)
+
+// batchSummary contains the counters of a batch run
+type batchSummary struct {
+	analyzed atomic.Int64
+	skipped  atomic.Int64
+	failed   atomic.Int64
+}
+
+// BatchAnalysis analyzes all audio files under settings.Input.Path with a pool of
+// workers sized by BirdNET.Threads. Progress is tracked in a state file so that an
+// interrupted run resumes where it stopped, and files are skipped by content hash
+// when they were already analyzed. Detections of all files are merged into one report.
+func BatchAnalysis(settings *conf.Settings, opts *BatchOptions, ctx context.Context) error {
+	if err := normalizeBatchOptions(opts); err != nil {
+		return err
+	}
+
+	if err := initializeBirdNET(settings); err != nil {
+		return err
+	}
+
+	resultsDir := filepath.Join(opts.OutputDir, batchResultsDirName)
+	if err := os.MkdirAll(resultsDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	statePath := filepath.Join(opts.OutputDir, batchStateFileName)
+	var state *BatchState
+	if opts.Restart {
+		state = newBatchState(statePath)
+		if err := removeBatchResults(resultsDir); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if state, err = loadBatchState(statePath); err != nil {
+			return err
+		}
+		if done := state.countDone(); done > 0 {
+			log.Printf("Resuming batch analysis, %d file(s) already analyzed", done)
+		}
+	}
+
+	files, err := findBatchFiles(settings.Input.Path, opts.Recursive)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		log.Printf("No audio files found in %s", settings.Input.Path)
+		return nil
+	}
+
+	workers := batchWorkerCount(settings.BirdNET.Threads, len(files))
+	analyzers, cleanup, err := newBatchAnalyzers(settings, workers)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	log.Printf("Batch analysis of %d file(s) with %d worker(s)", len(files), len(analyzers))
+	start := time.Now()
+
+	summary, runErr := runBatch(ctx, files, state, resultsDir, analyzers)
+	log.Printf("Batch analysis finished in %v: %d analyzed, %d skipped, %d failed",
+		time.Since(start).Round(time.Second), summary.analyzed.Load(), summary.skipped.Load(), summary.failed.Load())
+	if runErr != nil {
+		return runErr
+	}
+
+	if err := writeBatchReports(state, resultsDir, opts.OutputDir, opts.Formats); err != nil {
+		return err
+	}
+
+	if opts.Datastore {
+		return storeBatchDetections(settings, state, resultsDir)
+	}
+	return nil
+}
+
+// normalizeBatchOptions applies defaults and validates the batch options
+func normalizeBatchOptions(opts *BatchOptions) error {
+	if opts.OutputDir == "" {
+		opts.OutputDir = "."
+	}
+	if len(opts.Formats) == 0 {
+		opts.Formats = []string{BatchFormatCSV, BatchFormatJSONL}
+	}
+	for i, format := range opts.Formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != BatchFormatCSV && format != BatchFormatJSONL {
+			return errors.Newf("unsupported batch report format: %s", format).
+				Component("analysis.batch").
+				Category(errors.CategoryValidation).
+				Context("supported_formats", "csv,jsonl").
+				Build()
+		}
+		opts.Formats[i] = format
+	}
+	return nil
+}
+
+// batchWorkerCount returns the number of workers for the configured thread count
+func batchWorkerCount(threads, files int) int {
+	workers := threads
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return max(1, min(workers, files))
+}
+
+// newBatchAnalyzers creates one analyzer per worker. Workers run their own
+// single threaded interpreter so that files are analyzed in parallel.
+func newBatchAnalyzers(settings *conf.Settings, workers int) (analyzers []batchAnalyzeFunc, cleanup func(), err error) {
+	if workers == 1 {
+		return []batchAnalyzeFunc{newBatchAnalyzer(bn, settings)}, func() {}, nil
+	}
+
+	interpreters := make([]*birdnet.BirdNET, 0, workers)
+	cleanup = func() {
+		for _, interpreter := range interpreters {
+			interpreter.Delete()
+		}
+	}
+
+	for range workers {
+		workerSettings := *settings
+		workerSettings.BirdNET.Threads = 1
+		interpreter, err := birdnet.NewBirdNET(&workerSettings)
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to initialize BirdNET for batch worker: %w", err)
+		}
+		interpreters = append(interpreters, interpreter)
+		analyzers = append(analyzers, newBatchAnalyzer(interpreter, settings))
+	}
+
+	return analyzers, cleanup, nil
+}
+
+// newBatchAnalyzer returns an analyzer running the model on a whole file.
+// Detections below the threshold or outside the range filter are dropped.
+func newBatchAnalyzer(model *birdnet.BirdNET, settings *conf.Settings) batchAnalyzeFunc {
+	return func(ctx context.Context, path string) ([]datastore.Note, error) {
+		if err := validateAudioFile(path); err != nil {
+			return nil, err
+		}
+
+		fileSettings := *settings
+		fileSettings.Input.Path = path
+
+		step := time.Duration((3 - settings.BirdNET.Overlap) * float64(time.Second))
+		var offset time.Duration
+		var notes []datastore.Note
+
+		err := myaudio.ReadAudioFileBuffered(&fileSettings, func(chunk []float32, isEOF bool) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if len(chunk) == 0 {
+				return nil
+			}
+
+			// Chunk positions are offsets from the zero time, converted to seconds for the report
+			chunkNotes, err := model.ProcessChunk(chunk, time.Time{}.Add(offset))
+			if err != nil {
+				return err
+			}
+			for i := range chunkNotes {
+				if chunkNotes[i].Confidence > settings.BirdNET.Threshold && settings.IsSpeciesIncluded(chunkNotes[i].ScientificName) {
+					notes = append(notes, chunkNotes[i])
+				}
+			}
+			offset += step
+			return nil
+		})
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return notes, err
+	}
+}
+
+// findBatchFiles returns the audio files under root in lexical order
+func findBatchFiles(root string, recursive bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("error accessing path %s: %w", path, err)
+		}
+		if d.IsDir() {
+			if !recursive && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if myaudio.IsSupportedAudioFile(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking directory: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// runBatch analyzes the files with one worker per analyzer. Per-file results are
+// written to resultsDir before the file is marked as done in the state.
+func runBatch(ctx context.Context, files []string, state *BatchState, resultsDir string, analyzers []batchAnalyzeFunc) (*batchSummary, error) {
+	summary := &batchSummary{}
+	jobs := make(chan int)
+	var position atomic.Int64
+
+	var wg sync.WaitGroup
+	for _, analyze := range analyzers {
+		wg.Add(1)
+		go func(analyze batchAnalyzeFunc) {
+			defer wg.Done()
+			for i := range jobs {
+				n := position.Add(1)
+				processBatchFile(ctx, files[i], fmt.Sprintf("[%d/%d]", n, len(files)), state, resultsDir, analyze, summary)
+			}
+		}(analyze)
+	}
+
+feed:
+	for i := range files {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
+
+// processBatchFile analyzes a single file of a batch run and records the result
+func processBatchFile(ctx context.Context, path, progress string, state *BatchState, resultsDir string, analyze batchAnalyzeFunc, summary *batchSummary) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Printf("%s ❌ %s: %v", progress, path, err)
+		summary.failed.Add(1)
+		return
+	}
+
+	hash, ok := state.cachedHash(path, info)
+	if !ok {
+		if hash, err = hashFile(path); err != nil {
+			log.Printf("%s ❌ %s: %v", progress, path, err)
+			summary.failed.Add(1)
+			return
+		}
+	}
+
+	if !state.claim(hash) {
+		log.Printf("%s ⏭️  %s already analyzed", progress, path)
+		summary.skipped.Add(1)
+		return
+	}
+
+	start := time.Now()
+	notes, err := analyze(ctx, path)
+	if err != nil && ctx.Err() != nil {
+		// Interrupted, the file is analyzed again when the run is resumed
+		state.release(hash)
+		return
+	}
+
+	file := &BatchFileState{Path: path, Size: info.Size(), ModTime: info.ModTime()}
+	if err == nil {
+		err = writeBatchResults(filepath.Join(resultsDir, hash+".jsonl"), batchDetections(path, hash, info, notes))
+	}
+	if err != nil {
+		file.Status = batchStatusFailed
+		file.Error = err.Error()
+		summary.failed.Add(1)
+		log.Printf("%s ❌ %s: %v", progress, path, err)
+	} else {
+		file.Status = batchStatusDone
+		file.Detections = len(notes)
+		summary.analyzed.Add(1)
+		log.Printf("%s ✅ %s: %d detection(s) in %v", progress, path, len(notes), time.Since(start).Round(time.Millisecond))
+	}
+
+	if err := state.complete(hash, file); err != nil {
+		log.Printf("Failed to save batch state: %v", err)
+	}
+}
+
+// recordingStartTime returns the start time of a recording from an AudioMoth style
+// file name, falling back to the file modification time
+func recordingStartTime(path string, info os.FileInfo) time.Time {
+	if match := audioMothTimestamp.FindString(filepath.Base(path)); match != "" {
+		if t, err := time.ParseInLocation("20060102_150405", match, time.Local); err == nil {
+			return t
+		}
+	}
+	return info.ModTime()
+}
+
+// batchDetections converts the notes of a file to report detections
+func batchDetections(path, hash string, info os.FileInfo, notes []datastore.Note) []BatchDetection {
+	recordingStart := recordingStartTime(path, info)
+	detections := make([]BatchDetection, 0, len(notes))
+	for i := range notes {
+		begin := notes[i].BeginTime.Sub(time.Time{})
+		end := notes[i].EndTime.Sub(time.Time{})
+		detections = append(detections, BatchDetection{
+			File:           path,
+			FileHash:       hash,
+			Begin:          begin.Seconds(),
+			End:            end.Seconds(),
+			Timestamp:      recordingStart.Add(begin),
+			ScientificName: notes[i].ScientificName,
+			CommonName:     notes[i].CommonName,
+			SpeciesCode:    notes[i].SpeciesCode,
+			Confidence:     notes[i].Confidence,
+			Model:          notes[i].Model,
+		})
+	}
+	return detections
+}
+
+// writeBatchResults writes the detections of a file as JSON Lines
+func writeBatchResults(path string, detections []BatchDetection) error {
+	var sb strings.Builder
+	encoder := json.NewEncoder(&sb)
+	for i := range detections {
+		if err := encoder.Encode(&detections[i]); err != nil {
+			return fmt.Errorf("failed to encode detection: %w", err)
+		}
+	}
+	return writeFileAtomic(path, []byte(sb.String()))
+}
+
+// readBatchResults calls fn for each detection in a per-file results file
+func readBatchResults(path string, fn func(*BatchDetection) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open batch results: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var detection BatchDetection
+		if err := json.Unmarshal(scanner.Bytes(), &detection); err != nil {
+			return fmt.Errorf("failed to parse batch results %s: %w", path, err)
+		}
+		if err := fn(&detection); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// removeBatchResults removes the per-file results of a previous run
+func removeBatchResults(resultsDir string) error {
+	matches, err := filepath.Glob(filepath.Join(resultsDir, "*.jsonl"))
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove previous batch results: %w", err)
+		}
+	}
+	return nil
+}
+
+// doneFiles returns the content hashes of analyzed files ordered by path
+func (s *BatchState) doneFiles() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hashes := make([]string, 0, len(s.Files))
+	for hash, file := range s.Files {
+		if file.Status == batchStatusDone {
+			hashes = append(hashes, hash)
+		}
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return s.Files[hashes[i]].Path < s.Files[hashes[j]].Path
+	})
+	return hashes
+}
+
+// countDone returns the number of analyzed files
+func (s *BatchState) countDone() int {
+	return len(s.doneFiles())
+}
+
+// writeBatchReports merges the per-file results of all analyzed files into one
+// report per format. Detections are streamed, the report is not held in memory.
+func writeBatchReports(state *BatchState, resultsDir, outputDir string, formats []string) error {
+	for _, format := range formats {
+		reportPath := filepath.Join(outputDir, batchReportBaseName+"."+format)
+		if err := writeBatchReport(state, resultsDir, reportPath, format); err != nil {
+			return err
+		}
+		log.Printf("📁 Batch report written to %s", reportPath)
+	}
+	return nil
+}
+
+// writeBatchReport writes the merged report in a single format
+func writeBatchReport(state *BatchState, resultsDir, reportPath, format string) error {
+	tmpPath := reportPath + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create batch report: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	var writeDetection func(*BatchDetection) error
+
+	switch format {
+	case BatchFormatCSV:
+		csvWriter := csv.NewWriter(w)
+		err = csvWriter.Write([]string{"File", "Begin (s)", "End (s)", "Timestamp", "Scientific name", "Common name", "Species code", "Confidence", "Model"})
+		writeDetection = func(d *BatchDetection) error {
+			if err := csvWriter.Write([]string{
+				d.File,
+				strconv.FormatFloat(d.Begin, 'f', 1, 64),
+				strconv.FormatFloat(d.End, 'f', 1, 64),
+				d.Timestamp.Format(time.RFC3339),
+				d.ScientificName,
+				d.CommonName,
+				d.SpeciesCode,
+				strconv.FormatFloat(d.Confidence, 'f', 4, 64),
+				d.Model,
+			}); err != nil {
+				return err
+			}
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+	default:
+		encoder := json.NewEncoder(w)
+		writeDetection = func(d *BatchDetection) error {
+			return encoder.Encode(d)
+		}
+	}
+
+	if err == nil {
+		for _, hash := range state.doneFiles() {
+			if err = readBatchResults(filepath.Join(resultsDir, hash+".jsonl"), writeDetection); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, reportPath)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write batch report %s: %w", reportPath, err)
+	}
+	return nil
+}
+
+// batchNoteSaver stores the notes of a file in one transaction, it is implemented by the
+// SQLite and MySQL datastores
+type batchNoteSaver interface {
+	SaveNotes(notes []datastore.Note) error
+}
+
+// storeBatchDetections inserts the detections of analyzed files into the configured
+// datastore. Files are marked as stored so that resumed runs do not insert them again.
+func storeBatchDetections(settings *conf.Settings, state *BatchState, resultsDir string) error {
+	ds := datastore.New(settings)
+	if ds == nil {
+		return errors.Newf("no datastore configured, enable sqlite or mysql output").
+			Component("analysis.batch").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "store_batch_detections").
+			Build()
+	}
+	saver, ok := ds.(batchNoteSaver)
+	if !ok {
+		return errors.Newf("datastore %T cannot store batch detections", ds).
+			Component("analysis.batch").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "store_batch_detections").
+			Build()
+	}
+	if err := ds.Open(); err != nil {
+		return fmt.Errorf("failed to open datastore: %w", err)
+	}
+	defer func() {
+		if err := ds.Close(); err != nil {
+			log.Printf("Failed to close datastore: %v", err)
+		}
+	}()
+
+	stored, err := storeBatchFiles(settings, saver, state, resultsDir)
+	log.Printf("Stored %d detection(s) in the datastore", stored)
+	return err
+}
+
+// storeBatchFiles saves the detections of each analyzed file that is not stored yet. The
+// detections of a file are saved in one transaction, so a failure leaves no part of the
+// file in the datastore and a resumed run stores the whole file.
+func storeBatchFiles(settings *conf.Settings, saver batchNoteSaver, state *BatchState, resultsDir string) (int, error) {
+	stored := 0
+	for _, hash := range state.doneFiles() {
+		state.mu.Lock()
+		alreadyStored := state.Files[hash].Stored
+		state.mu.Unlock()
+		if alreadyStored {
+			continue
+		}
+
+		var notes []datastore.Note
+		err := readBatchResults(filepath.Join(resultsDir, hash+".jsonl"), func(d *BatchDetection) error {
+			notes = append(notes, batchDetectionNote(settings, d))
+			return nil
+		})
+		if err == nil {
+			err = saver.SaveNotes(notes)
+		}
+		if err != nil {
+			return stored, fmt.Errorf("failed to store batch detections: %w", err)
+		}
+		stored += len(notes)
+		if err := state.markStored(hash); err != nil {
+			return stored, err
+		}
+	}
+	return stored, nil
+}
+
+// batchDetectionNote converts a report detection to a note for the datastore
+func batchDetectionNote(settings *conf.Settings, d *BatchDetection) datastore.Note {
+	duration := time.Duration((d.End - d.Begin) * float64(time.Second))
+	return datastore.Note{
+		SourceNode: settings.Main.Name,
+		Date:       d.Timestamp.Format("2006-01-02"),
+		Time:       d.Timestamp.Format("15:04:05"),
+		Source: datastore.AudioSource{
+			ID:          d.File,
+			SafeString:  d.File,
+			DisplayName: filepath.Base(d.File),
+		},
+		BeginTime:      d.Timestamp,
+		EndTime:        d.Timestamp.Add(duration),
+		SpeciesCode:    d.SpeciesCode,
+		ScientificName: d.ScientificName,
+		CommonName:     d.CommonName,
+		Confidence:     d.Confidence,
+		Latitude:       settings.BirdNET.Latitude,
+		Longitude:      settings.BirdNET.Longitude,
+		Threshold:      settings.BirdNET.Threshold,
+		Sensitivity:    settings.BirdNET.Sensitivity,
+		Model:          d.Model,
+	}
+}
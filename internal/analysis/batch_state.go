@@ -0,0 +1,257 @@
+// batch_state.go: progress tracking for resumable batch analysis
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// batchStateVersion is the version of the batch state file format
+const batchStateVersion = 1
+
+// Batch file statuses
+const (
+	batchStatusDone   = "done"
+	batchStatusFailed = "failed"
+)
+
+// BatchFileState is the analysis state of a single file in a batch run
+type BatchFileState struct {
+	Path        string    `json:"path"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+	Status      string    `json:"status"`
+	Detections  int       `json:"detections"`
+	Error       string    `json:"error,omitempty"`
+	Stored      bool      `json:"stored,omitempty"` // true when detections were inserted into the datastore
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// BatchState tracks the progress of a batch analysis so that an interrupted run
+// resumes where it stopped. Files are keyed by their content hash.
+type BatchState struct {
+	Version int                        `json:"version"`
+	Created time.Time                  `json:"created"`
+	Updated time.Time                  `json:"updated"`
+	Files   map[string]*BatchFileState `json:"files"`
+
+	mu         sync.Mutex
+	path       string
+	pathIndex  map[string]string // file path to content hash
+	inProgress map[string]bool   // content hashes being analyzed
+}
+
+// newBatchState creates an empty batch state stored at path
+func newBatchState(path string) *BatchState {
+	now := time.Now()
+	return &BatchState{
+		Version:    batchStateVersion,
+		Created:    now,
+		Updated:    now,
+		Files:      make(map[string]*BatchFileState),
+		path:       path,
+		pathIndex:  make(map[string]string),
+		inProgress: make(map[string]bool),
+	}
+}
+
+// loadBatchState loads the batch state from path, a missing file results in an empty state
+func loadBatchState(path string) (*BatchState, error) {
+	state := newBatchState(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, errors.New(err).
+			Component("analysis.batch").
+			Category(errors.CategoryFileIO).
+			Context("operation", "load_batch_state").
+			Context("path", path).
+			Build()
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, errors.New(err).
+			Component("analysis.batch").
+			Category(errors.CategoryFileParsing).
+			Context("operation", "parse_batch_state").
+			Context("path", path).
+			Build()
+	}
+	if state.Version != batchStateVersion {
+		return nil, errors.Newf("unsupported batch state version %d", state.Version).
+			Component("analysis.batch").
+			Category(errors.CategoryValidation).
+			Context("operation", "load_batch_state").
+			Context("path", path).
+			Build()
+	}
+
+	if state.Files == nil {
+		state.Files = make(map[string]*BatchFileState)
+	}
+	for hash, file := range state.Files {
+		state.pathIndex[file.Path] = hash
+	}
+
+	return state, nil
+}
+
+// cachedHash returns the content hash recorded for a file if the file has not
+// changed since, which avoids re-reading large recordings when resuming
+func (s *BatchState) cachedHash(path string, info os.FileInfo) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, ok := s.pathIndex[path]
+	if !ok {
+		return "", false
+	}
+	file := s.Files[hash]
+	if file == nil || file.Size != info.Size() || !file.ModTime.Equal(info.ModTime()) {
+		return "", false
+	}
+	return hash, true
+}
+
+// claim marks a file as being analyzed. It returns false when a file with the
+// same content was already analyzed or is being analyzed by another worker.
+func (s *BatchState) claim(hash string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if file, ok := s.Files[hash]; ok && file.Status == batchStatusDone {
+		return false
+	}
+	if s.inProgress[hash] {
+		return false
+	}
+	s.inProgress[hash] = true
+	return true
+}
+
+// complete records the result of analyzing a file and saves the state
+func (s *BatchState) complete(hash string, file *BatchFileState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.inProgress, hash)
+	file.CompletedAt = time.Now()
+	s.Files[hash] = file
+	s.pathIndex[file.Path] = hash
+
+	return s.save()
+}
+
+// release drops the claim of a file whose analysis was interrupted
+func (s *BatchState) release(hash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inProgress, hash)
+}
+
+// markStored records that the detections of a file were inserted into the datastore
+func (s *BatchState) markStored(hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if file, ok := s.Files[hash]; ok {
+		file.Stored = true
+	}
+	return s.save()
+}
+
+// Save writes the state to disk
+func (s *BatchState) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save()
+}
+
+// save writes the state atomically, the caller must hold s.mu
+func (s *BatchState) save() error {
+	s.Updated = time.Now()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.New(err).
+			Component("analysis.batch").
+			Category(errors.CategoryFileIO).
+			Context("operation", "encode_batch_state").
+			Build()
+	}
+
+	return writeFileAtomic(s.path, data)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return errors.New(err).
+			Component("analysis.batch").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_temp_file").
+			Context("path", path).
+			Build()
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return errors.New(err).
+			Component("analysis.batch").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_file_atomic").
+			Context("path", path).
+			Build()
+	}
+	return nil
+}
+
+// hashFile returns the hex encoded SHA-256 hash of the file content
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.New(err).
+			Component("analysis.batch").
+			Category(errors.CategoryFileIO).
+			Context("operation", "open_file_for_hash").
+			Context("path", path).
+			Build()
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.New(err).
+			Component("analysis.batch").
+			Category(errors.CategoryFileIO).
+			Context("operation", "hash_file").
+			Context("path", path).
+			Build()
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,214 @@
+package analysis
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// fakeBatchAnalyzer returns one detection per file and records the analyzed paths
+type fakeBatchAnalyzer struct {
+	mu       sync.Mutex
+	analyzed []string
+	fail     map[string]bool
+}
+
+func (f *fakeBatchAnalyzer) analyze(ctx context.Context, path string) ([]datastore.Note, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.analyzed = append(f.analyzed, path)
+	if f.fail[filepath.Base(path)] {
+		return nil, assert.AnError
+	}
+	return []datastore.Note{{
+		BeginTime:      time.Time{}.Add(3 * time.Second),
+		EndTime:        time.Time{}.Add(6 * time.Second),
+		ScientificName: "Rana temporaria",
+		CommonName:     "Common Frog",
+		Confidence:     0.91,
+		Model:          "amphibians",
+	}}, nil
+}
+
+// createBatchFiles creates audio files with the given names and contents
+func createBatchFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+}
+
+func TestRunBatch_Resume(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	resultsDir := filepath.Join(outputDir, batchResultsDirName)
+	require.NoError(t, os.MkdirAll(resultsDir, 0o755))
+	statePath := filepath.Join(outputDir, batchStateFileName)
+
+	createBatchFiles(t, inputDir, map[string]string{
+		"20240501_053000.wav": "first recording",
+		"20240501_063000.wav": "second recording",
+		"copy.wav":            "first recording", // same content as the first file
+		"broken.wav":          "broken recording",
+	})
+	files, err := findBatchFiles(inputDir, false)
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+
+	// First run, one file fails
+	analyzer := &fakeBatchAnalyzer{fail: map[string]bool{"broken.wav": true}}
+	summary, err := runBatch(context.Background(), files, newBatchState(statePath), resultsDir, []batchAnalyzeFunc{analyzer.analyze, analyzer.analyze})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), summary.analyzed.Load())
+	assert.Equal(t, int64(1), summary.skipped.Load(), "duplicate content is skipped")
+	assert.Equal(t, int64(1), summary.failed.Load())
+
+	// Second run resumes from the state file and only retries the failed file
+	state, err := loadBatchState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, 2, state.countDone())
+
+	analyzer = &fakeBatchAnalyzer{}
+	summary, err = runBatch(context.Background(), files, state, resultsDir, []batchAnalyzeFunc{analyzer.analyze})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(inputDir, "broken.wav")}, analyzer.analyzed)
+	assert.Equal(t, int64(1), summary.analyzed.Load())
+	assert.Equal(t, int64(3), summary.skipped.Load())
+
+	// The merged report contains one detection per analyzed file
+	require.NoError(t, writeBatchReports(state, resultsDir, outputDir, []string{BatchFormatCSV, BatchFormatJSONL}))
+
+	f, err := os.Open(filepath.Join(outputDir, batchReportBaseName+".csv"))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, f.Close())
+	}()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4, "header and three detections")
+	assert.Equal(t, "File", records[0][0])
+	assert.Equal(t, filepath.Join(inputDir, "20240501_053000.wav"), records[1][0])
+	assert.Equal(t, "3.0", records[1][1])
+	assert.Equal(t, "amphibians", records[1][8])
+
+	expected := time.Date(2024, 5, 1, 5, 30, 3, 0, time.Local).Format(time.RFC3339)
+	assert.Equal(t, expected, records[1][3], "timestamp from AudioMoth file name")
+
+	jsonl, err := os.ReadFile(filepath.Join(outputDir, batchReportBaseName+".jsonl"))
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(jsonl)), "\n"), 3)
+}
+
+func TestRunBatch_Canceled(t *testing.T) {
+	inputDir := t.TempDir()
+	resultsDir := t.TempDir()
+	statePath := filepath.Join(t.TempDir(), batchStateFileName)
+	createBatchFiles(t, inputDir, map[string]string{"a.wav": "a"})
+
+	files, err := findBatchFiles(inputDir, false)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	analyze := func(ctx context.Context, path string) ([]datastore.Note, error) {
+		cancel()
+		return nil, ctx.Err()
+	}
+
+	state := newBatchState(statePath)
+	_, err = runBatch(ctx, files, state, resultsDir, []batchAnalyzeFunc{analyze})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, state.Files, "interrupted files are not recorded")
+	assert.Empty(t, state.inProgress)
+}
+
+// failingNoteSaver records saved notes and fails for notes of one file
+type failingNoteSaver struct {
+	saved    []datastore.Note
+	failFile string
+}
+
+func (f *failingNoteSaver) SaveNotes(notes []datastore.Note) error {
+	for i := range notes {
+		if filepath.Base(notes[i].Source.ID) == f.failFile {
+			return assert.AnError
+		}
+	}
+	f.saved = append(f.saved, notes...)
+	return nil
+}
+
+func TestStoreBatchFiles_Resume(t *testing.T) {
+	inputDir := t.TempDir()
+	resultsDir := t.TempDir()
+	statePath := filepath.Join(t.TempDir(), batchStateFileName)
+	createBatchFiles(t, inputDir, map[string]string{"a.wav": "a", "b.wav": "b"})
+
+	files, err := findBatchFiles(inputDir, false)
+	require.NoError(t, err)
+	state := newBatchState(statePath)
+	_, err = runBatch(context.Background(), files, state, resultsDir, []batchAnalyzeFunc{(&fakeBatchAnalyzer{}).analyze})
+	require.NoError(t, err)
+
+	settings := &conf.Settings{}
+	saver := &failingNoteSaver{failFile: "b.wav"}
+	_, err = storeBatchFiles(settings, saver, state, resultsDir)
+	require.Error(t, err)
+	require.Len(t, saver.saved, 1)
+	assert.Equal(t, filepath.Join(inputDir, "a.wav"), saver.saved[0].Source.ID)
+
+	// A resumed run only stores the file that failed
+	saver.failFile = ""
+	stored, err := storeBatchFiles(settings, saver, state, resultsDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored)
+	require.Len(t, saver.saved, 2)
+	assert.Equal(t, filepath.Join(inputDir, "b.wav"), saver.saved[1].Source.ID)
+}
+
+func TestBatchState_CachedHash(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.wav")
+	require.NoError(t, os.WriteFile(path, []byte("audio"), 0o600))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	state := newBatchState(filepath.Join(dir, batchStateFileName))
+	hash, err := hashFile(path)
+	require.NoError(t, err)
+	require.NoError(t, state.complete(hash, &BatchFileState{Path: path, Size: info.Size(), ModTime: info.ModTime(), Status: batchStatusDone}))
+
+	cached, ok := state.cachedHash(path, info)
+	assert.True(t, ok)
+	assert.Equal(t, hash, cached)
+
+	// A modified file is hashed again
+	require.NoError(t, os.WriteFile(path, []byte("changed audio"), 0o600))
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	_, ok = state.cachedHash(path, info)
+	assert.False(t, ok)
+}
+
+func TestNormalizeBatchOptions(t *testing.T) {
+	opts := &BatchOptions{}
+	require.NoError(t, normalizeBatchOptions(opts))
+	assert.Equal(t, ".", opts.OutputDir)
+	assert.Equal(t, []string{BatchFormatCSV, BatchFormatJSONL}, opts.Formats)
+
+	opts = &BatchOptions{Formats: []string{" CSV "}}
+	require.NoError(t, normalizeBatchOptions(opts))
+	assert.Equal(t, []string{BatchFormatCSV}, opts.Formats)
+
+	assert.Error(t, normalizeBatchOptions(&BatchOptions{Formats: []string{"xml"}}))
+}
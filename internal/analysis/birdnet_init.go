@@ -11,6 +11,8 @@ import (
 
 var bn *birdnet.BirdNET // BirdNET interpreter
 
+var models *birdnet.ModelRegistry // Models used for realtime analysis, including BirdNET
+
 // modelNameBirdNET is the model name used for metrics tracking
 const modelNameBirdNET = "birdnet"
 
@@ -29,6 +31,8 @@ func initializeBirdNET(settings *conf.Settings) error {
 			return fmt.Errorf("failed to initialize BirdNET: %w", err)
 		}
 		
+		models = birdnet.NewModelRegistry(bn)
+
 		// Initialize float32 pool for audio conversion
 		if err := myaudio.InitFloat32Pool(); err != nil {
 			return fmt.Errorf("failed to initialize float32 pool: %w", err)
@@ -37,6 +41,15 @@ func initializeBirdNET(settings *conf.Settings) error {
 	return nil
 }
 
+// initializeAdditionalModels loads the additional classifier models configured to
+// run alongside BirdNET. initializeBirdNET must be called first.
+func initializeAdditionalModels(settings *conf.Settings) error {
+	if err := models.LoadAdditionalModels(settings); err != nil {
+		return fmt.Errorf("failed to initialize additional models: %w", err)
+	}
+	return nil
+}
+
 // UpdateBirdNETModelLoadedMetric updates the model loaded metric status.
 // This should be called after metrics are initialized to report model status.
 // 
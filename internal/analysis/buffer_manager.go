@@ -14,7 +14,7 @@ import (
 // BufferManager handles the lifecycle of analysis buffer monitors
 type BufferManager struct {
 	monitors sync.Map
-	bn       *birdnet.BirdNET
+	models   *birdnet.ModelRegistry
 	quitChan chan struct{}
 	wg       *sync.WaitGroup
 	logger   *slog.Logger
@@ -26,16 +26,16 @@ type BufferManager struct {
 // if any required parameter is nil, following project guidelines.
 //
 // Parameters:
-//   - bn: BirdNET instance for audio analysis
+//   - models: Registry of the models used for audio analysis
 //   - quitChan: Channel for coordinated shutdown signaling
 //   - wg: WaitGroup for goroutine lifecycle management
 //
 // Returns:
 //   - *BufferManager: New buffer manager instance
 //   - error: Validation error if any parameter is nil
-func NewBufferManager(bn *birdnet.BirdNET, quitChan chan struct{}, wg *sync.WaitGroup) (*BufferManager, error) {
+func NewBufferManager(models *birdnet.ModelRegistry, quitChan chan struct{}, wg *sync.WaitGroup) (*BufferManager, error) {
 	// Validate required parameters
-	if bn == nil {
+	if models == nil || models.Primary() == nil {
 		return nil, errors.Newf("BirdNET instance cannot be nil").
 			Component("analysis.buffer").
 			Category(errors.CategoryValidation).
@@ -58,7 +58,7 @@ func NewBufferManager(bn *birdnet.BirdNET, quitChan chan struct{}, wg *sync.Wait
 	}
 	
 	return &BufferManager{
-		bn:       bn,
+		models:   models,
 		quitChan: quitChan,
 		wg:       wg,
 		logger:   GetLogger(),
@@ -72,7 +72,7 @@ func NewBufferManager(bn *birdnet.BirdNET, quitChan chan struct{}, wg *sync.Wait
 // and panics if validation fails.
 //
 // Parameters:
-//   - bn: BirdNET instance for audio analysis
+//   - models: Registry of the models used for audio analysis
 //   - quitChan: Channel for coordinated shutdown signaling
 //   - wg: WaitGroup for goroutine lifecycle management
 //
@@ -81,8 +81,8 @@ func NewBufferManager(bn *birdnet.BirdNET, quitChan chan struct{}, wg *sync.Wait
 //
 // Panics:
 //   - If any parameter validation fails
-func MustNewBufferManager(bn *birdnet.BirdNET, quitChan chan struct{}, wg *sync.WaitGroup) *BufferManager {
-	bm, err := NewBufferManager(bn, quitChan, wg)
+func MustNewBufferManager(models *birdnet.ModelRegistry, quitChan chan struct{}, wg *sync.WaitGroup) *BufferManager {
+	bm, err := NewBufferManager(models, quitChan, wg)
 	if err != nil {
 		panic(fmt.Sprintf("MustNewBufferManager: %v", err))
 	}
@@ -102,7 +102,7 @@ func (m *BufferManager) AddMonitor(source string) error {
 	}
 
 	// Check if BirdNET instance is available
-	if m.bn == nil {
+	if m.models == nil {
 		return errors.Newf("BirdNET instance not initialized").
 			Component("analysis.buffer").
 			Category(errors.CategoryBuffer).
@@ -155,7 +155,7 @@ func (m *BufferManager) AddMonitor(source string) error {
 		}()
 		
 		// Run the monitor
-		myaudio.AnalysisBufferMonitor(m.wg, m.bn, monitorQuit, source)
+		myaudio.AnalysisBufferMonitor(m.wg, m.models, monitorQuit, source)
 	}()
 
 	return nil
@@ -42,6 +42,9 @@ type ControlMonitor struct {
 
 	// Sound level manager for lifecycle management
 	soundLevelManager *SoundLevelManager
+
+	// Commands received on the MQTT command topic
+	mqttCommands *MQTTCommandHandler
 	
 	// Track telemetry endpoint
 	telemetryEndpoint      *observability.Endpoint
@@ -65,6 +68,7 @@ func NewControlMonitor(wg *sync.WaitGroup, controlChan chan string, quitChan, re
 		proc:                   proc,
 		bn:                     proc.Bn,
 		metrics:                metrics,
+		mqttCommands:           NewMQTTCommandHandler(controlChan),
 	}
 	
 	// Initialize the sound level manager but don't start it yet
@@ -79,12 +83,22 @@ func (cm *ControlMonitor) Start() {
 	
 	// Initialize sound level monitoring if enabled
 	cm.initializeSoundLevelIfEnabled()
+
+	// Accept commands over MQTT if enabled
+	if cm.proc != nil {
+		cm.subscribeMQTTCommands(cm.proc.GetMQTTClient())
+	}
 	
 	go cm.monitor()
 }
 
 // Stop stops the control monitor and cleans up resources
 func (cm *ControlMonitor) Stop() {
+	// Stop accepting MQTT commands before the control channel is closed
+	if cm.mqttCommands != nil {
+		cm.mqttCommands.Stop()
+	}
+
 	// Stop sound level monitoring if running
 	if cm.soundLevelManager != nil {
 		cm.soundLevelManager.Stop()
@@ -249,21 +263,31 @@ func (cm *ControlMonitor) handleReconfigureMQTT() {
 	// First, safely disconnect any existing client
 	cm.proc.DisconnectMQTTClient()
 
+	// Additional brokers are enabled on their own, connection errors are reported in their status
+	cm.proc.ConnectMQTTBrokers(settings)
+
 	// If MQTT is enabled, initialize and connect
 	if settings.Realtime.MQTT.Enabled {
 		var err error
-		newClient, err := mqtt.NewClient(settings, cm.proc.Metrics)
+		newClient, err := cm.proc.NewMQTTClient(settings)
 		if err != nil {
 			log.Printf("\033[31m❌ Error creating MQTT client: %v\033[0m", err)
 			cm.notifyError("Failed to create MQTT client", err)
 			return
 		}
 
+		// The subscription is made once the client connects
+		cm.subscribeMQTTCommands(newClient)
+
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		if err := newClient.Connect(ctx); err != nil {
 			cancel()
 			log.Printf("\033[31m❌ Error connecting to MQTT broker: %v\033[0m", err)
 			cm.notifyError("Failed to connect to MQTT broker", err)
+			// With a spool the client keeps reconnecting and queues messages meanwhile
+			if cm.proc.MQTTSpool() != nil {
+				cm.proc.SetMQTTClient(newClient)
+			}
 			return
 		}
 		cancel()
@@ -279,6 +303,19 @@ func (cm *ControlMonitor) handleReconfigureMQTT() {
 	}
 }
 
+// subscribeMQTTCommands subscribes the client to the MQTT command topic if commands are enabled
+func (cm *ControlMonitor) subscribeMQTTCommands(client mqtt.Client) {
+	if client == nil || cm.mqttCommands == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := cm.mqttCommands.Subscribe(ctx, client); err != nil {
+		log.Printf("\033[31m❌ Error subscribing to MQTT command topic: %v\033[0m", err)
+		cm.notifyError("Failed to subscribe to MQTT command topic", err)
+	}
+}
+
 // handleReconfigureRTSP reconfigures RTSP sources
 func (cm *ControlMonitor) handleReconfigureRTSP() {
 	log.Printf("\033[32m🔄 Reconfiguring RTSP sources...\033[0m")
@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/observation"
 )
 
 // cleanupProcessingFiles removes all .processing files from the output directory
@@ -46,6 +48,8 @@ func isProcessed(path, outputPath string, processedFiles map[string]bool) bool {
 	// Check for output files
 	outputPathCSV := filepath.Join(outputPath, baseName+".csv")
 	outputPathTable := filepath.Join(outputPath, baseName+".txt")
+	outputPathRaven := filepath.Join(outputPath, baseName+observation.RavenFileSuffix)
+	outputPathAudacity := filepath.Join(outputPath, baseName+observation.AudacityFileSuffix)
 	outputPathProcessing := filepath.Join(outputPath, baseName+".processing")
 
 	// Check if any of the output files exist
@@ -57,6 +61,14 @@ func isProcessed(path, outputPath string, processedFiles map[string]bool) bool {
 		processedFiles[path] = true
 		return true
 	}
+	if _, err := os.Stat(outputPathRaven); err == nil {
+		processedFiles[path] = true
+		return true
+	}
+	if _, err := os.Stat(outputPathAudacity); err == nil {
+		processedFiles[path] = true
+		return true
+	}
 
 	// Check for processing lock file
 	if info, err := os.Stat(outputPathProcessing); err == nil {
@@ -198,12 +210,8 @@ func processFile(path string, settings *conf.Settings, processedFiles map[string
 
 	// Create processing lock file
 	outputPath := filepath.Join(settings.Output.File.Path, filepath.Base(path))
-	ext := strings.ToLower(filepath.Ext(outputPath))
-	switch ext {
-	case ".wav":
-		outputPath = outputPath[:len(outputPath)-4]
-	case ".flac":
-		outputPath = outputPath[:len(outputPath)-5]
+	if myaudio.IsSupportedAudioFile(outputPath) {
+		outputPath = strings.TrimSuffix(outputPath, filepath.Ext(outputPath))
 	}
 	lockFile := outputPath + ".processing"
 
@@ -287,9 +295,8 @@ func scanDirectory(watchDir string, settings *conf.Settings, processedFiles map[
 			return nil
 		}
 
-		// Check for both .wav and .flac files (case-insensitive)
-		ext := strings.ToLower(filepath.Ext(d.Name()))
-		if ext == ".wav" || ext == ".flac" {
+		// Check for supported audio files (case-insensitive)
+		if myaudio.IsSupportedAudioFile(d.Name()) {
 			wasProcessed, err := processFile(path, settings, processedFiles, ctx)
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
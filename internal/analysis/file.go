@@ -7,7 +7,6 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -118,8 +117,7 @@ func validateAudioFile(filePath string) error {
 	}
 
 	// Check file extension (case-insensitive)
-	ext := strings.ToLower(filepath.Ext(filePath))
-	if ext != ".wav" && ext != ".flac" {
+	if !myaudio.IsSupportedAudioFile(filePath) {
 		return fmt.Errorf("\033[31m❌ Invalid audio file %s: unsupported audio format: %s\033[0m", filepath.Base(filePath), filepath.Ext(filePath))
 	}
 
@@ -524,9 +522,12 @@ func processAudioData(
 	// Initialize filePosition before the loop
 	filePosition := time.Time{}
 
+	// Each chunk advances the file position by the chunk length minus the overlap
+	step := time.Duration((3.0 - settings.BirdNET.Overlap) * float64(time.Second))
+
 	// Read and send audio chunks with timing information
 	return myaudio.ReadAudioFileBuffered(settings, func(chunkData []float32, isEOF bool) error {
-		return handleAudioChunk(
+		err := handleAudioChunk(
 			ctx,
 			chunkData,
 			isEOF,
@@ -534,6 +535,10 @@ func processAudioData(
 			channels,
 			errHolder,
 		)
+		if len(chunkData) > 0 {
+			filePosition = filePosition.Add(step)
+		}
+		return err
 	})
 }
 
@@ -679,5 +684,17 @@ func writeResults(settings *conf.Settings, notes []datastore.Note) error {
 			return fmt.Errorf("failed to write notes CSV: %w", err)
 		}
 	}
+	// If OutputType is set to "raven", output as Raven Pro selection table.
+	if settings.Output.File.Type == "raven" {
+		if err := observation.WriteNotesRaven(settings, notes, outputFile); err != nil {
+			return fmt.Errorf("failed to write Raven selection table: %w", err)
+		}
+	}
+	// If OutputType is set to "audacity", output as Audacity label track.
+	if settings.Output.File.Type == "audacity" {
+		if err := observation.WriteNotesAudacity(settings, notes, outputFile); err != nil {
+			return fmt.Errorf("failed to write Audacity labels: %w", err)
+		}
+	}
 	return nil
 }
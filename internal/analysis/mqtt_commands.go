@@ -0,0 +1,284 @@
+// mqtt_commands.go: Remote control of the station over MQTT
+package analysis
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/tphakala/birdnet-go/internal/api/v2"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// MQTT command actions in addition to the control actions of /api/v2/control
+const (
+	MQTTActionToggleSource = "toggle_source" // enable or disable an RTSP source
+	MQTTActionSetSpecies   = "set_species"   // replace the species include and exclude lists
+)
+
+// mqttCommandTimeout limits the time a command waits for the control monitor
+const mqttCommandTimeout = 10 * time.Second
+
+// signalReconfigureRTSP is the control signal sent by the settings API after RTSP changes
+const signalReconfigureRTSP = "reconfigure_rtsp_sources"
+
+// MQTTCommand is a control message received on the command topic
+type MQTTCommand struct {
+	ID     string `json:"id,omitempty"` // optional, copied to the response to match replies
+	Token  string `json:"token"`        // must match realtime.mqtt.commands.token
+	Action string `json:"action"`
+
+	// toggle_source: the RTSP URL, or the source ID of a configured stream when disabling
+	Source  string `json:"source,omitempty"`
+	Enabled *bool  `json:"enabled,omitempty"`
+
+	// set_species: lists that are omitted are left unchanged
+	Include *[]string `json:"include,omitempty"`
+	Exclude *[]string `json:"exclude,omitempty"`
+}
+
+// MQTTCommandResponse is published on the response topic for every command
+type MQTTCommandResponse struct {
+	ID string `json:"id,omitempty"`
+	api.ControlResult
+}
+
+// MQTTCommandTopic returns the command topic of the settings
+func MQTTCommandTopic(settings *conf.MQTTSettings) string {
+	if settings.Commands.Topic != "" {
+		return settings.Commands.Topic
+	}
+	return strings.TrimSuffix(settings.Topic, "/") + "/command"
+}
+
+// MQTTResponseTopic returns the topic the command responses are published to
+func MQTTResponseTopic(settings *conf.MQTTSettings) string {
+	return MQTTCommandTopic(settings) + "/response"
+}
+
+// MQTTCommandHandler executes the commands received on the MQTT command topic. The
+// control actions send the same signals as the /api/v2/control endpoints, source and
+// species changes are saved to the settings like the settings API does.
+type MQTTCommandHandler struct {
+	controlChan chan string
+	settings    func() *conf.Settings
+	save        func() error
+
+	mu      sync.RWMutex // held for reading while sending control signals
+	stopped bool
+
+	settingsMu sync.Mutex // serializes settings changes
+}
+
+// NewMQTTCommandHandler creates a handler sending control signals to controlChan
+func NewMQTTCommandHandler(controlChan chan string) *MQTTCommandHandler {
+	return &MQTTCommandHandler{
+		controlChan: controlChan,
+		settings:    conf.GetSettings,
+		save:        conf.SaveSettings,
+	}
+}
+
+// Subscribe subscribes the client to the command topic if commands are enabled
+func (h *MQTTCommandHandler) Subscribe(ctx context.Context, client mqtt.Client) error {
+	settings := h.settings()
+	if client == nil || settings == nil || !settings.Realtime.MQTT.Commands.Enabled {
+		return nil
+	}
+	topic := MQTTCommandTopic(&settings.Realtime.MQTT)
+	return client.Subscribe(ctx, topic, 1, func(_ string, payload []byte) {
+		h.Handle(client, payload)
+	})
+}
+
+// Stop rejects all further commands, the control channel may be closed afterwards
+func (h *MQTTCommandHandler) Stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopped = true
+}
+
+// Handle executes a command and publishes the result on the response topic
+func (h *MQTTCommandHandler) Handle(client mqtt.Client, payload []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), mqttCommandTimeout)
+	defer cancel()
+
+	settings := h.settings()
+	if settings == nil {
+		return
+	}
+	logger := GetLogger()
+
+	var cmd MQTTCommand
+	var message string
+	err := json.Unmarshal(payload, &cmd)
+	switch {
+	case err != nil:
+		err = mqttCommandError(err, errors.CategoryValidation, "parse_command")
+	case !h.authorized(settings, cmd.Token):
+		err = mqttCommandError(errors.NewStd("invalid command token"), errors.CategoryMQTTAuth, "authorize_command")
+	default:
+		message, err = h.execute(ctx, &cmd)
+	}
+
+	response := MQTTCommandResponse{
+		ID: cmd.ID,
+		ControlResult: api.ControlResult{
+			Success:   err == nil,
+			Message:   message,
+			Action:    cmd.Action,
+			Timestamp: time.Now(),
+		},
+	}
+	if err != nil {
+		response.Message = err.Error()
+		logger.Warn("MQTT command failed", "action", cmd.Action, "error", err, "operation", "mqtt_command")
+	} else {
+		logger.Info("MQTT command executed", "action", cmd.Action, "operation", "mqtt_command")
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	topic := MQTTResponseTopic(&settings.Realtime.MQTT)
+	if err := client.PublishWithOptions(ctx, topic, string(data), mqtt.PublishOptions{QoS: 1}); err != nil {
+		logger.Warn("Failed to publish MQTT command response", "topic", topic, "error", err, "operation", "mqtt_command")
+	}
+}
+
+// authorized compares the command token with the configured token in constant time
+func (h *MQTTCommandHandler) authorized(settings *conf.Settings, token string) bool {
+	expected := settings.Realtime.MQTT.Commands.Token
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// execute runs an authorized command and returns the success message
+func (h *MQTTCommandHandler) execute(ctx context.Context, cmd *MQTTCommand) (string, error) {
+	switch cmd.Action {
+	case api.ActionRestartAnalysis:
+		return "Analysis restart signal sent", h.signal(ctx, api.SignalRestartAnalysis)
+	case api.ActionReloadModel:
+		return "Model reload signal sent", h.signal(ctx, api.SignalReloadModel)
+	case api.ActionRebuildFilter:
+		return "Filter rebuild signal sent", h.signal(ctx, api.SignalRebuildFilter)
+	case MQTTActionToggleSource:
+		return h.toggleSource(ctx, cmd)
+	case MQTTActionSetSpecies:
+		return h.setSpecies(ctx, cmd)
+	default:
+		return "", mqttCommandError(fmt.Errorf("unknown action %q", cmd.Action), errors.CategoryValidation, "execute_command")
+	}
+}
+
+// signal sends a control signal to the control monitor
+func (h *MQTTCommandHandler) signal(ctx context.Context, signal string) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.stopped {
+		return mqttCommandError(errors.NewStd("control interface not available"), errors.CategoryState, "send_control_signal")
+	}
+
+	select {
+	case h.controlChan <- signal:
+		return nil
+	case <-ctx.Done():
+		return mqttCommandError(ctx.Err(), errors.CategoryTimeout, "send_control_signal")
+	}
+}
+
+// toggleSource adds an RTSP URL to the configured streams or removes one from them
+func (h *MQTTCommandHandler) toggleSource(ctx context.Context, cmd *MQTTCommand) (string, error) {
+	if cmd.Source == "" || cmd.Enabled == nil {
+		return "", mqttCommandError(errors.NewStd("toggle_source requires source and enabled"), errors.CategoryValidation, "toggle_source")
+	}
+
+	url := cmd.Source
+	if registry := myaudio.GetRegistry(); registry != nil {
+		if source, ok := registry.GetSourceByID(cmd.Source); ok {
+			if conn, err := source.GetConnectionString(); err == nil {
+				url = conn
+			}
+		}
+	}
+	if *cmd.Enabled && !strings.Contains(url, "://") {
+		return "", mqttCommandError(errors.NewStd("an RTSP URL is required to enable a source"), errors.CategoryValidation, "toggle_source")
+	}
+
+	h.settingsMu.Lock()
+	settings := h.settings()
+	urls := settings.Realtime.RTSP.URLs
+	index := slices.Index(urls, url)
+	name := privacy.SanitizeRTSPUrl(url)
+	switch {
+	case *cmd.Enabled && index >= 0:
+		h.settingsMu.Unlock()
+		return "Audio source already enabled: " + name, nil
+	case !*cmd.Enabled && index < 0:
+		h.settingsMu.Unlock()
+		return "Audio source already disabled: " + name, nil
+	case *cmd.Enabled:
+		urls = append(slices.Clone(urls), url)
+	default:
+		urls = slices.Delete(slices.Clone(urls), index, index+1)
+	}
+	settings.Realtime.RTSP.URLs = urls
+	err := h.save()
+	h.settingsMu.Unlock()
+	if err != nil {
+		return "", mqttCommandError(err, errors.CategoryConfiguration, "save_settings")
+	}
+
+	if err := h.signal(ctx, signalReconfigureRTSP); err != nil {
+		return "", err
+	}
+	if *cmd.Enabled {
+		return "Audio source enabled: " + name, nil
+	}
+	return "Audio source disabled: " + name, nil
+}
+
+// setSpecies replaces the species include and exclude lists and rebuilds the range filter
+func (h *MQTTCommandHandler) setSpecies(ctx context.Context, cmd *MQTTCommand) (string, error) {
+	if cmd.Include == nil && cmd.Exclude == nil {
+		return "", mqttCommandError(errors.NewStd("set_species requires include or exclude"), errors.CategoryValidation, "set_species")
+	}
+
+	h.settingsMu.Lock()
+	settings := h.settings()
+	if cmd.Include != nil {
+		settings.Realtime.Species.Include = slices.Clone(*cmd.Include)
+	}
+	if cmd.Exclude != nil {
+		settings.Realtime.Species.Exclude = slices.Clone(*cmd.Exclude)
+	}
+	err := h.save()
+	h.settingsMu.Unlock()
+	if err != nil {
+		return "", mqttCommandError(err, errors.CategoryConfiguration, "save_settings")
+	}
+
+	if err := h.signal(ctx, api.SignalRebuildFilter); err != nil {
+		return "", err
+	}
+	return "Species lists updated, filter rebuild signal sent", nil
+}
+
+// mqttCommandError wraps an error of a command
+func mqttCommandError(err error, category errors.ErrorCategory, operation string) error {
+	return errors.New(err).
+		Component("analysis").
+		Category(category).
+		Context("operation", operation).
+		Context("integration", "mqtt").
+		Build()
+}
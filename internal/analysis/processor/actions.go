@@ -110,8 +110,11 @@ type BirdWeatherAction struct {
 	pcmData      []byte
 	BwClient     *birdweather.BwClient
 	EventTracker *EventTracker
-	RetryConfig  jobqueue.RetryConfig // Configuration for retry behavior
+	RetryConfig  jobqueue.RetryConfig         // Configuration for retry behavior
+	Outbox       *datastore.BirdweatherOutbox // Outbox of failed uploads, nil without a database
 	Description  string
+	attempts     int        // Failed upload attempts
+	tracked      bool       // true once the event passed the tracker, retries are not rate limited
 	mu           sync.Mutex // Protect concurrent access to Note and pcmData
 }
 
@@ -121,7 +124,9 @@ type MqttAction struct {
 	BirdImageCache *imageprovider.BirdImageCache
 	MqttClient     mqtt.Client
 	EventTracker   *EventTracker
-	RetryConfig    jobqueue.RetryConfig // Configuration for retry behavior
+	RetryConfig    jobqueue.RetryConfig   // Configuration for retry behavior
+	Templates      *mqtt.MessageTemplates // Topic and payload templates, nil for the full payload on the configured topic
+	Broker         *conf.MQTTSettings     // Settings of an additional broker, nil for the main broker
 	Description    string
 	mu             sync.Mutex // Protect concurrent access to Note
 }
@@ -214,6 +219,14 @@ func (a *BirdWeatherAction) GetDescription() string {
 	return "Upload detection to BirdWeather"
 }
 
+// mqttSettings returns the settings of the broker the action publishes to
+func (a *MqttAction) mqttSettings() *conf.MQTTSettings {
+	if a.Broker != nil {
+		return a.Broker
+	}
+	return &a.Settings.Realtime.MQTT
+}
+
 // GetDescription returns a human-readable description of the MqttAction
 func (a *MqttAction) GetDescription() string {
 	if a.Description != "" {
@@ -737,9 +750,12 @@ func (a *BirdWeatherAction) Execute(data interface{}) error {
 
 	species := strings.ToLower(a.Note.CommonName)
 
-	// Check event frequency
-	if !a.EventTracker.TrackEvent(species, BirdWeatherSubmit) {
-		return nil
+	// Check event frequency, a retry of a tracked event is always uploaded
+	if !a.tracked {
+		if !a.EventTracker.TrackEvent(species, BirdWeatherSubmit) {
+			return nil
+		}
+		a.tracked = true
 	}
 
 	// Early check if BirdWeather is still enabled in settings
@@ -797,9 +813,13 @@ func (a *BirdWeatherAction) Execute(data interface{}) error {
 			"clip_name", note.ClipName,
 			"retry_enabled", a.RetryConfig.Enabled,
 			"operation", "birdweather_upload")
-		if a.RetryConfig.Enabled {
+		a.attempts++
+		if a.RetryConfig.Enabled && a.attempts <= a.RetryConfig.MaxRetries {
 			log.Printf("❌ Error uploading %s (%s) to BirdWeather (confidence: %.2f, clip: %s) (will retry): %v\n",
 				note.CommonName, note.ScientificName, note.Confidence, note.ClipName, sanitizedErr)
+		} else if a.enqueue(&note, sanitizedErr) {
+			log.Printf("❌ Error uploading %s (%s) to BirdWeather (confidence: %.2f, clip: %s) (queued for replay): %v\n",
+				note.CommonName, note.ScientificName, note.Confidence, note.ClipName, sanitizedErr)
 		} else {
 			log.Printf("❌ Error uploading %s (%s) to BirdWeather (confidence: %.2f, clip: %s): %v\n",
 				note.CommonName, note.ScientificName, note.Confidence, note.ClipName, sanitizedErr)
@@ -823,6 +843,17 @@ func (a *BirdWeatherAction) Execute(data interface{}) error {
 			Build()
 	}
 
+	// Record the upload so that replays and backfills do not post the detection again
+	if a.Outbox != nil && a.Settings.Realtime.Birdweather.Outbox.Enabled {
+		if err := a.Outbox.MarkPosted(&note); err != nil {
+			GetLogger().Warn("Failed to record BirdWeather upload",
+				"component", "analysis.processor.actions",
+				"error", err,
+				"species", note.CommonName,
+				"operation", "birdweather_outbox_posted")
+		}
+	}
+
 	if a.Settings.Debug {
 		// Add structured logging
 		GetLogger().Debug("Successfully uploaded to BirdWeather",
@@ -837,6 +868,23 @@ func (a *BirdWeatherAction) Execute(data interface{}) error {
 	return nil
 }
 
+// enqueue queues a detection whose upload failed for the last time in the outbox, it
+// returns false if the detection could not be queued
+func (a *BirdWeatherAction) enqueue(note *datastore.Note, cause error) bool {
+	if a.Outbox == nil || !a.Settings.Realtime.Birdweather.Outbox.Enabled || note.ClipName == "" {
+		return false
+	}
+	if err := a.Outbox.Enqueue(note, cause); err != nil {
+		GetLogger().Error("Failed to queue BirdWeather upload for replay",
+			"component", "analysis.processor.actions",
+			"error", err,
+			"species", note.CommonName,
+			"operation", "birdweather_outbox_enqueue")
+		return false
+	}
+	return true
+}
+
 type NoteWithBirdImage struct {
 	datastore.Note
 	BirdImage imageprovider.BirdImage
@@ -848,7 +896,8 @@ func (a *MqttAction) Execute(data interface{}) error {
 	defer a.mu.Unlock()
 
 	// Rely on background reconnect; fail action if not currently connected.
-	if !a.MqttClient.IsConnected() {
+	// With the offline spool the client queues the message instead.
+	if !a.MqttClient.IsConnected() && !a.mqttSettings().Spool.Enabled {
 		// Log slightly differently to indicate it's waiting for background reconnect
 		// Add structured logging
 		GetLogger().Warn("MQTT client not connected, skipping publish",
@@ -881,7 +930,8 @@ func (a *MqttAction) Execute(data interface{}) error {
 	}
 
 	// Validate MQTT settings
-	if a.Settings.Realtime.MQTT.Topic == "" {
+	mqttSettings := a.mqttSettings()
+	if mqttSettings.Topic == "" && a.Templates == nil {
 		return errors.Newf("MQTT topic is not specified").
 			Component("analysis.processor").
 			Category(errors.CategoryConfiguration).
@@ -926,26 +976,60 @@ func (a *MqttAction) Execute(data interface{}) error {
 	// Wrap note with bird image (using copy)
 	noteWithBirdImage := NoteWithBirdImage{Note: noteCopy, BirdImage: birdImage}
 
-	// Create a JSON representation of the note
-	noteJson, err := json.Marshal(noteWithBirdImage)
-	if err != nil {
-		// Add structured logging
-		GetLogger().Error("Failed to marshal note to JSON",
-			"component", "analysis.processor.actions",
-			"error", err,
-			"species", a.Note.CommonName,
-			"scientific_name", a.Note.ScientificName,
-			"operation", "json_marshal")
-		log.Printf("❌ Error marshalling note to JSON")
-		return err
+	// Render the topic and payload, without templates the note is published on the configured topic
+	topic := mqttSettings.Topic
+	var payload string
+	if a.Templates != nil {
+		msg := NewMQTTDetectionMessage(a.Settings, &noteCopy)
+		var err error
+		if topic, err = a.Templates.Topic(msg); err == nil {
+			payload, err = a.Templates.Payload(msg, noteWithBirdImage)
+		}
+		if err != nil {
+			GetLogger().Error("Failed to render MQTT message templates",
+				"component", "analysis.processor.actions",
+				"error", err,
+				"species", a.Note.CommonName,
+				"operation", "mqtt_render_templates")
+			return errors.New(err).
+				Component("analysis.processor").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "mqtt_render_templates").
+				Context("integration", "mqtt").
+				Context("retryable", false). // Template errors do not go away on retry
+				Build()
+		}
+	} else {
+		// Create a JSON representation of the note
+		noteJson, err := json.Marshal(noteWithBirdImage)
+		if err != nil {
+			// Add structured logging
+			GetLogger().Error("Failed to marshal note to JSON",
+				"component", "analysis.processor.actions",
+				"error", err,
+				"species", a.Note.CommonName,
+				"scientific_name", a.Note.ScientificName,
+				"operation", "json_marshal")
+			log.Printf("❌ Error marshalling note to JSON")
+			return err
+		}
+		payload = string(noteJson)
 	}
 
 	// Create a context with timeout for publishing
 	ctx, cancel := context.WithTimeout(context.Background(), MQTTPublishTimeout)
 	defer cancel()
 
-	// Publish the note to the MQTT broker
-	err = a.MqttClient.Publish(ctx, a.Settings.Realtime.MQTT.Topic, string(noteJson))
+	// Publish the note to the MQTT broker, templates come with the configured QoS
+	var err error
+	if a.Templates != nil {
+		err = a.MqttClient.PublishWithOptions(ctx, topic, payload, mqtt.PublishOptions{
+			QoS:    byte(mqttSettings.QoS), // #nosec G115 -- validated to 0-2
+			Retain: mqttSettings.Retain,
+		})
+	} else {
+		err = a.MqttClient.Publish(ctx, topic, payload)
+	}
 	if err != nil {
 		// Log the error with retry information if retries are enabled
 		// Sanitize error before logging
@@ -958,15 +1042,15 @@ func (a *MqttAction) Execute(data interface{}) error {
 			"scientific_name", a.Note.ScientificName,
 			"confidence", a.Note.Confidence,
 			"clip_name", a.Note.ClipName,
-			"topic", a.Settings.Realtime.MQTT.Topic,
+			"topic", topic,
 			"retry_enabled", a.RetryConfig.Enabled,
 			"operation", "mqtt_publish")
 		if a.RetryConfig.Enabled {
 			log.Printf("❌ Error publishing %s (%s) to MQTT topic %s (confidence: %.2f, clip: %s) (will retry): %v\n",
-				a.Note.CommonName, a.Note.ScientificName, a.Settings.Realtime.MQTT.Topic, a.Note.Confidence, a.Note.ClipName, sanitizedErr)
+				a.Note.CommonName, a.Note.ScientificName, topic, a.Note.Confidence, a.Note.ClipName, sanitizedErr)
 		} else {
 			log.Printf("❌ Error publishing %s (%s) to MQTT topic %s (confidence: %.2f, clip: %s): %v\n",
-				a.Note.CommonName, a.Note.ScientificName, a.Settings.Realtime.MQTT.Topic, a.Note.Confidence, a.Note.ClipName, sanitizedErr)
+				a.Note.CommonName, a.Note.ScientificName, topic, a.Note.Confidence, a.Note.ClipName, sanitizedErr)
 			// Send notification for non-retryable failures
 			notification.NotifyIntegrationFailure("MQTT", err)
 		}
@@ -976,7 +1060,7 @@ func (a *MqttAction) Execute(data interface{}) error {
 			Context("operation", "mqtt_publish").
 			Context("species", a.Note.CommonName).
 			Context("confidence", a.Note.Confidence).
-			Context("topic", a.Settings.Realtime.MQTT.Topic).
+			Context("topic", topic).
 			Context("clip_name", a.Note.ClipName).
 			Context("integration", "mqtt").
 			Context("retryable", true). // MQTT publish failures are typically retryable
@@ -990,10 +1074,10 @@ func (a *MqttAction) Execute(data interface{}) error {
 			"species", a.Note.CommonName,
 			"scientific_name", a.Note.ScientificName,
 			"confidence", a.Note.Confidence,
-			"topic", a.Settings.Realtime.MQTT.Topic,
+			"topic", topic,
 			"operation", "mqtt_publish_success")
 		log.Printf("✅ Successfully published %s to MQTT topic %s\n",
-			a.Note.CommonName, a.Settings.Realtime.MQTT.Topic)
+			a.Note.CommonName, topic)
 	}
 	return nil
 }
@@ -29,6 +29,16 @@ func (m *MockMqttClient) Publish(ctx context.Context, topic, payload string) err
 	return args.Error(0)
 }
 
+func (m *MockMqttClient) PublishWithOptions(ctx context.Context, topic, payload string, opts mqtt.PublishOptions) error {
+	args := m.Called(ctx, topic, payload, opts)
+	return args.Error(0)
+}
+
+func (m *MockMqttClient) Subscribe(ctx context.Context, topic string, qos byte, handler mqtt.MessageHandler) error {
+	args := m.Called(ctx, topic, qos, handler)
+	return args.Error(0)
+}
+
 func (m *MockMqttClient) IsConnected() bool {
 	args := m.Called()
 	return args.Bool(0)
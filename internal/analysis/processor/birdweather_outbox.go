@@ -0,0 +1,249 @@
+// birdweather_outbox.go: replays BirdWeather uploads that failed from the saved clips
+package processor
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+const (
+	// maxBirdWeatherReplayDelay caps the delay between replay attempts of an upload
+	maxBirdWeatherReplayDelay = 24 * time.Hour
+
+	// birdWeatherSoundscapeSeconds is the soundscape length, detections are posted for
+	// three second soundscapes
+	birdWeatherSoundscapeSeconds = 3
+
+	// birdWeatherPostedRetention is how long posted uploads are kept to prevent posting
+	// a detection twice
+	birdWeatherPostedRetention = 30 * 24 * time.Hour
+)
+
+// birdWeatherPublisher uploads a detection with its soundscape, implemented by the BirdWeather client
+type birdWeatherPublisher interface {
+	Publish(note *datastore.Note, pcmData []byte) error
+}
+
+// BirdWeatherReplayer periodically replays the pending uploads of the BirdWeather outbox.
+// A run stops at the first failed upload, BirdWeather is most likely still unreachable then.
+type BirdWeatherReplayer struct {
+	settings *conf.Settings
+	outbox   *datastore.BirdweatherOutbox
+	client   func() birdWeatherPublisher // nil while BirdWeather is disabled
+
+	mu     sync.Mutex // Serializes replay runs
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewBirdWeatherReplayer creates a replayer uploading with the client returned by client
+func NewBirdWeatherReplayer(settings *conf.Settings, outbox *datastore.BirdweatherOutbox, client func() birdWeatherPublisher) *BirdWeatherReplayer {
+	return &BirdWeatherReplayer{
+		settings: settings,
+		outbox:   outbox,
+		client:   client,
+	}
+}
+
+// Start replays pending uploads at the configured interval until Stop is called
+func (r *BirdWeatherReplayer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			interval := time.Duration(r.settings.Realtime.Birdweather.Outbox.ReplayInterval) * time.Minute
+			if interval <= 0 {
+				interval = 5 * time.Minute
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+			if _, err := r.Replay(ctx); err != nil {
+				GetLogger().Warn("BirdWeather outbox replay failed",
+					"error", sanitizeError(err),
+					"operation", "birdweather_outbox_replay")
+			}
+			r.prune(time.Now())
+		}
+	}()
+}
+
+// prune deletes the posted uploads older than the retention period, the live uploads
+// record every posted detection
+func (r *BirdWeatherReplayer) prune(now time.Time) {
+	pruned, err := r.outbox.PrunePosted(now.Add(-birdWeatherPostedRetention))
+	if err != nil {
+		GetLogger().Warn("BirdWeather outbox prune failed",
+			"error", err,
+			"operation", "birdweather_outbox_prune")
+		return
+	}
+	if pruned > 0 {
+		GetLogger().Debug("Pruned posted BirdWeather uploads",
+			"pruned", pruned,
+			"operation", "birdweather_outbox_prune")
+	}
+}
+
+// Stop ends the periodic replay
+func (r *BirdWeatherReplayer) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+// Replay uploads the pending uploads that are due and returns how many were posted
+func (r *BirdWeatherReplayer) Replay(ctx context.Context) (int, error) {
+	settings := &r.settings.Realtime.Birdweather
+	if !settings.Enabled || !settings.Outbox.Enabled {
+		return 0, nil
+	}
+	client := r.client()
+	if client == nil {
+		return 0, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	uploads, err := r.outbox.Due(time.Now(), settings.Outbox.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for i := range uploads {
+		if ctx.Err() != nil {
+			break
+		}
+		upload := &uploads[i]
+
+		pcmData, err := r.readClip(ctx, upload)
+		if err != nil {
+			// A missing or unreadable clip does not come back, stop replaying the upload
+			GetLogger().Warn("BirdWeather replay clip unavailable",
+				"species", upload.CommonName,
+				"clip_name", upload.ClipName,
+				"error", err,
+				"operation", "birdweather_outbox_replay")
+			if err := r.outbox.Fail(upload.ID, err); err != nil {
+				return posted, err
+			}
+			continue
+		}
+
+		note := datastore.Note{
+			ID:             upload.NoteID,
+			Date:           upload.Date,
+			Time:           upload.Time,
+			CommonName:     upload.CommonName,
+			ScientificName: upload.ScientificName,
+			Confidence:     upload.Confidence,
+			ClipName:       upload.ClipName,
+		}
+		if err := client.Publish(&note, pcmData); err != nil {
+			return posted, r.retryLater(upload, err)
+		}
+		if err := r.outbox.Posted(upload.ID); err != nil {
+			return posted, err
+		}
+		posted++
+	}
+
+	if posted > 0 {
+		GetLogger().Info("Replayed BirdWeather uploads",
+			"posted", posted,
+			"operation", "birdweather_outbox_replay")
+	}
+	return posted, nil
+}
+
+// readClip returns the detection segment of the saved clip of an upload
+func (r *BirdWeatherReplayer) readClip(ctx context.Context, upload *datastore.BirdweatherUpload) ([]byte, error) {
+	if upload.ClipName == "" {
+		return nil, errors.Newf("detection has no saved audio clip").
+			Component("analysis.processor").
+			Category(errors.CategoryNotFound).
+			Context("operation", "birdweather_outbox_read_clip").
+			Build()
+	}
+	path := filepath.Join(r.settings.Realtime.Audio.Export.Path, upload.ClipName)
+	pcmData, err := myaudio.ReadAudioFilePCM(ctx, path, r.settings.Realtime.Audio.FfmpegPath)
+	if err != nil {
+		return nil, err
+	}
+	// Clips start with the analyzed chunk of the detection
+	segment := birdWeatherSoundscapeSeconds * conf.SampleRate * conf.NumChannels * conf.BitDepth / 8
+	if len(pcmData) > segment {
+		pcmData = pcmData[:segment]
+	}
+	return pcmData, nil
+}
+
+// retryLater reschedules an upload with exponential backoff, or marks it as failed after
+// the maximum number of attempts
+func (r *BirdWeatherReplayer) retryLater(upload *datastore.BirdweatherUpload, cause error) error {
+	outbox := &r.settings.Realtime.Birdweather.Outbox
+	GetLogger().Warn("BirdWeather replay upload failed",
+		"species", upload.CommonName,
+		"attempt", upload.Attempts+1,
+		"max_attempts", outbox.MaxAttempts,
+		"error", sanitizeError(cause),
+		"operation", "birdweather_outbox_replay")
+
+	if upload.Attempts+1 >= outbox.MaxAttempts {
+		return r.outbox.Fail(upload.ID, cause)
+	}
+	delay := time.Duration(outbox.ReplayInterval) * time.Minute << min(upload.Attempts, 16)
+	if delay <= 0 || delay > maxBirdWeatherReplayDelay {
+		delay = maxBirdWeatherReplayDelay
+	}
+	return r.outbox.Reschedule(upload.ID, cause, time.Now().Add(delay))
+}
+
+// initBirdWeatherOutbox creates the BirdWeather outbox on the database of the datastore and
+// starts replaying it. The replayer follows the BirdWeather settings, so it also runs while
+// BirdWeather is disabled.
+func (p *Processor) initBirdWeatherOutbox(ds datastore.Interface) {
+	outbox, err := datastore.NewBirdweatherOutboxFor(ds)
+	if err != nil {
+		GetLogger().Debug("BirdWeather outbox unavailable",
+			"error", err,
+			"operation", "birdweather_outbox_init")
+		return
+	}
+	p.bwOutbox = outbox
+	p.bwReplayer = NewBirdWeatherReplayer(p.Settings, outbox, func() birdWeatherPublisher {
+		if client := p.GetBwClient(); client != nil {
+			return client
+		}
+		return nil
+	})
+	p.bwReplayer.Start()
+}
+
+// BirdWeatherOutbox returns the outbox of BirdWeather uploads, nil without a database
+func (p *Processor) BirdWeatherOutbox() *datastore.BirdweatherOutbox {
+	return p.bwOutbox
+}
+
+// ReplayBirdWeatherOutbox replays the due BirdWeather uploads now and returns how many were posted
+func (p *Processor) ReplayBirdWeatherOutbox(ctx context.Context) (int, error) {
+	if p.bwReplayer == nil {
+		return 0, nil
+	}
+	return p.bwReplayer.Replay(ctx)
+}
@@ -0,0 +1,101 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeBirdWeatherPublisher records published detections and fails while err is set
+type fakeBirdWeatherPublisher struct {
+	err       error
+	published []string
+	pcmLen    int
+}
+
+func (f *fakeBirdWeatherPublisher) Publish(note *datastore.Note, pcmData []byte) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.published = append(f.published, note.ScientificName)
+	f.pcmLen = len(pcmData)
+	return nil
+}
+
+// newTestBirdWeatherOutbox returns an outbox on a temporary database and settings with
+// the clip export path
+func newTestBirdWeatherOutbox(t *testing.T) (*datastore.BirdweatherOutbox, *conf.Settings) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "outbox.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&datastore.Note{}, &datastore.BirdweatherUpload{}))
+
+	settings := &conf.Settings{}
+	settings.Realtime.Audio.Export.Path = dir
+	settings.Realtime.Birdweather.Enabled = true
+	settings.Realtime.Birdweather.Outbox = conf.BirdweatherOutboxSettings{Enabled: true, ReplayInterval: 5, BatchSize: 10, MaxAttempts: 2}
+	return datastore.NewBirdweatherOutbox(db), settings
+}
+
+// writeTestClip writes a 15 second WAV clip to the export path
+func writeTestClip(t *testing.T, settings *conf.Settings, name string) {
+	t.Helper()
+	wav, err := myaudio.EncodePCMtoWAVWithContext(context.Background(), make([]byte, 15*conf.SampleRate*2))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(settings.Realtime.Audio.Export.Path, name), wav.Bytes(), 0o600))
+}
+
+func TestBirdWeatherReplayer_Replay(t *testing.T) {
+	t.Parallel()
+
+	outbox, settings := newTestBirdWeatherOutbox(t)
+	writeTestClip(t, settings, "owl.wav")
+	owl := &datastore.Note{Date: "2024-05-01", Time: "06:30:00", ScientificName: "Bubo bubo", CommonName: "Eurasian Eagle-Owl", ClipName: "owl.wav"}
+	tit := &datastore.Note{Date: "2024-05-01", Time: "06:31:00", ScientificName: "Parus major", CommonName: "Great Tit", ClipName: "missing.wav"}
+	require.NoError(t, outbox.Enqueue(owl, fmt.Errorf("offline")))
+	require.NoError(t, outbox.Enqueue(tit, fmt.Errorf("offline")))
+
+	client := &fakeBirdWeatherPublisher{err: fmt.Errorf("connection refused")}
+	replayer := NewBirdWeatherReplayer(settings, outbox, func() birdWeatherPublisher { return client })
+
+	// While BirdWeather is unreachable the upload is rescheduled and the run stops
+	posted, err := replayer.Replay(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, posted)
+	due, err := outbox.Due(time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1, "the owl is rescheduled, the tit was not tried")
+	assert.Equal(t, "Parus major", due[0].ScientificName)
+
+	// The owl is replayed with the detection segment of its clip, the tit without a clip fails
+	client.err = nil
+	require.NoError(t, outbox.Enqueue(owl, fmt.Errorf("offline")))
+	posted, err = replayer.Replay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, posted)
+	assert.Equal(t, []string{"Bubo bubo"}, client.published)
+	assert.Equal(t, 3*conf.SampleRate*2, client.pcmLen)
+
+	counts, err := outbox.Counts()
+	require.NoError(t, err)
+	assert.Equal(t, datastore.BirdweatherOutboxCounts{Posted: 1, Failed: 1}, counts)
+
+	// Nothing is replayed while the outbox is disabled
+	settings.Realtime.Birdweather.Outbox.Enabled = false
+	require.NoError(t, outbox.Enqueue(tit, nil))
+	posted, err = replayer.Replay(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, posted)
+}
@@ -0,0 +1,91 @@
+// calibration.go: raises species thresholds from the precision of reviewed detections
+package processor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/calibration"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// ThresholdCalibrator periodically raises species thresholds to the thresholds suggested
+// by the reviewed detections while calibration auto apply is enabled
+type ThresholdCalibrator struct {
+	settings *conf.Settings
+	ds       datastore.Interface
+	save     func() error // Persists the settings, conf.SaveSettings by default
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewThresholdCalibrator creates a calibrator of the species thresholds in settings
+func NewThresholdCalibrator(settings *conf.Settings, ds datastore.Interface) *ThresholdCalibrator {
+	return &ThresholdCalibrator{
+		settings: settings,
+		ds:       ds,
+		save:     conf.SaveSettings,
+	}
+}
+
+// Start calibrates the thresholds at the configured interval until Stop is called
+func (c *ThresholdCalibrator) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			interval := time.Duration(c.settings.Realtime.Calibration.Interval) * time.Hour
+			if interval <= 0 {
+				interval = 24 * time.Hour
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+			if !c.settings.Realtime.Calibration.AutoApply {
+				continue
+			}
+			if _, err := c.Calibrate(ctx); err != nil {
+				GetLogger().Warn("Species threshold calibration failed",
+					"error", err,
+					"operation", "threshold_calibration")
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic calibration
+func (c *ThresholdCalibrator) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+}
+
+// Calibrate raises the species thresholds to the suggested thresholds and saves the settings
+func (c *ThresholdCalibrator) Calibrate(ctx context.Context) ([]calibration.Change, error) {
+	species, err := calibration.Load(ctx, c.ds, calibration.OptionsFromSettings(c.settings))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := calibration.Apply(c.settings, species)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	for _, change := range changes {
+		GetLogger().Info("Raised species threshold from reviewed detections",
+			"species", change.CommonName,
+			"from", change.From,
+			"to", change.To,
+			"operation", "threshold_calibration")
+	}
+	return changes, c.save()
+}
@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/calibration"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestThresholdCalibrator_Calibrate(t *testing.T) {
+	t.Parallel()
+
+	storeSettings := &conf.Settings{}
+	storeSettings.Output.SQLite.Enabled = true
+	storeSettings.Output.SQLite.Path = filepath.Join(t.TempDir(), "birdnet.db")
+	store := &datastore.SQLiteStore{Settings: storeSettings}
+	require.NoError(t, store.Open())
+	t.Cleanup(func() { _ = store.Close() })
+
+	// Wren false positives stop at 0.85, unreviewed detections are ignored
+	for i, review := range []struct {
+		confidence float64
+		verified   string
+	}{
+		{0.80, "false_positive"}, {0.82, "false_positive"}, {0.85, "false_positive"},
+		{0.81, "correct"}, {0.88, "correct"}, {0.9, "correct"}, {0.93, "correct"}, {0.96, "correct"},
+		{0.7, ""},
+	} {
+		note := datastore.Note{
+			Date:           "2024-05-01",
+			Time:           fmt.Sprintf("05:%02d:00", i),
+			CommonName:     "Eurasian Wren",
+			ScientificName: "Troglodytes troglodytes",
+			Confidence:     review.confidence,
+		}
+		require.NoError(t, store.Save(&note, nil))
+		if review.verified != "" {
+			require.NoError(t, store.SaveNoteReview(&datastore.NoteReview{NoteID: note.ID, Verified: review.verified}))
+		}
+	}
+
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.8
+	settings.Realtime.Calibration = conf.CalibrationSettings{TargetPrecision: 0.9, MinReviews: 5, MaxThreshold: 0.95}
+
+	saved := 0
+	calibrator := NewThresholdCalibrator(settings, store)
+	calibrator.save = func() error {
+		saved++
+		return nil
+	}
+
+	changes, err := calibrator.Calibrate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []calibration.Change{{CommonName: "Eurasian Wren", From: 0.8, To: 0.86}}, changes)
+	assert.InDelta(t, 0.86, settings.Realtime.Species.Config["eurasian wren"].Threshold, 1e-9)
+	assert.Equal(t, 1, saved)
+
+	// The raised threshold reaches the target precision, nothing changes on the next run
+	changes, err = calibrator.Calibrate(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Equal(t, 1, saved)
+}
@@ -19,6 +19,7 @@ const (
 	BirdWeatherSubmit                  // Represents a bird weather submit event
 	MQTTPublish                        // Represents an MQTT publish event
 	SSEBroadcast                       // Represents a Server-Sent Events broadcast
+	WebhookPost                        // Represents a webhook post event
 )
 
 // EventBehaviorFunc defines the signature for functions that determine the behavior of an event.
@@ -113,6 +114,7 @@ func initEventTracker(interval time.Duration, speciesConfigs map[string]conf.Spe
 			BirdWeatherSubmit: NewEventHandler(interval, StandardEventBehavior),
 			MQTTPublish:       NewEventHandler(interval, StandardEventBehavior),
 			SSEBroadcast:      NewEventHandler(interval, StandardEventBehavior),
+			WebhookPost:       NewEventHandler(interval, StandardEventBehavior),
 		},
 		SpeciesConfigs: normalizedSpeciesConfigs, // Always initialized, even if empty
 	}
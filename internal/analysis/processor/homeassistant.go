@@ -0,0 +1,347 @@
+// homeassistant.go: Home Assistant MQTT discovery and state publishing for the processor
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// homeAssistantRefreshInterval is the interval of connection checks and audio source health updates
+const homeAssistantRefreshInterval = 30 * time.Second
+
+// homeAssistantPublishOptions are used for all state and discovery messages, retained
+// messages let Home Assistant restore the sensors after a restart
+var homeAssistantPublishOptions = mqtt.PublishOptions{QoS: 1, Retain: true}
+
+// HomeAssistantPublisher publishes Home Assistant discovery messages and keeps the
+// station state topics up to date: the last detection with today's detection and species
+// counts, one topic per detected species, sound levels and audio source health.
+type HomeAssistantPublisher struct {
+	settings     *conf.Settings
+	ds           datastore.Interface
+	client       func() mqtt.Client
+	sourceHealth func() map[string]myaudio.StreamHealth
+
+	mu         sync.Mutex
+	announced  mqtt.Client // client the discovery messages were published with
+	day        string      // date of the daily counts
+	detections int
+	species    map[string]*mqtt.HASpeciesState // today's species by scientific name
+	last       mqtt.HAStationState
+	soundLevel map[string]bool // sources with a published sound level sensor
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewHomeAssistantPublisher creates a publisher using the MQTT client returned by client
+func NewHomeAssistantPublisher(settings *conf.Settings, ds datastore.Interface, client func() mqtt.Client) *HomeAssistantPublisher {
+	return &HomeAssistantPublisher{
+		settings:     settings,
+		ds:           ds,
+		client:       client,
+		sourceHealth: myaudio.GetRTSPStreamHealth,
+		species:      make(map[string]*mqtt.HASpeciesState),
+		soundLevel:   make(map[string]bool),
+	}
+}
+
+// enabled returns true if MQTT and Home Assistant discovery are enabled
+func (h *HomeAssistantPublisher) enabled() bool {
+	return h.settings.Realtime.MQTT.Enabled && h.settings.Realtime.MQTT.HomeAssistant.Enabled
+}
+
+// discovery returns the discovery builder for the current settings
+func (h *HomeAssistantPublisher) discovery() *mqtt.HomeAssistant {
+	ha := h.settings.Realtime.MQTT.HomeAssistant
+	return mqtt.NewHomeAssistant(ha.DiscoveryPrefix, h.settings.Realtime.MQTT.Topic, h.settings.Main.Name, ha.DeviceName, h.settings.Version)
+}
+
+// connectedClient returns the MQTT client if it is connected
+func (h *HomeAssistantPublisher) connectedClient() mqtt.Client {
+	client := h.client()
+	if client == nil || !client.IsConnected() {
+		return nil
+	}
+	return client
+}
+
+// Start runs the periodic refresh until Stop is called
+func (h *HomeAssistantPublisher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		ticker := time.NewTicker(homeAssistantRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			h.Refresh(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic refresh
+func (h *HomeAssistantPublisher) Stop() {
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.wg.Wait()
+}
+
+// Refresh publishes the discovery messages and station state after the MQTT client has
+// (re)connected and at midnight, and updates the audio source health.
+func (h *HomeAssistantPublisher) Refresh(ctx context.Context) {
+	if !h.enabled() {
+		return
+	}
+
+	h.mu.Lock()
+	rollover := h.rollover(time.Now())
+	state := h.last
+	client := h.connectedClient()
+	if client == nil {
+		// Announce again when the connection is back, the broker may have lost the retained messages
+		h.announced = nil
+		h.mu.Unlock()
+		return
+	}
+	announce := h.announced != client
+	h.mu.Unlock()
+
+	logger := GetLogger()
+	if announce {
+		if err := h.announce(ctx, client); err != nil {
+			logger.Warn("Failed to publish Home Assistant discovery", "error", err, "operation", "homeassistant_discovery")
+			return
+		}
+		h.mu.Lock()
+		h.announced = client
+		h.mu.Unlock()
+		logger.Info("Published Home Assistant discovery", "operation", "homeassistant_discovery")
+	}
+	if announce || rollover {
+		if err := h.publishJSON(ctx, client, h.discovery().StatsTopic(), state); err != nil {
+			logger.Warn("Failed to publish Home Assistant station state", "error", err, "operation", "homeassistant_stats")
+		}
+	}
+	if err := h.publishJSON(ctx, client, h.discovery().SourcesTopic(), h.sourcesState()); err != nil {
+		logger.Warn("Failed to publish Home Assistant audio source health", "error", err, "operation", "homeassistant_sources")
+	}
+}
+
+// announce publishes the discovery messages of the station and of the known sound level sources
+func (h *HomeAssistantPublisher) announce(ctx context.Context, client mqtt.Client) error {
+	discovery := h.discovery()
+	for _, msg := range discovery.StationConfigs() {
+		if err := client.PublishWithOptions(ctx, msg.Topic, msg.Payload, homeAssistantPublishOptions); err != nil {
+			return err
+		}
+	}
+
+	// Sound level sensors are announced again with their first reading
+	h.mu.Lock()
+	clear(h.soundLevel)
+	h.mu.Unlock()
+	return nil
+}
+
+// rollover resets the daily counts on a new day. On startup the detections already
+// stored for today are loaded. Returns true if the counts changed. Must be called with mu held.
+func (h *HomeAssistantPublisher) rollover(now time.Time) bool {
+	today := now.Format("2006-01-02")
+	if h.day == today {
+		return false
+	}
+	startup := h.day == ""
+	h.day = today
+	h.detections = 0
+	h.species = make(map[string]*mqtt.HASpeciesState)
+
+	if startup && h.ds != nil {
+		summaries, err := h.ds.GetSpeciesSummaryData(today, today)
+		if err != nil {
+			GetLogger().Warn("Failed to load today's detections for Home Assistant", "error", err, "operation", "homeassistant_counts")
+		}
+		for i := range summaries {
+			s := &summaries[i]
+			h.detections += s.Count
+			h.species[s.ScientificName] = &mqtt.HASpeciesState{
+				CommonName:     s.CommonName,
+				ScientificName: s.ScientificName,
+				Confidence:     s.MaxConfidence,
+				DetectedAt:     s.LastSeen.Format(time.RFC3339),
+				CountToday:     s.Count,
+			}
+		}
+	}
+
+	h.last.DetectionsToday = h.detections
+	h.last.SpeciesToday = len(h.species)
+	return true
+}
+
+// RecordDetection adds a detection to the daily counts and publishes the station and species state
+func (h *HomeAssistantPublisher) RecordDetection(ctx context.Context, note *datastore.Note) error {
+	if !h.enabled() {
+		return nil
+	}
+
+	detectedAt := note.BeginTime
+	if detectedAt.IsZero() {
+		detectedAt = time.Now()
+	}
+
+	// Counts are updated while disconnected so that they are right after a reconnect
+	h.mu.Lock()
+	h.rollover(detectedAt)
+	h.detections++
+	species, ok := h.species[note.ScientificName]
+	if !ok {
+		species = &mqtt.HASpeciesState{ScientificName: note.ScientificName}
+		h.species[note.ScientificName] = species
+	}
+	species.CommonName = note.CommonName
+	species.Confidence = note.Confidence
+	species.DetectedAt = detectedAt.Format(time.RFC3339)
+	species.CountToday++
+	speciesState := *species
+
+	h.last = mqtt.HAStationState{
+		LastDetection:   note.CommonName,
+		ScientificName:  note.ScientificName,
+		Confidence:      note.Confidence,
+		DetectedAt:      speciesState.DetectedAt,
+		Source:          note.Source.DisplayName,
+		DetectionsToday: h.detections,
+		SpeciesToday:    len(h.species),
+	}
+	state := h.last
+	h.mu.Unlock()
+
+	client := h.connectedClient()
+	if client == nil {
+		return errors.Newf("MQTT client not connected").
+			Component("analysis.processor").
+			Category(errors.CategoryMQTTConnection).
+			Context("operation", "homeassistant_detection").
+			Context("integration", "mqtt").
+			Build()
+	}
+
+	discovery := h.discovery()
+	if err := h.publishJSON(ctx, client, discovery.StatsTopic(), state); err != nil {
+		return err
+	}
+	return h.publishJSON(ctx, client, discovery.SpeciesTopic(note.CommonName), speciesState)
+}
+
+// PublishSoundLevel publishes the overall sound level of an audio source in dB and
+// announces its sensor with the first reading
+func (h *HomeAssistantPublisher) PublishSoundLevel(ctx context.Context, sourceID, name string, level float64, timestamp time.Time) error {
+	if !h.enabled() {
+		return nil
+	}
+	client := h.connectedClient()
+	if client == nil {
+		return nil
+	}
+
+	discovery := h.discovery()
+	h.mu.Lock()
+	announce := !h.soundLevel[sourceID]
+	h.mu.Unlock()
+	if announce {
+		msg := discovery.SoundLevelConfig(sourceID, privacy.SanitizeRTSPUrl(name))
+		if err := client.PublishWithOptions(ctx, msg.Topic, msg.Payload, homeAssistantPublishOptions); err != nil {
+			return err
+		}
+		h.mu.Lock()
+		h.soundLevel[sourceID] = true
+		h.mu.Unlock()
+	}
+
+	return h.publishJSON(ctx, client, discovery.SoundLevelTopic(sourceID), mqtt.HASoundLevelState{
+		Level:     math.Round(level*10) / 10,
+		Name:      privacy.SanitizeRTSPUrl(name),
+		Timestamp: timestamp.Format(time.RFC3339),
+	})
+}
+
+// sourcesState returns the health of the audio streams, credentials are removed from the stream URLs
+func (h *HomeAssistantPublisher) sourcesState() mqtt.HASourcesState {
+	state := mqtt.HASourcesState{Status: mqtt.HASourcesOK, Sources: make(map[string]mqtt.HASourceHealth)}
+	for url, health := range h.sourceHealth() {
+		source := mqtt.HASourceHealth{
+			Healthy:   health.IsHealthy,
+			Receiving: health.IsReceivingData,
+			Restarts:  health.RestartCount,
+		}
+		if !health.LastDataReceived.IsZero() {
+			source.LastData = health.LastDataReceived.Format(time.RFC3339)
+		}
+		if health.Error != nil {
+			source.Error = privacy.SanitizeRTSPUrls(health.Error.Error())
+		}
+		if !health.IsHealthy {
+			state.Status = mqtt.HASourcesProblem
+		}
+		state.Sources[privacy.SanitizeRTSPUrl(url)] = source
+	}
+	return state
+}
+
+// publishJSON publishes a retained JSON state message
+func (h *HomeAssistantPublisher) publishJSON(ctx context.Context, client mqtt.Client, topic string, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return errors.New(err).
+			Component("analysis.processor").
+			Category(errors.CategoryMQTTPublish).
+			Context("operation", "homeassistant_marshal").
+			Context("topic", topic).
+			Build()
+	}
+	return client.PublishWithOptions(ctx, topic, string(payload), homeAssistantPublishOptions)
+}
+
+// HomeAssistantAction updates the Home Assistant state topics with a detection
+type HomeAssistantAction struct {
+	Publisher   *HomeAssistantPublisher
+	Note        datastore.Note
+	Description string
+}
+
+// GetDescription returns a human-readable description of the HomeAssistantAction
+func (a *HomeAssistantAction) GetDescription() string {
+	if a.Description != "" {
+		return a.Description
+	}
+	return "Update Home Assistant state"
+}
+
+// Execute publishes the detection to the Home Assistant state topics. The action is not
+// retried, the detection is counted once and the next refresh publishes the current state.
+func (a *HomeAssistantAction) Execute(data interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), MQTTPublishTimeout)
+	defer cancel()
+	return a.Publisher.RecordDetection(ctx, &a.Note)
+}
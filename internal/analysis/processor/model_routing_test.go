@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// newModelTestProcessor creates a processor with a primary model and one additional model
+func newModelTestProcessor() *Processor {
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.8
+	settings.BirdNET.RangeFilter.Species = []string{"Turdus merula_Eurasian Blackbird"}
+	settings.BirdNET.Models = []conf.ModelConfig{
+		{ID: "amphibians", Enabled: true, Threshold: 0.5},
+	}
+	settings.Realtime.Species.Config = map[string]conf.SpeciesConfig{
+		"common frog": {Threshold: 0.9},
+	}
+
+	return &Processor{
+		Settings: settings,
+		Bn:       &birdnet.BirdNET{ModelInfo: birdnet.ModelInfo{ID: birdnet.DefaultModelVersion}},
+	}
+}
+
+func TestProcessor_ModelID(t *testing.T) {
+	p := newModelTestProcessor()
+
+	assert.True(t, p.isPrimaryModel(""))
+	assert.True(t, p.isPrimaryModel(birdnet.DefaultModelVersion))
+	assert.False(t, p.isPrimaryModel("amphibians"))
+
+	assert.Equal(t, birdnet.DefaultModelVersion, p.modelID(""))
+	assert.Equal(t, "amphibians", p.modelID("amphibians"))
+}
+
+func TestProcessor_GetModelConfidenceThreshold(t *testing.T) {
+	p := newModelTestProcessor()
+
+	testCases := []struct {
+		name     string
+		model    string
+		species  string
+		expected float32
+	}{
+		{"primary model uses global threshold", birdnet.DefaultModelVersion, "eurasian blackbird", 0.8},
+		{"additional model uses model threshold", "amphibians", "natterjack toad", 0.5},
+		{"species threshold overrides model threshold", "amphibians", "common frog", 0.9},
+		{"unknown model uses global threshold", "bats", "noctule", 0.8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.expected, p.getModelConfidenceThreshold(tc.model, tc.species), 0.0001)
+		})
+	}
+}
+
+func TestProcessor_ShouldFilterDetectionRangeFilter(t *testing.T) {
+	p := newModelTestProcessor()
+	toad := datastore.Results{Species: "Epidalea calamita_Natterjack Toad", Confidence: 0.95}
+
+	// Species outside the BirdNET range filter are dropped for the primary model
+	filtered, _ := p.shouldFilterDetection(toad, "Natterjack Toad", "natterjack toad", 0.5, "source", birdnet.DefaultModelVersion, time.Now())
+	assert.True(t, filtered)
+
+	// The range filter does not apply to additional models
+	filtered, _ = p.shouldFilterDetection(toad, "Natterjack Toad", "natterjack toad", 0.5, "source", "amphibians", time.Now())
+	assert.False(t, filtered)
+}
@@ -4,6 +4,7 @@ package processor
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/tphakala/birdnet-go/internal/conf"
@@ -46,6 +47,77 @@ func (p *Processor) PublishMQTT(ctx context.Context, topic, payload string) erro
 	return fmt.Errorf("MQTT client not available or not connected")
 }
 
+// HomeAssistant returns the Home Assistant state publisher, nil if the processor was
+// created without one
+func (p *Processor) HomeAssistant() *HomeAssistantPublisher {
+	return p.homeAssistant
+}
+
+// MQTTSpool returns the offline message spool, nil if spooling is disabled or the
+// spool could not be opened
+func (p *Processor) MQTTSpool() *mqtt.Spool {
+	if !p.Settings.Realtime.MQTT.Spool.Enabled {
+		return nil
+	}
+	p.mqttMutex.RLock()
+	defer p.mqttMutex.RUnlock()
+	return p.mqttSpool
+}
+
+// openMQTTSpool opens the offline message spool on first use and applies the limits of
+// the settings. Returns nil if spooling is disabled.
+func (p *Processor) openMQTTSpool(settings *conf.Settings) *mqtt.Spool {
+	spoolSettings := settings.Realtime.MQTT.Spool
+	if !spoolSettings.Enabled {
+		return nil
+	}
+
+	maxBytes := int64(spoolSettings.MaxSizeMB) * 1024 * 1024
+	maxAge := time.Duration(spoolSettings.MaxAge) * time.Hour
+
+	p.mqttMutex.Lock()
+	defer p.mqttMutex.Unlock()
+
+	// The spool directory is kept for the lifetime of the process, limits can change
+	if p.mqttSpool != nil {
+		p.mqttSpool.SetLimits(spoolSettings.MaxMessages, maxBytes, maxAge)
+		return p.mqttSpool
+	}
+
+	dir := spoolSettings.Path
+	if dir == "" {
+		configPaths, err := conf.GetDefaultConfigPaths()
+		if err != nil || len(configPaths) == 0 {
+			GetLogger().Error("Failed to resolve MQTT spool directory", "error", err, "operation", "mqtt_spool_open")
+			return nil
+		}
+		dir = filepath.Join(configPaths[0], "mqtt-spool")
+	}
+
+	spool, err := mqtt.OpenSpool(mqtt.SpoolConfig{
+		Dir:         dir,
+		MaxMessages: spoolSettings.MaxMessages,
+		MaxBytes:    maxBytes,
+		MaxAge:      maxAge,
+	})
+	if err != nil {
+		GetLogger().Error("Failed to open MQTT spool, messages will not be queued", "error", err, "dir", dir, "operation", "mqtt_spool_open")
+		return nil
+	}
+	p.mqttSpool = spool
+	return spool
+}
+
+// NewMQTTClient creates the MQTT client of the processor. It queues messages in the
+// offline spool if it is enabled and publishes the station availability for Home Assistant.
+func (p *Processor) NewMQTTClient(settings *conf.Settings) (mqtt.Client, error) {
+	opts := mqtt.ClientOptions{Spool: p.openMQTTSpool(settings)}
+	if settings.Realtime.MQTT.HomeAssistant.Enabled {
+		opts.AvailabilityTopic = mqtt.AvailabilityTopic(settings.Realtime.MQTT.Topic)
+	}
+	return mqtt.NewClientWithOptions(settings, p.Metrics, opts)
+}
+
 // initializeMQTT initializes the MQTT client if enabled in settings
 func (p *Processor) initializeMQTT(settings *conf.Settings) {
 	if !settings.Realtime.MQTT.Enabled {
@@ -53,7 +125,7 @@ func (p *Processor) initializeMQTT(settings *conf.Settings) {
 	}
 
 	// Create a new MQTT client using the settings and metrics
-	mqttClient, err := mqtt.NewClient(settings, p.Metrics)
+	mqttClient, err := p.NewMQTTClient(settings)
 	if err != nil {
 		// Log an error if client creation fails
 		logger := GetLogger()
@@ -70,6 +142,10 @@ func (p *Processor) initializeMQTT(settings *conf.Settings) {
 		// Log an error if the connection attempt fails
 		logger := GetLogger()
 		logger.Error("Failed to connect to MQTT broker", "error", err)
+		// With a spool the client reconnects in the background and queues messages meanwhile
+		if p.MQTTSpool() != nil {
+			p.SetMQTTClient(mqttClient)
+		}
 		return
 	}
 
@@ -0,0 +1,180 @@
+// mqtt_brokers.go: additional MQTT brokers detections are published to
+package processor
+
+import (
+	"cmp"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/jobqueue"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+)
+
+// mqttBroker is an additional MQTT broker with the client and templates of its settings
+type mqttBroker struct {
+	name      string
+	settings  conf.MQTTSettings // settings the client was created with
+	client    mqtt.Client       // nil if the client could not be created
+	templates *mqtt.MessageTemplates
+	tracker   *EventTracker // publish rate limit, independent of the other brokers
+	lastError string        // error of the last client creation or connection attempt
+}
+
+// MQTTBrokerState is the connection state of an additional MQTT broker
+type MQTTBrokerState struct {
+	Name      string
+	Broker    string
+	Topic     string
+	Connected bool
+	LastError string
+}
+
+// MQTTBrokers returns the state of the additional MQTT brokers in the order of the settings
+func (p *Processor) MQTTBrokers() []MQTTBrokerState {
+	brokers := p.getMQTTBrokers()
+	states := make([]MQTTBrokerState, 0, len(brokers))
+	for _, b := range brokers {
+		states = append(states, MQTTBrokerState{
+			Name:      b.name,
+			Broker:    b.settings.Broker,
+			Topic:     cmp.Or(b.settings.TopicTemplate, b.settings.Topic),
+			Connected: b.client != nil && b.client.IsConnected(),
+			LastError: b.lastError,
+		})
+	}
+	return states
+}
+
+// getMQTTBrokers safely returns the current additional brokers
+func (p *Processor) getMQTTBrokers() []*mqttBroker {
+	p.mqttMutex.RLock()
+	defer p.mqttMutex.RUnlock()
+	return p.mqttBrokers
+}
+
+// ConnectMQTTBrokers replaces the clients of the additional brokers with clients for the
+// enabled brokers of the settings. The brokers are connected concurrently.
+func (p *Processor) ConnectMQTTBrokers(settings *conf.Settings) {
+	p.DisconnectMQTTBrokers()
+
+	var brokers []*mqttBroker
+	for i := range settings.Realtime.MQTT.Brokers {
+		brokerSettings := &settings.Realtime.MQTT.Brokers[i]
+		if !brokerSettings.Enabled {
+			continue
+		}
+		brokers = append(brokers, p.newMQTTBroker(settings, brokerSettings))
+	}
+
+	var wg sync.WaitGroup
+	for _, b := range brokers {
+		if b.client == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(b *mqttBroker) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := b.client.Connect(ctx); err != nil {
+				b.lastError = err.Error()
+				GetLogger().Error("Failed to connect to MQTT broker", "broker_name", b.name, "error", err, "operation", "mqtt_broker_connect")
+			}
+		}(b)
+	}
+	wg.Wait()
+
+	p.mqttMutex.Lock()
+	p.mqttBrokers = brokers
+	p.mqttMutex.Unlock()
+}
+
+// newMQTTBroker creates the client and templates of an additional broker. Errors are kept
+// in the broker state.
+func (p *Processor) newMQTTBroker(settings *conf.Settings, brokerSettings *conf.MQTTBrokerSettings) *mqttBroker {
+	b := &mqttBroker{
+		name:     brokerSettings.Name,
+		settings: brokerSettings.MQTTSettings(settings.Realtime.MQTT.Debug),
+		tracker: NewEventTrackerWithConfig(
+			time.Duration(settings.Realtime.Interval)*time.Second,
+			settings.Realtime.Species.Config,
+		),
+	}
+	logger := GetLogger()
+
+	var err error
+	b.templates, err = mqtt.NewMessageTemplates(cmp.Or(b.settings.TopicTemplate, b.settings.Topic), b.settings.PayloadTemplate, b.settings.PayloadProfile)
+	if err != nil {
+		b.lastError = err.Error()
+		logger.Error("Invalid MQTT message templates", "broker_name", b.name, "error", err, "operation", "mqtt_templates")
+		return b
+	}
+
+	// The client reads the broker from the MQTT settings
+	clientSettings := *settings
+	clientSettings.Realtime.MQTT = b.settings
+	b.client, err = mqtt.NewClientWithOptions(&clientSettings, p.Metrics, mqtt.ClientOptions{
+		ClientID: settings.Main.Name + "-" + b.name,
+	})
+	if err != nil {
+		b.lastError = err.Error()
+		logger.Error("Failed to create MQTT client", "broker_name", b.name, "error", err, "operation", "mqtt_broker_create")
+	}
+	return b
+}
+
+// DisconnectMQTTBrokers disconnects and removes the clients of the additional brokers
+func (p *Processor) DisconnectMQTTBrokers() {
+	p.mqttMutex.Lock()
+	brokers := p.mqttBrokers
+	p.mqttBrokers = nil
+	p.mqttMutex.Unlock()
+
+	for _, b := range brokers {
+		if b.client != nil {
+			b.client.Disconnect()
+		}
+	}
+}
+
+// mqttBrokerActions returns the publish actions of the additional brokers that accept the
+// species of the detection. Publishes to a disconnected broker fail and are retried by the
+// job queue until the broker reconnects, with the retry settings of the main broker if the
+// broker has none of its own.
+func (p *Processor) mqttBrokerActions(detection *Detections) []Action {
+	var actions []Action
+	for _, b := range p.getMQTTBrokers() {
+		if b.client == nil || !b.settings.Species.Allows(detection.Note.CommonName, detection.Note.ScientificName) {
+			continue
+		}
+		retrySettings := b.settings.RetrySettings
+		if retrySettings == (conf.RetrySettings{}) {
+			retrySettings = p.Settings.Realtime.MQTT.RetrySettings
+		}
+		actions = append(actions, &MqttAction{
+			Settings:       p.Settings,
+			Broker:         &b.settings,
+			MqttClient:     b.client,
+			EventTracker:   b.tracker,
+			Note:           detection.Note,
+			BirdImageCache: p.BirdImageCache,
+			RetryConfig:    mqttRetryConfig(&retrySettings),
+			Templates:      b.templates,
+			Description:    "Publish detection to MQTT broker " + b.name,
+		})
+	}
+	return actions
+}
+
+// mqttRetryConfig returns the job queue retry config of MQTT retry settings
+func mqttRetryConfig(settings *conf.RetrySettings) jobqueue.RetryConfig {
+	return jobqueue.RetryConfig{
+		Enabled:      settings.Enabled,
+		MaxRetries:   settings.MaxRetries,
+		InitialDelay: time.Duration(settings.InitialDelay) * time.Second,
+		MaxDelay:     time.Duration(settings.MaxDelay) * time.Second,
+		Multiplier:   settings.BackoffMultiplier,
+	}
+}
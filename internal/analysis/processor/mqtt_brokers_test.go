@@ -0,0 +1,90 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+)
+
+// newTestMQTTBroker returns a broker publishing to a test client
+func newTestMQTTBroker(t *testing.T, settings *conf.MQTTBrokerSettings) (*mqttBroker, *haTestClient) {
+	t.Helper()
+	b := &mqttBroker{
+		name:     settings.Name,
+		settings: settings.MQTTSettings(false),
+		tracker:  NewEventTracker(60 * time.Second),
+	}
+	var err error
+	b.templates, err = mqtt.NewMessageTemplates(b.settings.Topic, "", b.settings.PayloadProfile)
+	require.NoError(t, err)
+	client := newHATestClient()
+	b.client = client
+	return b, client
+}
+
+func TestMQTTBrokerActions(t *testing.T) {
+	t.Parallel()
+
+	home, homeClient := newTestMQTTBroker(t, &conf.MQTTBrokerSettings{
+		Name: "home", Enabled: true, Topic: "home/birds", PayloadProfile: mqtt.PayloadProfileCompact, Retain: true,
+	})
+	research, researchClient := newTestMQTTBroker(t, &conf.MQTTBrokerSettings{
+		Name: "research", Enabled: true, Topic: "stations/{{.Station}}", QoS: 2,
+		Species: conf.SpeciesFilter{Include: []string{"Bubo bubo"}},
+		RetrySettings: conf.RetrySettings{
+			Enabled: true, MaxRetries: 4, InitialDelay: 10, MaxDelay: 60, BackoffMultiplier: 2,
+		},
+	})
+
+	settings := &conf.Settings{}
+	settings.Main.Name = "garden"
+	settings.Realtime.MQTT.Topic = "birdnet"
+	settings.Realtime.MQTT.RetrySettings = conf.RetrySettings{Enabled: true, MaxRetries: 8, InitialDelay: 5, MaxDelay: 300, BackoffMultiplier: 2}
+	p := &Processor{Settings: settings, mqttBrokers: []*mqttBroker{home, research}}
+
+	owl := &Detections{Note: datastore.Note{CommonName: "Eurasian Eagle-Owl", ScientificName: "Bubo bubo", Confidence: 0.9}}
+	actions := p.mqttBrokerActions(owl)
+	require.Len(t, actions, 2)
+	for _, action := range actions {
+		require.NoError(t, action.Execute(nil))
+	}
+
+	// Each broker publishes with its own topic, payload and delivery options
+	var msg mqtt.DetectionMessage
+	homeClient.message(t, "home/birds", &msg)
+	assert.Equal(t, "Eurasian Eagle-Owl", msg.CommonName)
+	assert.True(t, homeClient.options["home/birds"].Retain)
+	researchClient.message(t, "stations/garden", &map[string]any{})
+	assert.Equal(t, byte(2), researchClient.options["stations/garden"].QoS)
+
+	researchAction, ok := actions[1].(*MqttAction)
+	require.True(t, ok)
+	assert.Equal(t, 4, researchAction.RetryConfig.MaxRetries)
+	assert.Equal(t, "Publish detection to MQTT broker research", researchAction.GetDescription())
+
+	// The species filter of a broker only applies to that broker
+	tit := &Detections{Note: datastore.Note{CommonName: "Great Tit", ScientificName: "Parus major"}}
+	actions = p.mqttBrokerActions(tit)
+	require.Len(t, actions, 1)
+	assert.Same(t, home.client, actions[0].(*MqttAction).MqttClient)
+
+	// Publishes to disconnected brokers fail with a retryable error and are retried by the
+	// job queue, brokers without retry settings use those of the main broker
+	homeClient.connected = false
+	actions = p.mqttBrokerActions(tit)
+	require.Len(t, actions, 1)
+	require.Error(t, actions[0].Execute(nil))
+	homeAction, ok := actions[0].(*MqttAction)
+	require.True(t, ok)
+	assert.True(t, homeAction.RetryConfig.Enabled)
+	assert.Equal(t, 8, homeAction.RetryConfig.MaxRetries)
+	assert.Equal(t, []MQTTBrokerState{
+		{Name: "home", Topic: "home/birds"},
+		{Name: "research", Topic: "stations/{{.Station}}", Connected: true},
+	}, p.MQTTBrokers())
+}
@@ -41,6 +41,14 @@ func (m *MockMqttClientWithCapture) Publish(_ context.Context, topic, data strin
 	return m.PublishError
 }
 
+func (m *MockMqttClientWithCapture) PublishWithOptions(ctx context.Context, topic, data string, _ mqtt.PublishOptions) error {
+	return m.Publish(ctx, topic, data)
+}
+
+func (m *MockMqttClientWithCapture) Subscribe(_ context.Context, _ string, _ byte, _ mqtt.MessageHandler) error {
+	return nil
+}
+
 func (m *MockMqttClientWithCapture) SetControlChannel(_ chan string) {
 	// Not needed for test
 }
@@ -0,0 +1,154 @@
+// mqtt_templates.go: MQTT message templates and new species events for the processor
+package processor
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/events"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+)
+
+// mqttTemplateCache holds the message templates parsed for the current settings
+type mqttTemplateCache struct {
+	mu        sync.Mutex
+	key       string
+	templates *mqtt.MessageTemplates
+	err       error
+}
+
+// MQTTTemplates returns the detection topic and payload templates of the current settings.
+// The templates are parsed again after the settings change.
+func (p *Processor) MQTTTemplates() (*mqtt.MessageTemplates, error) {
+	settings := &p.Settings.Realtime.MQTT
+	topic := cmp.Or(settings.TopicTemplate, settings.Topic)
+	key := topic + "\x00" + settings.PayloadTemplate + "\x00" + settings.PayloadProfile
+
+	c := &p.mqttTemplates
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.key != key || (c.templates == nil && c.err == nil) {
+		c.key = key
+		c.templates, c.err = mqtt.NewMessageTemplates(topic, settings.PayloadTemplate, settings.PayloadProfile)
+		if c.err != nil {
+			GetLogger().Error("Invalid MQTT message templates", "error", c.err, "operation", "mqtt_templates")
+		}
+	}
+	return c.templates, c.err
+}
+
+// NewMQTTDetectionMessage returns the template fields of a detection
+func NewMQTTDetectionMessage(settings *conf.Settings, note *datastore.Note) *mqtt.DetectionMessage {
+	timestamp := note.BeginTime
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	return &mqtt.DetectionMessage{
+		Station:        settings.Main.Name,
+		Source:         note.Source.DisplayName,
+		SourceID:       note.Source.ID,
+		CommonName:     note.CommonName,
+		ScientificName: note.ScientificName,
+		SpeciesCode:    note.SpeciesCode,
+		Confidence:     note.Confidence,
+		Timestamp:      timestamp,
+		ClipName:       note.ClipName,
+		Latitude:       note.Latitude,
+		Longitude:      note.Longitude,
+		Threshold:      note.Threshold,
+		Sensitivity:    note.Sensitivity,
+		Overlap:        settings.BirdNET.Overlap,
+	}
+}
+
+// mqttNewSpeciesConsumer publishes new species events from the event bus to the new
+// species topic
+type mqttNewSpeciesConsumer struct {
+	processor *Processor
+}
+
+// Name returns the consumer name for identification
+func (c *mqttNewSpeciesConsumer) Name() string {
+	return "mqtt-new-species-consumer"
+}
+
+// ProcessEvent implements the EventConsumer interface, error events are not published
+func (c *mqttNewSpeciesConsumer) ProcessEvent(events.ErrorEvent) error {
+	return nil
+}
+
+// ProcessBatch implements the EventConsumer interface (not used)
+func (c *mqttNewSpeciesConsumer) ProcessBatch([]events.ErrorEvent) error {
+	return nil
+}
+
+// SupportsBatching indicates whether this consumer supports batch processing
+func (c *mqttNewSpeciesConsumer) SupportsBatching() bool {
+	return false
+}
+
+// ProcessDetectionEvent publishes a new species event if the new species topic is set
+func (c *mqttNewSpeciesConsumer) ProcessDetectionEvent(event events.DetectionEvent) error {
+	settings := c.processor.Settings
+	mqttSettings := &settings.Realtime.MQTT
+	if !event.IsNewSpecies() || !mqttSettings.Enabled || mqttSettings.NewSpeciesTopic == "" {
+		return nil
+	}
+	client := c.processor.GetMQTTClient()
+	if client == nil {
+		return nil
+	}
+
+	msg := &mqtt.DetectionMessage{
+		Station:            settings.Main.Name,
+		Source:             event.GetLocation(),
+		CommonName:         event.GetSpeciesName(),
+		ScientificName:     event.GetScientificName(),
+		Confidence:         event.GetConfidence(),
+		Timestamp:          event.GetTimestamp(),
+		Latitude:           settings.BirdNET.Latitude,
+		Longitude:          settings.BirdNET.Longitude,
+		NewSpecies:         true,
+		DaysSinceFirstSeen: event.GetDaysSinceFirstSeen(),
+	}
+
+	// New species events are rare, the topic template is parsed for each of them
+	templates, err := mqtt.NewMessageTemplates(mqttSettings.NewSpeciesTopic, "", mqtt.PayloadProfileCompact)
+	if err != nil {
+		return err
+	}
+	topic, err := templates.Topic(msg)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return errors.New(err).
+			Component("analysis.processor").
+			Category(errors.CategoryMQTTPublish).
+			Context("operation", "mqtt_new_species_marshal").
+			Build()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MQTTPublishTimeout)
+	defer cancel()
+	return client.PublishWithOptions(ctx, topic, string(payload), mqtt.PublishOptions{
+		QoS: byte(mqttSettings.QoS), // #nosec G115 -- validated to 0-2
+	})
+}
+
+// registerMQTTNewSpeciesConsumer subscribes the new species publisher to the event bus
+func (p *Processor) registerMQTTNewSpeciesConsumer() {
+	if !events.IsInitialized() {
+		return
+	}
+	if err := events.GetEventBus().RegisterConsumer(&mqttNewSpeciesConsumer{processor: p}); err != nil {
+		GetLogger().Debug("MQTT new species consumer not registered", "error", err, "operation", "mqtt_new_species_register")
+	}
+}
@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/events"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+)
+
+// newTemplateTestSettings returns settings with MQTT enabled and detection templates
+func newTemplateTestSettings() *conf.Settings {
+	settings := &conf.Settings{}
+	settings.Main.Name = "garden"
+	settings.Realtime.MQTT.Enabled = true
+	settings.Realtime.MQTT.Topic = "birdnet"
+	settings.Realtime.MQTT.TopicTemplate = "birds/{{.Source}}/{{.ScientificName | slug}}"
+	settings.Realtime.MQTT.PayloadProfile = mqtt.PayloadProfileCompact
+	settings.Realtime.MQTT.QoS = 2
+	return settings
+}
+
+func TestMqttAction_Templates(t *testing.T) {
+	t.Parallel()
+
+	settings := newTemplateTestSettings()
+	p := &Processor{Settings: settings}
+	templates, err := p.MQTTTemplates()
+	require.NoError(t, err)
+
+	client := newHATestClient()
+	action := &MqttAction{
+		Settings:     settings,
+		MqttClient:   client,
+		EventTracker: NewEventTracker(60 * time.Second),
+		Templates:    templates,
+		Note: datastore.Note{
+			CommonName:     "Eurasian Blue Tit",
+			ScientificName: "Cyanistes caeruleus",
+			Confidence:     0.91,
+			Date:           "2024-05-01",
+			Time:           "06:30:00",
+			BeginTime:      time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC),
+			Source:         datastore.AudioSource{ID: "rtsp_1", DisplayName: "front/yard"},
+		},
+	}
+	require.NoError(t, action.Execute(nil))
+
+	topic := "birds/front_yard/cyanistes_caeruleus"
+	var msg mqtt.DetectionMessage
+	client.message(t, topic, &msg)
+	assert.Equal(t, "garden", msg.Station)
+	assert.Equal(t, "front/yard", msg.Source, "payload values are not sanitized")
+	assert.Equal(t, "rtsp_1", msg.SourceID)
+	assert.InDelta(t, 0.91, msg.Confidence, 0.001)
+	assert.Equal(t, byte(2), client.options[topic].QoS)
+
+	// Changed settings are parsed again
+	settings.Realtime.MQTT.TopicTemplate = ""
+	reparsed, err := p.MQTTTemplates()
+	require.NoError(t, err)
+	assert.NotSame(t, templates, reparsed)
+	again, _ := p.MQTTTemplates()
+	assert.Same(t, reparsed, again)
+}
+
+func TestMQTTNewSpeciesConsumer(t *testing.T) {
+	t.Parallel()
+
+	settings := newTemplateTestSettings()
+	settings.Realtime.MQTT.NewSpeciesTopic = "birdnet/new/{{.CommonName}}"
+	client := newHATestClient()
+	p := &Processor{Settings: settings}
+	p.SetMQTTClient(client)
+	consumer := &mqttNewSpeciesConsumer{processor: p}
+
+	// Detections of known species are not published
+	known, err := events.NewDetectionEvent("Great Tit", "Parus major", 0.8, "garden", false, 10)
+	require.NoError(t, err)
+	require.NoError(t, consumer.ProcessDetectionEvent(known))
+	assert.Empty(t, client.messages)
+
+	event, err := events.NewDetectionEvent("Common/Crossbill", "Loxia curvirostra", 0.85, "garden", true, 0)
+	require.NoError(t, err)
+	require.NoError(t, consumer.ProcessDetectionEvent(event))
+
+	var msg mqtt.DetectionMessage
+	client.message(t, "birdnet/new/Common_Crossbill", &msg)
+	assert.True(t, msg.NewSpecies)
+	assert.Equal(t, "Loxia curvirostra", msg.ScientificName)
+	assert.Equal(t, byte(2), client.options["birdnet/new/Common_Crossbill"].QoS)
+
+	// Nothing is published without a new species topic
+	settings.Realtime.MQTT.NewSpeciesTopic = ""
+	other := newHATestClient()
+	p.SetMQTTClient(other)
+	require.NoError(t, consumer.ProcessDetectionEvent(event))
+	assert.Empty(t, other.messages)
+}
@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"math"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+const (
+	// Frequency range of the octave bands used as the ambient level in the birdsong band
+	birdsongMinFreq = 1000.0
+	birdsongMaxFreq = 8000.0
+
+	// Noise adjusted thresholds stay within these limits
+	minNoiseAdjustedThreshold = 0.01
+	maxNoiseAdjustedThreshold = 0.99
+)
+
+// latestSoundLevel returns the most recent sound level measurement of a source, replaced in tests
+var latestSoundLevel = myaudio.LatestSoundLevel
+
+// noiseThresholdAdjustment returns the threshold change for an ambient level. Thresholds are
+// lowered by up to MaxRelax in quiet conditions and raised by up to MaxRaise in noisy ones,
+// changing linearly between the quiet and noisy levels.
+func noiseThresholdAdjustment(settings *conf.NoiseThresholdSettings, level float64) float64 {
+	switch {
+	case level <= settings.QuietLevel:
+		return -settings.MaxRelax
+	case level >= settings.NoisyLevel:
+		return settings.MaxRaise
+	}
+	position := (level - settings.QuietLevel) / (settings.NoisyLevel - settings.QuietLevel)
+	return -settings.MaxRelax + position*(settings.MaxRaise+settings.MaxRelax)
+}
+
+// ambientNoiseLevel returns the ambient level of a source around the time of a detection, false
+// if sound level monitoring has no recent measurement of the source. The ambient level is the
+// background of the interval, the quietest second overall or of each band, so that the bird
+// calls heard during the interval don't raise it.
+func (p *Processor) ambientNoiseLevel(source string, detectedAt time.Time) (float64, bool) {
+	data, ok := latestSoundLevel(source)
+	if !ok {
+		return 0, false
+	}
+
+	// Measurements older than two intervals no longer describe the conditions of the detection
+	maxAge := 2 * time.Duration(max(p.Settings.Realtime.Audio.SoundLevel.Interval, conf.MinSoundLevelInterval)) * time.Second
+	if age := detectedAt.Sub(data.Timestamp); age > maxAge || age < -maxAge {
+		return 0, false
+	}
+
+	if p.Settings.Realtime.NoiseThreshold.Band == "broadband" {
+		return data.Lmin, true
+	}
+	return data.BandMinLevel(birdsongMinFreq, birdsongMaxFreq), true
+}
+
+// getNoiseAdjustedThreshold adjusts a confidence threshold to the ambient noise level of the
+// source, raising it during rain, wind or traffic noise and relaxing it in quiet conditions.
+// The threshold is returned unchanged if the source has no recent sound level measurement.
+func (p *Processor) getNoiseAdjustedThreshold(threshold float32, speciesLowercase, source string, detectedAt time.Time) float32 {
+	settings := &p.Settings.Realtime.NoiseThreshold
+	level, ok := p.ambientNoiseLevel(source, detectedAt)
+	if !ok || math.IsNaN(level) || math.IsInf(level, 0) {
+		return threshold
+	}
+
+	adjusted := float64(threshold) + noiseThresholdAdjustment(settings, level)
+	adjusted = math.Max(minNoiseAdjustedThreshold, math.Min(maxNoiseAdjustedThreshold, adjusted))
+
+	if settings.Debug {
+		GetLogger().Debug("Adjusted confidence threshold to ambient noise",
+			"species", speciesLowercase,
+			"source", p.getDisplayNameForSource(source),
+			"ambient_level_db", level,
+			"band", settings.Band,
+			"threshold", threshold,
+			"adjusted_threshold", adjusted,
+			"operation", "noise_threshold")
+	}
+
+	return float32(adjusted)
+}
@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+func TestNoiseThresholdAdjustment(t *testing.T) {
+	settings := &conf.NoiseThresholdSettings{QuietLevel: -70, NoisyLevel: -40, MaxRaise: 0.15, MaxRelax: 0.05}
+
+	testCases := []struct {
+		name  string
+		level float64
+		want  float64
+	}{
+		{"below quiet level", -80, -0.05},
+		{"at quiet level", -70, -0.05},
+		{"between levels", -55, 0.05},
+		{"at noisy level", -40, 0.15},
+		{"above noisy level", -20, 0.15},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.want, noiseThresholdAdjustment(settings, tc.level), 0.0001)
+		})
+	}
+}
+
+// setAmbientSoundLevel replaces the sound level measurements with a single measurement of a source
+func setAmbientSoundLevel(t *testing.T, source string, data myaudio.SoundLevelData) {
+	t.Helper()
+	original := latestSoundLevel
+	latestSoundLevel = func(s string) (myaudio.SoundLevelData, bool) {
+		return data, s == source
+	}
+	t.Cleanup(func() { latestSoundLevel = original })
+}
+
+func TestProcessor_GetNoiseAdjustedThreshold(t *testing.T) {
+	p := newModelTestProcessor()
+	p.Settings.Realtime.Audio.SoundLevel.Interval = 10
+	p.Settings.Realtime.NoiseThreshold = conf.NoiseThresholdSettings{
+		Enabled: true, Band: "birdsong", QuietLevel: -70, NoisyLevel: -40, MaxRaise: 0.15, MaxRelax: 0.05,
+	}
+	now := time.Now()
+
+	// Wind noise is loud in the low bands and moderate in the birdsong bands, where the
+	// calls of the interval raise the mean but not the background
+	setAmbientSoundLevel(t, "garden", myaudio.SoundLevelData{
+		Timestamp: now,
+		Leq:       -20,
+		Lmin:      -30,
+		OctaveBands: map[string]myaudio.OctaveBandData{
+			"125.0_Hz": {CenterFreq: 125, Min: -30, Mean: -28},
+			"2.0_kHz":  {CenterFreq: 2000, Min: -55, Mean: -25},
+		},
+	})
+
+	assert.InDelta(t, 0.85, p.getNoiseAdjustedThreshold(0.8, "eurasian blackbird", "garden", now), 0.0001)
+	assert.InDelta(t, 0.8, p.getNoiseAdjustedThreshold(0.8, "eurasian blackbird", "street", now), 0.0001,
+		"sources without measurements keep their threshold")
+	assert.InDelta(t, 0.8, p.getNoiseAdjustedThreshold(0.8, "eurasian blackbird", "garden", now.Add(time.Minute)), 0.0001,
+		"stale measurements are ignored")
+
+	p.Settings.Realtime.NoiseThreshold.Band = "broadband"
+	assert.InDelta(t, 0.95, p.getNoiseAdjustedThreshold(0.8, "eurasian blackbird", "garden", now), 0.0001)
+	assert.InDelta(t, 0.99, p.getNoiseAdjustedThreshold(0.9, "eurasian blackbird", "garden", now), 0.0001,
+		"raised thresholds stay below one")
+}
+
+func TestProcessor_ShouldFilterDetectionNoiseThreshold(t *testing.T) {
+	p := newModelTestProcessor()
+	p.Settings.Realtime.Audio.SoundLevel.Interval = 10
+	p.Settings.Realtime.NoiseThreshold = conf.NoiseThresholdSettings{
+		Enabled: true, Band: "broadband", QuietLevel: -70, NoisyLevel: -40, MaxRaise: 0.15, MaxRelax: 0.05,
+	}
+	now := time.Now()
+	blackbird := datastore.Results{Species: "Turdus merula_Eurasian Blackbird", Confidence: 0.85}
+
+	// A gusty day raises the bar above the detection confidence
+	setAmbientSoundLevel(t, "garden", myaudio.SoundLevelData{Timestamp: now, Leq: -30, Lmin: -35})
+	filtered, threshold := p.shouldFilterDetection(blackbird, "Eurasian Blackbird", "eurasian blackbird", 0.8, "garden", birdnet.DefaultModelVersion, now)
+	assert.True(t, filtered)
+	assert.InDelta(t, 0.95, threshold, 0.0001)
+
+	// Quiet conditions relax it, the effective threshold is returned for the note
+	setAmbientSoundLevel(t, "garden", myaudio.SoundLevelData{Timestamp: now, Leq: -60, Lmin: -75})
+	filtered, threshold = p.shouldFilterDetection(blackbird, "Eurasian Blackbird", "eurasian blackbird", 0.8, "garden", birdnet.DefaultModelVersion, now)
+	assert.False(t, filtered)
+	assert.InDelta(t, 0.75, threshold, 0.0001)
+}
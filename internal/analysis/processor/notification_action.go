@@ -0,0 +1,197 @@
+// notification_action.go
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+// Default templates used when a SendNotification action does not define its own
+const (
+	DefaultNotificationTitleTemplate   = "{{.CommonName}} detected"
+	DefaultNotificationMessageTemplate = "{{.CommonName}} ({{.ScientificName}}) detected with {{.Confidence}}% confidence at {{.Source}} on {{.Date}} {{.Time}}"
+)
+
+// SendNotificationAction raises a detection notification through the notification service.
+// It is configured per species via a conf.SpeciesAction of type "SendNotification" and
+// honors the per-species interval through the EventTracker.
+type SendNotificationAction struct {
+	Note            datastore.Note
+	EventTracker    *EventTracker
+	Service         *notification.Service // Notification service, defaults to the global instance when nil
+	TitleTemplate   string                // Go text/template for the notification title
+	MessageTemplate string                // Go text/template for the notification message
+	Priority        notification.Priority // Notification priority
+	Description     string
+	mu              sync.Mutex // Protect concurrent access to Note
+}
+
+// notificationTemplateData holds the fields available to notification templates
+type notificationTemplateData struct {
+	CommonName     string
+	ScientificName string
+	Confidence     int     // Confidence as a percentage (0-100)
+	ConfidenceRaw  float64 // Confidence as reported by the model (0-1)
+	Source         string
+	Date           string
+	Time           string
+}
+
+// NewSendNotificationAction creates a SendNotificationAction from a species action configuration
+func NewSendNotificationAction(note *datastore.Note, tracker *EventTracker, actionConfig *conf.SpeciesAction) *SendNotificationAction {
+	return &SendNotificationAction{
+		Note:            *note,
+		EventTracker:    tracker,
+		Service:         notification.GetService(),
+		TitleTemplate:   actionConfig.Title,
+		MessageTemplate: actionConfig.Message,
+		Priority:        parseNotificationPriority(actionConfig.Priority),
+	}
+}
+
+// GetDescription returns a human-readable description of the SendNotificationAction
+func (a *SendNotificationAction) GetDescription() string {
+	if a.Description != "" {
+		return a.Description
+	}
+	return "Send detection notification"
+}
+
+// Execute renders the notification templates and creates the notification
+func (a *SendNotificationAction) Execute(data interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	species := strings.ToLower(a.Note.CommonName)
+
+	// Respect the per-species cooldown
+	if a.EventTracker != nil && !a.EventTracker.TrackEvent(species, SendNotification) {
+		return nil
+	}
+
+	service := a.Service
+	if service == nil {
+		service = notification.GetService()
+	}
+	if service == nil {
+		return errors.Newf("notification service not initialized").
+			Component("analysis.processor").
+			Category(errors.CategorySystem).
+			Context("operation", "send_notification").
+			Context("species", a.Note.CommonName).
+			Build()
+	}
+
+	templateData := newNotificationTemplateData(&a.Note)
+
+	title, err := renderNotificationTemplate("title", a.TitleTemplate, DefaultNotificationTitleTemplate, &templateData)
+	if err != nil {
+		return err
+	}
+	message, err := renderNotificationTemplate("message", a.MessageTemplate, DefaultNotificationMessageTemplate, &templateData)
+	if err != nil {
+		return err
+	}
+
+	priority := a.Priority
+	if priority == "" {
+		priority = notification.PriorityHigh
+	}
+
+	notif := notification.NewNotification(notification.TypeDetection, priority, title, message).
+		WithComponent("detection").
+		WithMetadata("species", a.Note.CommonName).
+		WithMetadata("scientific_name", a.Note.ScientificName).
+		WithMetadata("confidence", a.Note.Confidence).
+		WithMetadata("location", templateData.Source).
+		WithMetadata("species_action", true).
+		WithExpiry(24 * time.Hour)
+
+	if err := service.CreateWithMetadata(notif); err != nil {
+		GetLogger().Error("Failed to create species notification",
+			"component", "analysis.processor.actions",
+			"error", err,
+			"species", a.Note.CommonName,
+			"operation", "send_notification")
+		return err
+	}
+
+	GetLogger().Info("Species notification sent",
+		"component", "analysis.processor.actions",
+		"species", a.Note.CommonName,
+		"confidence", a.Note.Confidence,
+		"priority", priority,
+		"operation", "send_notification")
+
+	return nil
+}
+
+// newNotificationTemplateData builds the template data for a note
+func newNotificationTemplateData(note *datastore.Note) notificationTemplateData {
+	source := note.Source.DisplayName
+	if source == "" {
+		source = note.Source.SafeString
+	}
+
+	return notificationTemplateData{
+		CommonName:     note.CommonName,
+		ScientificName: note.ScientificName,
+		Confidence:     int(note.Confidence*100 + 0.5),
+		ConfidenceRaw:  note.Confidence,
+		Source:         source,
+		Date:           note.Date,
+		Time:           note.Time,
+	}
+}
+
+// renderNotificationTemplate executes a notification template, falling back to the default when empty
+func renderNotificationTemplate(name, text, fallback string, data *notificationTemplateData) (string, error) {
+	if text == "" {
+		text = fallback
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", errors.New(fmt.Errorf("invalid notification %s template: %w", name, err)).
+			Component("analysis.processor").
+			Category(errors.CategoryValidation).
+			Context("operation", "parse_notification_template").
+			Context("template_field", name).
+			Build()
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", errors.New(fmt.Errorf("failed to render notification %s template: %w", name, err)).
+			Component("analysis.processor").
+			Category(errors.CategoryValidation).
+			Context("operation", "render_notification_template").
+			Context("template_field", name).
+			Build()
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// parseNotificationPriority converts a configured priority string into a notification priority
+func parseNotificationPriority(priority string) notification.Priority {
+	switch strings.ToLower(strings.TrimSpace(priority)) {
+	case string(notification.PriorityLow):
+		return notification.PriorityLow
+	case string(notification.PriorityMedium):
+		return notification.PriorityMedium
+	case string(notification.PriorityCritical):
+		return notification.PriorityCritical
+	default:
+		return notification.PriorityHigh
+	}
+}
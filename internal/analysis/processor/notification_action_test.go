@@ -0,0 +1,145 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+func newTestGoshawkNote() datastore.Note {
+	return datastore.Note{
+		CommonName:     "Northern Goshawk",
+		ScientificName: "Accipiter gentilis",
+		Confidence:     0.876,
+		Date:           "2024-05-01",
+		Time:           "05:42:10",
+		Source: datastore.AudioSource{
+			ID:          "rtsp_1",
+			DisplayName: "Backyard camera",
+		},
+	}
+}
+
+func TestSendNotificationAction_Execute(t *testing.T) {
+	service := notification.NewService(notification.DefaultServiceConfig())
+	defer service.Stop()
+
+	note := newTestGoshawkNote()
+	action := NewSendNotificationAction(&note, NewEventTracker(time.Minute), &conf.SpeciesAction{
+		Type:     "SendNotification",
+		Title:    "Raptor alert: {{.CommonName}}",
+		Message:  "{{.CommonName}} heard at {{.Source}} ({{.Confidence}}%)",
+		Priority: "critical",
+	})
+	action.Service = service
+
+	require.NoError(t, action.Execute(nil))
+
+	notifications, err := service.List(&notification.FilterOptions{})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+
+	notif := notifications[0]
+	assert.Equal(t, notification.TypeDetection, notif.Type)
+	assert.Equal(t, notification.PriorityCritical, notif.Priority)
+	assert.Equal(t, "Raptor alert: Northern Goshawk", notif.Title)
+	assert.Equal(t, "Northern Goshawk heard at Backyard camera (88%)", notif.Message)
+	assert.Equal(t, "detection", notif.Component)
+	assert.Equal(t, "Accipiter gentilis", notif.Metadata["scientific_name"])
+}
+
+func TestSendNotificationAction_DefaultTemplates(t *testing.T) {
+	service := notification.NewService(notification.DefaultServiceConfig())
+	defer service.Stop()
+
+	note := newTestGoshawkNote()
+	action := NewSendNotificationAction(&note, NewEventTracker(time.Minute), &conf.SpeciesAction{Type: "SendNotification"})
+	action.Service = service
+
+	require.NoError(t, action.Execute(nil))
+
+	notifications, err := service.List(&notification.FilterOptions{})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, notification.PriorityHigh, notifications[0].Priority)
+	assert.Equal(t, "Northern Goshawk detected", notifications[0].Title)
+	assert.Equal(t,
+		"Northern Goshawk (Accipiter gentilis) detected with 88% confidence at Backyard camera on 2024-05-01 05:42:10",
+		notifications[0].Message)
+}
+
+func TestSendNotificationAction_HonorsSpeciesInterval(t *testing.T) {
+	service := notification.NewService(notification.DefaultServiceConfig())
+	defer service.Stop()
+
+	tracker := NewEventTrackerWithConfig(0, map[string]conf.SpeciesConfig{
+		"northern goshawk": {Interval: 3600},
+	})
+
+	note := newTestGoshawkNote()
+	for range 3 {
+		action := NewSendNotificationAction(&note, tracker, &conf.SpeciesAction{Type: "SendNotification"})
+		action.Service = service
+		require.NoError(t, action.Execute(nil))
+	}
+
+	notifications, err := service.List(&notification.FilterOptions{})
+	require.NoError(t, err)
+	assert.Len(t, notifications, 1, "repeated detections within the species interval should not notify again")
+}
+
+func TestSendNotificationAction_InvalidTemplate(t *testing.T) {
+	service := notification.NewService(notification.DefaultServiceConfig())
+	defer service.Stop()
+
+	note := newTestGoshawkNote()
+	action := NewSendNotificationAction(&note, nil, &conf.SpeciesAction{
+		Type:  "SendNotification",
+		Title: "{{.NoSuchField}}",
+	})
+	action.Service = service
+
+	require.Error(t, action.Execute(nil))
+}
+
+func TestParseNotificationPriority(t *testing.T) {
+	tests := []struct {
+		input string
+		want  notification.Priority
+	}{
+		{"", notification.PriorityHigh},
+		{"low", notification.PriorityLow},
+		{"Medium", notification.PriorityMedium},
+		{" critical ", notification.PriorityCritical},
+		{"bogus", notification.PriorityHigh},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseNotificationPriority(tt.input), "input %q", tt.input)
+	}
+}
+
+func TestGetActionsForItem_SendNotification(t *testing.T) {
+	settings := &conf.Settings{}
+	settings.Realtime.Species.Config = map[string]conf.SpeciesConfig{
+		"northern goshawk": {
+			Actions: []conf.SpeciesAction{{Type: "SendNotification"}},
+		},
+	}
+
+	p := &Processor{
+		Settings:     settings,
+		EventTracker: NewEventTracker(time.Minute),
+	}
+
+	detection := &Detections{Note: newTestGoshawkNote()}
+	actions := p.getActionsForItem(detection)
+	require.Len(t, actions, 1)
+	_, ok := actions[0].(*SendNotificationAction)
+	assert.True(t, ok, "expected SendNotificationAction, got %T", actions[0])
+}
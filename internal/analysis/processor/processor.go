@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/tphakala/birdnet-go/internal/analysis/jobqueue"
+	"github.com/tphakala/birdnet-go/internal/backup"
 	"github.com/tphakala/birdnet-go/internal/birdnet"
 	"github.com/tphakala/birdnet-go/internal/birdweather"
 	"github.com/tphakala/birdnet-go/internal/conf"
@@ -22,6 +23,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/myaudio"
 	"github.com/tphakala/birdnet-go/internal/observability"
 	"github.com/tphakala/birdnet-go/internal/privacy"
+	"github.com/tphakala/birdnet-go/internal/suppression"
 )
 
 // Species identification constants for filtering
@@ -65,12 +67,38 @@ type Processor struct {
 	sseBroadcasterMutex sync.RWMutex                                                         // Mutex to protect SSE broadcaster access
 
 	// Backup system fields (optional)
-	backupManager   interface{} // Use interface{} to avoid import cycle
-	backupScheduler interface{} // Use interface{} to avoid import cycle
+	backupManager   *backup.Manager
+	backupScheduler *backup.Scheduler
 	backupMutex     sync.RWMutex
 
 	// Log deduplication (extracted to separate type for SRP)
 	logDedup *LogDeduplicator // Handles log deduplication logic
+
+	// Home Assistant discovery and state topics, idle unless enabled in settings
+	homeAssistant *HomeAssistantPublisher
+
+	// Offline MQTT message spool, shared by the MQTT clients created for the settings
+	mqttSpool *mqtt.Spool
+
+	// MQTT topic and payload templates parsed for the current settings
+	mqttTemplates mqttTemplateCache
+
+	// Additional MQTT brokers, guarded by mqttMutex
+	mqttBrokers []*mqttBroker
+
+	// Webhook post rate limits by endpoint name
+	webhookTrackers map[string]*EventTracker
+	webhookMutex    sync.Mutex
+
+	// BirdWeather upload outbox and its replayer, nil without a database
+	bwOutbox   *datastore.BirdweatherOutbox
+	bwReplayer *BirdWeatherReplayer
+
+	// Raises species thresholds from reviewed detections, nil without a datastore
+	calibrator *ThresholdCalibrator
+
+	// Matches detections against the suppression rules and counts suppressed detections
+	suppressionEngine *suppression.Engine
 }
 
 // DynamicThreshold represents the dynamic threshold configuration for a species.
@@ -123,6 +151,7 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 		lastDogDetectionLog: make(map[string]time.Time),
 		controlChan:         make(chan string, 10),  // Buffered channel to prevent blocking
 		JobQueue:            jobqueue.NewJobQueue(), // Initialize the job queue
+		suppressionEngine:   suppression.NewEngine(),
 	}
 
 	// Initialize log deduplicator with configuration from settings
@@ -224,8 +253,25 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 		}
 	}
 
+	// Replay BirdWeather uploads that failed after their retries
+	p.initBirdWeatherOutbox(ds)
+
+	// Raise species thresholds from reviewed detections when calibration auto apply is enabled
+	if ds != nil {
+		p.calibrator = NewThresholdCalibrator(settings, ds)
+		p.calibrator.Start()
+	}
+
 	// Initialize MQTT client if enabled in settings
 	p.initializeMQTT(settings)
+	p.ConnectMQTTBrokers(settings)
+
+	// Publish new species events to the new species topic if one is configured
+	p.registerMQTTNewSpeciesConsumer()
+
+	// Start Home Assistant state publishing, it follows MQTT client changes and settings
+	p.homeAssistant = NewHomeAssistantPublisher(settings, ds, p.GetMQTTClient)
+	p.homeAssistant.Start()
 
 	// Start the job queue
 	p.JobQueue.Start()
@@ -368,10 +414,10 @@ func (p *Processor) processResults(item birdnet.Results) []Detections {
 		p.handleHumanDetection(item, speciesLowercase, result)
 
 		// Determine confidence threshold and check filters
-		baseThreshold := p.getBaseConfidenceThreshold(speciesLowercase)
+		baseThreshold := p.getModelConfidenceThreshold(item.Model, speciesLowercase)
 		
 		// Check if detection should be filtered
-		shouldSkip, _ := p.shouldFilterDetection(result, commonName, speciesLowercase, baseThreshold, item.Source.ID)
+		shouldSkip, confidenceThreshold := p.shouldFilterDetection(result, commonName, speciesLowercase, baseThreshold, item.Source.ID, item.Model, item.StartTime)
 		if shouldSkip {
 			continue
 		}
@@ -382,7 +428,7 @@ func (p *Processor) processResults(item birdnet.Results) []Detections {
 		}
 
 		// Create the detection
-		detection := p.createDetection(item, result, scientificName, commonName, speciesCode)
+		detection := p.createDetection(item, result, scientificName, commonName, speciesCode, confidenceThreshold)
 		detections = append(detections, detection)
 	}
 
@@ -430,7 +476,7 @@ func (p *Processor) parseAndValidateSpecies(result datastore.Results, item birdn
 }
 
 // shouldFilterDetection checks if a detection should be filtered out
-func (p *Processor) shouldFilterDetection(result datastore.Results, commonName, speciesLowercase string, baseThreshold float32, source string) (shouldFilter bool, confidenceThreshold float32) {
+func (p *Processor) shouldFilterDetection(result datastore.Results, commonName, speciesLowercase string, baseThreshold float32, source, model string, detectedAt time.Time) (shouldFilter bool, confidenceThreshold float32) {
 	// Check human detection privacy filter
 	if strings.Contains(strings.ToLower(commonName), speciesHuman) && result.Confidence > baseThreshold {
 		return true, 0 // Filter out human detections for privacy
@@ -443,6 +489,11 @@ func (p *Processor) shouldFilterDetection(result datastore.Results, commonName,
 		confidenceThreshold = baseThreshold
 	}
 
+	// Raise or relax the threshold to the ambient noise level of the source
+	if p.Settings.Realtime.NoiseThreshold.Enabled {
+		confidenceThreshold = p.getNoiseAdjustedThreshold(confidenceThreshold, speciesLowercase, source, detectedAt)
+	}
+
 	// Check confidence threshold
 	if result.Confidence <= confidenceThreshold {
 		if p.Settings.Debug {
@@ -456,8 +507,8 @@ func (p *Processor) shouldFilterDetection(result datastore.Results, commonName,
 		return true, confidenceThreshold
 	}
 
-	// Check species inclusion filter
-	if !p.Settings.IsSpeciesIncluded(result.Species) {
+	// Check species inclusion filter, the range filter only covers species of the primary BirdNET model
+	if p.isPrimaryModel(model) && !p.Settings.IsSpeciesIncluded(result.Species) {
 		if p.Settings.Debug {
 			GetLogger().Debug("Species not on included list",
 				"species", result.Species,
@@ -468,13 +519,19 @@ func (p *Processor) shouldFilterDetection(result datastore.Results, commonName,
 		return true, confidenceThreshold
 	}
 
+	// Check source and time scoped suppression rules of recurring false positives
+	if p.isSuppressed(result, commonName, source, detectedAt) {
+		return true, confidenceThreshold
+	}
+
 	return false, confidenceThreshold
 }
 
-// createDetection creates a detection object with all necessary information
+// createDetection creates a detection object with all necessary information, the note records
+// the effective confidence threshold the detection passed
 //
 //nolint:gocritic // hugeParam: Pass by value is intentional - avoids pointer dereferencing in hot path
-func (p *Processor) createDetection(item birdnet.Results, result datastore.Results, scientificName, commonName, speciesCode string) Detections {
+func (p *Processor) createDetection(item birdnet.Results, result datastore.Results, scientificName, commonName, speciesCode string, confidenceThreshold float32) Detections {
 	// Create file name for audio clip
 	clipName := p.generateClipName(scientificName, result.Confidence)
 
@@ -491,6 +548,8 @@ func (p *Processor) createDetection(item birdnet.Results, result datastore.Resul
 		float64(result.Confidence),
 		item.Source.ID, clipName,
 		item.ElapsedTime, occurrence)
+	note.Model = p.modelID(item.Model)
+	note.Threshold = float64(confidenceThreshold)
 
 	// Update species tracker if enabled
 	p.speciesTrackerMu.RLock()
@@ -597,6 +656,36 @@ func (p *Processor) getBaseConfidenceThreshold(speciesLowercase string) float32
 	return float32(p.Settings.BirdNET.Threshold)
 }
 
+// isPrimaryModel reports whether results were produced by the primary BirdNET model
+func (p *Processor) isPrimaryModel(model string) bool {
+	return model == "" || p.Bn == nil || model == p.Bn.ModelInfo.ID
+}
+
+// modelID returns the ID of the model that produced results, results without a
+// model ID were produced by the primary BirdNET model
+func (p *Processor) modelID(model string) string {
+	if model == "" && p.Bn != nil {
+		return p.Bn.ModelInfo.ID
+	}
+	return model
+}
+
+// getModelConfidenceThreshold retrieves the confidence threshold for a species detected by a model.
+// Additional models use their configured threshold unless the species has a custom threshold.
+func (p *Processor) getModelConfidenceThreshold(model, speciesLowercase string) float32 {
+	if !p.isPrimaryModel(model) {
+		if _, exists := p.Settings.Realtime.Species.Config[speciesLowercase]; !exists {
+			for i := range p.Settings.BirdNET.Models {
+				if cfg := &p.Settings.BirdNET.Models[i]; cfg.ID == model && cfg.Threshold > 0 {
+					return float32(cfg.Threshold)
+				}
+			}
+		}
+	}
+
+	return p.getBaseConfidenceThreshold(speciesLowercase)
+}
+
 // generateClipName generates a clip name for the given scientific name and confidence.
 func (p *Processor) generateClipName(scientificName string, confidence float32) string {
 	// Replace whitespaces with underscores and convert to lowercase
@@ -827,8 +916,11 @@ func (p *Processor) getActionsForItem(detection *Detections) []Action {
 					})
 				}
 			case "SendNotification":
-				// Add notification action handling
-				// ... implementation ...
+				actions = append(actions, NewSendNotificationAction(&detection.Note, p.GetEventTracker(), &actionConfig))
+			case "Webhook":
+				if action := p.webhookAction(actionConfig.Endpoint, detection); action != nil {
+					actions = append(actions, action)
+				}
 			}
 			// If any action has ExecuteDefaults set to true, we'll include default actions
 			if actionConfig.ExecuteDefaults {
@@ -975,34 +1067,45 @@ func (p *Processor) getDefaultActions(detection *Detections) []Action {
 				Note:         detection.Note,
 				pcmData:      detection.pcmData3s,
 				RetryConfig:  bwRetryConfig,
+				Outbox:       p.bwOutbox,
 			})
 		}
 	}
 
-	// Add MQTT action if enabled and client is available
-	if p.Settings.Realtime.MQTT.Enabled {
+	// Add MQTT action if enabled, client is available and the species is published
+	if p.Settings.Realtime.MQTT.Enabled && p.Settings.Realtime.MQTT.Species.Allows(detection.Note.CommonName, detection.Note.ScientificName) {
 		mqttClient := p.GetMQTTClient()
-		if mqttClient != nil && mqttClient.IsConnected() {
-			// Create MQTT retry config from settings
-			mqttRetryConfig := jobqueue.RetryConfig{
-				Enabled:      p.Settings.Realtime.MQTT.RetrySettings.Enabled,
-				MaxRetries:   p.Settings.Realtime.MQTT.RetrySettings.MaxRetries,
-				InitialDelay: time.Duration(p.Settings.Realtime.MQTT.RetrySettings.InitialDelay) * time.Second,
-				MaxDelay:     time.Duration(p.Settings.Realtime.MQTT.RetrySettings.MaxDelay) * time.Second,
-				Multiplier:   p.Settings.Realtime.MQTT.RetrySettings.BackoffMultiplier,
-			}
+		// With the offline spool, messages are queued while the client reconnects
+		if mqttClient != nil && (mqttClient.IsConnected() || p.MQTTSpool() != nil) {
 
+			// Invalid templates are logged when parsed, the full payload is published on the topic then
+			templates, _ := p.MQTTTemplates()
 			actions = append(actions, &MqttAction{
 				Settings:       p.Settings,
 				MqttClient:     mqttClient,
 				EventTracker:   p.GetEventTracker(),
 				Note:           detection.Note,
 				BirdImageCache: p.BirdImageCache,
-				RetryConfig:    mqttRetryConfig,
+				RetryConfig:    mqttRetryConfig(&p.Settings.Realtime.MQTT.RetrySettings),
+				Templates:      templates,
 			})
 		}
 	}
 
+	// Add MQTT actions of the additional brokers
+	actions = append(actions, p.mqttBrokerActions(detection)...)
+
+	// Add webhook actions of the enabled endpoints
+	actions = append(actions, p.webhookActions(detection)...)
+
+	// Add Home Assistant state action if discovery is enabled
+	if p.Settings.Realtime.MQTT.Enabled && p.Settings.Realtime.MQTT.HomeAssistant.Enabled && p.homeAssistant != nil {
+		actions = append(actions, &HomeAssistantAction{
+			Publisher: p.homeAssistant,
+			Note:      detection.Note,
+		})
+	}
+
 	// Check if UpdateRangeFilterAction needs to be executed for the day
 	today := time.Now().Truncate(24 * time.Hour) // Current date with time set to midnight
 	if p.Settings.BirdNET.RangeFilter.LastUpdated.Before(today) {
@@ -1093,28 +1196,28 @@ func (p *Processor) GetSSEBroadcaster() func(note *datastore.Note, birdImage *im
 }
 
 // SetBackupManager safely sets the backup manager
-func (p *Processor) SetBackupManager(manager interface{}) {
+func (p *Processor) SetBackupManager(manager *backup.Manager) {
 	p.backupMutex.Lock()
 	defer p.backupMutex.Unlock()
 	p.backupManager = manager
 }
 
 // GetBackupManager safely returns the backup manager
-func (p *Processor) GetBackupManager() interface{} {
+func (p *Processor) GetBackupManager() *backup.Manager {
 	p.backupMutex.RLock()
 	defer p.backupMutex.RUnlock()
 	return p.backupManager
 }
 
 // SetBackupScheduler safely sets the backup scheduler
-func (p *Processor) SetBackupScheduler(scheduler interface{}) {
+func (p *Processor) SetBackupScheduler(scheduler *backup.Scheduler) {
 	p.backupMutex.Lock()
 	defer p.backupMutex.Unlock()
 	p.backupScheduler = scheduler
 }
 
 // GetBackupScheduler safely returns the backup scheduler
-func (p *Processor) GetBackupScheduler() interface{} {
+func (p *Processor) GetBackupScheduler() *backup.Scheduler {
 	p.backupMutex.RLock()
 	defer p.backupMutex.RUnlock()
 	return p.backupScheduler
@@ -1175,14 +1278,27 @@ func (p *Processor) Shutdown() error {
 		log.Printf("Warning: job queue shutdown timed out: %v", err)
 	}
 
-	// Disconnect BirdWeather client
+	// Stop replaying BirdWeather uploads and disconnect the client
+	if p.bwReplayer != nil {
+		p.bwReplayer.Stop()
+	}
 	p.DisconnectBwClient()
 
+	if p.calibrator != nil {
+		p.calibrator.Stop()
+	}
+
+	// Stop Home Assistant updates before the MQTT client goes away
+	if p.homeAssistant != nil {
+		p.homeAssistant.Stop()
+	}
+
 	// Disconnect MQTT client if connected
 	mqttClient := p.GetMQTTClient()
 	if mqttClient != nil && mqttClient.IsConnected() {
 		mqttClient.Disconnect()
 	}
+	p.DisconnectMQTTBrokers()
 
 	// Close the species tracker to release resources
 	p.speciesTrackerMu.RLock()
@@ -1277,6 +1393,7 @@ func (p *Processor) NewWithSpeciesInfo(
 		Date:           date,                           // Use ISO 8601 date format
 		Time:           timeStr,                        // Use 24-hour time format
 		Source:         sourceStruct,                   // Proper AudioSource struct with ID, SafeString, DisplayName
+		SourceID:       sourceStruct.ID,                // Source ID kept in the database for suppression rules
 		BeginTime:      beginTime,                      // Start time of the observation
 		EndTime:        endTime,                        // End time of the observation
 		SpeciesCode:    speciesCode,                    // Species code from taxonomy lookup
@@ -0,0 +1,45 @@
+// suppression.go: source and time scoped suppression of recurring false positives
+package processor
+
+import (
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/observation"
+	"github.com/tphakala/birdnet-go/internal/suppression"
+)
+
+// isSuppressed reports whether a detection matches a suppression rule
+func (p *Processor) isSuppressed(result datastore.Results, commonName, source string, detectedAt time.Time) bool {
+	rules := p.Settings.Realtime.Suppression.Rules
+	if len(rules) == 0 {
+		return false
+	}
+
+	scientificName, _, _ := observation.ParseSpeciesString(result.Species)
+	rule := p.suppressionEngine.Match(rules, &suppression.Detection{
+		CommonName:     commonName,
+		ScientificName: scientificName,
+		Source:         source,
+		Confidence:     float64(result.Confidence),
+		Time:           detectedAt,
+	})
+	if rule == nil {
+		return false
+	}
+
+	if p.Settings.Debug {
+		GetLogger().Debug("Detection suppressed by rule",
+			"species", commonName,
+			"confidence", result.Confidence,
+			"rule_id", rule.ID,
+			"source", p.getDisplayNameForSource(source),
+			"operation", "suppression_filter")
+	}
+	return true
+}
+
+// SuppressionStats returns the number of detections suppressed by each rule since startup
+func (p *Processor) SuppressionStats() map[string]suppression.RuleStats {
+	return p.suppressionEngine.Stats()
+}
@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/suppression"
+)
+
+func TestProcessor_ShouldFilterDetectionSuppression(t *testing.T) {
+	p := newModelTestProcessor()
+	p.suppressionEngine = suppression.NewEngine()
+	p.Settings.Realtime.Suppression.Rules = []conf.SuppressionRule{
+		{ID: "rule-1", Species: "Turdus merula", Source: "garden", Start: "04:30", End: "05:30", MaxConfidence: 0.9},
+	}
+	blackbird := datastore.Results{Species: "Turdus merula_Eurasian Blackbird", Confidence: 0.85}
+	dawn := time.Date(2024, 5, 1, 5, 0, 0, 0, time.Local)
+
+	filter := func(result datastore.Results, source string, detectedAt time.Time) bool {
+		filtered, _ := p.shouldFilterDetection(result, "Eurasian Blackbird", "eurasian blackbird", 0.8, source, birdnet.DefaultModelVersion, detectedAt)
+		return filtered
+	}
+
+	assert.True(t, filter(blackbird, "garden", dawn))
+	// The rule is scoped to its source, time window and confidence
+	assert.False(t, filter(blackbird, "street", dawn))
+	assert.False(t, filter(blackbird, "garden", dawn.Add(time.Hour)))
+	assert.False(t, filter(datastore.Results{Species: blackbird.Species, Confidence: 0.95}, "garden", dawn))
+
+	assert.Equal(t, int64(1), p.SuppressionStats()["rule-1"].Suppressed)
+}
+
+func TestProcessor_NewWithSpeciesInfoRecordsSource(t *testing.T) {
+	p := newModelTestProcessor()
+	now := time.Now()
+
+	// The source ID is stored with the note so that rules can be created from it later
+	note := p.NewWithSpeciesInfo(now, now.Add(15*time.Second), "Turdus merula", "Eurasian Blackbird", "eurbla",
+		0.9, "suppression_test_source", "blackbird.wav", time.Second, 0)
+	assert.Equal(t, "suppression_test_source", note.SourceID)
+	assert.Equal(t, note.Source.ID, note.SourceID)
+}
@@ -0,0 +1,303 @@
+// webhook_action.go: posts detections to HTTP endpoints
+package processor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/jobqueue"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 signature of the request body as
+// "sha256=<hex>" when the endpoint has a secret
+const WebhookSignatureHeader = "X-BirdNET-Signature"
+
+// defaultWebhookTimeout is the request timeout of endpoints without a timeout
+const defaultWebhookTimeout = 10 * time.Second
+
+// maxWebhookResponseBody limits how much of an error response body is included in errors
+const maxWebhookResponseBody = 512
+
+// WebhookAction posts a detection to an HTTP endpoint. The body is the compact detection
+// or the rendered endpoint template, optionally sent together with the audio clip as
+// multipart/form-data.
+type WebhookAction struct {
+	Settings     *conf.Settings
+	Endpoint     conf.WebhookEndpoint
+	Note         datastore.Note
+	pcmData      []byte
+	Client       *http.Client // HTTP client, nil for a client with the endpoint timeout
+	EventTracker *EventTracker
+	RetryConfig  jobqueue.RetryConfig // Configuration for retry behavior
+	Description  string
+	tracked      bool       // true once the event passed the tracker, retries are not rate limited
+	mu           sync.Mutex // Protect concurrent access to Note and pcmData
+}
+
+// NewWebhookAction creates a WebhookAction posting a detection to an endpoint
+func NewWebhookAction(settings *conf.Settings, endpoint *conf.WebhookEndpoint, detection *Detections, tracker *EventTracker) *WebhookAction {
+	return &WebhookAction{
+		Settings:     settings,
+		Endpoint:     *endpoint,
+		Note:         detection.Note,
+		pcmData:      detection.pcmData3s,
+		EventTracker: tracker,
+		RetryConfig:  mqttRetryConfig(&endpoint.RetrySettings),
+	}
+}
+
+// webhookAccepts reports whether a detection passes the confidence and species filters of an endpoint
+func webhookAccepts(endpoint *conf.WebhookEndpoint, note *datastore.Note) bool {
+	return note.Confidence >= endpoint.MinConfidence && endpoint.Species.Allows(note.CommonName, note.ScientificName)
+}
+
+// GetDescription returns a human-readable description of the WebhookAction
+func (a *WebhookAction) GetDescription() string {
+	if a.Description != "" {
+		return a.Description
+	}
+	return "Post detection to webhook " + a.Endpoint.Name
+}
+
+// Execute posts the detection to the endpoint
+func (a *WebhookAction) Execute(data interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Respect the per-species interval, a retry of a tracked event is always posted
+	if !a.tracked {
+		if a.EventTracker != nil && !a.EventTracker.TrackEvent(a.Note.CommonName, WebhookPost) {
+			return nil
+		}
+		a.tracked = true
+	}
+
+	timeout := defaultWebhookTimeout
+	if a.Endpoint.Timeout > 0 {
+		timeout = time.Duration(a.Endpoint.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	body, err := a.renderBody()
+	if err != nil {
+		return err
+	}
+	contentType := "application/json"
+	if a.Endpoint.IncludeClip && len(a.pcmData) > 0 {
+		if body, contentType, err = a.multipartBody(ctx, body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return a.wrapError(err, errors.CategoryConfiguration, "webhook_request", false)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "BirdNET-Go")
+	for key, value := range a.Endpoint.Headers {
+		req.Header.Set(key, value)
+	}
+	if a.Endpoint.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookBody(a.Endpoint.Secret, body))
+	}
+
+	client := a.Client
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return a.wrapError(err, errors.CategoryNetwork, "webhook_send", true)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBody))
+		// Client errors other than timeouts and rate limits do not go away on retry
+		retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+		return a.wrapError(fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet))),
+			errors.CategoryNetwork, "webhook_send", retryable)
+	}
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponseBody))
+
+	if a.Settings.Debug {
+		GetLogger().Debug("Posted detection to webhook",
+			"endpoint", a.Endpoint.Name,
+			"species", a.Note.CommonName,
+			"confidence", a.Note.Confidence,
+			"status", resp.StatusCode,
+			"operation", "webhook_send")
+	}
+	return nil
+}
+
+// renderBody renders the JSON body, the compact detection if the endpoint has no template
+func (a *WebhookAction) renderBody() ([]byte, error) {
+	msg := NewMQTTDetectionMessage(a.Settings, &a.Note)
+	if a.Endpoint.Template == "" {
+		body, err := json.Marshal(msg)
+		if err != nil {
+			return nil, a.wrapError(err, errors.CategorySystem, "webhook_marshal", false)
+		}
+		return body, nil
+	}
+
+	tmpl, err := mqtt.ParseTemplate("webhook", a.Endpoint.Template)
+	if err != nil {
+		return nil, a.wrapError(err, errors.CategoryConfiguration, "webhook_template", false)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, msg); err != nil {
+		return nil, a.wrapError(err, errors.CategoryConfiguration, "webhook_template", false)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, a.wrapError(errors.NewStd("webhook template did not produce valid JSON"), errors.CategoryConfiguration, "webhook_template", false)
+	}
+	return buf.Bytes(), nil
+}
+
+// multipartBody returns a multipart/form-data body with the JSON body in the "detection"
+// part and the audio clip as WAV in the "clip" part
+func (a *WebhookAction) multipartBody(ctx context.Context, payload []byte) (body []byte, contentType string, err error) {
+	wav, err := myaudio.EncodePCMtoWAVWithContext(ctx, a.pcmData)
+	if err != nil {
+		return nil, "", a.wrapError(err, errors.CategoryAudio, "webhook_encode_clip", false)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="detection"`)
+	header.Set("Content-Type", "application/json")
+	part, err := writer.CreatePart(header)
+	if err == nil {
+		_, err = part.Write(payload)
+	}
+	if err == nil {
+		name := strings.TrimSuffix(filepath.Base(a.Note.ClipName), filepath.Ext(a.Note.ClipName))
+		if a.Note.ClipName == "" {
+			name = "clip"
+		}
+		header = make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="clip"; filename=%q`, name+".wav"))
+		header.Set("Content-Type", "audio/wav")
+		part, err = writer.CreatePart(header)
+	}
+	if err == nil {
+		_, err = io.Copy(part, wav)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return nil, "", a.wrapError(err, errors.CategorySystem, "webhook_multipart", false)
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// wrapError logs and wraps an error of the action, URLs in the message are sanitized
+func (a *WebhookAction) wrapError(err error, category errors.ErrorCategory, operation string, retryable bool) error {
+	GetLogger().Warn("Webhook post failed",
+		"endpoint", a.Endpoint.Name,
+		"species", a.Note.CommonName,
+		"error", privacy.SanitizeRTSPUrls(err.Error()),
+		"retryable", retryable,
+		"operation", operation)
+	return errors.New(err).
+		Component("analysis.processor").
+		Category(category).
+		Context("operation", operation).
+		Context("integration", "webhook").
+		Context("endpoint", a.Endpoint.Name).
+		Context("species", a.Note.CommonName).
+		Context("retryable", retryable).
+		Build()
+}
+
+// SignWebhookBody returns the signature header value of a body, receivers compute the
+// HMAC-SHA256 of the raw body with the shared secret and compare it in constant time
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookTracker returns the event tracker of an endpoint, endpoints are rate limited
+// independently of each other and of the other actions
+func (p *Processor) webhookTracker(name string) *EventTracker {
+	p.webhookMutex.Lock()
+	defer p.webhookMutex.Unlock()
+	if p.webhookTrackers == nil {
+		p.webhookTrackers = make(map[string]*EventTracker)
+	}
+	tracker, ok := p.webhookTrackers[name]
+	if !ok {
+		tracker = NewEventTrackerWithConfig(
+			time.Duration(p.Settings.Realtime.Interval)*time.Second,
+			p.Settings.Realtime.Species.Config,
+		)
+		p.webhookTrackers[name] = tracker
+	}
+	return tracker
+}
+
+// webhookActions returns the post actions of the enabled endpoints that accept the detection
+func (p *Processor) webhookActions(detection *Detections) []Action {
+	if !p.Settings.Realtime.Webhooks.Enabled {
+		return nil
+	}
+	var actions []Action
+	for i := range p.Settings.Realtime.Webhooks.Endpoints {
+		endpoint := &p.Settings.Realtime.Webhooks.Endpoints[i]
+		if !endpoint.Enabled || !webhookAccepts(endpoint, &detection.Note) {
+			continue
+		}
+		actions = append(actions, NewWebhookAction(p.Settings, endpoint, detection, p.webhookTracker(endpoint.Name)))
+	}
+	return actions
+}
+
+// webhookAction returns the post action of a species action endpoint, nil if the endpoint
+// does not exist or does not accept the detection
+func (p *Processor) webhookAction(name string, detection *Detections) Action {
+	for i := range p.Settings.Realtime.Webhooks.Endpoints {
+		endpoint := &p.Settings.Realtime.Webhooks.Endpoints[i]
+		if endpoint.Name != name {
+			continue
+		}
+		if !webhookAccepts(endpoint, &detection.Note) {
+			return nil
+		}
+		return NewWebhookAction(p.Settings, endpoint, detection, p.webhookTracker(endpoint.Name))
+	}
+	GetLogger().Warn("Unknown webhook endpoint in species action",
+		"endpoint", name,
+		"species", detection.Note.CommonName,
+		"operation", "webhook_species_action")
+	return nil
+}
@@ -0,0 +1,177 @@
+package processor
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/mqtt"
+)
+
+// webhookRequest is a request received by the test webhook server
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newWebhookTestServer returns a server recording requests and answering with status
+func newWebhookTestServer(t *testing.T, status int) (server *httptest.Server, requests chan webhookRequest) {
+	t.Helper()
+	requests = make(chan webhookRequest, 10)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- webhookRequest{header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("rejected by test"))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+// newWebhookTestDetection returns a detection with three seconds of silence
+func newWebhookTestDetection() *Detections {
+	return &Detections{
+		pcmData3s: make([]byte, 3*conf.SampleRate*2),
+		Note: datastore.Note{
+			CommonName:     "Eurasian Eagle-Owl",
+			ScientificName: "Bubo bubo",
+			Confidence:     0.92,
+			ClipName:       "clips/2024/05/bubo_bubo_92p_20240501T063000Z.wav",
+			BeginTime:      time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestWebhookAction_Execute(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookTestServer(t, http.StatusAccepted)
+	settings := &conf.Settings{}
+	settings.Main.Name = "garden"
+
+	endpoint := &conf.WebhookEndpoint{
+		Name:     "ops",
+		URL:      server.URL,
+		Template: `{"text": {{printf "%s at %s" .CommonName .Station | json}}, "confidence": {{.Confidence}}}`,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Secret:   "s3cret",
+	}
+
+	action := NewWebhookAction(settings, endpoint, newWebhookTestDetection(), NewEventTracker(60*time.Second))
+	require.NoError(t, action.Execute(nil))
+
+	req := <-requests
+	assert.JSONEq(t, `{"text": "Eurasian Eagle-Owl at garden", "confidence": 0.92}`, string(req.body))
+	assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", req.header.Get("Authorization"))
+	assert.Equal(t, SignWebhookBody("s3cret", req.body), req.header.Get(WebhookSignatureHeader))
+	assert.True(t, strings.HasPrefix(req.header.Get(WebhookSignatureHeader), "sha256="))
+
+	// The species interval applies to new detections of the endpoint
+	again := NewWebhookAction(settings, endpoint, newWebhookTestDetection(), action.EventTracker)
+	require.NoError(t, again.Execute(nil))
+	assert.Empty(t, requests)
+}
+
+func TestWebhookAction_Clip(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWebhookTestServer(t, http.StatusOK)
+	endpoint := &conf.WebhookEndpoint{Name: "archive", URL: server.URL, IncludeClip: true}
+	action := NewWebhookAction(&conf.Settings{}, endpoint, newWebhookTestDetection(), nil)
+	require.NoError(t, action.Execute(nil))
+
+	req := <-requests
+	mediaType, params, err := mime.ParseMediaType(req.header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+
+	reader := multipart.NewReader(strings.NewReader(string(req.body)), params["boundary"])
+	part, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "detection", part.FormName())
+	var msg mqtt.DetectionMessage
+	require.NoError(t, json.NewDecoder(part).Decode(&msg))
+	assert.Equal(t, "Bubo bubo", msg.ScientificName)
+
+	part, err = reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "clip", part.FormName())
+	assert.Equal(t, "bubo_bubo_92p_20240501T063000Z.wav", part.FileName())
+	assert.Equal(t, "audio/wav", part.Header.Get("Content-Type"))
+	clip, err := io.ReadAll(part)
+	require.NoError(t, err)
+	assert.Equal(t, "RIFF", string(clip[:4]))
+}
+
+func TestWebhookAction_Errors(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		status    int
+		retryable bool
+	}{
+		{http.StatusInternalServerError, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusUnauthorized, false},
+	} {
+		server, _ := newWebhookTestServer(t, tt.status)
+		endpoint := &conf.WebhookEndpoint{Name: "ops", URL: server.URL}
+		action := NewWebhookAction(&conf.Settings{}, endpoint, newWebhookTestDetection(), NewEventTracker(60*time.Second))
+
+		err := action.Execute(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rejected by test")
+		var enhancedErr *errors.EnhancedError
+		require.ErrorAs(t, err, &enhancedErr)
+		assert.Equal(t, tt.retryable, enhancedErr.GetContext()["retryable"], "status %d", tt.status)
+
+		// A retry of the same action is posted again despite the species interval
+		require.Error(t, action.Execute(nil))
+	}
+}
+
+func TestProcessor_WebhookActions(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.Webhooks = conf.WebhookSettings{
+		Enabled: true,
+		Endpoints: []conf.WebhookEndpoint{
+			{Name: "all", Enabled: true, URL: "https://hooks.example.com/all"},
+			{Name: "owls", Enabled: true, URL: "https://hooks.example.com/owls", Species: conf.SpeciesFilter{Include: []string{"Bubo bubo"}}},
+			{Name: "confident", Enabled: true, URL: "https://hooks.example.com/confident", MinConfidence: 0.95},
+			{Name: "manual", URL: "https://hooks.example.com/manual"},
+		},
+	}
+	p := &Processor{Settings: settings}
+
+	detection := newWebhookTestDetection()
+	actions := p.webhookActions(detection)
+	require.Len(t, actions, 2)
+	assert.Equal(t, "Post detection to webhook all", actions[0].GetDescription())
+	assert.Equal(t, "Post detection to webhook owls", actions[1].GetDescription())
+
+	// Endpoints are rate limited independently
+	assert.NotSame(t, actions[0].(*WebhookAction).EventTracker, actions[1].(*WebhookAction).EventTracker)
+	assert.Same(t, actions[0].(*WebhookAction).EventTracker, p.webhookActions(detection)[0].(*WebhookAction).EventTracker)
+
+	// Species actions may post to disabled endpoints but respect the endpoint filters
+	assert.NotNil(t, p.webhookAction("manual", detection))
+	assert.Nil(t, p.webhookAction("confident", detection))
+	assert.Nil(t, p.webhookAction("missing", detection))
+
+	settings.Realtime.Webhooks.Enabled = false
+	assert.Empty(t, p.webhookActions(detection))
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/tphakala/birdnet-go/internal/analysis/processor"
 	"github.com/tphakala/birdnet-go/internal/audiocore/adapter"
 	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/backup/sources"
+	"github.com/tphakala/birdnet-go/internal/backup/targets"
 	"github.com/tphakala/birdnet-go/internal/birdnet"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
@@ -112,6 +114,17 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 			Build()
 	}
 
+	// Load additional classifier models run alongside BirdNET
+	if err := initializeAdditionalModels(settings); err != nil {
+		return errors.New(err).
+			Component("analysis.realtime").
+			Category(errors.CategoryModelInit).
+			Context("operation", "initialize_additional_models").
+			Context("model_count", len(settings.BirdNET.Models)).
+			Context("retryable", false).
+			Build()
+	}
+
 	// Clean up any leftover HLS streaming files from previous runs
 	if err := cleanupHLSStreamingFiles(); err != nil {
 		logHLSCleanup(err)
@@ -226,6 +239,9 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 			Build()
 	}
 
+	// Persist notifications in the database now that it is open
+	initializeNotificationPersistence(settings, dataStore)
+
 	// Initialize system monitor if monitoring is enabled
 	systemMonitor := initializeSystemMonitor(settings)
 
@@ -237,7 +253,7 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 	var wg sync.WaitGroup
 
 	// Initialize the buffer manager
-	bufferManager := MustNewBufferManager(bn, quitChan, &wg)
+	bufferManager := MustNewBufferManager(models, quitChan, &wg)
 
 	// Start buffer monitors for each audio source only if we have active sources
 	if len(settings.Realtime.RTSP.URLs) > 0 || settings.Realtime.Audio.Source != "" {
@@ -501,7 +517,7 @@ func RealtimeAnalysis(settings *conf.Settings, notificationChan chan handlers.No
 					"step", 9,
 					"operation", "shutdown_birdnet_cleanup")
 				log.Println("  9️⃣ Cleaning up BirdNET interpreter...")
-				bn.Delete()
+				models.Delete()
 
 				// Add structured logging
 				GetLogger().Info("Graceful shutdown completed",
@@ -676,6 +692,43 @@ func monitorShutdownSignals(quitChan chan struct{}) {
 	}()
 }
 
+// initializeNotificationPersistence switches the notification service to the
+// database-backed store so notifications survive restarts
+func initializeNotificationPersistence(settings *conf.Settings, store datastore.Interface) {
+	persistence := settings.Notification.Persistence
+	if !persistence.Enabled {
+		return
+	}
+
+	service := notification.GetService()
+	if service == nil {
+		GetLogger().Warn("Notification service not initialized, notifications will not be persisted",
+			"operation", "notification_persistence_init")
+		return
+	}
+
+	retention := time.Duration(persistence.RetentionDays) * 24 * time.Hour
+	notificationStore, err := datastore.NewNotificationStoreFor(store, retention, persistence.MaxRecords)
+	if err != nil {
+		GetLogger().Warn("Notification persistence unavailable",
+			"error", err,
+			"operation", "notification_persistence_init")
+		return
+	}
+
+	if err := service.SetStore(notificationStore); err != nil {
+		GetLogger().Error("Failed to enable notification persistence",
+			"error", err,
+			"operation", "notification_persistence_init")
+		return
+	}
+
+	GetLogger().Info("Notification persistence enabled",
+		"retention_days", persistence.RetentionDays,
+		"max_records", persistence.MaxRecords,
+		"operation", "notification_persistence_init")
+}
+
 // closeDataStore attempts to close the database connection and logs the result.
 func closeDataStore(store datastore.Interface) {
 	// If this is an SQLite store, perform WAL checkpoint before closing
@@ -1444,6 +1497,10 @@ func initializeBackupSystem(settings *conf.Settings, backupLogger *slog.Logger)
 			Build()
 	}
 
+	if settings.Backup.Enabled {
+		registerBackupSourcesAndTargets(settings, backupManager, backupLogger)
+	}
+
 	// Load schedule for backupScheduler if backup is enabled
 	switch {
 	case settings.Backup.Enabled && len(settings.Backup.Schedules) > 0:
@@ -1475,6 +1532,35 @@ func initializeBackupSystem(settings *conf.Settings, backupLogger *slog.Logger)
 	return backupManager, backupScheduler, nil
 }
 
+// registerBackupSourcesAndTargets registers the database source and the enabled
+// backup targets with the backup manager. Failures are logged so that a single
+// misconfigured target does not disable the other targets.
+func registerBackupSourcesAndTargets(settings *conf.Settings, backupManager *backup.Manager, backupLogger *slog.Logger) {
+	if settings.Output.SQLite.Enabled {
+		if err := backupManager.RegisterSource(sources.NewSQLiteSource(settings, backupLogger)); err != nil {
+			backupLogger.Error("Failed to register SQLite backup source", "error", err)
+		}
+	}
+
+	for i := range settings.Backup.Targets {
+		targetConfig := &settings.Backup.Targets[i]
+		if !targetConfig.Enabled {
+			continue
+		}
+
+		target, err := targets.NewTargetFromConfig(targetConfig, settings.Backup.Debug, backupLogger)
+		if err != nil {
+			backupLogger.Error("Failed to create backup target", "type", targetConfig.Type, "error", err)
+			continue
+		}
+		if err := backupManager.RegisterTarget(target); err != nil {
+			backupLogger.Error("Failed to register backup target", "type", targetConfig.Type, "error", err)
+			continue
+		}
+		backupLogger.Info("Registered backup target", "type", targetConfig.Type)
+	}
+}
+
 // initializeSystemMonitor initializes and starts the system resource monitor if enabled
 func initializeSystemMonitor(settings *conf.Settings) *monitor.SystemMonitor {
 	logging.Info("initializeSystemMonitor called",
@@ -16,6 +16,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/analysis/processor"
 	api "github.com/tphakala/birdnet-go/internal/api/v2"
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/httpcontroller"
 	"github.com/tphakala/birdnet-go/internal/logging"
@@ -105,6 +106,9 @@ func sanitizeSoundLevelData(data myaudio.SoundLevelData) myaudio.SoundLevelData
 		Source:      sanitizeString(data.Source, "unknown"),
 		Name:        sanitizeString(data.Name, "unknown"),
 		Duration:    data.Duration,
+		Leq:         roundToDecimalPlaces(sanitizeFloat64(data.Leq, -100.0), 2),
+		Lmin:        roundToDecimalPlaces(sanitizeFloat64(data.Lmin, -100.0), 2),
+		Lmax:        roundToDecimalPlaces(sanitizeFloat64(data.Lmax, -100.0), 2),
 		OctaveBands: make(map[string]myaudio.OctaveBandData),
 	}
 
@@ -357,6 +361,19 @@ func toCompactFormat(data myaudio.SoundLevelData) CompactSoundLevelData {
 	return compact
 }
 
+// overallSoundLevel returns the level of all octave bands combined, the energetic sum of
+// the band means in dB
+func overallSoundLevel(bands map[string]myaudio.OctaveBandData) float64 {
+	var power float64
+	for _, band := range bands {
+		power += math.Pow(10, band.Mean/10)
+	}
+	if power == 0 {
+		return -100.0
+	}
+	return roundToDecimalPlaces(10*math.Log10(power), 1)
+}
+
 // startSoundLevelMQTTPublisher starts a goroutine to consume sound level data and publish to MQTT
 func startSoundLevelMQTTPublisher(wg *sync.WaitGroup, quitChan <-chan struct{}, proc *processor.Processor, soundLevelChan <-chan myaudio.SoundLevelData) {
 	wg.Add(1)
@@ -475,6 +492,17 @@ func publishSoundLevelToMQTT(soundData myaudio.SoundLevelData, proc *processor.P
 
 	LogSoundLevelMQTTPublished(topic, soundData.Source, len(soundData.OctaveBands))
 
+	// Update the Home Assistant sound level sensor of the source
+	if ha := proc.HomeAssistant(); ha != nil && len(sanitizedData.OctaveBands) > 0 {
+		if err := ha.PublishSoundLevel(ctx, soundData.Source, soundData.Name, overallSoundLevel(sanitizedData.OctaveBands), soundData.Timestamp); err != nil {
+			getSoundLevelLogger().Warn("Failed to publish Home Assistant sound level",
+				"error", err,
+				"source", soundData.Source,
+				"component", "analysis.soundlevel",
+				"operation", "publish_homeassistant")
+		}
+	}
+
 	// Log detailed sound level data if debug is enabled
 	// These logs are for publishing events, not realtime processing
 	if settings.Realtime.Audio.SoundLevel.Debug {
@@ -519,6 +547,18 @@ func startSoundLevelPublishers(wg *sync.WaitGroup, doneChan chan struct{}, proc
 		close(mergedQuitChan)
 	}()
 
+	// Record the history before the publishers, which compete for the channel data
+	if history := settings.Realtime.Audio.SoundLevel.History; history.Enabled && proc != nil {
+		store, err := datastore.NewSoundLevelHistoryFor(proc.Ds)
+		if err != nil {
+			getSoundLevelLogger().Error("Sound level history disabled", "error", err)
+		} else {
+			publishChan := make(chan myaudio.SoundLevelData, cap(soundLevelChan))
+			startSoundLevelHistoryRecorder(wg, mergedQuitChan, newSoundLevelHistoryRecorder(store, &history), history, soundLevelChan, publishChan)
+			soundLevelChan = publishChan
+		}
+	}
+
 	// Start MQTT publisher if enabled
 	if settings.Realtime.MQTT.Enabled {
 		startSoundLevelMQTTPublisherWithDone(wg, mergedQuitChan, proc, soundLevelChan)
@@ -0,0 +1,184 @@
+package analysis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// soundLevelHistoryMaintenanceInterval is how often old summaries are downsampled and pruned
+const soundLevelHistoryMaintenanceInterval = time.Hour
+
+// soundLevelHistoryRecorder merges the interval measurements of each source into summaries
+// of the configured resolution and stores them in the sound level history
+type soundLevelHistoryRecorder struct {
+	history    *datastore.SoundLevelHistory
+	resolution time.Duration
+	pending    map[string][]datastore.SoundLevelRecord // measurements of the current period by source
+}
+
+// newSoundLevelHistoryRecorder creates a recorder for the history settings
+func newSoundLevelHistoryRecorder(history *datastore.SoundLevelHistory, settings *conf.SoundLevelHistorySettings) *soundLevelHistoryRecorder {
+	return &soundLevelHistoryRecorder{
+		history:    history,
+		resolution: time.Duration(settings.Resolution) * time.Second,
+		pending:    make(map[string][]datastore.SoundLevelRecord),
+	}
+}
+
+// newSoundLevelRecord converts an interval measurement into a sound level summary
+func newSoundLevelRecord(data *myaudio.SoundLevelData) (datastore.SoundLevelRecord, error) {
+	record := datastore.SoundLevelRecord{
+		Source:   data.Source,
+		Name:     data.Name,
+		Start:    data.Timestamp.Add(-time.Duration(data.Duration) * time.Second),
+		Duration: data.Duration,
+		Measured: data.Duration,
+		Leq:      data.Leq,
+		Lmin:     data.Lmin,
+		Lmax:     data.Lmax,
+	}
+	bands := make(map[string]datastore.SoundLevelBand, len(data.OctaveBands))
+	for key, band := range data.OctaveBands {
+		bands[key] = datastore.SoundLevelBand{Freq: band.CenterFreq, Mean: band.Mean, Min: band.Min, Max: band.Max}
+	}
+	return record, record.SetBandLevels(bands)
+}
+
+// record adds an interval measurement to the period of its source. The summary of the
+// previous period is stored when a measurement of a new period arrives.
+func (r *soundLevelHistoryRecorder) record(data *myaudio.SoundLevelData) error {
+	if err := validateSoundLevelData(data); err != nil {
+		return err
+	}
+	sanitized := sanitizeSoundLevelData(*data)
+	record, err := newSoundLevelRecord(&sanitized)
+	if err != nil {
+		return err
+	}
+
+	var flushErr error
+	if pending := r.pending[record.Source]; len(pending) > 0 &&
+		!datastore.SoundLevelPeriodStart(pending[0].Start, r.resolution).Equal(datastore.SoundLevelPeriodStart(record.Start, r.resolution)) {
+		flushErr = r.flush(record.Source)
+	}
+	r.pending[record.Source] = append(r.pending[record.Source], record)
+	return flushErr
+}
+
+// flush stores the summary of the current period of a source
+func (r *soundLevelHistoryRecorder) flush(source string) error {
+	pending := r.pending[source]
+	if len(pending) == 0 {
+		return nil
+	}
+	delete(r.pending, source)
+
+	summary, err := datastore.MergeSoundLevelRecords(datastore.SoundLevelPeriodStart(pending[0].Start, r.resolution), r.resolution, pending)
+	if err != nil {
+		return err
+	}
+	return r.history.Save(&summary)
+}
+
+// flushAll stores the summaries of the current periods of all sources
+func (r *soundLevelHistoryRecorder) flushAll() error {
+	var firstErr error
+	for source := range r.pending {
+		if err := r.flush(source); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// maintain merges summaries older than the downsampling age into hourly summaries and
+// deletes summaries older than the retention period
+func (r *soundLevelHistoryRecorder) maintain(settings *conf.SoundLevelHistorySettings, now time.Time) {
+	logger := getSoundLevelLogger()
+	if settings.DownsampleDays > 0 {
+		merged, err := r.history.Downsample(now.AddDate(0, 0, -settings.DownsampleDays), time.Hour)
+		if err != nil {
+			logger.Error("Failed to downsample sound level history", "error", err)
+		} else if merged > 0 {
+			logger.Info("Downsampled sound level history", "hourly_summaries", merged)
+		}
+	}
+	if settings.RetentionDays > 0 {
+		deleted, err := r.history.Prune(now.AddDate(0, 0, -settings.RetentionDays))
+		if err != nil {
+			logger.Error("Failed to prune sound level history", "error", err)
+		} else if deleted > 0 {
+			logger.Info("Pruned sound level history", "deleted", deleted)
+		}
+	}
+}
+
+// startSoundLevelHistoryMaintenance starts a goroutine that downsamples and prunes the
+// history at startup and then every maintenance interval until stop is closed. Maintenance
+// runs apart from the recorder so that slow queries do not hold up the live publishers.
+func startSoundLevelHistoryMaintenance(wg *sync.WaitGroup, stop <-chan struct{}, recorder *soundLevelHistoryRecorder, settings *conf.SoundLevelHistorySettings) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		recorder.maintain(settings, time.Now())
+		ticker := time.NewTicker(soundLevelHistoryMaintenanceInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				recorder.maintain(settings, now)
+			}
+		}
+	}()
+}
+
+// startSoundLevelHistoryRecorder starts a goroutine that records the sound level data of
+// a channel in the history and passes it on to the publishers, which consume the output
+// channel. The summaries of the current periods are stored when the recorder stops.
+func startSoundLevelHistoryRecorder(wg *sync.WaitGroup, doneChan <-chan struct{}, recorder *soundLevelHistoryRecorder, settings conf.SoundLevelHistorySettings, in <-chan myaudio.SoundLevelData, out chan<- myaudio.SoundLevelData) {
+	stopMaintenance := make(chan struct{})
+	startSoundLevelHistoryMaintenance(wg, stopMaintenance, recorder, &settings)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(stopMaintenance)
+		logger := getSoundLevelLogger()
+		logger.Info("Started sound level history recorder", "resolution_seconds", settings.Resolution)
+		defer func() {
+			if err := recorder.flushAll(); err != nil {
+				logger.Error("Failed to store sound level history", "error", err)
+			}
+			logger.Info("Stopped sound level history recorder")
+		}()
+
+		for {
+			select {
+			case <-doneChan:
+				return
+			case soundData, ok := <-in:
+				if !ok {
+					return
+				}
+				if err := recorder.record(&soundData); err != nil {
+					logger.Error("Failed to record sound level history",
+						"error", err,
+						"source", soundData.Source,
+						"name", soundData.Name)
+				}
+				// Pass the data on, dropping it if the publishers fall behind
+				select {
+				case out <- soundData:
+				default:
+				}
+			}
+		}
+	}()
+}
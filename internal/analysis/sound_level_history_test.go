@@ -0,0 +1,112 @@
+package analysis
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// setupSoundLevelHistoryRecorder creates a recorder with a 60 second resolution backed by
+// a temporary SQLite database
+func setupSoundLevelHistoryRecorder(t *testing.T) (*soundLevelHistoryRecorder, *datastore.SoundLevelHistory) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "soundlevels.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&datastore.SoundLevelRecord{}))
+	history := datastore.NewSoundLevelHistory(db)
+	return newSoundLevelHistoryRecorder(history, &conf.SoundLevelHistorySettings{Enabled: true, Resolution: 60}), history
+}
+
+// testHistorySoundLevel returns a 10 second measurement of a source ending at a time
+func testHistorySoundLevel(source string, end time.Time, leq float64) myaudio.SoundLevelData {
+	return myaudio.SoundLevelData{
+		Timestamp: end,
+		Source:    source,
+		Name:      "Test " + source,
+		Duration:  10,
+		Leq:       leq,
+		Lmin:      leq - 5,
+		Lmax:      leq + 5,
+		OctaveBands: map[string]myaudio.OctaveBandData{
+			"1.0_kHz": {CenterFreq: 1000, Min: leq - 5, Max: leq + 5, Mean: leq},
+		},
+	}
+}
+
+func TestSoundLevelHistoryRecorder_MergesPeriods(t *testing.T) {
+	recorder, history := setupSoundLevelHistoryRecorder(t)
+	period := datastore.SoundLevelPeriodStart(time.Now().Add(-time.Hour), time.Minute)
+
+	require.Error(t, recorder.record(&myaudio.SoundLevelData{}), "invalid data is rejected")
+
+	// Six measurements fill the first minute of each source
+	for i := 1; i <= 6; i++ {
+		end := period.Add(time.Duration(i) * 10 * time.Second)
+		data := testHistorySoundLevel("rtsp_1", end, -30)
+		require.NoError(t, recorder.record(&data))
+		data = testHistorySoundLevel("malgo_1", end, -50)
+		require.NoError(t, recorder.record(&data))
+	}
+	stored, err := history.Query("", period.Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, stored, "summaries are stored when their period ends")
+
+	// A measurement of the next minute stores the summary of the source
+	data := testHistorySoundLevel("rtsp_1", period.Add(70*time.Second), -20)
+	require.NoError(t, recorder.record(&data))
+	stored, err = history.Query("rtsp_1", period.Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.True(t, period.Equal(stored[0].Start))
+	assert.Equal(t, 60, stored[0].Duration)
+	assert.Equal(t, 60, stored[0].Measured)
+	assert.InDelta(t, -30, stored[0].Leq, 0.001)
+	assert.InDelta(t, -35, stored[0].Lmin, 0.001)
+
+	// Stopping stores the current periods of all sources
+	require.NoError(t, recorder.flushAll())
+	stored, err = history.Query("", period.Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+	assert.Empty(t, recorder.pending)
+}
+
+func TestStartSoundLevelHistoryRecorder_PassesDataOn(t *testing.T) {
+	recorder, history := setupSoundLevelHistoryRecorder(t)
+	in := make(chan myaudio.SoundLevelData, 1)
+	out := make(chan myaudio.SoundLevelData, 1)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// A summary older than the retention period is pruned by the maintenance at startup
+	old := datastore.SoundLevelRecord{Source: "rtsp_1", Start: time.Now().AddDate(0, 0, -3), Duration: 60, Measured: 60, Leq: -40}
+	require.NoError(t, history.Save(&old))
+	startSoundLevelHistoryRecorder(&wg, done, recorder, conf.SoundLevelHistorySettings{Enabled: true, Resolution: 60, RetentionDays: 2}, in, out)
+
+	in <- testHistorySoundLevel("rtsp_1", time.Now(), -30)
+	select {
+	case data := <-out:
+		assert.Equal(t, "rtsp_1", data.Source)
+	case <-time.After(5 * time.Second):
+		t.Fatal("sound level data was not passed on to the publishers")
+	}
+
+	close(done)
+	wg.Wait()
+	stored, err := history.Query("rtsp_1", time.Now().Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+	assert.Len(t, stored, 1, "the current period is stored when the recorder stops")
+	stored, err = history.Query("rtsp_1", time.Now().AddDate(0, 0, -4), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, stored, "old summaries are pruned")
+}
@@ -1592,6 +1592,14 @@ func (m *mockMQTTClient) Publish(ctx context.Context, topic, payload string) err
 	return nil
 }
 
+func (m *mockMQTTClient) PublishWithOptions(ctx context.Context, topic, payload string, opts mqtt.PublishOptions) error {
+	return m.Publish(ctx, topic, payload)
+}
+
+func (m *mockMQTTClient) Subscribe(ctx context.Context, topic string, qos byte, handler mqtt.MessageHandler) error {
+	return nil
+}
+
 func (m *mockMQTTClient) TestConnection(ctx context.Context, resultChan chan<- mqtt.TestResult) {
 	// Not needed for our tests
 }
@@ -1630,3 +1638,21 @@ func createMockProcessor(publishFunc func(ctx context.Context, topic, payload st
 	
 	return proc
 }
+
+// TestOverallSoundLevel tests the energetic sum of octave band levels
+func TestOverallSoundLevel(t *testing.T) {
+	t.Parallel()
+
+	// Two equal bands add 3 dB
+	bands := map[string]myaudio.OctaveBandData{
+		"1000_Hz": {CenterFreq: 1000, Mean: 60},
+		"2000_Hz": {CenterFreq: 2000, Mean: 60},
+	}
+	assert.InDelta(t, 63.0, overallSoundLevel(bands), 0.05)
+
+	// A much quieter band does not change the total
+	bands["4000_Hz"] = myaudio.OctaveBandData{CenterFreq: 4000, Mean: -100}
+	assert.InDelta(t, 63.0, overallSoundLevel(bands), 0.05)
+
+	assert.InDelta(t, -100.0, overallSoundLevel(nil), 0.0001)
+}
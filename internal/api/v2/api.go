@@ -432,6 +432,11 @@ func (c *Controller) initRoutes() {
 		{"support routes", c.initSupportRoutes},
 		{"debug routes", c.initDebugRoutes},
 		{"species routes", c.initSpeciesRoutes},
+		{"backup routes", c.initBackupRoutes},
+		{"export routes", c.initExportRoutes},
+		{"calibration routes", c.initCalibrationRoutes},
+		{"suppression routes", c.initSuppressionRoutes},
+		{"sound level routes", c.initSoundLevelRoutes},
 	}
 
 	for _, initializer := range routeInitializers {
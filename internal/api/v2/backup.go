@@ -0,0 +1,438 @@
+// internal/api/v2/backup.go
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/backup"
+)
+
+// Backup API errors
+var (
+	errBackupUnavailable = fmt.Errorf("backup system is not initialized")
+	errBackupDisabled    = fmt.Errorf("backup system is disabled in configuration")
+	errInvalidBackupID   = fmt.Errorf("backup ID contains invalid characters")
+	errBackupInProgress  = fmt.Errorf("a backup is in progress")
+)
+
+// backupIDPattern restricts backup IDs to the characters used by the backup manager
+var backupIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// BackupInfoResponse describes a stored backup
+type BackupInfoResponse struct {
+	ID         string    `json:"id"`
+	Target     string    `json:"target"`
+	Timestamp  time.Time `json:"timestamp"`
+	Size       int64     `json:"size"`
+	Type       string    `json:"type"`
+	Source     string    `json:"source"`
+	IsDaily    bool      `json:"isDaily"`
+	IsWeekly   bool      `json:"isWeekly"`
+	AppVersion string    `json:"appVersion,omitempty"`
+	Checksum   string    `json:"checksum,omitempty"`
+	Encrypted  bool      `json:"encrypted"`
+}
+
+// BackupTargetResponse lists the backups stored in a single target
+type BackupTargetResponse struct {
+	Name    string               `json:"name"`
+	Count   int                  `json:"count"`
+	Size    int64                `json:"size"`
+	Backups []BackupInfoResponse `json:"backups"`
+}
+
+// BackupListResponse is the response of GET /api/v2/backup
+type BackupListResponse struct {
+	Targets []BackupTargetResponse `json:"targets"`
+	Error   string                 `json:"error,omitempty"` // Set when some targets could not be listed
+}
+
+// BackupScheduleStatus describes a backup schedule and its persisted state
+type BackupScheduleStatus struct {
+	Type           string     `json:"type"`
+	Hour           int        `json:"hour"`
+	Minute         int        `json:"minute"`
+	Weekday        string     `json:"weekday,omitempty"`
+	NextRun        *time.Time `json:"nextRun,omitempty"`
+	LastAttempted  *time.Time `json:"lastAttempted,omitempty"`
+	LastSuccessful *time.Time `json:"lastSuccessful,omitempty"`
+	FailureCount   int        `json:"failureCount"`
+}
+
+// BackupTargetStatus describes the persisted state of a backup target
+type BackupTargetStatus struct {
+	Name             string     `json:"name"`
+	LastBackupID     string     `json:"lastBackupId,omitempty"`
+	LastBackupTime   *time.Time `json:"lastBackupTime,omitempty"`
+	LastBackupStatus string     `json:"lastBackupStatus,omitempty"`
+	LastBackupSize   int64      `json:"lastBackupSize"`
+	TotalBackups     int        `json:"totalBackups"`
+	TotalSize        int64      `json:"totalSize"`
+}
+
+// BackupMissedRun describes a scheduled backup that did not run or failed
+type BackupMissedRun struct {
+	ScheduledTime time.Time `json:"scheduledTime"`
+	Reason        string    `json:"reason"`
+	IsWeekly      bool      `json:"isWeekly"`
+	Weekday       string    `json:"weekday,omitempty"`
+}
+
+// BackupManualRun describes the most recent manually triggered backup
+type BackupManualRun struct {
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// BackupStatusResponse is the response of GET /api/v2/backup/status
+type BackupStatusResponse struct {
+	Enabled          bool                   `json:"enabled"`
+	SchedulerRunning bool                   `json:"schedulerRunning"`
+	BackupRunning    bool                   `json:"backupRunning"`
+	Schedules        []BackupScheduleStatus `json:"schedules"`
+	Targets          []BackupTargetStatus   `json:"targets"`
+	MissedRuns       []BackupMissedRun      `json:"missedRuns"`
+	LastManualRun    *BackupManualRun       `json:"lastManualRun,omitempty"`
+}
+
+// BackupRestoreRequest is the request body of POST /api/v2/backup/:id/restore
+type BackupRestoreRequest struct {
+	Target        string `json:"target,omitempty"` // Restore from this target only
+	RestoreConfig bool   `json:"restoreConfig"`    // Also restore the sanitized configuration
+}
+
+// BackupRestoreResponse describes a completed restore
+type BackupRestoreResponse struct {
+	BackupID           string    `json:"backupId"`
+	Target             string    `json:"target"`
+	Source             string    `json:"source"`
+	Timestamp          time.Time `json:"timestamp"`
+	PreviousDataPath   string    `json:"previousDataPath,omitempty"`
+	ConfigRestored     bool      `json:"configRestored"`
+	PreviousConfigPath string    `json:"previousConfigPath,omitempty"`
+	RestartRequired    bool      `json:"restartRequired"` // A restored configuration takes effect after a restart
+	DurationMs         int64     `json:"durationMs"`
+}
+
+// initBackupRoutes registers the backup management endpoints
+func (c *Controller) initBackupRoutes() {
+	// All backup endpoints require authentication
+	backupGroup := c.Group.Group("/backup", c.getEffectiveAuthMiddleware())
+
+	backupGroup.GET("", c.ListBackups)
+	backupGroup.GET("/status", c.GetBackupStatus)
+	backupGroup.POST("/run", c.TriggerBackup)
+	backupGroup.DELETE("/:id", c.DeleteBackup)
+	backupGroup.GET("/:id/download", c.DownloadBackup)
+	backupGroup.POST("/:id/restore", c.RestoreBackup)
+}
+
+// backupSystem returns the backup manager and scheduler, or nil if the backup
+// system has not been initialized
+func (c *Controller) backupSystem() (*backup.Manager, *backup.Scheduler) {
+	if c.Processor == nil {
+		return nil, nil
+	}
+	return c.Processor.GetBackupManager(), c.Processor.GetBackupScheduler()
+}
+
+// ListBackups handles GET /api/v2/backup
+// Lists the stored backups grouped by target
+func (c *Controller) ListBackups(ctx echo.Context) error {
+	manager, _ := c.backupSystem()
+	if manager == nil {
+		return c.HandleError(ctx, errBackupUnavailable, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	backups, listErr := manager.ListBackups(ctx.Request().Context())
+	if listErr != nil && len(backups) == 0 {
+		return c.HandleError(ctx, listErr, "Failed to list backups", http.StatusInternalServerError)
+	}
+
+	// Include registered targets that hold no backups yet
+	targetIndex := make(map[string]int)
+	response := BackupListResponse{Targets: []BackupTargetResponse{}}
+	for _, name := range manager.TargetNames() {
+		targetIndex[name] = len(response.Targets)
+		response.Targets = append(response.Targets, BackupTargetResponse{Name: name, Backups: []BackupInfoResponse{}})
+	}
+
+	for i := range backups {
+		info := &backups[i]
+		idx, ok := targetIndex[info.Target]
+		if !ok {
+			idx = len(response.Targets)
+			targetIndex[info.Target] = idx
+			response.Targets = append(response.Targets, BackupTargetResponse{Name: info.Target, Backups: []BackupInfoResponse{}})
+		}
+
+		target := &response.Targets[idx]
+		target.Backups = append(target.Backups, toBackupInfoResponse(info))
+		target.Count++
+		target.Size += info.Size
+	}
+
+	if listErr != nil {
+		response.Error = listErr.Error()
+		c.logAPIRequest(ctx, slog.LevelWarn, "Listed backups with errors", "error", listErr.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// GetBackupStatus handles GET /api/v2/backup/status
+// Returns the scheduler state: schedules with their last and next runs, target
+// state, missed or failed runs and the last manual backup
+func (c *Controller) GetBackupStatus(ctx echo.Context) error {
+	manager, scheduler := c.backupSystem()
+	if manager == nil || scheduler == nil {
+		return c.HandleError(ctx, errBackupUnavailable, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	response := BackupStatusResponse{
+		Enabled:          c.Settings.Backup.Enabled,
+		SchedulerRunning: scheduler.IsRunning(),
+		BackupRunning:    scheduler.IsBackupRunning(),
+		Schedules:        []BackupScheduleStatus{},
+		Targets:          []BackupTargetStatus{},
+		MissedRuns:       []BackupMissedRun{},
+	}
+
+	for _, status := range scheduler.GetScheduleStatus() {
+		schedule := BackupScheduleStatus{
+			Type:           status.Type,
+			Hour:           status.Schedule.Hour,
+			Minute:         status.Schedule.Minute,
+			NextRun:        optionalTime(status.Schedule.NextRun),
+			LastAttempted:  optionalTime(status.State.LastAttempted),
+			LastSuccessful: optionalTime(status.State.LastSuccessful),
+			FailureCount:   status.State.FailureCount,
+		}
+		if status.Schedule.IsWeekly {
+			schedule.Weekday = status.Schedule.Weekday.String()
+		}
+		response.Schedules = append(response.Schedules, schedule)
+	}
+
+	for _, name := range manager.TargetNames() {
+		state := scheduler.GetTargetState(name)
+		response.Targets = append(response.Targets, BackupTargetStatus{
+			Name:             name,
+			LastBackupID:     state.LastBackupID,
+			LastBackupTime:   optionalTime(state.LastBackupTime),
+			LastBackupStatus: state.LastBackupStatus,
+			LastBackupSize:   state.LastBackupSize,
+			TotalBackups:     state.TotalBackups,
+			TotalSize:        state.TotalSize,
+		})
+	}
+
+	for _, missed := range scheduler.GetMissedBackups() {
+		response.MissedRuns = append(response.MissedRuns, BackupMissedRun{
+			ScheduledTime: missed.ScheduledTime,
+			Reason:        missed.Reason,
+			IsWeekly:      missed.IsWeekly,
+			Weekday:       missed.Weekday,
+		})
+	}
+
+	if run := scheduler.GetLastManualRun(); !run.StartedAt.IsZero() {
+		response.LastManualRun = &BackupManualRun{
+			StartedAt:  run.StartedAt,
+			FinishedAt: optionalTime(run.FinishedAt),
+			Error:      run.Error,
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// TriggerBackup handles POST /api/v2/backup/run
+// Starts an on-demand backup in the background, progress is reported by GET /backup/status
+func (c *Controller) TriggerBackup(ctx echo.Context) error {
+	_, scheduler := c.backupSystem()
+	if scheduler == nil {
+		return c.HandleError(ctx, errBackupUnavailable, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+	if !c.Settings.Backup.Enabled {
+		return c.HandleError(ctx, errBackupDisabled, "Backup system is disabled", http.StatusConflict)
+	}
+
+	if err := scheduler.StartBackup(); err != nil {
+		if backup.IsErrorCode(err, backup.ErrLocked) {
+			return c.HandleError(ctx, err, "A backup is already in progress", http.StatusConflict)
+		}
+		return c.HandleError(ctx, err, "Failed to start backup", http.StatusInternalServerError)
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Manual backup started")
+
+	return ctx.JSON(http.StatusAccepted, map[string]any{
+		"status":    "started",
+		"message":   "Backup started",
+		"timestamp": time.Now(),
+	})
+}
+
+// DeleteBackup handles DELETE /api/v2/backup/:id
+func (c *Controller) DeleteBackup(ctx echo.Context) error {
+	manager, _ := c.backupSystem()
+	if manager == nil {
+		return c.HandleError(ctx, errBackupUnavailable, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	id := ctx.Param("id")
+	if !backupIDPattern.MatchString(id) {
+		return c.HandleError(ctx, errInvalidBackupID, "Invalid backup ID", http.StatusBadRequest)
+	}
+
+	if err := manager.DeleteBackup(ctx.Request().Context(), id); err != nil {
+		if backup.IsErrorCode(err, backup.ErrNotFound) {
+			return c.HandleError(ctx, err, "Backup not found", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to delete backup", http.StatusInternalServerError)
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Deleted backup", "backup_id", id)
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// DownloadBackup handles GET /api/v2/backup/:id/download
+// Retrieves the backup archive from its target and sends it as an attachment
+func (c *Controller) DownloadBackup(ctx echo.Context) error {
+	manager, _ := c.backupSystem()
+	if manager == nil {
+		return c.HandleError(ctx, errBackupUnavailable, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	id := ctx.Param("id")
+	if !backupIDPattern.MatchString(id) {
+		return c.HandleError(ctx, errInvalidBackupID, "Invalid backup ID", http.StatusBadRequest)
+	}
+
+	// Stage the archive in a temporary file so that target errors can still be reported
+	tempFile, err := os.CreateTemp("", "birdnet-go-backup-download-*")
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to prepare backup download", http.StatusInternalServerError)
+	}
+	tempPath := tempFile.Name()
+	defer func() {
+		_ = os.Remove(tempPath)
+	}()
+
+	info, err := manager.DownloadBackup(ctx.Request().Context(), id, tempFile)
+	if closeErr := tempFile.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		switch {
+		case backup.IsErrorCode(err, backup.ErrNotFound):
+			return c.HandleError(ctx, err, "Backup not found", http.StatusNotFound)
+		case backup.IsErrorCode(err, backup.ErrValidation):
+			return c.HandleError(ctx, err, "Backup target does not support downloads", http.StatusNotImplemented)
+		default:
+			return c.HandleError(ctx, err, "Failed to download backup", http.StatusInternalServerError)
+		}
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Downloading backup", "backup_id", id, "target", info.Target)
+
+	return ctx.Attachment(tempPath, info.ArchiveFileName())
+}
+
+// RestoreBackup handles POST /api/v2/backup/:id/restore
+// Restores the database, and optionally the configuration, from a backup. The
+// datastore is closed while the database file is replaced and reopened afterwards.
+func (c *Controller) RestoreBackup(ctx echo.Context) error {
+	manager, scheduler := c.backupSystem()
+	if manager == nil {
+		return c.HandleError(ctx, errBackupUnavailable, "Backup system is not available", http.StatusServiceUnavailable)
+	}
+
+	id := ctx.Param("id")
+	if !backupIDPattern.MatchString(id) {
+		return c.HandleError(ctx, errInvalidBackupID, "Invalid backup ID", http.StatusBadRequest)
+	}
+
+	var req BackupRestoreRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid restore request", http.StatusBadRequest)
+	}
+
+	if scheduler != nil && scheduler.IsBackupRunning() {
+		return c.HandleError(ctx, errBackupInProgress, "A backup is in progress, try again when it has finished", http.StatusConflict)
+	}
+
+	opts := &backup.RestoreOptions{
+		Target:        req.Target,
+		RestoreConfig: req.RestoreConfig,
+	}
+	if c.DS != nil {
+		opts.StopDatastore = c.DS.Close
+		opts.StartDatastore = c.DS.Open
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Restoring backup", "backup_id", id, "restore_config", req.RestoreConfig)
+
+	result, err := manager.Restore(ctx.Request().Context(), id, opts)
+	if err != nil {
+		switch {
+		case backup.IsErrorCode(err, backup.ErrNotFound):
+			return c.HandleError(ctx, err, "Backup not found", http.StatusNotFound)
+		case backup.IsErrorCode(err, backup.ErrValidation), backup.IsErrorCode(err, backup.ErrCorruption):
+			return c.HandleError(ctx, err, "Backup cannot be restored", http.StatusUnprocessableEntity)
+		default:
+			return c.HandleError(ctx, err, "Failed to restore backup", http.StatusInternalServerError)
+		}
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Restored backup",
+		"backup_id", result.BackupID,
+		"target", result.Target,
+		"config_restored", result.ConfigRestored)
+
+	return ctx.JSON(http.StatusOK, BackupRestoreResponse{
+		BackupID:           result.BackupID,
+		Target:             result.Target,
+		Source:             result.Source,
+		Timestamp:          result.Timestamp,
+		PreviousDataPath:   result.PreviousDataPath,
+		ConfigRestored:     result.ConfigRestored,
+		PreviousConfigPath: result.PreviousConfigPath,
+		RestartRequired:    result.ConfigRestored,
+		DurationMs:         result.Duration.Milliseconds(),
+	})
+}
+
+// toBackupInfoResponse converts backup information into its API representation
+func toBackupInfoResponse(info *backup.BackupInfo) BackupInfoResponse {
+	return BackupInfoResponse{
+		ID:         info.ID,
+		Target:     info.Target,
+		Timestamp:  info.Timestamp,
+		Size:       info.Size,
+		Type:       info.Type,
+		Source:     info.Source,
+		IsDaily:    info.IsDaily,
+		IsWeekly:   info.IsWeekly,
+		AppVersion: info.AppVersion,
+		Checksum:   info.Checksum,
+		Encrypted:  info.Encrypted,
+	}
+}
+
+// optionalTime returns nil for zero times so they are omitted from responses
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
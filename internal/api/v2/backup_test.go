@@ -0,0 +1,285 @@
+// backup_test.go: Package api provides tests for API v2 backup endpoints.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/analysis/processor"
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/backup/targets"
+)
+
+// setupBackupTestController creates a controller with a backup manager using a
+// local target in a temporary directory
+func setupBackupTestController(t *testing.T) (*Controller, *targets.LocalTarget, string) {
+	t.Helper()
+
+	// The backup state file is stored in the user's config directory
+	t.Setenv("HOME", t.TempDir())
+
+	_, _, controller := setupTestEnvironment(t)
+	controller.Settings.Backup.Enabled = true
+
+	stateManager, err := backup.NewStateManager(nil)
+	require.NoError(t, err)
+
+	manager, err := backup.NewManager(controller.Settings, nil, stateManager, "test")
+	require.NoError(t, err)
+
+	targetDir := t.TempDir()
+	localTarget, err := targets.NewLocalTarget(targets.LocalTargetConfig{Path: targetDir}, nil)
+	require.NoError(t, err)
+	require.NoError(t, manager.RegisterTarget(localTarget))
+
+	scheduler, err := backup.NewScheduler(manager, nil, stateManager)
+	require.NoError(t, err)
+
+	controller.Processor = &processor.Processor{}
+	controller.Processor.SetBackupManager(manager)
+	controller.Processor.SetBackupScheduler(scheduler)
+
+	return controller, localTarget, targetDir
+}
+
+// storeTestBackup stores a fake backup archive in the target and returns its content
+func storeTestBackup(t *testing.T, target backup.Target, id string) []byte {
+	t.Helper()
+
+	content := []byte("test backup archive " + id)
+	archivePath := filepath.Join(t.TempDir(), id+".tar")
+	require.NoError(t, os.WriteFile(archivePath, content, 0o600))
+
+	metadata := &backup.Metadata{
+		Version:   1,
+		ID:        id,
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+		Size:      int64(len(content)),
+		Type:      "sqlite",
+		Source:    "sqlite",
+		IsDaily:   true,
+	}
+	require.NoError(t, target.Store(context.Background(), archivePath, metadata))
+
+	return content
+}
+
+// newBackupRequest creates an echo context for a backup endpoint
+func newBackupRequest(e *echo.Echo, method, path, id string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, path, http.NoBody)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	if id != "" {
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+	}
+	return ctx, rec
+}
+
+// TestListBackups tests that backups are listed grouped by target
+func TestListBackups(t *testing.T) {
+	controller, localTarget, _ := setupBackupTestController(t)
+	storeTestBackup(t, localTarget, "sqlite-20240101-120000")
+	storeTestBackup(t, localTarget, "sqlite-20240102-120000")
+
+	ctx, rec := newBackupRequest(controller.Echo, http.MethodGet, "/api/v2/backup", "")
+	require.NoError(t, controller.ListBackups(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response BackupListResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Targets, 1)
+	assert.Equal(t, localTarget.Name(), response.Targets[0].Name)
+	assert.Equal(t, 2, response.Targets[0].Count)
+	assert.Len(t, response.Targets[0].Backups, 2)
+	assert.Empty(t, response.Error)
+}
+
+// TestListBackupsUnavailable tests that backup endpoints fail when the backup system is not initialized
+func TestListBackupsUnavailable(t *testing.T) {
+	_, _, controller := setupTestEnvironment(t)
+
+	ctx, rec := newBackupRequest(controller.Echo, http.MethodGet, "/api/v2/backup", "")
+	require.NoError(t, controller.ListBackups(ctx))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+// TestGetBackupStatus tests the backup status endpoint
+func TestGetBackupStatus(t *testing.T) {
+	controller, localTarget, _ := setupBackupTestController(t)
+
+	ctx, rec := newBackupRequest(controller.Echo, http.MethodGet, "/api/v2/backup/status", "")
+	require.NoError(t, controller.GetBackupStatus(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response BackupStatusResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.True(t, response.Enabled)
+	assert.False(t, response.BackupRunning)
+	require.Len(t, response.Targets, 1)
+	assert.Equal(t, localTarget.Name(), response.Targets[0].Name)
+	assert.NotNil(t, response.Schedules)
+	assert.NotNil(t, response.MissedRuns)
+	assert.Nil(t, response.LastManualRun)
+}
+
+// TestTriggerBackupDisabled tests that a manual backup is rejected when backups are disabled
+func TestTriggerBackupDisabled(t *testing.T) {
+	controller, _, _ := setupBackupTestController(t)
+	controller.Settings.Backup.Enabled = false
+
+	ctx, rec := newBackupRequest(controller.Echo, http.MethodPost, "/api/v2/backup/run", "")
+	require.NoError(t, controller.TriggerBackup(ctx))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+// TestDeleteBackup tests deleting backups
+func TestDeleteBackup(t *testing.T) {
+	controller, localTarget, targetDir := setupBackupTestController(t)
+	storeTestBackup(t, localTarget, "sqlite-20240101-120000")
+
+	testCases := []struct {
+		name         string
+		id           string
+		expectedCode int
+	}{
+		{"Invalid ID", "..", http.StatusBadRequest},
+		{"Unknown backup", "sqlite-20990101-000000", http.StatusNotFound},
+		{"Existing backup", "sqlite-20240101-120000", http.StatusNoContent},
+		{"Already deleted", "sqlite-20240101-120000", http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, rec := newBackupRequest(controller.Echo, http.MethodDelete, "/api/v2/backup/"+tc.id, tc.id)
+			require.NoError(t, controller.DeleteBackup(ctx))
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	// Both the archive and its metadata must be removed
+	entries, err := os.ReadDir(targetDir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), "sqlite-20240101-120000")
+	}
+}
+
+// TestDownloadBackup tests downloading a backup archive
+func TestDownloadBackup(t *testing.T) {
+	controller, localTarget, _ := setupBackupTestController(t)
+	content := storeTestBackup(t, localTarget, "sqlite-20240101-120000")
+
+	t.Run("Existing backup", func(t *testing.T) {
+		ctx, rec := newBackupRequest(controller.Echo, http.MethodGet, "/api/v2/backup/sqlite-20240101-120000/download", "sqlite-20240101-120000")
+		require.NoError(t, controller.DownloadBackup(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, content, rec.Body.Bytes())
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "sqlite-20240101-120000.tar")
+	})
+
+	t.Run("Unknown backup", func(t *testing.T) {
+		ctx, rec := newBackupRequest(controller.Echo, http.MethodGet, "/api/v2/backup/missing/download", "missing")
+		require.NoError(t, controller.DownloadBackup(ctx))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		ctx, rec := newBackupRequest(controller.Echo, http.MethodGet, "/api/v2/backup/.hidden/download", ".hidden")
+		require.NoError(t, controller.DownloadBackup(ctx))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+// restoreTestSource is a backup source returning fixed data
+type restoreTestSource struct{}
+
+func (restoreTestSource) Name() string    { return "birdnet" }
+func (restoreTestSource) Validate() error { return nil }
+func (restoreTestSource) Backup(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("database contents")), nil
+}
+
+// restoreTestRestorer records the restored data and runs the datastore hooks
+type restoreTestRestorer struct {
+	restored []byte
+}
+
+func (r *restoreTestRestorer) Name() string { return "birdnet" }
+
+func (r *restoreTestRestorer) Restore(ctx context.Context, dataPath string, opts *backup.RestoreOptions) (string, error) {
+	data, err := os.ReadFile(dataPath)
+	if err != nil {
+		return "", err
+	}
+	if err := opts.StopDatastore(); err != nil {
+		return "", err
+	}
+	r.restored = data
+	return dataPath + ".previous", opts.StartDatastore()
+}
+
+// TestRestoreBackup tests restoring a backup through the API
+func TestRestoreBackup(t *testing.T) {
+	controller, localTarget, _ := setupBackupTestController(t)
+	manager := controller.Processor.GetBackupManager()
+
+	// Create a real backup archive
+	require.NoError(t, manager.RegisterSource(restoreTestSource{}))
+	require.NoError(t, manager.RunBackup(context.Background()))
+	backups, err := localTarget.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backupID := backups[0].ID
+
+	// A backup that is not a valid archive
+	storeTestBackup(t, localTarget, "birdnet-20240101-120000")
+
+	restorer := &restoreTestRestorer{}
+	manager.RegisterRestorer(restorer)
+
+	mockDS := controller.DS.(*MockDataStore)
+	mockDS.On("Close").Return(nil).Once()
+	mockDS.On("Open").Return(nil).Once()
+
+	testCases := []struct {
+		name         string
+		id           string
+		expectedCode int
+	}{
+		{"Invalid ID", "..", http.StatusBadRequest},
+		{"Unknown backup", "birdnet-20990101-000000", http.StatusNotFound},
+		{"Invalid archive", "birdnet-20240101-120000", http.StatusUnprocessableEntity},
+		{"Valid backup", backupID, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/backup/"+tc.id+"/restore", bytes.NewBufferString(`{"restoreConfig":false}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			ctx := controller.Echo.NewContext(req, rec)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(tc.id)
+
+			require.NoError(t, controller.RestoreBackup(ctx))
+			assert.Equal(t, tc.expectedCode, rec.Code, rec.Body.String())
+		})
+	}
+
+	assert.Equal(t, []byte("database contents"), restorer.restored)
+	mockDS.AssertExpectations(t)
+}
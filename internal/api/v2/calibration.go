@@ -0,0 +1,130 @@
+// internal/api/v2/calibration.go
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/calibration"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// CalibrationResponse is the species threshold calibration derived from reviewed detections
+type CalibrationResponse struct {
+	TargetPrecision float64               `json:"targetPrecision"`
+	MinReviews      int                   `json:"minReviews"`
+	MaxThreshold    float64               `json:"maxThreshold"`
+	AutoApply       bool                  `json:"autoApply"`
+	Species         []calibration.Species `json:"species"`
+}
+
+// CalibrationApplyRequest selects the species whose suggested thresholds are applied
+type CalibrationApplyRequest struct {
+	Species []string `json:"species"` // Common or scientific names, all species with a suggestion if empty
+}
+
+// CalibrationApplyResponse lists the raised species thresholds
+type CalibrationApplyResponse struct {
+	Changes []calibration.Change `json:"changes"`
+	Message string               `json:"message"`
+}
+
+// initCalibrationRoutes registers the species threshold calibration endpoints
+func (c *Controller) initCalibrationRoutes() {
+	// Calibration reads and changes the species thresholds, it requires authentication
+	calibrationGroup := c.Group.Group("/calibration", c.getEffectiveAuthMiddleware())
+	calibrationGroup.GET("", c.GetCalibration)
+	calibrationGroup.POST("/apply", c.ApplyCalibration)
+}
+
+// loadCalibration computes the species calibrations from the reviewed detections
+func (c *Controller) loadCalibration(ctx echo.Context) ([]calibration.Species, calibration.Options, error) {
+	c.settingsMutex.RLock()
+	opts := calibration.OptionsFromSettings(c.Settings)
+	c.settingsMutex.RUnlock()
+
+	species, err := calibration.Load(ctx.Request().Context(), c.DS, opts)
+	return species, opts, err
+}
+
+// GetCalibration handles GET /api/v2/calibration
+// Returns the precision curve of each reviewed species, the expected precision at the
+// threshold in use and the suggested threshold for species below the target precision.
+func (c *Controller) GetCalibration(ctx echo.Context) error {
+	if c.DS == nil {
+		return c.HandleError(ctx, errExportNoStore, "Datastore is not available", http.StatusServiceUnavailable)
+	}
+
+	species, opts, err := c.loadCalibration(ctx)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to calibrate species thresholds", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusOK, CalibrationResponse{
+		TargetPrecision: opts.TargetPrecision,
+		MinReviews:      opts.MinReviews,
+		MaxThreshold:    opts.MaxThreshold,
+		AutoApply:       c.Settings.Realtime.Calibration.AutoApply,
+		Species:         species,
+	})
+}
+
+// ApplyCalibration handles POST /api/v2/calibration/apply
+// Raises the thresholds of the selected species to the suggested thresholds and saves
+// the settings.
+func (c *Controller) ApplyCalibration(ctx echo.Context) error {
+	if c.DS == nil {
+		return c.HandleError(ctx, errExportNoStore, "Datastore is not available", http.StatusServiceUnavailable)
+	}
+
+	var req CalibrationApplyRequest
+	if ctx.Request().ContentLength != 0 {
+		if err := ctx.Bind(&req); err != nil {
+			return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+		}
+	}
+
+	species, _, err := c.loadCalibration(ctx)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to calibrate species thresholds", http.StatusInternalServerError)
+	}
+	if len(req.Species) > 0 {
+		selected := species[:0]
+		for i := range species {
+			if calibrationSelected(&species[i], req.Species) {
+				selected = append(selected, species[i])
+			}
+		}
+		species = selected
+	}
+
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	changes := calibration.Apply(c.Settings, species)
+	if len(changes) > 0 && !c.DisableSaveSettings {
+		if err := conf.SaveSettings(); err != nil {
+			return c.HandleError(ctx, err, "Failed to save settings", http.StatusInternalServerError)
+		}
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Applied species threshold calibration", "changes", len(changes))
+
+	return ctx.JSON(http.StatusOK, CalibrationApplyResponse{
+		Changes: changes,
+		Message: fmt.Sprintf("Raised the thresholds of %d species", len(changes)),
+	})
+}
+
+// calibrationSelected reports whether a species is in a list of common or scientific names
+func calibrationSelected(species *calibration.Species, names []string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, species.CommonName) || strings.EqualFold(name, species.ScientificName) {
+			return true
+		}
+	}
+	return false
+}
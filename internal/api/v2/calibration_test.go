@@ -0,0 +1,108 @@
+// calibration_test.go: Package api provides tests for the species threshold calibration endpoints.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// calibrationTestNotes returns reviewed wren detections whose false positives stop at 0.85
+// and a correct blackbird detection
+func calibrationTestNotes() []datastore.Note {
+	var notes []datastore.Note
+	for i, confidence := range []float64{0.8, 0.82, 0.85, 0.81, 0.88, 0.9, 0.93, 0.96} {
+		verified := "correct"
+		if i < 3 {
+			verified = "false_positive"
+		}
+		notes = append(notes, datastore.Note{
+			ID:             uint(i + 1),
+			Time:           fmt.Sprintf("05:%02d:00", i),
+			CommonName:     "Eurasian Wren",
+			ScientificName: "Troglodytes troglodytes",
+			Confidence:     confidence,
+			Verified:       verified,
+		})
+	}
+	return append(notes, datastore.Note{ID: 9, CommonName: "Eurasian Blackbird", ScientificName: "Turdus merula", Confidence: 0.9, Verified: "correct"})
+}
+
+// setupCalibrationTest returns a controller whose datastore serves the calibration notes
+func setupCalibrationTest(t *testing.T) (*echo.Echo, *Controller) {
+	t.Helper()
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.DisableSaveSettings = true
+	controller.Settings.BirdNET.Threshold = 0.8
+	controller.Settings.Realtime.Calibration = conf.CalibrationSettings{TargetPrecision: 0.9, MinReviews: 5, MaxThreshold: 0.95}
+
+	mockDS.On("StreamNotesAdvanced", mock.MatchedBy(func(f *datastore.AdvancedSearchFilters) bool {
+		return f.Verified != nil && *f.Verified
+	}), mock.Anything).Return(calibrationTestNotes(), nil)
+	return e, controller
+}
+
+func TestGetCalibration(t *testing.T) {
+	e, controller := setupCalibrationTest(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/calibration", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetCalibration(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp CalibrationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.InDelta(t, 0.9, resp.TargetPrecision, 1e-9)
+	require.Len(t, resp.Species, 2)
+
+	blackbird, wren := resp.Species[0], resp.Species[1]
+	assert.Equal(t, "Eurasian Blackbird", blackbird.CommonName)
+	assert.Nil(t, blackbird.SuggestedThreshold)
+
+	assert.Equal(t, 8, wren.Reviewed)
+	assert.Equal(t, 3, wren.FalsePositives)
+	require.NotNil(t, wren.ExpectedPrecision)
+	assert.InDelta(t, 5.0/8, *wren.ExpectedPrecision, 1e-9)
+	require.NotNil(t, wren.SuggestedThreshold)
+	assert.InDelta(t, 0.86, *wren.SuggestedThreshold, 1e-9)
+	assert.NotEmpty(t, wren.Curve)
+}
+
+func TestApplyCalibration(t *testing.T) {
+	e, controller := setupCalibrationTest(t)
+
+	apply := func(body string) CalibrationApplyResponse {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/calibration/apply", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		require.NoError(t, controller.ApplyCalibration(e.NewContext(req, rec)))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp CalibrationApplyResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+
+	// Species that are not selected are left alone
+	assert.Empty(t, apply(`{"species": ["Turdus merula"]}`).Changes)
+	assert.NotContains(t, controller.Settings.Realtime.Species.Config, "eurasian wren")
+
+	resp := apply(`{"species": ["troglodytes troglodytes"]}`)
+	require.Len(t, resp.Changes, 1)
+	assert.Equal(t, "Eurasian Wren", resp.Changes[0].CommonName)
+	assert.InDelta(t, 0.86, controller.Settings.Realtime.Species.Config["eurasian wren"].Threshold, 1e-9)
+
+	// The raised threshold reaches the target precision
+	assert.Empty(t, apply("").Changes)
+}
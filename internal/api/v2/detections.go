@@ -83,6 +83,7 @@ type DetectionResponse struct {
 	ScientificName     string       `json:"scientificName"`
 	CommonName         string       `json:"commonName"`
 	Confidence         float64      `json:"confidence"`
+	Model              string       `json:"model,omitempty"` // ID of the classifier model that produced the detection
 	Verified           string       `json:"verified"`
 	Locked             bool         `json:"locked"`
 	Comments           []string     `json:"comments,omitempty"`
@@ -482,6 +483,7 @@ func (c *Controller) noteToDetectionResponse(note *datastore.Note, includeWeathe
 		ScientificName: note.ScientificName,
 		CommonName:     note.CommonName,
 		Confidence:     note.Confidence,
+		Model:          note.Model,
 		Locked:         note.Locked,
 	}
 
@@ -717,11 +719,37 @@ func (c *Controller) getSpeciesDetections(species, date, hour string, duration,
 // getSearchDetectionsAdvanced handles advanced search with filters
 func (c *Controller) getSearchDetectionsAdvanced(params *detectionQueryParams) ([]datastore.Note, int64, error) {
 	// Parse advanced filters from query parameters
+	filters := buildAdvancedSearchFilters(params)
+	filters.Limit = params.NumResults
+	filters.Offset = params.Offset
+	filters.SortAscending = false // Default to descending
+
+	// Use the advanced search method
+	notes, totalCount, err := c.DS.SearchNotesAdvanced(&filters)
+	if err != nil {
+		if c.apiLogger != nil {
+			c.apiLogger.Error("Failed to perform advanced search",
+				"filters", fmt.Sprintf("%+v", filters),
+				"error", err.Error(),
+			)
+		}
+		return nil, 0, err
+	}
+
+	// Cache the results
+	cacheKey := fmt.Sprintf("adv_search:%s:%d:%d", params.Search, params.NumResults, params.Offset)
+	c.detectionCache.Set(cacheKey, struct {
+		Notes []datastore.Note
+		Total int64
+	}{notes, totalCount}, cache.DefaultExpiration)
+
+	return notes, totalCount, nil
+}
+
+// buildAdvancedSearchFilters converts the advanced filter query parameters into search filters
+func buildAdvancedSearchFilters(params *detectionQueryParams) datastore.AdvancedSearchFilters {
 	filters := datastore.AdvancedSearchFilters{
-		TextQuery:     params.Search,
-		Limit:         params.NumResults,
-		Offset:        params.Offset,
-		SortAscending: false, // Default to descending
+		TextQuery: params.Search,
 	}
 
 	// Parse confidence filter
@@ -835,26 +863,7 @@ func (c *Controller) getSearchDetectionsAdvanced(params *detectionQueryParams) (
 		filters.Location = []string{params.Location}
 	}
 
-	// Use the advanced search method
-	notes, totalCount, err := c.DS.SearchNotesAdvanced(&filters)
-	if err != nil {
-		if c.apiLogger != nil {
-			c.apiLogger.Error("Failed to perform advanced search",
-				"filters", fmt.Sprintf("%+v", filters),
-				"error", err.Error(),
-			)
-		}
-		return nil, 0, err
-	}
-
-	// Cache the results
-	cacheKey := fmt.Sprintf("adv_search:%s:%d:%d", params.Search, params.NumResults, params.Offset)
-	c.detectionCache.Set(cacheKey, struct {
-		Notes []datastore.Note
-		Total int64
-	}{notes, totalCount}, cache.DefaultExpiration)
-
-	return notes, totalCount, nil
+	return filters
 }
 
 // getSearchDetections handles search query type logic
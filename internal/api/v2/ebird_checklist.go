@@ -0,0 +1,179 @@
+// internal/api/v2/ebird_checklist.go
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/ebird"
+)
+
+// eBird checklist export errors
+var (
+	errChecklistDate     = fmt.Errorf("date must be in YYYY-MM-DD format")
+	errChecklistTime     = fmt.Errorf("start_time and end_time are required in HH:MM format")
+	errChecklistProtocol = fmt.Errorf("protocol must be stationary or incidental")
+	errChecklistCount    = fmt.Errorf("count must be presence or detections")
+	errChecklistNoEBird  = fmt.Errorf("eBird integration is not enabled")
+)
+
+// parseChecklistRequest parses the observation window, location and effort of a checklist
+// export. The window ends on the next day if end_time is not after start_time.
+func (c *Controller) parseChecklistRequest(ctx echo.Context) (*ebird.ChecklistOptions, error) {
+	date, err := time.ParseInLocation("2006-01-02", ctx.QueryParam("date"), time.Local)
+	if err != nil {
+		return nil, errChecklistDate
+	}
+	startClock, err1 := time.Parse("15:04", ctx.QueryParam("start_time"))
+	endClock, err2 := time.Parse("15:04", ctx.QueryParam("end_time"))
+	if err1 != nil || err2 != nil {
+		return nil, errChecklistTime
+	}
+	start := time.Date(date.Year(), date.Month(), date.Day(), startClock.Hour(), startClock.Minute(), 0, 0, time.Local)
+	end := time.Date(date.Year(), date.Month(), date.Day(), endClock.Hour(), endClock.Minute(), 0, 0, time.Local)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	opts := ebird.ChecklistOptions{
+		Location: ebird.ChecklistLocation{
+			Name:      ctx.QueryParam("location_name"),
+			Latitude:  c.Settings.BirdNET.Latitude,
+			Longitude: c.Settings.BirdNET.Longitude,
+			State:     strings.ToUpper(ctx.QueryParam("state")),
+			Country:   strings.ToUpper(ctx.QueryParam("country")),
+		},
+		Start:    start,
+		End:      end,
+		Comments: ctx.QueryParam("comments"),
+		Complete: ctx.QueryParam("complete") == "true",
+	}
+	if opts.Location.Name == "" {
+		opts.Location.Name = c.Settings.Main.Name
+	}
+
+	if value := ctx.QueryParam("latitude"); value != "" {
+		if opts.Location.Latitude, err = strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("invalid latitude: %s", value)
+		}
+	}
+	if value := ctx.QueryParam("longitude"); value != "" {
+		if opts.Location.Longitude, err = strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("invalid longitude: %s", value)
+		}
+	}
+	if value := ctx.QueryParam("observers"); value != "" {
+		if opts.Observers, err = strconv.Atoi(value); err != nil || opts.Observers < 1 {
+			return nil, fmt.Errorf("invalid observers: %s", value)
+		}
+	}
+
+	// Detections are not individuals, so counts are only reported when asked for
+	switch strings.ToLower(ctx.QueryParam("count")) {
+	case "", "presence":
+	case "detections":
+		opts.ReportCounts = true
+	default:
+		return nil, errChecklistCount
+	}
+
+	// Traveling counts need an effort distance that detections do not have
+	switch strings.ToLower(ctx.QueryParam("protocol")) {
+	case "", "stationary":
+		opts.Protocol = ebird.ProtocolStationary
+	case "incidental":
+		opts.Protocol = ebird.ProtocolIncidental
+	default:
+		return nil, errChecklistProtocol
+	}
+
+	return &opts, nil
+}
+
+// ExportEBirdChecklist handles GET /api/v2/detections/export/ebird
+// Builds an eBird checklist in the eBird Record Format (Extended) from the detections of
+// an observation window. Query parameters:
+//   - date (YYYY-MM-DD), start_time and end_time (HH:MM): the observation window
+//   - location_name, latitude, longitude, state, country: the checklist location, the
+//     node name and configured coordinates by default
+//   - protocol (stationary or incidental), observers, complete, comments: the effort
+//   - count: "presence" (default) reports species as present ("X"), "detections" reports
+//     the number of detections per species, which overstates the number of birds
+//
+// Only detections reviewed as correct are included, unverified and false positive
+// detections are left out. Species are mapped to the eBird taxonomy by scientific name.
+func (c *Controller) ExportEBirdChecklist(ctx echo.Context) error {
+	if c.DS == nil {
+		return c.HandleError(ctx, errExportNoStore, "Datastore is not available", http.StatusServiceUnavailable)
+	}
+	if c.EBirdClient == nil {
+		return c.HandleError(ctx, errChecklistNoEBird, "eBird integration is not enabled", http.StatusConflict)
+	}
+
+	opts, err := c.parseChecklistRequest(ctx)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid checklist request", http.StatusBadRequest)
+	}
+
+	taxonomy, err := c.EBirdClient.GetTaxonomy(ctx.Request().Context(), "")
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to fetch eBird taxonomy", http.StatusBadGateway)
+	}
+	checklist, err := ebird.NewChecklist(taxonomy, *opts)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid checklist request", http.StatusBadRequest)
+	}
+
+	verified := true
+	filters := datastore.AdvancedSearchFilters{
+		DateRange: &datastore.DateRange{Start: opts.Start, End: opts.End},
+		Verified:  &verified,
+	}
+	err = c.DS.StreamNotesAdvanced(ctx.Request().Context(), &filters, exportBatchSize, func(notes []datastore.Note) error {
+		for i := range notes {
+			note := &notes[i]
+			if note.Verified != "correct" {
+				continue
+			}
+			detected, err := time.ParseInLocation("2006-01-02 15:04:05", note.Date+" "+note.Time, time.Local)
+			if err != nil || detected.Before(opts.Start) || !detected.Before(opts.End) {
+				continue
+			}
+			checklist.Add(note.ScientificName, note.CommonName, note.Confidence)
+		}
+		return nil
+	})
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to read detections", http.StatusInternalServerError)
+	}
+
+	if unmatched := checklist.Unmatched(); len(unmatched) > 0 {
+		species := make([]string, 0, len(unmatched))
+		for name := range unmatched {
+			species = append(species, name)
+		}
+		c.logAPIRequest(ctx, slog.LevelWarn, "Species not in the eBird taxonomy left out of checklist", "species", strings.Join(species, ", "))
+	}
+
+	name := fmt.Sprintf("ebird-checklist_%s.csv", opts.Start.Format("2006-01-02_1504"))
+	resp := ctx.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name))
+	resp.WriteHeader(http.StatusOK)
+	if err := checklist.WriteCSV(resp); err != nil {
+		c.logAPIRequest(ctx, slog.LevelError, "Failed to write eBird checklist", "error", err.Error())
+		return nil
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Exported eBird checklist",
+		"species", len(checklist.Entries()),
+		"detections", checklist.Detections())
+
+	return nil
+}
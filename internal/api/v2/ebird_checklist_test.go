@@ -0,0 +1,106 @@
+// ebird_checklist_test.go: Package api provides tests for the eBird checklist export endpoint.
+
+package api
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/ebird"
+)
+
+// checklistTestTaxonomy is the eBird taxonomy served to the checklist tests
+const checklistTestTaxonomy = `[
+	{"sciName": "Turdus merula", "comName": "Eurasian Blackbird", "speciesCode": "eurbla", "category": "species", "taxonOrder": 26000},
+	{"sciName": "Erithacus rubecula", "comName": "European Robin", "speciesCode": "eurrob1", "category": "species", "taxonOrder": 27000}
+]`
+
+// newChecklistTestClient returns an eBird client using a test server for the taxonomy
+func newChecklistTestClient(t *testing.T) *ebird.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(checklistTestTaxonomy))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := ebird.NewClient(ebird.Config{APIKey: "test-key", BaseURL: server.URL, RateLimitMS: 1})
+	require.NoError(t, err)
+	return client
+}
+
+// newChecklistRequest creates an echo context for the checklist export endpoint
+func newChecklistRequest(e *echo.Echo, query string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/detections/export/ebird?"+query, http.NoBody)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestExportEBirdChecklist(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.EBirdClient = newChecklistTestClient(t)
+
+	notes := exportTestNotes()
+	notes = append(notes,
+		datastore.Note{ID: 3, Date: "2024-05-01", Time: "05:45:00", ScientificName: "Erithacus rubecula", Confidence: 0.93, Verified: "correct"},
+		datastore.Note{ID: 4, Date: "2024-05-01", Time: "05:50:00", ScientificName: "Turdus merula", Confidence: 0.9, Verified: "false_positive"},
+		datastore.Note{ID: 5, Date: "2024-05-01", Time: "07:30:00", ScientificName: "Turdus merula", Confidence: 0.9, Verified: "correct"},
+	)
+	mockDS.On("StreamNotesAdvanced", mock.MatchedBy(func(f *datastore.AdvancedSearchFilters) bool {
+		return f.Verified != nil && *f.Verified && f.DateRange != nil
+	}), exportBatchSize).Return(notes, nil).Twice()
+
+	ctx, rec := newChecklistRequest(e, "date=2024-05-01&start_time=05:00&end_time=07:00&location_name=Garden&latitude=60.1&longitude=24.9&country=fi")
+	require.NoError(t, controller.ExportEBirdChecklist(ctx))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "ebird-checklist_2024-05-01_0500.csv")
+
+	// Only the correct detections of the window are counted
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, []string{"European Robin", "Erithacus", "rubecula", "X"}, rows[0][:4])
+	assert.Contains(t, rows[0][4], "2 verified detection(s)")
+	assert.Equal(t, []string{"Garden", "60.100000", "24.900000", "05/01/2024", "05:00", "", "FI", "Stationary", "1", "120", "N"}, rows[0][5:16])
+
+	// Detection counts are reported only when asked for
+	ctx, rec = newChecklistRequest(e, "date=2024-05-01&start_time=05:00&end_time=07:00&count=detections")
+	require.NoError(t, controller.ExportEBirdChecklist(ctx))
+	require.Equal(t, http.StatusOK, rec.Code)
+	rows, err = csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "2", rows[0][3])
+	mockDS.AssertExpectations(t)
+}
+
+func TestExportEBirdChecklist_Errors(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	// eBird integration is required for the taxonomy
+	ctx, rec := newChecklistRequest(e, "date=2024-05-01&start_time=05:00&end_time=07:00")
+	require.NoError(t, controller.ExportEBirdChecklist(ctx))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	controller.EBirdClient = newChecklistTestClient(t)
+	for _, query := range []string{
+		"date=05/01/2024&start_time=05:00&end_time=07:00",
+		"date=2024-05-01&start_time=5am&end_time=07:00",
+		"date=2024-05-01&start_time=05:00",
+		"date=2024-05-01&start_time=05:00&end_time=07:00&protocol=traveling",
+		"date=2024-05-01&start_time=05:00&end_time=07:00&observers=0",
+		"date=2024-05-01&start_time=05:00&end_time=07:00&count=individuals",
+	} {
+		ctx, rec := newChecklistRequest(e, query)
+		require.NoError(t, controller.ExportEBirdChecklist(ctx))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -0,0 +1,301 @@
+// internal/api/v2/export.go
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/observation"
+)
+
+// Detection export formats
+const (
+	ExportFormatCSV      = "csv"
+	ExportFormatNDJSON   = "ndjson"
+	ExportFormatDwCA     = "dwca"
+	ExportFormatRaven    = "raven"
+	ExportFormatAudacity = "audacity"
+)
+
+// exportBatchSize is the number of detections read from the datastore at a time
+const exportBatchSize = 1000
+
+// exportFormat describes the download of an export format
+type exportFormat struct {
+	suffix      string
+	contentType string
+}
+
+// exportFormats lists the supported export formats
+var exportFormats = map[string]exportFormat{
+	ExportFormatCSV:      {".csv", "text/csv; charset=utf-8"},
+	ExportFormatNDJSON:   {".ndjson", "application/x-ndjson"},
+	ExportFormatDwCA:     {"-dwca.zip", "application/zip"},
+	ExportFormatRaven:    {observation.RavenFileSuffix, "text/plain; charset=utf-8"},
+	ExportFormatAudacity: {observation.AudacityFileSuffix, "text/plain; charset=utf-8"},
+}
+
+// Export API errors
+var (
+	errExportDateRange = fmt.Errorf("a date range (date or start_date and end_date) is required for selection exports")
+	errExportFormat    = fmt.Errorf("unsupported export format")
+	errExportNoStore   = fmt.Errorf("datastore is not available")
+)
+
+// initExportRoutes registers the detection export endpoints
+func (c *Controller) initExportRoutes() {
+	// Bulk export requires authentication
+	exportGroup := c.Group.Group("/detections/export", c.getEffectiveAuthMiddleware())
+	exportGroup.GET("", c.ExportDetections)
+	exportGroup.GET("/ebird", c.ExportEBirdChecklist)
+}
+
+// DetectionExportRecord is a detection in the CSV and NDJSON exports
+type DetectionExportRecord struct {
+	ID             uint    `json:"id"`
+	Date           string  `json:"date"`
+	Time           string  `json:"time"`
+	BeginTime      string  `json:"beginTime"`
+	EndTime        string  `json:"endTime"`
+	ScientificName string  `json:"scientificName"`
+	CommonName     string  `json:"commonName"`
+	SpeciesCode    string  `json:"speciesCode"`
+	Confidence     float64 `json:"confidence"`
+	Model          string  `json:"model,omitempty"`
+	Verified       string  `json:"verified"`
+	Locked         bool    `json:"locked"`
+	ClipName       string  `json:"clipName,omitempty"`
+}
+
+// exportCSVHeader is the header row of the CSV export, in DetectionExportRecord field order
+var exportCSVHeader = []string{
+	"id", "date", "time", "begin_time", "end_time", "scientific_name", "common_name", "species_code",
+	"confidence", "model", "verified", "locked", "clip_name",
+}
+
+// noteToExportRecord converts a note to an export record
+func (c *Controller) noteToExportRecord(note *datastore.Note) DetectionExportRecord {
+	begin, end := observation.NoteTimeRange(note)
+	return DetectionExportRecord{
+		ID:             note.ID,
+		Date:           note.Date,
+		Time:           note.Time,
+		BeginTime:      begin.Format(time.RFC3339),
+		EndTime:        end.Format(time.RFC3339),
+		ScientificName: note.ScientificName,
+		CommonName:     note.CommonName,
+		SpeciesCode:    note.SpeciesCode,
+		Confidence:     note.Confidence,
+		Model:          note.Model,
+		Verified:       c.mapVerificationStatus(note.Verified),
+		Locked:         note.Locked,
+		ClipName:       note.ClipName,
+	}
+}
+
+// exportEncoder writes batches of detections in an export format
+type exportEncoder interface {
+	Write(notes []datastore.Note) error
+	// Close writes any buffered data and completes the output
+	Close() error
+}
+
+// newExportEncoder creates the encoder for an export format. Selection exports use
+// origin as the zero time of the recording.
+func (c *Controller) newExportEncoder(format string, w io.Writer, origin time.Time) (exportEncoder, error) {
+	switch format {
+	case ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportCSVHeader); err != nil {
+			return nil, err
+		}
+		return &csvExportEncoder{c: c, w: cw}, nil
+	case ExportFormatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonExportEncoder{c: c, w: bw, enc: json.NewEncoder(bw)}, nil
+	case ExportFormatDwCA:
+		return newDwCAExportEncoder(w, c.Settings, c.mapVerificationStatus)
+	case ExportFormatRaven:
+		sw, err := observation.NewRavenSelectionWriter(w, origin)
+		if err != nil {
+			return nil, err
+		}
+		return &selectionExportEncoder{sw}, nil
+	case ExportFormatAudacity:
+		return &selectionExportEncoder{observation.NewAudacityLabelWriter(w, origin)}, nil
+	default:
+		return nil, errExportFormat
+	}
+}
+
+// csvExportEncoder writes detections as CSV rows
+type csvExportEncoder struct {
+	c *Controller
+	w *csv.Writer
+}
+
+func (e *csvExportEncoder) Write(notes []datastore.Note) error {
+	for i := range notes {
+		r := e.c.noteToExportRecord(&notes[i])
+		if err := e.w.Write([]string{
+			strconv.FormatUint(uint64(r.ID), 10), r.Date, r.Time, r.BeginTime, r.EndTime,
+			r.ScientificName, r.CommonName, r.SpeciesCode, strconv.FormatFloat(r.Confidence, 'f', 4, 64),
+			r.Model, r.Verified, strconv.FormatBool(r.Locked), r.ClipName,
+		}); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExportEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ndjsonExportEncoder writes detections as newline delimited JSON
+type ndjsonExportEncoder struct {
+	c   *Controller
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonExportEncoder) Write(notes []datastore.Note) error {
+	for i := range notes {
+		if err := e.enc.Encode(e.c.noteToExportRecord(&notes[i])); err != nil {
+			return err
+		}
+	}
+	return e.w.Flush()
+}
+
+func (e *ndjsonExportEncoder) Close() error {
+	return e.w.Flush()
+}
+
+// selectionExportEncoder writes detections as a Raven selection table or Audacity labels
+type selectionExportEncoder struct {
+	sw *observation.SelectionWriter
+}
+
+func (e *selectionExportEncoder) Write(notes []datastore.Note) error {
+	if err := e.sw.Write(notes); err != nil {
+		return err
+	}
+	return e.sw.Flush()
+}
+
+func (e *selectionExportEncoder) Close() error {
+	return e.sw.Flush()
+}
+
+// exportFileName returns the download file name for an export
+func exportFileName(format string, filters *datastore.AdvancedSearchFilters) string {
+	name := "birdnet-go-detections"
+	if filters.DateRange != nil {
+		name = fmt.Sprintf("birdnet-go_%s_%s", filters.DateRange.Start.Format("2006-01-02"), filters.DateRange.End.Format("2006-01-02"))
+	}
+	return name + exportFormats[format].suffix
+}
+
+// ExportDetections handles GET /api/v2/detections/export
+// Streams all detections matching the advanced search filters of GET /api/v2/detections
+// (search, species, confidence, date or start_date/end_date, hour, hourRange, timeOfDay,
+// verified, locked, location) in the format given by the format parameter:
+//   - csv (default) and ndjson: one record per detection
+//   - dwca: Darwin Core Archive with occurrence.txt and meta.xml
+//   - raven and audacity: Raven Pro selection table and Audacity label track, selection
+//     times are seconds from midnight of the first day of the required date range
+//
+// Detections are read from the datastore and written in batches, so memory use does not
+// grow with the size of the export.
+func (c *Controller) ExportDetections(ctx echo.Context) error {
+	if c.DS == nil {
+		return c.HandleError(ctx, errExportNoStore, "Datastore is not available", http.StatusServiceUnavailable)
+	}
+
+	format := ctx.QueryParam("format")
+	if format == "" {
+		format = ExportFormatCSV
+	}
+	if _, ok := exportFormats[format]; !ok {
+		return c.HandleError(ctx, errExportFormat, "Invalid export request", http.StatusBadRequest)
+	}
+
+	params, err := c.parseDetectionQueryParams(ctx)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid export request", http.StatusBadRequest)
+	}
+	filters := buildAdvancedSearchFilters(params)
+
+	// Selection times are relative to the start of the date range
+	var origin time.Time
+	if format == ExportFormatRaven || format == ExportFormatAudacity {
+		if filters.DateRange == nil {
+			return c.HandleError(ctx, errExportDateRange, "Invalid export request", http.StatusBadRequest)
+		}
+		start := filters.DateRange.Start
+		origin = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	}
+
+	// The response is started with the first batch so that errors reading it can still be reported
+	resp := ctx.Response()
+	var enc exportEncoder
+	begin := func() error {
+		resp.Header().Set(echo.HeaderContentType, exportFormats[format].contentType)
+		resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", exportFileName(format, &filters)))
+		resp.WriteHeader(http.StatusOK)
+
+		var err error
+		enc, err = c.newExportEncoder(format, resp, origin)
+		return err
+	}
+
+	total := 0
+	err = c.DS.StreamNotesAdvanced(ctx.Request().Context(), &filters, exportBatchSize, func(notes []datastore.Note) error {
+		if enc == nil {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+		if err := enc.Write(notes); err != nil {
+			return err
+		}
+		resp.Flush()
+		total += len(notes)
+		return nil
+	})
+	if err != nil {
+		if !resp.Committed {
+			return c.HandleError(ctx, err, "Failed to export detections", http.StatusInternalServerError)
+		}
+		// The response has already started, log the error and end the incomplete output
+		c.logAPIRequest(ctx, slog.LevelError, "Failed to export detections", "format", format, "error", err.Error(), "exported", total)
+		return nil
+	}
+
+	// An export without detections still contains the headers of the format
+	if enc == nil {
+		if err := begin(); err != nil {
+			return err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		c.logAPIRequest(ctx, slog.LevelError, "Failed to complete detection export", "format", format, "error", err.Error())
+		return nil
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Exported detections", "format", format, "count", total)
+
+	return nil
+}
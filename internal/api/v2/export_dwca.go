@@ -0,0 +1,203 @@
+// internal/api/v2/export_dwca.go
+package api
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/observation"
+)
+
+// Darwin Core Archive file names and namespaces
+const (
+	dwcaOccurrenceFile = "occurrence.txt"
+	dwcaMetaFile       = "meta.xml"
+	dwcaTextNamespace  = "http://rs.tdwg.org/dwc/text/"
+	dwcaTermsNamespace = "http://rs.tdwg.org/dwc/terms/"
+	dwcaOccurrenceType = dwcaTermsNamespace + "Occurrence"
+)
+
+// dwcaTerms lists the Darwin Core terms of the occurrence core, in column order.
+// The first column is the record identifier.
+var dwcaTerms = []string{
+	"occurrenceID",
+	"basisOfRecord",
+	"eventDate",
+	"scientificName",
+	"vernacularName",
+	"taxonRank",
+	"occurrenceStatus",
+	"decimalLatitude",
+	"decimalLongitude",
+	"geodeticDatum",
+	"recordedBy",
+	"identifiedBy",
+	"identificationVerificationStatus",
+	"identificationRemarks",
+	"samplingProtocol",
+}
+
+// dwcaArchive is the meta.xml descriptor of a Darwin Core Archive
+type dwcaArchive struct {
+	XMLName xml.Name `xml:"archive"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Core    dwcaCore `xml:"core"`
+}
+
+// dwcaCore describes the occurrence core data file
+type dwcaCore struct {
+	Encoding           string         `xml:"encoding,attr"`
+	FieldsTerminatedBy string         `xml:"fieldsTerminatedBy,attr"`
+	LinesTerminatedBy  string         `xml:"linesTerminatedBy,attr"`
+	FieldsEnclosedBy   string         `xml:"fieldsEnclosedBy,attr"`
+	IgnoreHeaderLines  int            `xml:"ignoreHeaderLines,attr"`
+	RowType            string         `xml:"rowType,attr"`
+	Files              []string       `xml:"files>location"`
+	ID                 dwcaFieldIndex `xml:"id"`
+	Fields             []dwcaField    `xml:"field"`
+}
+
+// dwcaFieldIndex refers to a column of the data file
+type dwcaFieldIndex struct {
+	Index int `xml:"index,attr"`
+}
+
+// dwcaField maps a column of the data file to a Darwin Core term
+type dwcaField struct {
+	Index int    `xml:"index,attr"`
+	Term  string `xml:"term,attr"`
+}
+
+// dwcaMeta returns the meta.xml descriptor for the occurrence file
+func dwcaMeta() dwcaArchive {
+	fields := make([]dwcaField, len(dwcaTerms))
+	for i, term := range dwcaTerms {
+		fields[i] = dwcaField{Index: i, Term: dwcaTermsNamespace + term}
+	}
+
+	return dwcaArchive{
+		Xmlns: dwcaTextNamespace,
+		Core: dwcaCore{
+			Encoding:           "UTF-8",
+			FieldsTerminatedBy: `\t`,
+			LinesTerminatedBy:  `\n`,
+			IgnoreHeaderLines:  1,
+			RowType:            dwcaOccurrenceType,
+			Files:              []string{dwcaOccurrenceFile},
+			ID:                 dwcaFieldIndex{Index: 0},
+			Fields:             fields,
+		},
+	}
+}
+
+// dwcaExportEncoder writes detections as a Darwin Core Archive. Occurrences are
+// streamed into occurrence.txt, meta.xml is added when the archive is closed.
+type dwcaExportEncoder struct {
+	zw       *zip.Writer
+	w        *bufio.Writer
+	station  string
+	lat, lon string
+	verified func(string) string
+}
+
+// newDwCAExportEncoder starts a Darwin Core Archive with the station name and
+// coordinates of the settings
+func newDwCAExportEncoder(w io.Writer, settings *conf.Settings, verified func(string) string) (*dwcaExportEncoder, error) {
+	e := &dwcaExportEncoder{
+		zw:       zip.NewWriter(w),
+		station:  settings.Main.Name,
+		verified: verified,
+	}
+
+	// Coordinates of 0,0 mean the station location is not configured
+	if settings.BirdNET.Latitude != 0 || settings.BirdNET.Longitude != 0 {
+		e.lat = strconv.FormatFloat(settings.BirdNET.Latitude, 'f', -1, 64)
+		e.lon = strconv.FormatFloat(settings.BirdNET.Longitude, 'f', -1, 64)
+	}
+
+	fw, err := e.zw.Create(dwcaOccurrenceFile)
+	if err != nil {
+		return nil, err
+	}
+	e.w = bufio.NewWriter(fw)
+
+	if _, err := e.w.WriteString(strings.Join(dwcaTerms, "\t") + "\n"); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *dwcaExportEncoder) Write(notes []datastore.Note) error {
+	for i := range notes {
+		if _, err := e.w.WriteString(strings.Join(e.occurrence(&notes[i]), "\t") + "\n"); err != nil {
+			return err
+		}
+	}
+	return e.w.Flush()
+}
+
+// occurrence returns the occurrence columns of a note in dwcaTerms order
+func (e *dwcaExportEncoder) occurrence(note *datastore.Note) []string {
+	begin, end := observation.NoteTimeRange(note)
+
+	identifiedBy := "BirdNET-Go"
+	if note.Model != "" {
+		identifiedBy += " (" + note.Model + ")"
+	}
+
+	row := []string{
+		fmt.Sprintf("%s:%d", e.station, note.ID),
+		"MachineObservation",
+		begin.Format(time.RFC3339) + "/" + end.Format(time.RFC3339),
+		note.ScientificName,
+		note.CommonName,
+		"species",
+		"present",
+		e.lat,
+		e.lon,
+		"",
+		e.station,
+		identifiedBy,
+		e.verified(note.Verified),
+		fmt.Sprintf("confidence %.4f", note.Confidence),
+		"passive acoustic monitoring",
+	}
+	if e.lat != "" {
+		row[9] = "WGS84"
+	}
+
+	for i := range row {
+		row[i] = observation.TabSeparatedField(row[i])
+	}
+	return row
+}
+
+// Close adds meta.xml and completes the archive
+func (e *dwcaExportEncoder) Close() error {
+	if err := e.w.Flush(); err != nil {
+		return err
+	}
+
+	mw, err := e.zw.Create(dwcaMetaFile)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mw, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(mw)
+	enc.Indent("", "  ")
+	if err := enc.Encode(dwcaMeta()); err != nil {
+		return err
+	}
+
+	return e.zw.Close()
+}
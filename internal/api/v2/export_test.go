@@ -0,0 +1,275 @@
+// export_test.go: Package api provides tests for API v2 detection export endpoints.
+
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// newExportRequest creates an echo context for the export endpoint
+func newExportRequest(e *echo.Echo, query string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/detections/export?"+query, http.NoBody)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+// exportFilters matches the search filters of an export
+func exportFilters(match func(f *datastore.AdvancedSearchFilters) bool) any {
+	return mock.MatchedBy(match)
+}
+
+// exportTestNotes returns stored detections on 2024-05-01
+func exportTestNotes() []datastore.Note {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	return []datastore.Note{
+		{
+			ID:             1,
+			Date:           "2024-05-01",
+			Time:           "05:30:00",
+			BeginTime:      day.Add(5*time.Hour + 30*time.Minute),
+			EndTime:        day.Add(5*time.Hour + 30*time.Minute + 3*time.Second),
+			ScientificName: "Erithacus rubecula",
+			CommonName:     "European Robin",
+			SpeciesCode:    "eurrob1",
+			Confidence:     0.87,
+			ClipName:       "clips/2024/05/erithacus_rubecula_87p_20240501T053000Z.wav",
+			Verified:       "correct",
+		},
+		{
+			ID:             2,
+			Date:           "2024-05-01",
+			Time:           "06:00:10",
+			BeginTime:      day.Add(6*time.Hour + 10*time.Second),
+			EndTime:        day.Add(6*time.Hour + 13*time.Second),
+			ScientificName: "Turdus merula",
+			CommonName:     "Eurasian Blackbird",
+			SpeciesCode:    "eurbla",
+			Confidence:     0.75,
+			Model:          "custom",
+			Locked:         true,
+		},
+	}
+}
+
+func TestExportDetections_CSV(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	// The advanced search filters of the request are passed to the datastore
+	mockDS.On("StreamNotesAdvanced", exportFilters(func(f *datastore.AdvancedSearchFilters) bool {
+		return len(f.Species) == 1 && f.Species[0] == "eurrob1" && f.Confidence != nil &&
+			f.Confidence.Operator == ">=" && f.Confidence.Value == 0.7 && f.DateRange != nil
+	}), exportBatchSize).Return(exportTestNotes(), nil).Once()
+
+	ctx, rec := newExportRequest(e, "species=eurrob1&confidence=%3E%3D70&start_date=2024-05-01&end_date=2024-05-31")
+	require.NoError(t, controller.ExportDetections(ctx))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/csv")
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "birdnet-go_2024-05-01_2024-05-31.csv")
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, exportCSVHeader, records[0])
+	assert.Equal(t, []string{"1", "2024-05-01", "05:30:00"}, records[1][:3])
+	assert.Equal(t, "Erithacus rubecula", records[1][5])
+	assert.Equal(t, "0.8700", records[1][8])
+	assert.Equal(t, "correct", records[1][10])
+	assert.Equal(t, "clips/2024/05/erithacus_rubecula_87p_20240501T053000Z.wav", records[1][12])
+	assert.Equal(t, "custom", records[2][9])
+	assert.Equal(t, "unverified", records[2][10])
+	assert.Equal(t, "true", records[2][11])
+	mockDS.AssertExpectations(t)
+}
+
+func TestExportDetections_NDJSON(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	mockDS.On("StreamNotesAdvanced", mock.Anything, exportBatchSize).Return(exportTestNotes(), nil).Once()
+
+	ctx, rec := newExportRequest(e, "format=ndjson")
+	require.NoError(t, controller.ExportDetections(ctx))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "birdnet-go-detections.ndjson")
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var record DetectionExportRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, uint(2), record.ID)
+	assert.Equal(t, "Eurasian Blackbird", record.CommonName)
+	assert.Equal(t, "custom", record.Model)
+	assert.True(t, record.Locked)
+}
+
+func TestExportDetections_DwCA(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.Settings.Main.Name = "station-1"
+	controller.Settings.BirdNET.Latitude = 60.1699
+	controller.Settings.BirdNET.Longitude = 24.9384
+	mockDS.On("StreamNotesAdvanced", mock.Anything, exportBatchSize).Return(exportTestNotes(), nil).Once()
+
+	ctx, rec := newExportRequest(e, "format=dwca")
+	require.NoError(t, controller.ExportDetections(ctx))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get(echo.HeaderContentType))
+
+	body := rec.Body.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = string(data)
+	}
+	require.Contains(t, files, dwcaOccurrenceFile)
+	require.Contains(t, files, dwcaMetaFile)
+
+	// meta.xml describes every column of the occurrence file
+	var meta dwcaArchive
+	require.NoError(t, xml.Unmarshal([]byte(files[dwcaMetaFile]), &meta))
+	assert.Equal(t, dwcaOccurrenceType, meta.Core.RowType)
+	assert.Equal(t, []string{dwcaOccurrenceFile}, meta.Core.Files)
+	assert.Equal(t, `\t`, meta.Core.FieldsTerminatedBy)
+	require.Len(t, meta.Core.Fields, len(dwcaTerms))
+	assert.Equal(t, dwcaTermsNamespace+"decimalLatitude", meta.Core.Fields[7].Term)
+
+	lines := strings.Split(strings.TrimSpace(files[dwcaOccurrenceFile]), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, strings.Join(dwcaTerms, "\t"), lines[0])
+
+	row := strings.Split(lines[1], "\t")
+	require.Len(t, row, len(dwcaTerms))
+	assert.Equal(t, "station-1:1", row[0])
+	assert.Equal(t, "MachineObservation", row[1])
+	assert.Equal(t, "Erithacus rubecula", row[3])
+	assert.Equal(t, "60.1699", row[7])
+	assert.Equal(t, "24.9384", row[8])
+	assert.Equal(t, "WGS84", row[9])
+	assert.Equal(t, "correct", row[12])
+
+	row = strings.Split(lines[2], "\t")
+	assert.Equal(t, "BirdNET-Go (custom)", row[11])
+}
+
+func TestExportDetections_Raven(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	// Notes are written in batches, selections are numbered across all of them
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	notes := make([]datastore.Note, exportBatchSize+1)
+	for i := range notes {
+		notes[i] = datastore.Note{
+			BeginTime:  day.Add(time.Duration(i) * time.Minute),
+			EndTime:    day.Add(time.Duration(i)*time.Minute + 3*time.Second),
+			CommonName: "European Robin",
+			Confidence: 0.9,
+		}
+	}
+	notes[exportBatchSize].BeginTime = day.Add(25 * time.Hour)
+	notes[exportBatchSize].EndTime = day.Add(25*time.Hour + 3*time.Second)
+	mockDS.On("StreamNotesAdvanced", mock.Anything, exportBatchSize).Return(notes, nil).Once()
+
+	ctx, rec := newExportRequest(e, "start_date=2024-05-01&end_date=2024-05-02&format=raven")
+	require.NoError(t, controller.ExportDetections(ctx))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "birdnet-go_2024-05-01_2024-05-02.selections.txt")
+
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	require.Len(t, lines, exportBatchSize+2, "header and all detections")
+	assert.True(t, strings.HasPrefix(lines[0], "Selection\t"))
+
+	last := strings.Split(lines[len(lines)-1], "\t")
+	assert.Equal(t, "1001", last[0])
+	assert.Equal(t, "90000.000", last[3], "times are relative to the start date")
+	mockDS.AssertExpectations(t)
+}
+
+func TestExportDetections_Audacity(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	notes := []datastore.Note{{
+		BeginTime:  day.Add(10 * time.Second),
+		EndTime:    day.Add(13 * time.Second),
+		CommonName: "European Robin",
+		Confidence: 0.9,
+	}}
+	mockDS.On("StreamNotesAdvanced", mock.Anything, exportBatchSize).Return(notes, nil).Once()
+
+	ctx, rec := newExportRequest(e, "date=2024-05-01&format=audacity")
+	require.NoError(t, controller.ExportDetections(ctx))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "10.000\t13.000\tEuropean Robin (0.90)\n\\\t0\t15000\n", rec.Body.String())
+}
+
+func TestExportDetections_Empty(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	mockDS.On("StreamNotesAdvanced", mock.Anything, exportBatchSize).Return(nil, nil).Once()
+
+	ctx, rec := newExportRequest(e, "format=csv")
+	require.NoError(t, controller.ExportDetections(ctx))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, strings.Join(exportCSVHeader, ",")+"\n", rec.Body.String())
+}
+
+func TestExportDetections_DatastoreError(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	mockDS.On("StreamNotesAdvanced", mock.Anything, exportBatchSize).Return(nil, errors.New("database locked")).Once()
+
+	ctx, rec := newExportRequest(e, "format=ndjson")
+	_ = controller.ExportDetections(ctx)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestExportDetections_InvalidRequest(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"selection export without date range", "format=raven"},
+		{"invalid date", "start_date=2024-13-01&end_date=2024-05-01"},
+		{"reversed range", "start_date=2024-05-02&end_date=2024-05-01"},
+		{"unknown format", "start_date=2024-05-01&end_date=2024-05-01&format=xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, rec := newExportRequest(e, tt.query)
+			_ = controller.ExportDetections(ctx)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+
+	mockDS.AssertNotCalled(t, "StreamNotesAdvanced", mock.Anything, mock.Anything)
+}
@@ -2,6 +2,7 @@
 package api
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -11,19 +12,33 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/analysis/processor"
 	"github.com/tphakala/birdnet-go/internal/birdweather"
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/mqtt"
 	"github.com/tphakala/birdnet-go/internal/weather"
 )
 
 // MQTTStatus represents the current status of the MQTT connection
 type MQTTStatus struct {
-	Connected bool   `json:"connected"`            // Whether the MQTT client is currently connected to the broker
-	Broker    string `json:"broker"`               // The URI of the MQTT broker (e.g., tcp://mqtt.example.com:1883)
-	Topic     string `json:"topic"`                // The topic pattern used for publishing/subscribing to MQTT messages
-	ClientID  string `json:"client_id"`            // The unique identifier used by this client when connecting to the broker
-	LastError string `json:"last_error,omitempty"` // Most recent error message, if any connection issues occurred
+	Connected bool               `json:"connected"`            // Whether the MQTT client is currently connected to the broker
+	Broker    string             `json:"broker"`               // The URI of the MQTT broker (e.g., tcp://mqtt.example.com:1883)
+	Topic     string             `json:"topic"`                // The topic pattern used for publishing/subscribing to MQTT messages
+	ClientID  string             `json:"client_id"`            // The unique identifier used by this client when connecting to the broker
+	LastError string             `json:"last_error,omitempty"` // Most recent error message, if any connection issues occurred
+	Spool     *mqtt.SpoolStats   `json:"spool,omitempty"`      // Offline message spool depth, present if spooling is enabled
+	Brokers   []MQTTBrokerStatus `json:"brokers,omitempty"`    // Status of the additional brokers
+}
+
+// MQTTBrokerStatus represents the status of an additional MQTT broker
+type MQTTBrokerStatus struct {
+	Name      string `json:"name"`                 // Name of the broker in the settings
+	Enabled   bool   `json:"enabled"`              // Whether detections are published to the broker
+	Connected bool   `json:"connected"`            // Whether the client of the running station is connected
+	Broker    string `json:"broker"`               // The URI of the MQTT broker
+	Topic     string `json:"topic"`                // The topic or topic template of detection messages
+	LastError string `json:"last_error,omitempty"` // Error of the last connection attempt
 }
 
 // MQTTTestResult represents the result of an MQTT connection test
@@ -40,6 +55,28 @@ type BirdWeatherStatus struct {
 	Threshold        float64 `json:"threshold"`            // The confidence threshold for reporting detections
 	LocationAccuracy float64 `json:"location_accuracy"`    // The location accuracy in meters
 	LastError        string  `json:"last_error,omitempty"` // Most recent error message, if any issues occurred
+
+	Outbox *BirdWeatherOutboxStatus `json:"outbox,omitempty"` // Upload outbox counts, present if a database is available
+}
+
+// BirdWeatherOutboxStatus represents the state of the BirdWeather upload outbox
+type BirdWeatherOutboxStatus struct {
+	Enabled bool  `json:"enabled"` // Whether failed uploads are queued for replay
+	Pending int64 `json:"pending"` // Uploads waiting to be replayed
+	Failed  int64 `json:"failed"`  // Uploads that could not be replayed
+	Posted  int64 `json:"posted"`  // Detections recorded as posted
+}
+
+// BirdWeatherBackfillRequest represents a request to upload past detections to BirdWeather
+type BirdWeatherBackfillRequest struct {
+	StartDate string `json:"start_date"` // First day of the detections to upload (YYYY-MM-DD)
+	EndDate   string `json:"end_date"`   // Last day of the detections to upload (YYYY-MM-DD)
+}
+
+// BirdWeatherBackfillResult represents the result of a BirdWeather backfill request
+type BirdWeatherBackfillResult struct {
+	Queued  int    `json:"queued"`  // Number of detections queued for upload
+	Message string `json:"message"` // Human-readable description of the result
 }
 
 // initIntegrationsRoutes registers all integration-related API endpoints
@@ -60,6 +97,7 @@ func (c *Controller) initIntegrationsRoutes() {
 	bwGroup := integrationsGroup.Group("/birdweather")
 	bwGroup.GET("/status", c.GetBirdWeatherStatus)
 	bwGroup.POST("/test", c.TestBirdWeatherConnection)
+	bwGroup.POST("/backfill", c.BackfillBirdWeather)
 
 	// Weather routes
 	weatherGroup := integrationsGroup.Group("/weather")
@@ -92,6 +130,9 @@ func (c *Controller) GetMQTTStatus(ctx echo.Context) error {
 		ClientID:  c.Settings.Main.Name, // Use the application name as client ID
 	}
 
+	// Additional brokers are reported whether or not the main broker is enabled
+	status.Brokers = c.mqttBrokerStatuses()
+
 	// If MQTT is not enabled, return status as-is
 	if !mqttConfig.Enabled {
 		if c.apiLogger != nil {
@@ -100,6 +141,14 @@ func (c *Controller) GetMQTTStatus(ctx echo.Context) error {
 		return ctx.JSON(http.StatusOK, status)
 	}
 
+	// Report the messages waiting in the offline spool of the running client
+	if c.Processor != nil {
+		if spool := c.Processor.MQTTSpool(); spool != nil {
+			stats := spool.Stats()
+			status.Spool = &stats
+		}
+	}
+
 	// Check connection status using a temporary client
 	if c.apiLogger != nil {
 		c.apiLogger.Debug("Checking MQTT connection status", "path", path, "ip", ip)
@@ -122,6 +171,35 @@ func (c *Controller) GetMQTTStatus(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, status)
 }
 
+// mqttBrokerStatuses returns the status of the configured additional brokers. The connection
+// state comes from the clients of the running station, brokers are not contacted.
+func (c *Controller) mqttBrokerStatuses() []MQTTBrokerStatus {
+	var running []processor.MQTTBrokerState
+	if c.Processor != nil {
+		running = c.Processor.MQTTBrokers()
+	}
+
+	brokers := c.Settings.Realtime.MQTT.Brokers
+	statuses := make([]MQTTBrokerStatus, 0, len(brokers))
+	for i := range brokers {
+		broker := &brokers[i]
+		status := MQTTBrokerStatus{
+			Name:    broker.Name,
+			Enabled: broker.Enabled,
+			Broker:  broker.Broker,
+			Topic:   cmp.Or(broker.TopicTemplate, broker.Topic),
+		}
+		for _, state := range running {
+			if state.Name == broker.Name && state.Broker == broker.Broker {
+				status.Connected = state.Connected
+				status.LastError = state.LastError
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
 // checkMQTTConnectionStatus attempts to connect to the MQTT broker using a temporary client
 // to determine the current connection status.
 // Returns true if connected, false otherwise, along with any error message encountered.
@@ -190,8 +268,21 @@ func (c *Controller) GetBirdWeatherStatus(ctx echo.Context) error {
 		LocationAccuracy: bwConfig.LocationAccuracy,
 	}
 
-	// For now, we just return the configuration status
-	// In the future, we could add checks for client status here
+	// Add the upload outbox counts
+	if outbox := c.birdWeatherOutbox(); outbox != nil {
+		counts, err := outbox.Counts()
+		if err != nil {
+			status.LastError = err.Error()
+		} else {
+			status.Outbox = &BirdWeatherOutboxStatus{
+				Enabled: bwConfig.Outbox.Enabled,
+				Pending: counts.Pending,
+				Failed:  counts.Failed,
+				Posted:  counts.Posted,
+			}
+		}
+	}
+
 	if c.apiLogger != nil {
 		c.apiLogger.Info("Retrieved BirdWeather status successfully",
 			"enabled", status.Enabled,
@@ -345,6 +436,76 @@ func (c *Controller) TestMQTTConnection(ctx echo.Context) error {
 	return nil
 }
 
+// birdWeatherOutbox returns the BirdWeather outbox of the processor, or an outbox on the
+// datastore when the processor is not running. It returns nil without a database.
+func (c *Controller) birdWeatherOutbox() *datastore.BirdweatherOutbox {
+	if c.Processor != nil {
+		if outbox := c.Processor.BirdWeatherOutbox(); outbox != nil {
+			return outbox
+		}
+	}
+	outbox, err := datastore.NewBirdweatherOutboxFor(c.DS)
+	if err != nil {
+		return nil
+	}
+	return outbox
+}
+
+// BackfillBirdWeather handles POST /api/v2/integrations/birdweather/backfill
+// It queues the saved detections of a date range for upload, for stations that were
+// registered after they started recording. The outbox uploads them in batches.
+func (c *Controller) BackfillBirdWeather(ctx echo.Context) error {
+	var request BirdWeatherBackfillRequest
+	if err := ctx.Bind(&request); err != nil {
+		return c.HandleError(ctx, err, "Invalid BirdWeather backfill request", http.StatusBadRequest)
+	}
+
+	start, err := time.Parse("2006-01-02", request.StartDate)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid start date, expected YYYY-MM-DD", http.StatusBadRequest)
+	}
+	end, err := time.Parse("2006-01-02", request.EndDate)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid end date, expected YYYY-MM-DD", http.StatusBadRequest)
+	}
+	if end.Before(start) {
+		return c.HandleError(ctx, fmt.Errorf("end date %s is before start date %s", request.EndDate, request.StartDate),
+			"End date must not be before start date", http.StatusBadRequest)
+	}
+
+	bwConfig := c.Settings.Realtime.Birdweather
+	if !bwConfig.Enabled || !bwConfig.Outbox.Enabled {
+		return c.HandleError(ctx, fmt.Errorf("birdweather uploads or the upload outbox are disabled"),
+			"BirdWeather and its upload outbox must be enabled for a backfill", http.StatusConflict)
+	}
+	outbox := c.birdWeatherOutbox()
+	if outbox == nil {
+		return c.HandleError(ctx, fmt.Errorf("no database available"),
+			"BirdWeather backfill requires a database", http.StatusServiceUnavailable)
+	}
+
+	queued, err := outbox.Backfill(request.StartDate, request.EndDate, bwConfig.Threshold)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to queue detections for BirdWeather", http.StatusInternalServerError)
+	}
+
+	if c.apiLogger != nil {
+		c.apiLogger.Info("Queued BirdWeather backfill",
+			"start_date", request.StartDate,
+			"end_date", request.EndDate,
+			"queued", queued,
+			"path", ctx.Request().URL.Path,
+			"ip", ctx.RealIP(),
+		)
+	}
+
+	return ctx.JSON(http.StatusOK, BirdWeatherBackfillResult{
+		Queued: queued,
+		Message: fmt.Sprintf("Queued %d detections, up to %d are uploaded every %d minutes",
+			queued, bwConfig.Outbox.BatchSize, bwConfig.Outbox.ReplayInterval),
+	})
+}
+
 // TestBirdWeatherConnection handles POST /api/v2/integrations/birdweather/test
 func (c *Controller) TestBirdWeatherConnection(ctx echo.Context) error {
 	var request BirdWeatherTestRequest
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -16,6 +17,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
 )
 
 // runIntegrationConnectionHandlerTest runs table-driven tests for integration connection handlers
@@ -281,6 +284,30 @@ func TestGetMQTTStatus(t *testing.T) {
 	}
 }
 
+// TestGetMQTTStatusBrokers tests that additional brokers are reported separately
+func TestGetMQTTStatusBrokers(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	controller.Settings.Realtime.MQTT.Enabled = false
+	controller.Settings.Realtime.MQTT.Brokers = []conf.MQTTBrokerSettings{
+		{Name: "home", Enabled: true, Broker: "tcp://ha.local:1883", Topic: "birdnet"},
+		{Name: "research", Broker: "ssl://mqtt.example.edu:8883", TopicTemplate: "stations/{{.Station}}"},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/integrations/mqtt/status", http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetMQTTStatus(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var result MQTTStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	require.Len(t, result.Brokers, 2)
+	assert.Equal(t, MQTTBrokerStatus{Name: "home", Enabled: true, Broker: "tcp://ha.local:1883", Topic: "birdnet"}, result.Brokers[0])
+	assert.Equal(t, "research", result.Brokers[1].Name)
+	assert.False(t, result.Brokers[1].Enabled)
+	assert.Equal(t, "stations/{{.Station}}", result.Brokers[1].Topic)
+}
+
 // TestGetBirdWeatherStatus tests the GetBirdWeatherStatus handler
 func TestGetBirdWeatherStatus(t *testing.T) {
 	// Define test cases
@@ -360,6 +387,57 @@ func TestGetBirdWeatherStatus(t *testing.T) {
 	}
 }
 
+// TestBackfillBirdWeather tests queueing past detections and the outbox counts of the status
+func TestBackfillBirdWeather(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+
+	storeSettings := &conf.Settings{}
+	storeSettings.Output.SQLite.Enabled = true
+	storeSettings.Output.SQLite.Path = filepath.Join(t.TempDir(), "birdnet.db")
+	store := &datastore.SQLiteStore{Settings: storeSettings}
+	require.NoError(t, store.Open())
+	t.Cleanup(func() { _ = store.Close() })
+	controller.DS = store
+
+	for _, note := range []datastore.Note{
+		{Date: "2024-05-01", Time: "06:30:00", ScientificName: "Bubo bubo", CommonName: "Eurasian Eagle-Owl", Confidence: 0.9, ClipName: "owl.wav"},
+		{Date: "2024-05-02", Time: "06:30:00", ScientificName: "Parus major", CommonName: "Great Tit", Confidence: 0.5, ClipName: "tit.wav"},
+		{Date: "2024-06-01", Time: "06:30:00", ScientificName: "Strix aluco", CommonName: "Tawny Owl", Confidence: 0.9, ClipName: "tawny.wav"},
+	} {
+		require.NoError(t, store.Save(&note, nil))
+	}
+
+	backfill := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/integrations/birdweather/backfill", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		require.NoError(t, controller.BackfillBirdWeather(e.NewContext(req, rec)))
+		return rec
+	}
+
+	// BirdWeather and the outbox must be enabled
+	assert.Equal(t, http.StatusConflict, backfill(`{"start_date":"2024-05-01","end_date":"2024-05-31"}`).Code)
+
+	controller.Settings.Realtime.Birdweather.Enabled = true
+	controller.Settings.Realtime.Birdweather.Threshold = 0.7
+	controller.Settings.Realtime.Birdweather.Outbox = conf.BirdweatherOutboxSettings{Enabled: true, ReplayInterval: 5, BatchSize: 20, MaxAttempts: 10}
+	assert.Equal(t, http.StatusBadRequest, backfill(`{"start_date":"2024-05-31","end_date":"2024-05-01"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, backfill(`{"start_date":"May 1st","end_date":"2024-05-31"}`).Code)
+
+	rec := backfill(`{"start_date":"2024-05-01","end_date":"2024-05-31"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var result BirdWeatherBackfillResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, 1, result.Queued, "only detections above the BirdWeather threshold are queued")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/integrations/birdweather/status", http.NoBody)
+	rec = httptest.NewRecorder()
+	require.NoError(t, controller.GetBirdWeatherStatus(e.NewContext(req, rec)))
+	var status BirdWeatherStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, &BirdWeatherOutboxStatus{Enabled: true, Pending: 1}, status.Outbox)
+}
+
 // TestTestMQTTConnection tests the TestMQTTConnection handler
 func TestTestMQTTConnection(t *testing.T) {
 	// Define test cases
@@ -22,6 +22,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/securefs"
 	"github.com/tphakala/birdnet-go/internal/logging"
+	"github.com/tphakala/birdnet-go/internal/spectrogram"
 	"golang.org/x/sync/singleflight"
 )
 
@@ -592,19 +593,24 @@ func (c *Controller) generateSpectrogram(ctx context.Context, audioPath string,
 	var spectrogramFilename string
 	if raw {
 		// Raw spectrograms use old API format: filename_400px.png (for cache compatibility)
-		spectrogramFilename = fmt.Sprintf("%s_%dpx.png", relBaseFilename, width)
+		spectrogramFilename = fmt.Sprintf("%s_%dpx", relBaseFilename, width)
 	} else {
 		// Spectrograms with legends use new suffix: filename_400px-legend.png
-		spectrogramFilename = fmt.Sprintf("%s_%dpx-legend.png", relBaseFilename, width)
+		spectrogramFilename = fmt.Sprintf("%s_%dpx-legend", relBaseFilename, width)
 	}
 
+	// The native renderer may write WebP images, the external tools always write PNG images
+	renderSettings := &c.Settings.Realtime.Dashboard.Spectrogram
+
 	// Since we're constructing the spectrogram path from an already-validated audio path
 	// and appending a simple formatted filename, we can safely construct the path without
 	// re-validating. The path components are all known to be safe.
-	relSpectrogramPath := filepath.Join(relAudioDir, spectrogramFilename)
+	relSpectrogramPath := filepath.Join(relAudioDir, spectrogramFilename+spectrogram.Extension(renderSettings))
+	relPNGSpectrogramPath := filepath.Join(relAudioDir, spectrogramFilename+".png")
 
-	// Absolute path for the spectrogram on the host filesystem
+	// Absolute paths for the spectrogram on the host filesystem
 	absSpectrogramPath := filepath.Join(c.SFS.BaseDir(), relSpectrogramPath)
+	absPNGSpectrogramPath := filepath.Join(c.SFS.BaseDir(), relPNGSpectrogramPath)
 
 	// Generate a unique key for this spectrogram generation request
 	// Include both the path and width to ensure uniqueness
@@ -612,6 +618,12 @@ func (c *Controller) generateSpectrogram(ctx context.Context, audioPath string,
 
 	// FAST PATH: Check if spectrogram already exists BEFORE acquiring semaphore
 	// This eliminates unnecessary semaphore contention for existing files
+	if relSpectrogramPath != relPNGSpectrogramPath {
+		// Existing PNG spectrograms, such as those of the external fallback, are served as they are
+		if _, err := c.SFS.StatRel(relPNGSpectrogramPath); err == nil {
+			relSpectrogramPath = relPNGSpectrogramPath
+		}
+	}
 	if _, err := c.SFS.StatRel(relSpectrogramPath); err == nil {
 		spectrogramLogger.Debug("Fast path: spectrogram already exists, returning immediately",
 			"spectrogram_key", spectrogramKey,
@@ -680,7 +692,29 @@ func (c *Controller) generateSpectrogram(ctx context.Context, audioPath string,
 		generationStart := time.Now()
 
 		// --- Generate Spectrogram ---
-		if err := createSpectrogramWithSoX(ctx, absAudioPath, absSpectrogramPath, width, raw, c.Settings); err != nil {
+		if spectrogram.UseNative(renderSettings) {
+			opts := spectrogram.OptionsFromSettings(renderSettings, width, raw)
+			err := spectrogram.RenderFile(ctx, absAudioPath, absSpectrogramPath, opts, c.Settings.Realtime.Audio.FfmpegPath)
+			if err == nil {
+				spectrogramLogger.Debug("Spectrogram generation successful using native renderer",
+					"spectrogram_key", spectrogramKey,
+					"abs_audio_path", absAudioPath,
+					"generation_duration_ms", time.Since(generationStart).Milliseconds())
+				return spectrogramStatusGenerated, nil
+			}
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			if !spectrogram.UseExternal(renderSettings) {
+				return nil, fmt.Errorf("%w: %w", ErrSpectrogramGeneration, err)
+			}
+			spectrogramLogger.Debug("Native generation failed, trying SoX fallback",
+				"spectrogram_key", spectrogramKey,
+				"native_error", err.Error(),
+				"native_duration_ms", time.Since(generationStart).Milliseconds())
+		}
+
+		if err := createSpectrogramWithSoX(ctx, absAudioPath, absPNGSpectrogramPath, width, raw, c.Settings); err != nil {
 			spectrogramLogger.Debug("SoX generation failed, trying FFmpeg fallback",
 				"spectrogram_key", spectrogramKey,
 				"sox_error", err.Error(),
@@ -688,7 +722,7 @@ func (c *Controller) generateSpectrogram(ctx context.Context, audioPath string,
 
 			fallbackStart := time.Now()
 			// Pass the context down to the fallback function as well.
-			if err2 := createSpectrogramWithFFmpeg(ctx, absAudioPath, absSpectrogramPath, width, raw, c.Settings); err2 != nil {
+			if err2 := createSpectrogramWithFFmpeg(ctx, absAudioPath, absPNGSpectrogramPath, width, raw, c.Settings); err2 != nil {
 				spectrogramLogger.Debug("Both SoX and FFmpeg generation failed",
 					"spectrogram_key", spectrogramKey,
 					"sox_error", err.Error(),
@@ -739,6 +773,11 @@ func (c *Controller) generateSpectrogram(ctx context.Context, audioPath string,
 		return "", fmt.Errorf("failed to generate spectrogram: %w", err)
 	}
 
+	// The external fallback renders PNG images
+	if _, err := c.SFS.StatRel(relSpectrogramPath); err != nil && relSpectrogramPath != relPNGSpectrogramPath {
+		relSpectrogramPath = relPNGSpectrogramPath
+	}
+
 	spectrogramLogger.Debug("Spectrogram generation completed successfully",
 		"spectrogram_key", spectrogramKey,
 		"relative_spectrogram_path", relSpectrogramPath,
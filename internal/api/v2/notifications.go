@@ -32,6 +32,12 @@ const (
 	// Rate limits
 	rateLimitRequestsPerWindow = 10 // Maximum requests per rate limit window for notifications (increased from 1 to match other SSE endpoints)
 	rateLimitBurst             = 15 // Rate limit burst allowance (increased to handle quick navigation)
+
+	// Push provider test timeout
+	pushProviderTestTimeout = 30 * time.Second
+
+	// Maximum number of notifications returned per page
+	maxNotificationPageSize = 500
 )
 
 // SSENotificationData represents notification data sent via SSE
@@ -98,6 +104,10 @@ func (c *Controller) SetupNotificationRoutes() {
 	c.Group.PUT("/notifications/:id/acknowledge", c.MarkNotificationAcknowledged)
 	c.Group.DELETE("/notifications/:id", c.DeleteNotification)
 	c.Group.GET("/notifications/unread/count", c.GetUnreadCount)
+
+	// External push provider endpoints (authenticated)
+	c.Group.GET("/notifications/providers", c.GetPushProviders, c.getEffectiveAuthMiddleware())
+	c.Group.POST("/notifications/providers/:name/test", c.TestPushProvider, c.getEffectiveAuthMiddleware())
 }
 
 // StreamNotifications handles the SSE connection for real-time notification streaming
@@ -438,20 +448,49 @@ func (c *Controller) GetNotifications(ctx echo.Context) error {
 		filter.Priorities = []notification.Priority{notification.Priority(priorityParam)}
 	}
 
+	// Parse component filter
+	if componentParam := ctx.QueryParam("component"); componentParam != "" {
+		filter.Component = componentParam
+	}
+
+	// Parse time range filters (RFC3339)
+	if sinceParam := ctx.QueryParam("since"); sinceParam != "" {
+		since, err := time.Parse(time.RFC3339, sinceParam)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid since parameter, expected RFC3339 timestamp",
+			})
+		}
+		filter.Since = &since
+	}
+	if untilParam := ctx.QueryParam("until"); untilParam != "" {
+		until, err := time.Parse(time.RFC3339, untilParam)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid until parameter, expected RFC3339 timestamp",
+			})
+		}
+		filter.Until = &until
+	}
+
 	// Parse limit
 	if limitParam := ctx.QueryParam("limit"); limitParam != "" {
 		if limit, err := strconv.Atoi(limitParam); err == nil && limit > 0 {
-			filter.Limit = limit
+			filter.Limit = min(limit, maxNotificationPageSize)
 		}
 	} else {
 		filter.Limit = 50 // Default limit
 	}
 
-	// Parse offset
+	// Parse offset, or derive it from a 1-based page number
 	if offsetParam := ctx.QueryParam("offset"); offsetParam != "" {
 		if offset, err := strconv.Atoi(offsetParam); err == nil && offset >= 0 {
 			filter.Offset = offset
 		}
+	} else if pageParam := ctx.QueryParam("page"); pageParam != "" {
+		if page, err := strconv.Atoi(pageParam); err == nil && page > 0 {
+			filter.Offset = (page - 1) * filter.Limit
+		}
 	}
 
 	if c.apiLogger != nil && c.Settings != nil && c.Settings.WebServer.Debug {
@@ -485,11 +524,22 @@ func (c *Controller) GetNotifications(ctx echo.Context) error {
 			"total_unread", unreadCount)
 	}
 
+	// Total number of matching notifications for paging
+	total, err := service.Count(filter)
+	if err != nil {
+		if c.apiLogger != nil {
+			c.apiLogger.Error("failed to count notifications", "error", err)
+		}
+		total = filter.Offset + len(notifications)
+	}
+
 	return ctx.JSON(http.StatusOK, map[string]any{
 		"notifications": notifications,
 		"count":         len(notifications),
+		"total":         total,
 		"limit":         filter.Limit,
 		"offset":        filter.Offset,
+		"hasMore":       filter.Offset+len(notifications) < total,
 	})
 }
 
@@ -646,3 +696,56 @@ func (c *Controller) GetUnreadCount(ctx echo.Context) error {
 	})
 }
 
+// GetPushProviders returns the active external push providers
+func (c *Controller) GetPushProviders(ctx echo.Context) error {
+	dispatcher := notification.GetPushDispatcher()
+	if dispatcher == nil {
+		return ctx.JSON(http.StatusOK, map[string]any{
+			"enabled":   false,
+			"providers": []notification.PushProviderInfo{},
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"enabled":   true,
+		"providers": dispatcher.Providers(),
+	})
+}
+
+// TestPushProvider sends a test notification through the named push provider
+func (c *Controller) TestPushProvider(ctx echo.Context) error {
+	dispatcher := notification.GetPushDispatcher()
+	if dispatcher == nil {
+		return ctx.JSON(http.StatusServiceUnavailable, map[string]string{
+			"error": "Push notifications are not enabled",
+		})
+	}
+
+	name := ctx.Param("name")
+	if name == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Provider name is required",
+		})
+	}
+
+	testCtx, cancel := context.WithTimeout(ctx.Request().Context(), pushProviderTestTimeout)
+	defer cancel()
+
+	if err := dispatcher.TestProvider(testCtx, name); err != nil {
+		if errors.Is(err, notification.ErrPushProviderNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Push provider not found",
+			})
+		}
+		if c.apiLogger != nil {
+			c.apiLogger.Error("push provider test failed", "error", err, "provider", name)
+		}
+		return ctx.JSON(http.StatusBadGateway, map[string]string{
+			"error": "Test notification failed, see the logs for details",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Test notification sent",
+	})
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -378,4 +380,83 @@ func TestMetadataExtraction(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestController_PushProviderEndpointsWithoutDispatcher(t *testing.T) {
+	t.Parallel()
+
+	c := mockController()
+	e := echo.New()
+
+	// Listing providers reports push delivery as disabled
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/notifications/providers", http.NoBody)
+	rec := httptest.NewRecorder()
+	if err := c.GetPushProviders(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("GetPushProviders() error = %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("GetPushProviders() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	// Testing a provider is unavailable when push delivery is disabled
+	req = httptest.NewRequest(http.MethodPost, "/api/v2/notifications/providers/hook/test", http.NoBody)
+	rec = httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("name")
+	ctx.SetParamValues("hook")
+	if err := c.TestPushProvider(ctx); err != nil {
+		t.Fatalf("TestPushProvider() error = %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("TestPushProvider() status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestController_GetNotificationsPaging(t *testing.T) {
+	setupTestNotificationService()
+	service := notification.GetService()
+
+	// Use a unique component so notifications created by other tests are filtered out
+	const component = "paging-test"
+	for i := range 5 {
+		_, err := service.CreateWithComponent(notification.TypeInfo, notification.PriorityLow,
+			"Paging", fmt.Sprintf("notification %d", i), component)
+		if err != nil {
+			t.Fatalf("CreateWithComponent() error = %v", err)
+		}
+	}
+
+	c := mockController()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/notifications?component="+component+"&limit=2&page=2", http.NoBody)
+	rec := httptest.NewRecorder()
+	if err := c.GetNotifications(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("GetNotifications() error = %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GetNotifications() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Count   int  `json:"count"`
+		Total   int  `json:"total"`
+		Offset  int  `json:"offset"`
+		HasMore bool `json:"hasMore"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Count != 2 || resp.Total != 5 || resp.Offset != 2 || !resp.HasMore {
+		t.Errorf("unexpected paging response: %+v", resp)
+	}
+
+	// Invalid time range parameters are rejected
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/notifications?since=yesterday", http.NoBody)
+	rec = httptest.NewRecorder()
+	if err := c.GetNotifications(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("GetNotifications() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("GetNotifications() with invalid since status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
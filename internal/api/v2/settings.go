@@ -1419,7 +1419,9 @@ func mqttSettingsChanged(oldSettings, currentSettings *conf.Settings) bool {
 		oldMQTT.TLS.InsecureSkipVerify != newMQTT.TLS.InsecureSkipVerify ||
 		oldMQTT.TLS.CACert != newMQTT.TLS.CACert ||
 		oldMQTT.TLS.ClientCert != newMQTT.TLS.ClientCert ||
-		oldMQTT.TLS.ClientKey != newMQTT.TLS.ClientKey
+		oldMQTT.TLS.ClientKey != newMQTT.TLS.ClientKey ||
+		oldMQTT.Commands != newMQTT.Commands ||
+		!reflect.DeepEqual(oldMQTT.Brokers, newMQTT.Brokers)
 }
 
 // rtspSettingsChanged checks if RTSP settings have changed
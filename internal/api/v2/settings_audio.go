@@ -27,6 +27,12 @@ func soundLevelSettingsChanged(oldSettings, currentSettings *conf.Settings) bool
 		return true
 	}
 
+	// Check for changes in history (only if enabled), the history recorder is started with the publishers
+	if currentSettings.Realtime.Audio.SoundLevel.Enabled &&
+		oldSettings.Realtime.Audio.SoundLevel.History != currentSettings.Realtime.Audio.SoundLevel.History {
+		return true
+	}
+
 	return false
 }
 
@@ -43,6 +49,20 @@ func (c *Controller) handleEqualizerChange(settings *conf.Settings) error {
 	return nil
 }
 
+// sourceFiltersChanged checks if the per-source filter chains or gains have changed
+func sourceFiltersChanged(oldSettings, newSettings []conf.SourceAudioSettings) bool {
+	return !reflect.DeepEqual(oldSettings, newSettings)
+}
+
+// handleSourceFiltersChange rebuilds the per-source filter chains, capturing sources pick
+// up the new chains with their next buffer
+func (c *Controller) handleSourceFiltersChange(settings *conf.Settings) error {
+	if err := myaudio.UpdateSourceFilters(settings); err != nil {
+		return fmt.Errorf("failed to update audio source filters: %w", err)
+	}
+	return nil
+}
+
 // getAudioBlockedFields returns the blocked fields map for the audio section
 func getAudioBlockedFields() map[string]interface{} {
 	return map[string]interface{}{
@@ -82,6 +102,16 @@ func (c *Controller) handleAudioSettingsChanges(oldSettings, currentSettings *co
 		_ = c.SendToast("Audio equalizer settings updated", "success", 3000)
 	}
 
+	// Check per-source filter chains and gains
+	if sourceFiltersChanged(oldSettings.Realtime.Audio.SourceFilters, currentSettings.Realtime.Audio.SourceFilters) {
+		c.Debug("Audio source filters changed, updating source filter chains")
+		if err := c.handleSourceFiltersChange(currentSettings); err != nil {
+			_ = c.SendToast("Failed to update audio source filters", "error", 5000)
+			return reconfigActions, fmt.Errorf("failed to update audio source filters: %w", err)
+		}
+		_ = c.SendToast("Audio source filters updated", "success", 3000)
+	}
+
 	return reconfigActions, nil
 }
 
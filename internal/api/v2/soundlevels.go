@@ -0,0 +1,345 @@
+// internal/api/v2/soundlevels.go
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// Default time ranges of the sound level history endpoints
+const (
+	defaultSoundLevelHistoryRange     = 24 * time.Hour
+	defaultSoundLevelCorrelationRange = 30 * 24 * time.Hour
+)
+
+// SoundLevelHistoryPoint is a stored sound level summary of a source. Levels are in dB
+// relative to full scale.
+type SoundLevelHistoryPoint struct {
+	Source          string                              `json:"source"`
+	Name            string                              `json:"name"`
+	Start           time.Time                           `json:"start"`
+	DurationSeconds int                                 `json:"durationSeconds"`
+	MeasuredSeconds int                                 `json:"measuredSeconds"`
+	Leq             float64                             `json:"leq"`
+	Lmin            float64                             `json:"lmin"`
+	Lmax            float64                             `json:"lmax"`
+	Bands           map[string]datastore.SoundLevelBand `json:"bands"`
+}
+
+// SoundLevelHistoryResponse is the sound level history of a time range
+type SoundLevelHistoryResponse struct {
+	Source     string                   `json:"source,omitempty"`
+	From       time.Time                `json:"from"`
+	To         time.Time                `json:"to"`
+	Resolution int                      `json:"resolution,omitempty"` // Seconds per point if the summaries were merged
+	Points     []SoundLevelHistoryPoint `json:"points"`
+}
+
+// SoundLevelCorrelationHour is the sound level and the number of detections of an hour
+type SoundLevelCorrelationHour struct {
+	Hour       time.Time `json:"hour"`
+	Leq        float64   `json:"leq"`
+	Detections int       `json:"detections"`
+}
+
+// SoundLevelCorrelationResponse relates the hourly sound level to the hourly number of
+// detections. The correlation and slope are omitted when there are fewer than three hours
+// or either series does not vary.
+type SoundLevelCorrelationResponse struct {
+	Source               string                      `json:"source,omitempty"`
+	Species              string                      `json:"species,omitempty"`
+	From                 time.Time                   `json:"from"`
+	To                   time.Time                   `json:"to"`
+	StartHour            int                         `json:"startHour"`
+	EndHour              int                         `json:"endHour"`
+	Hours                []SoundLevelCorrelationHour `json:"hours"`
+	Correlation          *float64                    `json:"correlation,omitempty"`          // Pearson correlation coefficient
+	DetectionsPerDecibel *float64                    `json:"detectionsPerDecibel,omitempty"` // Least squares slope of detections per hour over Leq
+}
+
+// initSoundLevelRoutes registers the sound level history endpoints
+func (c *Controller) initSoundLevelRoutes() {
+	// Sound level history is publicly accessible like the live sound level stream
+	soundLevelGroup := c.Group.Group("/soundlevels")
+	soundLevelGroup.GET("", c.GetSoundLevelHistory)
+	soundLevelGroup.GET("/correlation", c.GetSoundLevelCorrelation)
+}
+
+// soundLevelHistory returns the sound level history of the datastore, or nil without a
+// database
+func (c *Controller) soundLevelHistory() *datastore.SoundLevelHistory {
+	history, err := datastore.NewSoundLevelHistoryFor(c.DS)
+	if err != nil {
+		return nil
+	}
+	return history
+}
+
+// parseSoundLevelTime parses a time parameter in RFC 3339 or YYYY-MM-DD format. Dates are
+// local midnight, or the following midnight for the end of a range.
+func parseSoundLevelTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, use RFC 3339 or YYYY-MM-DD", value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// parseSoundLevelRange parses the from and to parameters, the range ends now and spans the
+// default range if they are missing
+func parseSoundLevelRange(ctx echo.Context, defaultRange time.Duration) (from, to time.Time, err error) {
+	to = time.Now()
+	if value := ctx.QueryParam("to"); value != "" {
+		if to, err = parseSoundLevelTime(value, true); err != nil {
+			return from, to, err
+		}
+	}
+	from = to.Add(-defaultRange)
+	if value := ctx.QueryParam("from"); value != "" {
+		if from, err = parseSoundLevelTime(value, false); err != nil {
+			return from, to, err
+		}
+	}
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("from %s is not before to %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	return from, to, nil
+}
+
+// parseIntParam parses an optional integer query parameter within bounds
+func parseIntParam(ctx echo.Context, name string, defaultValue, minValue, maxValue int) (int, error) {
+	value := ctx.QueryParam(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < minValue || n > maxValue {
+		return 0, fmt.Errorf("%s must be an integer between %d and %d, got %q", name, minValue, maxValue, value)
+	}
+	return n, nil
+}
+
+// mergeSoundLevelPeriods merges the summaries of each source into summaries of a
+// resolution, or of all sources if combine is set. The result is ordered by start time.
+func mergeSoundLevelPeriods(records []datastore.SoundLevelRecord, resolution time.Duration, combine bool) ([]datastore.SoundLevelRecord, error) {
+	type periodKey struct {
+		source string
+		start  int64
+	}
+	groups := make(map[periodKey][]datastore.SoundLevelRecord)
+	var keys []periodKey
+	for i := range records {
+		key := periodKey{start: datastore.SoundLevelPeriodStart(records[i].Start.In(time.Local), resolution).Unix()}
+		if !combine {
+			key.source = records[i].Source
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], records[i])
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].start != keys[j].start {
+			return keys[i].start < keys[j].start
+		}
+		return keys[i].source < keys[j].source
+	})
+
+	merged := make([]datastore.SoundLevelRecord, 0, len(keys))
+	for _, key := range keys {
+		record, err := datastore.MergeSoundLevelRecords(time.Unix(key.start, 0), resolution, groups[key])
+		if err != nil {
+			return nil, err
+		}
+		merged = append(merged, record)
+	}
+	return merged, nil
+}
+
+// GetSoundLevelHistory handles GET /api/v2/soundlevels
+// Returns the stored sound level summaries of a source, or of all sources, within a time
+// range. The optional resolution in seconds merges the summaries of each source for charts
+// of long ranges.
+func (c *Controller) GetSoundLevelHistory(ctx echo.Context) error {
+	from, to, err := parseSoundLevelRange(ctx, defaultSoundLevelHistoryRange)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid time range", http.StatusBadRequest)
+	}
+	resolution, err := parseIntParam(ctx, "resolution", 0, 0, 86400)
+	if err != nil || (resolution > 0 && 86400%resolution != 0) {
+		return c.HandleError(ctx, fmt.Errorf("invalid resolution %q", ctx.QueryParam("resolution")),
+			"Resolution must be a number of seconds that divides a day", http.StatusBadRequest)
+	}
+	history := c.soundLevelHistory()
+	if history == nil {
+		return c.HandleError(ctx, fmt.Errorf("no database available"),
+			"Sound level history requires a database", http.StatusServiceUnavailable)
+	}
+
+	source := ctx.QueryParam("source")
+	records, err := history.Query(source, from, to)
+	if err == nil && resolution > 0 {
+		records, err = mergeSoundLevelPeriods(records, time.Duration(resolution)*time.Second, false)
+	}
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get sound level history", http.StatusInternalServerError)
+	}
+
+	points := make([]SoundLevelHistoryPoint, 0, len(records))
+	for i := range records {
+		bands, err := records[i].BandLevels()
+		if err != nil {
+			return c.HandleError(ctx, err, "Failed to get sound level history", http.StatusInternalServerError)
+		}
+		points = append(points, SoundLevelHistoryPoint{
+			Source:          records[i].Source,
+			Name:            records[i].Name,
+			Start:           records[i].Start.In(time.Local),
+			DurationSeconds: records[i].Duration,
+			MeasuredSeconds: records[i].Measured,
+			Leq:             records[i].Leq,
+			Lmin:            records[i].Lmin,
+			Lmax:            records[i].Lmax,
+			Bands:           bands,
+		})
+	}
+
+	c.logAPIRequest(ctx, slog.LevelDebug, "Retrieved sound level history", "source", source, "points", len(points))
+
+	return ctx.JSON(http.StatusOK, SoundLevelHistoryResponse{
+		Source:     source,
+		From:       from,
+		To:         to,
+		Resolution: resolution,
+		Points:     points,
+	})
+}
+
+// GetSoundLevelCorrelation handles GET /api/v2/soundlevels/correlation
+// Returns the hourly Leq of a source, or of all sources, with the number of detections of
+// the same source in the same hour, optionally of one species and limited to the hours of
+// the day from startHour to endHour.
+func (c *Controller) GetSoundLevelCorrelation(ctx echo.Context) error {
+	from, to, err := parseSoundLevelRange(ctx, defaultSoundLevelCorrelationRange)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid time range", http.StatusBadRequest)
+	}
+	startHour, err := parseIntParam(ctx, "startHour", 0, 0, 23)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid start hour", http.StatusBadRequest)
+	}
+	endHour, err := parseIntParam(ctx, "endHour", 23, 0, 23)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid end hour", http.StatusBadRequest)
+	}
+	history := c.soundLevelHistory()
+	if history == nil {
+		return c.HandleError(ctx, fmt.Errorf("no database available"),
+			"Sound level history requires a database", http.StatusServiceUnavailable)
+	}
+
+	source, species := ctx.QueryParam("source"), ctx.QueryParam("species")
+	hours, err := soundLevelCorrelationHours(history, source, species, from, to, startHour, endHour)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to correlate sound levels with detections", http.StatusInternalServerError)
+	}
+
+	response := SoundLevelCorrelationResponse{
+		Source:    source,
+		Species:   species,
+		From:      from,
+		To:        to,
+		StartHour: startHour,
+		EndHour:   endHour,
+		Hours:     hours,
+	}
+	if r, slope, ok := soundLevelDetectionCorrelation(hours); ok {
+		response.Correlation = &r
+		response.DetectionsPerDecibel = &slope
+	}
+
+	c.logAPIRequest(ctx, slog.LevelDebug, "Correlated sound levels with detections", "source", source, "hours", len(hours))
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// soundLevelCorrelationHours returns the hours within a range and the hours of the day
+// from startHour to endHour that have sound level summaries, with their detection counts.
+// The hours of the day wrap around midnight if startHour is after endHour.
+func soundLevelCorrelationHours(history *datastore.SoundLevelHistory, source, species string, from, to time.Time, startHour, endHour int) ([]SoundLevelCorrelationHour, error) {
+	records, err := history.Query(source, datastore.SoundLevelPeriodStart(from, time.Hour), to)
+	if err != nil {
+		return nil, err
+	}
+	hourly, err := mergeSoundLevelPeriods(records, time.Hour, true)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := history.HourlyDetectionCounts(from, to, source, species)
+	if err != nil {
+		return nil, err
+	}
+
+	hours := make([]SoundLevelCorrelationHour, 0, len(hourly))
+	for i := range hourly {
+		hour := hourly[i].Start.In(time.Local)
+		h := hour.Hour()
+		inHours := h >= startHour && h <= endHour
+		if startHour > endHour {
+			inHours = h >= startHour || h <= endHour
+		}
+		if !inHours {
+			continue
+		}
+		hours = append(hours, SoundLevelCorrelationHour{
+			Hour:       hour,
+			Leq:        math.Round(hourly[i].Leq*10) / 10,
+			Detections: counts[hour],
+		})
+	}
+	return hours, nil
+}
+
+// soundLevelDetectionCorrelation returns the Pearson correlation coefficient of the hourly
+// Leq and detection counts and the least squares slope of detections over Leq
+func soundLevelDetectionCorrelation(hours []SoundLevelCorrelationHour) (r, slope float64, ok bool) {
+	n := float64(len(hours))
+	if len(hours) < 3 {
+		return 0, 0, false
+	}
+	var meanX, meanY float64
+	for _, hour := range hours {
+		meanX += hour.Leq
+		meanY += float64(hour.Detections)
+	}
+	meanX /= n
+	meanY /= n
+
+	var covariance, varianceX, varianceY float64
+	for _, hour := range hours {
+		dx, dy := hour.Leq-meanX, float64(hour.Detections)-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return 0, 0, false
+	}
+	r = covariance / math.Sqrt(varianceX*varianceY)
+	slope = covariance / varianceX
+	return math.Round(r*1000) / 1000, math.Round(slope*1000) / 1000, true
+}
@@ -0,0 +1,168 @@
+// soundlevels_test.go: Package api provides tests for the sound level history endpoints.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// setupSoundLevelTest returns a controller on a SQLite datastore with three dawn hours of
+// sound level summaries, getting louder each hour, and fewer detections as it gets louder
+func setupSoundLevelTest(t *testing.T) (*echo.Echo, *Controller, time.Time) {
+	t.Helper()
+	e, _, controller := setupTestEnvironment(t)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "soundlevels.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&datastore.Note{}, &datastore.SoundLevelRecord{}))
+	controller.DS = &datastore.SQLiteStore{DataStore: datastore.DataStore{DB: db}}
+
+	history := datastore.NewSoundLevelHistory(db)
+	dawn := time.Date(2024, 5, 1, 4, 0, 0, 0, time.Local)
+	for hour := 0; hour < 3; hour++ {
+		for _, source := range []string{"rtsp_road", "rtsp_garden"} {
+			leq := -50 + float64(hour*10)
+			if source == "rtsp_garden" {
+				leq -= 10
+			}
+			for quarter := 0; quarter < 4; quarter++ {
+				record := datastore.SoundLevelRecord{
+					Source: source, Name: source,
+					Start:    dawn.Add(time.Duration(hour)*time.Hour + time.Duration(quarter)*15*time.Minute),
+					Duration: 900, Measured: 900,
+					Leq: leq, Lmin: leq - 5, Lmax: leq + 5,
+				}
+				require.NoError(t, record.SetBandLevels(map[string]datastore.SoundLevelBand{
+					"1.0_kHz": {Freq: 1000, Mean: leq, Min: leq - 5, Max: leq + 5},
+				}))
+				require.NoError(t, history.Save(&record))
+			}
+		}
+	}
+
+	var notes []datastore.Note
+	for hour, count := range []int{6, 3, 1} {
+		for i := 0; i < count; i++ {
+			notes = append(notes, datastore.Note{
+				Date:           "2024-05-01",
+				Time:           dawn.Add(time.Duration(hour)*time.Hour + time.Duration(i)*time.Minute).Format("15:04:05"),
+				SourceID:       "rtsp_road",
+				ScientificName: "Turdus merula",
+				CommonName:     "Eurasian Blackbird",
+			})
+		}
+	}
+	// Detections of other sources are not counted for rtsp_road
+	notes = append(notes, datastore.Note{
+		Date:           "2024-05-01",
+		Time:           dawn.Add(time.Hour).Format("15:04:05"),
+		SourceID:       "rtsp_garden",
+		ScientificName: "Turdus merula",
+		CommonName:     "Eurasian Blackbird",
+	})
+	require.NoError(t, db.Create(&notes).Error)
+	return e, controller, dawn
+}
+
+// soundLevelRequest runs a sound level handler with query parameters
+func soundLevelRequest(t *testing.T, e *echo.Echo, handler echo.HandlerFunc, path string, params url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path+"?"+params.Encode(), http.NoBody)
+	rec := httptest.NewRecorder()
+	require.NoError(t, handler(e.NewContext(req, rec)))
+	return rec
+}
+
+func TestGetSoundLevelHistory(t *testing.T) {
+	e, controller, dawn := setupSoundLevelTest(t)
+	params := url.Values{
+		"source": {"rtsp_road"},
+		"from":   {dawn.Format(time.RFC3339)},
+		"to":     {dawn.Add(2 * time.Hour).Format(time.RFC3339)},
+	}
+
+	rec := soundLevelRequest(t, e, controller.GetSoundLevelHistory, "/api/v2/soundlevels", params)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp SoundLevelHistoryResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Points, 8)
+	assert.Equal(t, "rtsp_road", resp.Points[0].Source)
+	assert.True(t, dawn.Equal(resp.Points[0].Start))
+	assert.InDelta(t, -50, resp.Points[0].Leq, 0.001)
+	assert.InDelta(t, -50, resp.Points[0].Bands["1.0_kHz"].Mean, 0.001)
+
+	// Summaries of each source are merged to the requested resolution
+	params.Set("resolution", "3600")
+	params.Del("source")
+	rec = soundLevelRequest(t, e, controller.GetSoundLevelHistory, "/api/v2/soundlevels", params)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Points, 4)
+	assert.Equal(t, 3600, resp.Points[0].DurationSeconds)
+	assert.Equal(t, 3600, resp.Points[0].MeasuredSeconds)
+
+	for name, bad := range map[string]url.Values{
+		"resolution not dividing a day": {"resolution": {"7000"}},
+		"invalid time":                  {"from": {"yesterday"}},
+		"empty range":                   {"from": {"2024-05-02"}, "to": {"2024-05-01"}},
+	} {
+		rec = soundLevelRequest(t, e, controller.GetSoundLevelHistory, "/api/v2/soundlevels", bad)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, name)
+	}
+}
+
+func TestGetSoundLevelCorrelation(t *testing.T) {
+	e, controller, dawn := setupSoundLevelTest(t)
+	params := url.Values{
+		"source": {"rtsp_road"},
+		"from":   {"2024-05-01"},
+		"to":     {"2024-05-01"},
+	}
+
+	rec := soundLevelRequest(t, e, controller.GetSoundLevelCorrelation, "/api/v2/soundlevels/correlation", params)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp SoundLevelCorrelationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Hours, 3)
+	assert.True(t, dawn.Equal(resp.Hours[0].Hour))
+	assert.Equal(t, []int{6, 3, 1}, []int{resp.Hours[0].Detections, resp.Hours[1].Detections, resp.Hours[2].Detections})
+	assert.InDelta(t, -40, resp.Hours[1].Leq, 0.001)
+	require.NotNil(t, resp.Correlation)
+	assert.InDelta(t, -0.993, *resp.Correlation, 0.001, "detections drop as it gets louder")
+	require.NotNil(t, resp.DetectionsPerDecibel)
+	assert.InDelta(t, -0.25, *resp.DetectionsPerDecibel, 0.001)
+
+	// Hours of the day outside the selection are left out, too few hours have no correlation
+	params.Set("startHour", "5")
+	params.Set("endHour", "6")
+	rec = soundLevelRequest(t, e, controller.GetSoundLevelCorrelation, "/api/v2/soundlevels/correlation", params)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	resp = SoundLevelCorrelationResponse{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Hours, 2)
+	assert.Nil(t, resp.Correlation)
+
+	params.Set("endHour", "24")
+	rec = soundLevelRequest(t, e, controller.GetSoundLevelCorrelation, "/api/v2/soundlevels/correlation", params)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetSoundLevelHistoryWithoutDatabase(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	rec := soundLevelRequest(t, e, controller.GetSoundLevelHistory, "/api/v2/soundlevels", url.Values{})
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,207 @@
+// internal/api/v2/suppression.go
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/suppression"
+)
+
+// Suppression API errors
+var (
+	errSuppressNotFalsePositive = fmt.Errorf("detection is not reviewed as a false positive")
+	errSuppressRuleNotFound     = fmt.Errorf("suppression rule not found")
+	errSuppressConfidence       = fmt.Errorf("max_confidence must be between 0 and 1")
+	errSuppressWindow           = fmt.Errorf("window_minutes must not be negative")
+	errSuppressNoSource         = fmt.Errorf("detection has no recorded audio source")
+)
+
+// SuppressionRuleResponse is a suppression rule with the detections it suppressed since startup
+type SuppressionRuleResponse struct {
+	conf.SuppressionRule
+	Suppressed     int64      `json:"suppressed"`
+	LastSuppressed *time.Time `json:"lastSuppressed,omitempty"`
+}
+
+// SuppressDetectionRequest scopes a suppression rule created from a false positive
+type SuppressDetectionRequest struct {
+	Source        string  `json:"source"`         // Audio source ID, the recorded source of the detection if empty
+	AllSources    bool    `json:"all_sources"`    // true to suppress the species at every source
+	WindowMinutes int     `json:"window_minutes"` // Minutes before and after the detection time, 30 if 0
+	MaxConfidence float64 `json:"max_confidence"` // Detections above this are kept, 0 to suppress all
+	Comment       string  `json:"comment"`        // Reason for the rule, generated if empty
+}
+
+// SuppressionSource is an audio source suppression rules can be scoped to
+type SuppressionSource struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// initSuppressionRoutes registers the suppression rule endpoints
+func (c *Controller) initSuppressionRoutes() {
+	// Suppression rules change what is recorded, they require authentication
+	suppressionGroup := c.Group.Group("/suppression", c.getEffectiveAuthMiddleware())
+	suppressionGroup.GET("/rules", c.GetSuppressionRules)
+	suppressionGroup.GET("/sources", c.GetSuppressionSources)
+	suppressionGroup.DELETE("/rules/:id", c.DeleteSuppressionRule)
+
+	detectionGroup := c.Group.Group("/detections", c.AuthMiddleware)
+	detectionGroup.POST("/:id/suppress", c.SuppressDetection)
+}
+
+// suppressionRuleResponses returns the configured rules with their counters
+func (c *Controller) suppressionRuleResponses() []SuppressionRuleResponse {
+	var stats map[string]suppression.RuleStats
+	if c.Processor != nil {
+		stats = c.Processor.SuppressionStats()
+	}
+
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+	rules := make([]SuppressionRuleResponse, 0, len(c.Settings.Realtime.Suppression.Rules))
+	for _, rule := range c.Settings.Realtime.Suppression.Rules {
+		resp := SuppressionRuleResponse{SuppressionRule: rule}
+		if s, ok := stats[rule.ID]; ok {
+			resp.Suppressed = s.Suppressed
+			resp.LastSuppressed = &s.LastSuppressed
+		}
+		rules = append(rules, resp)
+	}
+	return rules
+}
+
+// updateSuppressionRules replaces the rules with the result of fn and saves the settings.
+// The slice is replaced rather than modified so that the processor keeps a consistent view.
+func (c *Controller) updateSuppressionRules(fn func([]conf.SuppressionRule) []conf.SuppressionRule) error {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	current := c.Settings.Realtime.Suppression.Rules
+	rules := fn(append([]conf.SuppressionRule(nil), current...))
+	c.Settings.Realtime.Suppression.Rules = rules
+
+	if c.DisableSaveSettings {
+		return nil
+	}
+	if err := conf.SaveSettings(); err != nil {
+		c.Settings.Realtime.Suppression.Rules = current
+		return err
+	}
+	return nil
+}
+
+// GetSuppressionRules handles GET /api/v2/suppression/rules
+func (c *Controller) GetSuppressionRules(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, c.suppressionRuleResponses())
+}
+
+// GetSuppressionSources handles GET /api/v2/suppression/sources
+// Lists the IDs of the registered audio sources for scoping rules
+func (c *Controller) GetSuppressionSources(ctx echo.Context) error {
+	sources := []SuppressionSource{}
+	if registry := myaudio.GetRegistry(); registry != nil {
+		for _, source := range registry.ListSources() {
+			sources = append(sources, SuppressionSource{ID: source.ID, DisplayName: source.DisplayName})
+		}
+	}
+	return ctx.JSON(http.StatusOK, sources)
+}
+
+// DeleteSuppressionRule handles DELETE /api/v2/suppression/rules/:id
+func (c *Controller) DeleteSuppressionRule(ctx echo.Context) error {
+	id := ctx.Param("id")
+	found := false
+	err := c.updateSuppressionRules(func(rules []conf.SuppressionRule) []conf.SuppressionRule {
+		kept := rules[:0]
+		for i := range rules {
+			if rules[i].ID == id {
+				found = true
+				continue
+			}
+			kept = append(kept, rules[i])
+		}
+		return kept
+	})
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to save settings", http.StatusInternalServerError)
+	}
+	if !found {
+		return c.HandleError(ctx, errSuppressRuleNotFound, "Suppression rule not found", http.StatusNotFound)
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Deleted suppression rule", "rule_id", id)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// SuppressDetection handles POST /api/v2/detections/:id/suppress
+// Creates a suppression rule from a detection reviewed as a false positive. The rule
+// suppresses the species at the audio source of the detection, or the source given in the
+// request, within a time window around the time of day of the detection. Detections
+// stored before their audio source was recorded need a source or all_sources in the request.
+func (c *Controller) SuppressDetection(ctx echo.Context) error {
+	if c.DS == nil {
+		return c.HandleError(ctx, errExportNoStore, "Datastore is not available", http.StatusServiceUnavailable)
+	}
+
+	note, err := c.DS.Get(ctx.Param("id"))
+	if err != nil {
+		return c.HandleError(ctx, err, "Detection not found", http.StatusNotFound)
+	}
+	if note.Verified != "false_positive" {
+		return c.HandleError(ctx, errSuppressNotFalsePositive, "Only detections reviewed as false positive can be suppressed", http.StatusConflict)
+	}
+
+	var req SuppressDetectionRequest
+	if ctx.Request().ContentLength != 0 {
+		if err := ctx.Bind(&req); err != nil {
+			return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+		}
+	}
+	if req.MaxConfidence < 0 || req.MaxConfidence > 1 {
+		return c.HandleError(ctx, errSuppressConfidence, "Invalid suppression request", http.StatusBadRequest)
+	}
+	if req.WindowMinutes < 0 {
+		return c.HandleError(ctx, errSuppressWindow, "Invalid suppression request", http.StatusBadRequest)
+	}
+
+	source := req.Source
+	if source == "" {
+		source = note.SourceID
+	}
+	if req.AllSources {
+		source = ""
+	} else if source == "" {
+		return c.HandleError(ctx, errSuppressNoSource, "Detection has no recorded audio source, set source or all_sources", http.StatusBadRequest)
+	}
+
+	rule, err := suppression.RuleFromNote(&note, source, time.Duration(req.WindowMinutes)*time.Minute, req.MaxConfidence)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid detection time", http.StatusUnprocessableEntity)
+	}
+	if req.Comment != "" {
+		rule.Comment = req.Comment
+	}
+
+	if err := c.updateSuppressionRules(func(rules []conf.SuppressionRule) []conf.SuppressionRule {
+		return append(rules, rule)
+	}); err != nil {
+		return c.HandleError(ctx, err, "Failed to save settings", http.StatusInternalServerError)
+	}
+
+	c.logAPIRequest(ctx, slog.LevelInfo, "Created suppression rule from false positive",
+		"rule_id", rule.ID,
+		"detection_id", note.ID,
+		"species", rule.Species,
+		"source", rule.Source,
+		"start", rule.Start,
+		"end", rule.End)
+
+	return ctx.JSON(http.StatusCreated, SuppressionRuleResponse{SuppressionRule: rule})
+}
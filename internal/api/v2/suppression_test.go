@@ -0,0 +1,106 @@
+// suppression_test.go: Package api provides tests for the suppression rule endpoints.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// suppressRequest posts a suppression request for a detection
+func suppressRequest(t *testing.T, e *echo.Echo, controller *Controller, id, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/detections/"+id+"/suppress", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(id)
+	require.NoError(t, controller.SuppressDetection(ctx))
+	return rec
+}
+
+func TestSuppressDetection(t *testing.T) {
+	e, mockDS, controller := setupTestEnvironment(t)
+	controller.DisableSaveSettings = true
+
+	mockDS.On("Get", "1").Return(datastore.Note{ID: 1, Date: "2024-05-01", Time: "23:50:00", CommonName: "Tawny Owl", ScientificName: "Strix aluco", Verified: "false_positive"}, nil)
+	mockDS.On("Get", "2").Return(datastore.Note{ID: 2, Time: "05:00:00", CommonName: "Eurasian Wren", ScientificName: "Troglodytes troglodytes", Verified: "correct"}, nil)
+	mockDS.On("Get", "3").Return(datastore.Note{}, errors.New("record not found"))
+	mockDS.On("Get", "4").Return(datastore.Note{ID: 4, Time: "06:00:00", CommonName: "Tawny Owl", ScientificName: "Strix aluco", SourceID: "rtsp_87b89761", Verified: "false_positive"}, nil)
+
+	rec := suppressRequest(t, e, controller, "1", `{"source": "garden", "window_minutes": 20, "max_confidence": 0.9}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp SuppressionRuleResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "Strix aluco", resp.Species)
+	assert.Equal(t, "garden", resp.Source)
+	assert.Equal(t, "23:30", resp.Start)
+	assert.Equal(t, "00:10", resp.End)
+	assert.Equal(t, uint(1), resp.NoteID)
+
+	require.Len(t, controller.Settings.Realtime.Suppression.Rules, 1)
+	assert.Equal(t, resp.ID, controller.Settings.Realtime.Suppression.Rules[0].ID)
+
+	// Only false positives can be suppressed
+	assert.Equal(t, http.StatusConflict, suppressRequest(t, e, controller, "2", "").Code)
+	assert.Equal(t, http.StatusNotFound, suppressRequest(t, e, controller, "3", "").Code)
+	assert.Equal(t, http.StatusBadRequest, suppressRequest(t, e, controller, "1", `{"max_confidence": 2}`).Code)
+	assert.Len(t, controller.Settings.Realtime.Suppression.Rules, 1)
+
+	// Without a source in the request the recorded source of the detection is used
+	rec = suppressRequest(t, e, controller, "4", "")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "rtsp_87b89761", resp.Source)
+
+	// Detections without a recorded source need a source or all_sources
+	assert.Equal(t, http.StatusBadRequest, suppressRequest(t, e, controller, "1", "").Code)
+	rec = suppressRequest(t, e, controller, "1", `{"all_sources": true}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Empty(t, resp.Source)
+	assert.Len(t, controller.Settings.Realtime.Suppression.Rules, 3)
+}
+
+func TestSuppressionRules(t *testing.T) {
+	e, _, controller := setupTestEnvironment(t)
+	controller.DisableSaveSettings = true
+	controller.Settings.Realtime.Suppression.Rules = []conf.SuppressionRule{
+		{ID: "rule-1", Species: "Strix aluco", Source: "garden"},
+		{ID: "rule-2", Species: "Troglodytes troglodytes"},
+	}
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, controller.GetSuppressionRules(e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v2/suppression/rules", http.NoBody), rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var rules []SuppressionRuleResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rules))
+	require.Len(t, rules, 2)
+	assert.Equal(t, "rule-1", rules[0].ID)
+
+	deleteRule := func(id string) int {
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(httptest.NewRequest(http.MethodDelete, "/api/v2/suppression/rules/"+id, http.NoBody), rec)
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+		require.NoError(t, controller.DeleteSuppressionRule(ctx))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, deleteRule("rule-1"))
+	assert.Equal(t, http.StatusNotFound, deleteRule("rule-1"))
+	require.Len(t, controller.Settings.Realtime.Suppression.Rules, 1)
+	assert.Equal(t, "rule-2", controller.Settings.Realtime.Suppression.Rules[0].ID)
+}
@@ -41,6 +41,21 @@ func safeSlice[T any](args mock.Arguments, index int) []T {
 	return nil // Return nil if the argument itself is nil
 }
 
+// streamMockNotes passes notes to fn in batches of batchSize, like StreamNotesAdvanced does.
+// If err is set it is returned after all notes have been passed.
+func streamMockNotes(notes []datastore.Note, batchSize int, fn func([]datastore.Note) error, err error) error {
+	if batchSize <= 0 {
+		batchSize = len(notes)
+	}
+	for start := 0; start < len(notes); start += batchSize {
+		end := min(start+batchSize, len(notes))
+		if fnErr := fn(notes[start:end]); fnErr != nil {
+			return fnErr
+		}
+	}
+	return err
+}
+
 // safePointer is a helper for mock methods returning pointers.
 // It safely handles nil arguments and performs type assertion.
 func safePointer[T any](args mock.Arguments, index int) *T {
@@ -135,6 +150,10 @@ func (m *MockDataStore) SearchNotesAdvanced(filters *datastore.AdvancedSearchFil
 	args := m.Called(filters)
 	return safeSlice[datastore.Note](args, 0), args.Get(1).(int64), args.Error(2)
 }
+func (m *MockDataStore) StreamNotesAdvanced(ctx context.Context, filters *datastore.AdvancedSearchFilters, batchSize int, fn func([]datastore.Note) error) error {
+	args := m.Called(filters, batchSize)
+	return streamMockNotes(safeSlice[datastore.Note](args, 0), batchSize, fn, args.Error(1))
+}
 
 func (m *MockDataStore) GetNoteClipPath(noteID string) (string, error) {
 	args := m.Called(noteID)
@@ -452,6 +471,10 @@ func (m *MockDataStoreV2) SearchNotesAdvanced(filters *datastore.AdvancedSearchF
 	args := m.Called(filters)
 	return safeSlice[datastore.Note](args, 0), args.Get(1).(int64), args.Error(2)
 }
+func (m *MockDataStoreV2) StreamNotesAdvanced(ctx context.Context, filters *datastore.AdvancedSearchFilters, batchSize int, fn func([]datastore.Note) error) error {
+	args := m.Called(filters, batchSize)
+	return streamMockNotes(safeSlice[datastore.Note](args, 0), batchSize, fn, args.Error(1))
+}
 func (m *MockDataStoreV2) GetNoteClipPath(noteID string) (string, error) {
 	args := m.Called(noteID)
 	return args.String(0), args.Error(1)
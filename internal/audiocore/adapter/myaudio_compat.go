@@ -307,6 +307,9 @@ func (s *SoundLevelAnalyzer) Process(audioData []byte) *myaudio.SoundLevelData {
 		Source:      "audiocore",
 		Name:        "AudioCore Source",
 		Duration:    s.interval,
+		Leq:         67.8, // Energetic sum of the mock bands
+		Lmin:        62.8,
+		Lmax:        72.8,
 		OctaveBands: octaveBands,
 	}
 }
@@ -98,6 +98,34 @@ func (m *Manager) getEncryptionKey() ([]byte, error) {
 		return key, nil
 	}
 
+	return decodeEncryptionKey(keyBytes)
+}
+
+// readEncryptionKey reads the existing encryption key without generating a new one.
+// Restores need the key that encrypted the archive even when encryption has since
+// been disabled.
+func (m *Manager) readEncryptionKey() ([]byte, error) {
+	keyPath, err := m.getEncryptionKeyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	secureOp := NewSecureFileOp("backup")
+	keyBytes, _, err := secureOp.SecureReadFile(keyPath)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "read_encryption_key").
+			Context("key_path", keyPath).
+			Build()
+	}
+
+	return decodeEncryptionKey(keyBytes)
+}
+
+// decodeEncryptionKey decodes and validates a hex encoded encryption key
+func decodeEncryptionKey(keyBytes []byte) ([]byte, error) {
 	// Decode existing key from hex
 	keyStr := strings.TrimSpace(string(keyBytes))
 	key, err := hex.DecodeString(keyStr)
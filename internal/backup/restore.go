@@ -0,0 +1,426 @@
+// restore.go provides restoring application data from backup archives
+package backup
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	archiveMetadataFile = "metadata.json"
+	archiveConfigFile   = "config.yml"
+
+	// maxArchiveSmallFileSize limits the size of metadata and config entries read into memory
+	maxArchiveSmallFileSize = 10 * 1024 * 1024
+)
+
+// RestoreOptions configures a restore from a backup archive
+type RestoreOptions struct {
+	Target        string // Restrict the backup lookup to this target, empty searches all targets
+	RestoreConfig bool   // Also restore the sanitized configuration stored in the archive
+	ConfigPath    string // Destination of the restored configuration, defaults to the active config file
+
+	// StopDatastore is called right before the live data is replaced, for
+	// example to close the database connection of a running application
+	StopDatastore func() error
+	// StartDatastore is called after the live data has been replaced, or after
+	// the swap has been rolled back, if StopDatastore succeeded
+	StartDatastore func() error
+}
+
+// RestoreResult describes a completed restore
+type RestoreResult struct {
+	BackupID           string
+	Target             string
+	Source             string
+	Timestamp          time.Time // Creation time of the restored backup
+	PreviousDataPath   string    // Where the replaced data was preserved, empty if there was none
+	ConfigRestored     bool
+	ConfigPath         string
+	PreviousConfigPath string // Where the replaced configuration was preserved
+	Duration           time.Duration
+}
+
+// extractedArchive holds the entries of an unpacked backup archive
+type extractedArchive struct {
+	metadata   *Metadata
+	dataPath   string
+	configData []byte
+}
+
+// PreservedPath returns the path where a file replaced by a restore is kept
+func PreservedPath(path string, t time.Time) string {
+	return path + ".pre-restore-" + t.UTC().Format("20060102-150405")
+}
+
+// Restore fetches a backup archive from its target, verifies its checksum,
+// decrypts and unpacks it, and hands the backup data to the restorer of the
+// source that created it. The sanitized configuration in the archive is
+// restored when requested, keeping the secrets of the current configuration.
+func (m *Manager) Restore(ctx context.Context, id string, opts *RestoreOptions) (*RestoreResult, error) {
+	if opts == nil {
+		opts = &RestoreOptions{}
+	}
+	start := time.Now()
+	m.logger.Info("Starting restore", "backup_id", id, "target", opts.Target, "restore_config", opts.RestoreConfig)
+
+	backupInfo, target, err := m.findBackupInTarget(ctx, id, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "birdnet-go-restore-*")
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_restore_temp_directory").
+			Build()
+	}
+	defer m.cleanupTempDirectories([]string{tempDir})
+
+	archivePath, err := m.fetchArchive(ctx, backupInfo, target, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	extracted, err := m.extractArchive(ctx, archivePath, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if extracted.metadata.ID != backupInfo.ID {
+		return nil, NewError(ErrValidation, fmt.Sprintf("archive belongs to backup '%s', expected '%s'", extracted.metadata.ID, backupInfo.ID), nil)
+	}
+
+	m.mu.RLock()
+	restorer, ok := m.restorers[extracted.metadata.Source]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, NewError(ErrValidation, fmt.Sprintf("no restorer registered for backup source '%s'", extracted.metadata.Source), nil)
+	}
+
+	// Prepare the configuration before touching any live data so that an
+	// unusable configuration does not leave a half finished restore behind
+	var restoredConfig *conf.Settings
+	configPath := opts.ConfigPath
+	if opts.RestoreConfig {
+		if restoredConfig, err = m.prepareRestoredConfig(extracted.configData); err != nil {
+			return nil, err
+		}
+		if configPath == "" {
+			if configPath, err = conf.FindConfigFile(); err != nil {
+				return nil, errors.New(err).
+					Component("backup").
+					Category(errors.CategoryConfiguration).
+					Context("operation", "find_config_file_for_restore").
+					Build()
+			}
+		}
+	}
+
+	// Hold the write lock while live data is replaced so that no backup runs concurrently
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previousDataPath, err := restorer.Restore(ctx, extracted.dataPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{
+		BackupID:         backupInfo.ID,
+		Target:           target.Name(),
+		Source:           extracted.metadata.Source,
+		Timestamp:        extracted.metadata.Timestamp,
+		PreviousDataPath: previousDataPath,
+	}
+
+	if restoredConfig != nil {
+		previousConfigPath, err := writeRestoredConfig(configPath, restoredConfig)
+		if err != nil {
+			return result, err
+		}
+		result.ConfigRestored = true
+		result.ConfigPath = configPath
+		result.PreviousConfigPath = previousConfigPath
+	}
+
+	result.Duration = time.Since(start)
+	m.logger.Info("Restore completed",
+		"backup_id", result.BackupID,
+		"target", result.Target,
+		"source", result.Source,
+		"previous_data_path", result.PreviousDataPath,
+		"config_restored", result.ConfigRestored,
+		"duration_ms", result.Duration.Milliseconds())
+
+	return result, nil
+}
+
+// fetchArchive downloads a backup archive into dir, verifies its checksum and
+// decrypts it if needed. It returns the path of the plain tar archive.
+func (m *Manager) fetchArchive(ctx context.Context, backupInfo *BackupInfo, target Target, dir string) (string, error) {
+	archivePath := filepath.Join(dir, backupInfo.ArchiveFileName())
+	file, err := os.OpenFile(archivePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_restore_archive").
+			Build()
+	}
+
+	err = m.downloadFromTarget(ctx, backupInfo, target, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = errors.New(closeErr).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "close_restore_archive").
+			Build()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if backupInfo.Checksum == "" {
+		m.logger.Warn("Backup has no recorded checksum, skipping checksum verification", "backup_id", backupInfo.ID)
+	} else {
+		checksum, err := calculateChecksum(archivePath)
+		if err != nil {
+			return "", errors.New(err).
+				Component("backup").
+				Category(errors.CategoryFileIO).
+				Context("operation", "calculate_restore_checksum").
+				Build()
+		}
+		if !strings.EqualFold(checksum, backupInfo.Checksum) {
+			return "", NewError(ErrCorruption, fmt.Sprintf("backup archive checksum mismatch: expected %s, got %s", backupInfo.Checksum, checksum), nil)
+		}
+		m.logger.Debug("Verified backup archive checksum", "backup_id", backupInfo.ID)
+	}
+
+	if !backupInfo.Encrypted {
+		return archivePath, nil
+	}
+
+	return m.decryptArchive(archivePath)
+}
+
+// decryptArchive decrypts an encrypted archive and returns the path of the plain archive
+func (m *Manager) decryptArchive(encryptedPath string) (string, error) {
+	key, err := m.readEncryptionKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to read encryption key for restore: %w", err)
+	}
+
+	secureOp := NewSecureFileOp("backup")
+	ciphertext, _, err := secureOp.SecureReadFile(encryptedPath)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := decryptData(ciphertext, key)
+	if err != nil {
+		return "", NewError(ErrCorruption, "failed to decrypt backup archive, it is damaged or was encrypted with a different key", err)
+	}
+
+	plainPath := strings.TrimSuffix(encryptedPath, ".enc")
+	if plainPath == encryptedPath {
+		plainPath += ".dec"
+	}
+	if err := os.WriteFile(plainPath, plaintext, 0o600); err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_decrypted_archive").
+			Build()
+	}
+
+	// The encrypted copy is no longer needed
+	if err := os.Remove(encryptedPath); err != nil {
+		m.logger.Warn("Failed to remove encrypted archive after decryption", "path", encryptedPath, "error", err)
+	}
+
+	return plainPath, nil
+}
+
+// extractArchive unpacks a backup archive into dir. Only the entries written by
+// createArchive are accepted.
+func (m *Manager) extractArchive(ctx context.Context, archivePath, dir string) (*extractedArchive, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "open_restore_archive").
+			Build()
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	extracted := &extractedArchive{}
+	var dataEntry string
+	tr := tar.NewReader(file)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategorySystem).
+				Context("operation", "extract_restore_archive").
+				Context("error_type", "cancelled").
+				Build()
+		}
+
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewError(ErrCorruption, "backup archive is damaged or not a valid archive", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, NewError(ErrValidation, fmt.Sprintf("unexpected entry '%s' in backup archive", hdr.Name), nil)
+		}
+
+		switch {
+		case hdr.Name == archiveMetadataFile:
+			data, err := readArchiveEntry(tr, hdr)
+			if err != nil {
+				return nil, err
+			}
+			var metadata Metadata
+			if err := json.Unmarshal(data, &metadata); err != nil {
+				return nil, NewError(ErrCorruption, "backup archive metadata is damaged", err)
+			}
+			extracted.metadata = &metadata
+		case hdr.Name == archiveConfigFile:
+			if extracted.configData, err = readArchiveEntry(tr, hdr); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(hdr.Name, "backup.") && !strings.ContainsAny(hdr.Name, `/\`):
+			dataEntry = hdr.Name
+			extracted.dataPath = filepath.Join(dir, "restore-"+hdr.Name)
+			if err := writeArchiveEntry(tr, extracted.dataPath); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, NewError(ErrValidation, fmt.Sprintf("unexpected entry '%s' in backup archive", hdr.Name), nil)
+		}
+	}
+
+	switch {
+	case extracted.metadata == nil:
+		return nil, NewError(ErrValidation, "backup archive does not contain metadata", nil)
+	case extracted.dataPath == "":
+		return nil, NewError(ErrValidation, "backup archive does not contain backup data", nil)
+	case dataEntry != backupDataFileName(extracted.metadata.Source):
+		return nil, NewError(ErrValidation, fmt.Sprintf("backup data entry '%s' does not match source '%s'", dataEntry, extracted.metadata.Source), nil)
+	}
+
+	m.logger.Debug("Extracted backup archive", "backup_id", extracted.metadata.ID, "source", extracted.metadata.Source)
+	return extracted, nil
+}
+
+// readArchiveEntry reads a small archive entry into memory
+func readArchiveEntry(r io.Reader, hdr *tar.Header) ([]byte, error) {
+	if hdr.Size > maxArchiveSmallFileSize {
+		return nil, NewError(ErrValidation, fmt.Sprintf("backup archive entry '%s' is too large", hdr.Name), nil)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_archive_entry").
+			Context("entry", hdr.Name).
+			Build()
+	}
+	return data, nil
+}
+
+// writeArchiveEntry writes the current archive entry to path
+func writeArchiveEntry(r io.Reader, path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_archive_entry_file").
+			Build()
+	}
+
+	_, err = io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "extract_archive_entry").
+			Build()
+	}
+	return nil
+}
+
+// prepareRestoredConfig parses the sanitized configuration from an archive and
+// fills in the secrets of the current configuration
+func (m *Manager) prepareRestoredConfig(configData []byte) (*conf.Settings, error) {
+	if len(configData) == 0 {
+		return nil, NewError(ErrValidation, "backup archive does not contain a configuration", nil)
+	}
+
+	var restored conf.Settings
+	if err := yaml.Unmarshal(configData, &restored); err != nil {
+		return nil, errors.New(err).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "parse_restored_config").
+			Build()
+	}
+
+	restoreSensitiveConfig(&restored, m.fullConfig)
+	return &restored, nil
+}
+
+// writeRestoredConfig preserves the current configuration file and replaces it
+// with the restored configuration. It returns the path of the preserved file.
+func writeRestoredConfig(configPath string, restored *conf.Settings) (string, error) {
+	var previousPath string
+	if current, err := os.ReadFile(configPath); err == nil {
+		previousPath = PreservedPath(configPath, time.Now())
+		if err := os.WriteFile(previousPath, current, 0o600); err != nil {
+			return "", errors.New(err).
+				Component("backup").
+				Category(errors.CategoryFileIO).
+				Context("operation", "preserve_config_before_restore").
+				Context("config_path", configPath).
+				Build()
+		}
+	} else if !os.IsNotExist(err) {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_config_before_restore").
+			Context("config_path", configPath).
+			Build()
+	}
+
+	if err := conf.SaveYAMLConfig(configPath, restored); err != nil {
+		return previousPath, err
+	}
+	return previousPath, nil
+}
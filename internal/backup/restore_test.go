@@ -0,0 +1,220 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"gopkg.in/yaml.v3"
+)
+
+// memorySource is a backup source returning fixed data
+type memorySource struct {
+	name string
+	data []byte
+}
+
+func (s *memorySource) Name() string    { return s.name }
+func (s *memorySource) Validate() error { return nil }
+func (s *memorySource) Backup(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.data)), nil
+}
+
+// memoryTarget is a backup target keeping archives in memory
+type memoryTarget struct {
+	mu       sync.Mutex
+	archives map[string][]byte
+	metadata map[string]Metadata
+}
+
+func newMemoryTarget() *memoryTarget {
+	return &memoryTarget{archives: make(map[string][]byte), metadata: make(map[string]Metadata)}
+}
+
+func (t *memoryTarget) Name() string    { return "memory" }
+func (t *memoryTarget) Validate() error { return nil }
+
+func (t *memoryTarget) Store(ctx context.Context, sourcePath string, metadata *Metadata) error {
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.archives[metadata.ID] = data
+	t.metadata[metadata.ID] = *metadata
+	return nil
+}
+
+func (t *memoryTarget) List(ctx context.Context) ([]BackupInfo, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	backups := make([]BackupInfo, 0, len(t.metadata))
+	for _, metadata := range t.metadata {
+		backups = append(backups, BackupInfo{Metadata: metadata, Target: t.Name()})
+	}
+	return backups, nil
+}
+
+func (t *memoryTarget) Delete(ctx context.Context, id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.archives, id)
+	delete(t.metadata, id)
+	return nil
+}
+
+func (t *memoryTarget) Download(ctx context.Context, id string, w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err := w.Write(t.archives[id])
+	return err
+}
+
+// recordingRestorer records the data it is asked to restore
+type recordingRestorer struct {
+	name     string
+	restored []byte
+	stopped  bool
+	started  bool
+}
+
+func (r *recordingRestorer) Name() string { return r.name }
+
+func (r *recordingRestorer) Restore(ctx context.Context, dataPath string, opts *RestoreOptions) (string, error) {
+	data, err := os.ReadFile(dataPath)
+	if err != nil {
+		return "", err
+	}
+	if opts.StopDatastore != nil {
+		r.stopped = opts.StopDatastore() == nil
+	}
+	r.restored = data
+	if opts.StartDatastore != nil {
+		r.started = opts.StartDatastore() == nil
+	}
+	return "/previous/data", nil
+}
+
+// setupRestoreTest creates a manager with a memory target and a stored backup
+func setupRestoreTest(t *testing.T, encrypted bool) (manager *Manager, target *memoryTarget, backupID string, data []byte) {
+	t.Helper()
+
+	// The encryption key and backup state are stored in the user's config directory
+	t.Setenv("HOME", t.TempDir())
+
+	settings := &conf.Settings{}
+	settings.Backup.Encryption = encrypted
+	settings.Realtime.MQTT.Password = "mqtt-secret"
+	settings.Main.Name = "backup-node"
+
+	stateManager, err := NewStateManager(nil)
+	require.NoError(t, err)
+	manager, err = NewManager(settings, nil, stateManager, "test")
+	require.NoError(t, err)
+
+	data = bytes.Repeat([]byte("birdnet database "), 1024)
+	require.NoError(t, manager.RegisterSource(&memorySource{name: "birdnet", data: data}))
+	target = newMemoryTarget()
+	require.NoError(t, manager.RegisterTarget(target))
+
+	require.NoError(t, manager.RunBackup(context.Background()))
+
+	backups, err := manager.ListBackups(context.Background())
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, encrypted, backups[0].Encrypted)
+	assert.NotEmpty(t, backups[0].Checksum)
+
+	return manager, target, backups[0].ID, data
+}
+
+func TestRestore_RoundTrip(t *testing.T) {
+	for _, encrypted := range []bool{false, true} {
+		t.Run(map[bool]string{false: "plain", true: "encrypted"}[encrypted], func(t *testing.T) {
+			manager, _, backupID, data := setupRestoreTest(t, encrypted)
+
+			restorer := &recordingRestorer{name: "birdnet"}
+			manager.RegisterRestorer(restorer)
+
+			result, err := manager.Restore(context.Background(), backupID, &RestoreOptions{
+				StopDatastore:  func() error { return nil },
+				StartDatastore: func() error { return nil },
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, data, restorer.restored)
+			assert.True(t, restorer.stopped)
+			assert.True(t, restorer.started)
+			assert.Equal(t, backupID, result.BackupID)
+			assert.Equal(t, "memory", result.Target)
+			assert.Equal(t, "birdnet", result.Source)
+			assert.Equal(t, "/previous/data", result.PreviousDataPath)
+			assert.False(t, result.ConfigRestored)
+		})
+	}
+}
+
+func TestRestore_Config(t *testing.T) {
+	manager, _, backupID, _ := setupRestoreTest(t, false)
+	manager.RegisterRestorer(&recordingRestorer{name: "birdnet"})
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("main:\n  name: current\n"), 0o600))
+
+	result, err := manager.Restore(context.Background(), backupID, &RestoreOptions{
+		RestoreConfig: true,
+		ConfigPath:    configPath,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.ConfigRestored)
+	assert.Equal(t, configPath, result.ConfigPath)
+
+	// The previous configuration is preserved
+	previous, err := os.ReadFile(result.PreviousConfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, "main:\n  name: current\n", string(previous))
+
+	// The restored configuration keeps the secrets removed from the archive
+	restoredData, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	var restored conf.Settings
+	require.NoError(t, yaml.Unmarshal(restoredData, &restored))
+	assert.Equal(t, "backup-node", restored.Main.Name)
+	assert.Equal(t, "mqtt-secret", restored.Realtime.MQTT.Password)
+}
+
+func TestRestore_ChecksumMismatch(t *testing.T) {
+	manager, target, backupID, _ := setupRestoreTest(t, false)
+	restorer := &recordingRestorer{name: "birdnet"}
+	manager.RegisterRestorer(restorer)
+
+	target.archives[backupID][len(target.archives[backupID])-1] ^= 0xff
+
+	_, err := manager.Restore(context.Background(), backupID, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+	assert.Nil(t, restorer.restored, "corrupted archives are not restored")
+}
+
+func TestRestore_Errors(t *testing.T) {
+	manager, _, backupID, _ := setupRestoreTest(t, false)
+
+	_, err := manager.Restore(context.Background(), backupID, nil)
+	require.Error(t, err, "restore fails without a restorer for the source")
+
+	manager.RegisterRestorer(&recordingRestorer{name: "birdnet"})
+
+	_, err = manager.Restore(context.Background(), "missing", nil)
+	assert.True(t, IsErrorCode(err, ErrNotFound))
+
+	_, err = manager.Restore(context.Background(), backupID, &RestoreOptions{Target: "other"})
+	assert.True(t, IsErrorCode(err, ErrNotFound))
+}
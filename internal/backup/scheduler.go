@@ -22,6 +22,20 @@ type BackupSchedule struct {
 	IsWeekly bool         // true for weekly backups, false for daily
 }
 
+// ScheduleStatus combines a configured schedule with its persisted state
+type ScheduleStatus struct {
+	Schedule BackupSchedule
+	Type     string // "daily" or "weekly"
+	State    ScheduleState
+}
+
+// ManualBackupRun describes the most recent manually triggered backup
+type ManualBackupRun struct {
+	StartedAt  time.Time
+	FinishedAt time.Time // Zero while the backup is running
+	Error      string    // Empty when the backup succeeded
+}
+
 // Scheduler manages backup schedules and their execution
 type Scheduler struct {
 	manager       *Manager
@@ -32,6 +46,7 @@ type Scheduler struct {
 	runningBackup sync.Mutex
 	logger        *slog.Logger
 	state         *StateManager
+	lastManualRun ManualBackupRun // Protected by mu
 }
 
 // NewScheduler creates a new backup scheduler
@@ -503,9 +518,46 @@ func (s *Scheduler) TriggerBackup(ctx context.Context) error {
 	}
 	defer s.runningBackup.Unlock()
 
+	return s.runManualBackup(ctx)
+}
+
+// StartBackup starts a manual backup in the background and returns immediately.
+// It returns an ErrLocked error if another backup is already running.
+func (s *Scheduler) StartBackup() error {
+	if !s.runningBackup.TryLock() {
+		s.logger.Warn("Cannot start manual backup - another backup is already running")
+		return NewError(ErrLocked, "another backup is already in progress", nil)
+	}
+
+	go func() {
+		defer s.runningBackup.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.manager.getBackupTimeout())
+		defer cancel()
+		_ = s.runManualBackup(ctx) // Outcome is logged and recorded in lastManualRun
+	}()
+	return nil
+}
+
+// runManualBackup runs a manual backup and records its outcome.
+// The caller must hold runningBackup.
+func (s *Scheduler) runManualBackup(ctx context.Context) error {
+	s.mu.Lock()
+	s.lastManualRun = ManualBackupRun{StartedAt: time.Now()}
+	s.mu.Unlock()
+
 	s.logger.Info("Manually triggering backup run...")
 	// Run the backup process directly (might block depending on implementation)
-	if err := s.manager.RunBackup(ctx); err != nil {
+	err := s.manager.RunBackup(ctx)
+
+	s.mu.Lock()
+	s.lastManualRun.FinishedAt = time.Now()
+	if err != nil {
+		s.lastManualRun.Error = err.Error()
+	}
+	s.mu.Unlock()
+
+	if err != nil {
 		s.logger.Error("Manual backup trigger failed", "error", err)
 		return err
 	}
@@ -513,6 +565,47 @@ func (s *Scheduler) TriggerBackup(ctx context.Context) error {
 	return nil
 }
 
+// IsBackupRunning reports whether a scheduled or manual backup is currently running
+func (s *Scheduler) IsBackupRunning() bool {
+	if s.runningBackup.TryLock() {
+		s.runningBackup.Unlock()
+		return false
+	}
+	return true
+}
+
+// GetLastManualRun returns the most recent manual backup run, or a zero value if none ran yet
+func (s *Scheduler) GetLastManualRun() ManualBackupRun {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastManualRun
+}
+
+// GetScheduleStatus returns the configured schedules together with their persisted state
+func (s *Scheduler) GetScheduleStatus() []ScheduleStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]ScheduleStatus, 0, len(s.schedules))
+	for i := range s.schedules {
+		scheduleType := "daily"
+		if s.schedules[i].IsWeekly {
+			scheduleType = "weekly"
+		}
+		statuses = append(statuses, ScheduleStatus{
+			Schedule: s.schedules[i],
+			Type:     scheduleType,
+			State:    s.state.GetScheduleState(&s.schedules[i]),
+		})
+	}
+	return statuses
+}
+
+// GetTargetState returns the persisted state of a backup target
+func (s *Scheduler) GetTargetState(targetName string) TargetState {
+	return s.state.GetTargetState(targetName)
+}
+
 // getScheduleType returns a string representation of the schedule type
 func (s *Scheduler) getScheduleType(schedule *BackupSchedule) string {
 	if schedule.IsWeekly {
@@ -0,0 +1,289 @@
+// sqlite_restore.go implements restoring SQLite databases from backups
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// requiredSchema lists the tables and columns a restored database must contain
+var requiredSchema = map[string][]string{
+	"notes":   {"id", "date", "time", "scientific_name", "common_name", "confidence"},
+	"results": {"id", "note_id", "species", "confidence"},
+}
+
+// sqliteSidecarSuffixes are the suffixes of the WAL mode files next to a database
+var sqliteSidecarSuffixes = []string{"-wal", "-shm"}
+
+// Compile-time check that SQLiteSource implements backup.Restorer
+var _ backup.Restorer = (*SQLiteSource)(nil)
+
+// Restore validates the integrity and schema of a database restored from a backup
+// and atomically replaces the configured database with it. The replaced database
+// is preserved next to the original path.
+func (s *SQLiteSource) Restore(ctx context.Context, dataPath string, opts *backup.RestoreOptions) (string, error) {
+	start := time.Now()
+
+	dbPath, err := s.validateConfig()
+	if err != nil {
+		return "", err
+	}
+
+	s.logger.Info("Validating database restored from backup", "restore_path", dataPath)
+	if err := s.validateRestoredDatabase(dataPath); err != nil {
+		return "", backup.NewError(backup.ErrCorruption, "database in backup failed validation", err)
+	}
+
+	// Stage the restored database next to the live database so that the final
+	// rename stays on the same filesystem and is atomic
+	stagedPath, err := s.stageRestoredDatabase(ctx, dataPath, dbPath)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := os.Remove(stagedPath); err != nil && !os.IsNotExist(err) {
+			s.logger.Warn("Failed to remove staged database", "path", stagedPath, "error", err)
+		}
+	}()
+
+	if opts != nil && opts.StopDatastore != nil {
+		s.logger.Info("Stopping datastore before replacing database")
+		if err := opts.StopDatastore(); err != nil {
+			return "", errors.New(err).
+				Component("backup").
+				Category(errors.CategoryDatabase).
+				Context("operation", "stop_datastore_for_restore").
+				Build()
+		}
+	}
+
+	previousPath, swapErr := s.swapDatabase(stagedPath, dbPath)
+
+	if opts != nil && opts.StartDatastore != nil {
+		s.logger.Info("Starting datastore after replacing database")
+		if err := opts.StartDatastore(); err != nil && swapErr == nil {
+			return previousPath, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryDatabase).
+				Context("operation", "start_datastore_after_restore").
+				Build()
+		}
+	}
+	if swapErr != nil {
+		return "", swapErr
+	}
+
+	s.logger.Info("Database restored from backup",
+		"db_path", dbPath,
+		"previous_path", previousPath,
+		"duration_ms", time.Since(start).Milliseconds())
+	return previousPath, nil
+}
+
+// validateRestoredDatabase checks the integrity and schema of a restored database
+func (s *SQLiteSource) validateRestoredDatabase(dataPath string) error {
+	return s.withDatabase(dataPath, true, func(conn *DatabaseConnection) error {
+		if err := s.verifyDatabaseIntegrity(conn.db); err != nil {
+			return err
+		}
+		return verifyDatabaseSchema(conn.db)
+	})
+}
+
+// verifyDatabaseSchema checks that the database contains the required tables and columns
+func verifyDatabaseSchema(db *sql.DB) error {
+	for table, columns := range requiredSchema {
+		rows, err := db.Query("SELECT name FROM pragma_table_info(?)", table)
+		if err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryDatabase).
+				Context("operation", "schema_check").
+				Context("table", table).
+				Build()
+		}
+
+		present := make(map[string]bool)
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				_ = rows.Close()
+				return errors.New(err).
+					Component("backup").
+					Category(errors.CategoryDatabase).
+					Context("operation", "schema_check").
+					Context("table", table).
+					Build()
+			}
+			present[name] = true
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return errors.New(err).
+				Component("backup").
+				Category(errors.CategoryDatabase).
+				Context("operation", "schema_check").
+				Context("table", table).
+				Build()
+		}
+
+		if len(present) == 0 {
+			return errors.Newf("restored database is missing table %s", table).
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "schema_check").
+				Context("table", table).
+				Build()
+		}
+		for _, column := range columns {
+			if !present[column] {
+				return errors.Newf("restored database table %s is missing column %s", table, column).
+					Component("backup").
+					Category(errors.CategoryValidation).
+					Context("operation", "schema_check").
+					Context("table", table).
+					Context("column", column).
+					Build()
+			}
+		}
+	}
+	return nil
+}
+
+// stageRestoredDatabase copies the restored database into the directory of the live database
+func (s *SQLiteSource) stageRestoredDatabase(ctx context.Context, dataPath, dbPath string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_database_directory").
+			Context("directory", filepath.Dir(dbPath)).
+			Build()
+	}
+
+	src, err := os.Open(dataPath)
+	if err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "open_restored_database").
+			Build()
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	dst, err := os.CreateTemp(filepath.Dir(dbPath), filepath.Base(dbPath)+".restore-*")
+	if err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "create_staged_database").
+			Build()
+	}
+	stagedPath := dst.Name()
+
+	_, err = io.Copy(dst, &contextReader{ctx: ctx, r: src})
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(stagedPath)
+		if isMediaError(err) {
+			return "", errors.New(err).
+				Component("backup").
+				Category(errors.CategoryDiskUsage).
+				Context("operation", "stage_restored_database").
+				Context("error_type", "media_error").
+				Build()
+		}
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "stage_restored_database").
+			Build()
+	}
+
+	return stagedPath, nil
+}
+
+// swapDatabase moves the live database and its WAL files aside and renames the
+// staged database into place. On failure the previous database is put back.
+func (s *SQLiteSource) swapDatabase(stagedPath, dbPath string) (string, error) {
+	var previousPath string
+	if _, err := os.Stat(dbPath); err == nil {
+		previousPath = backup.PreservedPath(dbPath, time.Now())
+		if err := os.Rename(dbPath, previousPath); err != nil {
+			return "", errors.New(err).
+				Component("backup").
+				Category(errors.CategoryFileIO).
+				Context("operation", "preserve_database_before_restore").
+				Context("db_path", dbPath).
+				Build()
+		}
+	}
+
+	// Stale WAL files would be applied to the restored database, keep them
+	// with the database they belong to
+	for _, suffix := range sqliteSidecarSuffixes {
+		sidecar := dbPath + suffix
+		if _, err := os.Stat(sidecar); err != nil {
+			continue
+		}
+		var err error
+		if previousPath != "" {
+			err = os.Rename(sidecar, previousPath+suffix)
+		} else {
+			err = os.Remove(sidecar)
+		}
+		if err != nil {
+			s.logger.Warn("Failed to move database sidecar file", "path", sidecar, "error", err)
+		}
+	}
+
+	if err := os.Rename(stagedPath, dbPath); err != nil {
+		if previousPath != "" {
+			if rollbackErr := os.Rename(previousPath, dbPath); rollbackErr != nil {
+				s.logger.Error("Failed to roll back database after failed restore",
+					"db_path", dbPath,
+					"previous_path", previousPath,
+					"error", rollbackErr)
+			}
+			for _, suffix := range sqliteSidecarSuffixes {
+				_ = os.Rename(previousPath+suffix, dbPath+suffix)
+			}
+		}
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "swap_restored_database").
+			Context("db_path", dbPath).
+			Build()
+	}
+
+	return previousPath, nil
+}
+
+// contextReader stops reading once its context is cancelled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,132 @@
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// createTestDatabase creates a SQLite database with the given statements
+func createTestDatabase(t *testing.T, path string, statements ...string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	for _, stmt := range statements {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+}
+
+// birdnetSchema creates the tables a restored database must contain
+var birdnetSchema = []string{
+	"CREATE TABLE notes (id INTEGER PRIMARY KEY, date TEXT, time TEXT, scientific_name TEXT, common_name TEXT, confidence REAL)",
+	"CREATE TABLE results (id INTEGER PRIMARY KEY, note_id INTEGER, species TEXT, confidence REAL)",
+}
+
+func countNotes(t *testing.T, path string) int {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM notes").Scan(&count))
+	return count
+}
+
+func newTestSQLiteSource(t *testing.T) (source *SQLiteSource, dbPath string) {
+	t.Helper()
+
+	dbPath = filepath.Join(t.TempDir(), "birdnet.db")
+	settings := &conf.Settings{}
+	settings.Output.SQLite.Enabled = true
+	settings.Output.SQLite.Path = dbPath
+	return NewSQLiteSource(settings, nil), dbPath
+}
+
+func TestSQLiteSource_Restore(t *testing.T) {
+	source, dbPath := newTestSQLiteSource(t)
+
+	// Live database with one detection and a stale WAL file
+	createTestDatabase(t, dbPath, birdnetSchema...)
+	createTestDatabase(t, dbPath, "INSERT INTO notes (date, scientific_name) VALUES ('2025-01-01', 'Turdus merula')")
+	require.NoError(t, os.WriteFile(dbPath+"-wal", []byte("stale"), 0o600))
+
+	// Backup with two detections
+	restorePath := filepath.Join(t.TempDir(), "backup.birdnet")
+	createTestDatabase(t, restorePath, birdnetSchema...)
+	createTestDatabase(t, restorePath,
+		"INSERT INTO notes (date, scientific_name) VALUES ('2024-06-01', 'Parus major')",
+		"INSERT INTO notes (date, scientific_name) VALUES ('2024-06-02', 'Erithacus rubecula')")
+
+	var stopped, started bool
+	previousPath, err := source.Restore(context.Background(), restorePath, &backup.RestoreOptions{
+		StopDatastore:  func() error { stopped = true; return nil },
+		StartDatastore: func() error { started = true; return nil },
+	})
+	require.NoError(t, err)
+	assert.True(t, stopped)
+	assert.True(t, started)
+
+	assert.FileExists(t, previousPath+"-wal", "stale WAL moves with the previous database")
+	assert.NoFileExists(t, dbPath+"-wal")
+	assert.Equal(t, 2, countNotes(t, dbPath), "live database is replaced")
+	assert.Equal(t, 1, countNotes(t, previousPath), "previous database is preserved")
+
+	// No staged copies are left behind
+	matches, err := filepath.Glob(dbPath + ".restore-*")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestSQLiteSource_RestoreRejectsInvalidDatabase(t *testing.T) {
+	source, dbPath := newTestSQLiteSource(t)
+	createTestDatabase(t, dbPath, birdnetSchema...)
+
+	testCases := []struct {
+		name  string
+		setup func(path string)
+	}{
+		{"missing table", func(path string) {
+			createTestDatabase(t, path, birdnetSchema[0])
+		}},
+		{"missing column", func(path string) {
+			createTestDatabase(t, path,
+				"CREATE TABLE notes (id INTEGER PRIMARY KEY, date TEXT)",
+				birdnetSchema[1])
+		}},
+		{"not a database", func(path string) {
+			require.NoError(t, os.WriteFile(path, []byte("not a sqlite database at all, just some text"), 0o600))
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			restorePath := filepath.Join(t.TempDir(), "backup.birdnet")
+			tc.setup(restorePath)
+
+			stopped := false
+			_, err := source.Restore(context.Background(), restorePath, &backup.RestoreOptions{
+				StopDatastore: func() error { stopped = true; return nil },
+			})
+			require.Error(t, err)
+			assert.False(t, stopped, "datastore is not stopped for invalid backups")
+			assert.FileExists(t, dbPath)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Package targets provides backup target implementations
+package targets
+
+import (
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// archiveBackupID returns the backup ID of an archive name. Archives are named after the
+// backup ID followed by their extensions, e.g. <id>.tar or <id>.tar.enc.
+func archiveBackupID(name string) string {
+	if i := strings.Index(name, ".tar"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// isEncryptedArchive reports whether an archive name belongs to an encrypted backup
+func isEncryptedArchive(name string) bool {
+	return strings.HasSuffix(name, ".enc")
+}
+
+// isBackupArchive reports whether name is the archive, not the metadata file, of a backup
+func isBackupArchive(name, backupID, metadataExt string) bool {
+	if strings.HasSuffix(name, metadataExt) {
+		return false
+	}
+	return name == backupID || strings.HasPrefix(name, backupID+".")
+}
+
+// validateBackupID rejects backup IDs that could address files outside the backup directory
+func validateBackupID(targetName, backupID string) error {
+	if backupID == "" || backupID == "." || backupID == ".." || strings.ContainsAny(backupID, `/\'`) {
+		return errors.Newf("%s: invalid backup ID %q", targetName, backupID).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "resolve_backup_file").
+			Build()
+	}
+	return nil
+}
+
+// backupNotFoundError is returned when a target holds no archive for a backup ID
+func backupNotFoundError(targetName, backupID string) error {
+	return errors.Newf("%s: backup not found: %s", targetName, backupID).
+		Component("backup").
+		Category(errors.CategoryNotFound).
+		Context("operation", "download_backup").
+		Context("backup_id", backupID).
+		Build()
+}
@@ -0,0 +1,51 @@
+// Package targets provides backup target implementations
+package targets
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// NewTargetFromConfig creates a backup target for a configured target entry.
+// The global debug flag is applied when the target settings do not set one.
+func NewTargetFromConfig(target *conf.BackupTarget, debug bool, logger *slog.Logger) (backup.Target, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	settings := make(map[string]any, len(target.Settings)+1)
+	for k, v := range target.Settings {
+		settings[strings.ToLower(k)] = v
+	}
+	if _, ok := settings["debug"]; !ok {
+		settings["debug"] = debug
+	}
+
+	switch strings.ToLower(target.Type) {
+	case "local":
+		path, _ := settings["path"].(string)
+		localDebug, _ := settings["debug"].(bool)
+		return NewLocalTarget(LocalTargetConfig{Path: path, Debug: localDebug}, nil)
+	case "ftp":
+		return NewFTPTargetFromMap(settings)
+	case "sftp":
+		return NewSFTPTarget(settings, logger)
+	case "rsync":
+		return NewRsyncTarget(settings)
+	case "gdrive":
+		return NewGDriveTargetFromMap(settings)
+	case "s3":
+		return NewS3TargetFromMap(settings)
+	default:
+		return nil, errors.Newf("unsupported backup target type: %s", target.Type).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_backup_target").
+			Context("target_type", target.Type).
+			Build()
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/jlaffaye/ftp"
 	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/errors"
 )
 
 const (
@@ -27,6 +28,8 @@ const (
 	metadataFileExt       = ".meta"
 )
 
+var _ backup.Downloader = (*FTPTarget)(nil)
+
 // FTPTarget implements the backup.Target interface for FTP storage
 type FTPTarget struct {
 	config      FTPTargetConfig
@@ -460,7 +463,9 @@ func (t *FTPTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 				backups = append(backups, backup.BackupInfo{
 					Target: entry.Name,
 					Metadata: backup.Metadata{
+						ID:        archiveBackupID(entry.Name),
 						Timestamp: entry.Time,
+						Encrypted: isEncryptedArchive(entry.Name),
 						Size:      int64(entry.Size), // #nosec G115 -- file size conversion safe for FTP listing
 					},
 				})
@@ -483,25 +488,103 @@ func (t *FTPTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 }
 
 // Delete implements the backup.Target interface
-func (t *FTPTarget) Delete(ctx context.Context, target string) error {
+func (t *FTPTarget) Delete(ctx context.Context, backupID string) error {
 	if t.config.Debug {
-		t.logger.Printf("🔄 FTP: Deleting backup %s from %s", target, t.config.Host)
+		t.logger.Printf("🔄 FTP: Deleting backup %s from %s", backupID, t.config.Host)
+	}
+
+	if err := validateBackupID("ftp", backupID); err != nil {
+		return err
 	}
 
 	return t.withRetry(ctx, func(conn *ftp.ServerConn) error {
-		backupPath := path.Join(t.config.BasePath, target)
+		backupPath, err := t.archivePath(conn, backupID)
+		if err != nil {
+			return err
+		}
 		if err := conn.Delete(backupPath); err != nil {
 			return backup.NewError(backup.ErrIO, "ftp: failed to delete backup", err)
 		}
 
+		// Remove the metadata file, older backups may not have one
+		if err := conn.Delete(backupPath + metadataFileExt); err != nil && t.config.Debug {
+			t.logger.Printf("⚠️ FTP: Failed to delete metadata of backup %s: %v", backupID, err)
+		}
+
 		if t.config.Debug {
-			t.logger.Printf("✅ FTP: Successfully deleted backup %s", target)
+			t.logger.Printf("✅ FTP: Successfully deleted backup %s", backupID)
 		}
 
 		return nil
 	})
 }
 
+// Download writes the archive of a stored backup to w. The transfer is not retried
+// because part of the archive may already have been written to w.
+func (t *FTPTarget) Download(ctx context.Context, backupID string, w io.Writer) error {
+	if err := validateBackupID("ftp", backupID); err != nil {
+		return err
+	}
+
+	var backupPath string
+	if err := t.withRetry(ctx, func(conn *ftp.ServerConn) error {
+		var err error
+		backupPath, err = t.archivePath(conn, backupID)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	conn, err := t.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := conn.Retr(backupPath)
+	if err != nil {
+		_ = conn.Quit()
+		return backup.NewError(backup.ErrIO, "ftp: failed to retrieve backup", err)
+	}
+
+	_, copyErr := io.Copy(w, &contextReader{ctx: ctx, r: resp})
+	// Closing the response reads the server's transfer status
+	closeErr := resp.Close()
+	if copyErr != nil || closeErr != nil {
+		_ = conn.Quit()
+		if copyErr == nil {
+			copyErr = closeErr
+		}
+		return errors.New(copyErr).
+			Component("backup").
+			Category(errors.CategoryNetwork).
+			Context("operation", "download_backup").
+			Context("backup_id", backupID).
+			Context("path", backupPath).
+			Build()
+	}
+
+	t.returnConnection(conn)
+	return nil
+}
+
+// archivePath returns the remote path of the archive stored for a backup ID
+func (t *FTPTarget) archivePath(conn *ftp.ServerConn, backupID string) (string, error) {
+	entries, err := conn.List(t.config.BasePath)
+	if err != nil {
+		return "", backup.NewError(backup.ErrIO, "ftp: failed to list backups", err)
+	}
+
+	for _, entry := range entries {
+		if entry.Type != ftp.EntryTypeFile || strings.HasPrefix(entry.Name, "ftp-upload-") {
+			continue
+		}
+		if isBackupArchive(entry.Name, backupID, metadataFileExt) {
+			return path.Join(t.config.BasePath, entry.Name), nil
+		}
+	}
+	return "", backupNotFoundError("ftp", backupID)
+}
+
 // Validate performs comprehensive validation of the FTP target
 func (t *FTPTarget) Validate() error {
 	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
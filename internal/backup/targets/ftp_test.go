@@ -0,0 +1,256 @@
+package targets
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFTPServer is a minimal FTP server that serves absolute paths from the local file system.
+// It supports the commands used by FTPTarget without authentication.
+type fakeFTPServer struct {
+	t        *testing.T
+	listener net.Listener
+}
+
+func newFakeFTPServer(t *testing.T) *fakeFTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	s := &fakeFTPServer{t: t, listener: listener}
+	go s.serve()
+	return s
+}
+
+func (s *fakeFTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeFTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeFTPServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	reply := func(format string, args ...any) {
+		_, _ = fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	var dataListener net.Listener
+	var renameFrom string
+	defer func() {
+		if dataListener != nil {
+			_ = dataListener.Close()
+		}
+	}()
+
+	// transfer accepts the passive data connection and hands it to fn
+	transfer := func(fn func(net.Conn) error) {
+		if dataListener == nil {
+			reply("425 Use EPSV first")
+			return
+		}
+		reply("150 Opening data connection")
+		dataConn, err := dataListener.Accept()
+		_ = dataListener.Close()
+		dataListener = nil
+		if err != nil {
+			reply("425 Cannot open data connection")
+			return
+		}
+		err = fn(dataConn)
+		_ = dataConn.Close()
+		if err != nil {
+			reply("451 %v", err)
+			return
+		}
+		reply("226 Transfer complete")
+	}
+
+	reply("220 fake FTP server ready")
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		command, arg, _ := strings.Cut(scanner.Text(), " ")
+		switch strings.ToUpper(command) {
+		case "PWD":
+			reply(`257 "/" is the current directory`)
+		case "NOOP", "TYPE":
+			reply("200 OK")
+		case "CWD":
+			if info, err := os.Stat(arg); err == nil && info.IsDir() {
+				reply("250 Directory changed")
+			} else {
+				reply("550 No such directory")
+			}
+		case "MKD":
+			if err := os.MkdirAll(arg, 0o755); err != nil {
+				reply("550 %v", err)
+			} else {
+				reply(`257 "%s" created`, arg)
+			}
+		case "EPSV":
+			var err error
+			dataListener, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				reply("425 %v", err)
+				continue
+			}
+			reply("229 Entering Extended Passive Mode (|||%d|)", dataListener.Addr().(*net.TCPAddr).Port)
+		case "LIST":
+			entries, err := os.ReadDir(arg)
+			if err != nil {
+				reply("550 No such file or directory")
+				continue
+			}
+			transfer(func(dataConn net.Conn) error {
+				for _, entry := range entries {
+					info, err := entry.Info()
+					if err != nil {
+						return err
+					}
+					mode := "-rw-r--r--"
+					if entry.IsDir() {
+						mode = "drwxr-xr-x"
+					}
+					_, _ = fmt.Fprintf(dataConn, "%s 1 ftp ftp %d %s %s\r\n",
+						mode, info.Size(), info.ModTime().Format("Jan 02 15:04"), entry.Name())
+				}
+				return nil
+			})
+		case "RETR":
+			file, err := os.Open(arg)
+			if err != nil {
+				reply("550 No such file")
+				continue
+			}
+			transfer(func(dataConn net.Conn) error {
+				_, err := io.Copy(dataConn, file)
+				return err
+			})
+			_ = file.Close()
+		case "STOR":
+			file, err := os.Create(arg)
+			if err != nil {
+				reply("553 %v", err)
+				continue
+			}
+			transfer(func(dataConn net.Conn) error {
+				_, err := io.Copy(file, dataConn)
+				return err
+			})
+			_ = file.Close()
+		case "RNFR":
+			renameFrom = arg
+			reply("350 Ready for RNTO")
+		case "RNTO":
+			if err := os.Rename(renameFrom, arg); err != nil {
+				reply("550 %v", err)
+			} else {
+				reply("250 Renamed")
+			}
+		case "DELE":
+			if err := os.Remove(arg); err != nil {
+				reply("550 %v", err)
+			} else {
+				reply("250 Deleted")
+			}
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func newTestFTPTarget(t *testing.T) (target *FTPTarget, basePath string) {
+	t.Helper()
+
+	server := newFakeFTPServer(t)
+	basePath = filepath.Join(t.TempDir(), "backups")
+	require.NoError(t, os.MkdirAll(basePath, 0o755))
+
+	target, err := NewFTPTarget(&FTPTargetConfig{
+		Host:       "127.0.0.1",
+		Port:       server.port(),
+		BasePath:   basePath,
+		MaxRetries: 1,
+	}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = target.Close() })
+
+	return target, basePath
+}
+
+func TestFTPTarget_StoreListDownloadDelete(t *testing.T) {
+	t.Parallel()
+
+	target, basePath := newTestFTPTarget(t)
+	ctx := context.Background()
+
+	archivePath, metadata := writeTestArchive(t, "birdnet-20250101-120000", 4096)
+	require.NoError(t, target.Store(ctx, archivePath, metadata))
+	assert.FileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"))
+	assert.FileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"+metadataFileExt))
+
+	backups, err := target.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "birdnet-20250101-120000", backups[0].ID)
+
+	data, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, target.Download(ctx, "birdnet-20250101-120000", &buf))
+	assert.Equal(t, data, buf.Bytes())
+
+	buf.Reset()
+	require.Error(t, target.Download(ctx, "birdnet-20990101-000000", &buf))
+	require.Error(t, target.Download(ctx, "../escape", &buf))
+	assert.Zero(t, buf.Len())
+
+	require.NoError(t, target.Delete(ctx, "birdnet-20250101-120000"))
+	assert.NoFileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"))
+	assert.NoFileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"+metadataFileExt))
+}
+
+func TestFTPTarget_EncryptedBackup(t *testing.T) {
+	t.Parallel()
+
+	target, basePath := newTestFTPTarget(t)
+	ctx := context.Background()
+
+	encrypted := []byte("encrypted archive")
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "birdnet-20250102-120000.tar.enc"), encrypted, 0o600))
+
+	backups, err := target.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "birdnet-20250102-120000", backups[0].ID)
+	assert.True(t, backups[0].Encrypted, "restore decrypts backups listed as encrypted")
+
+	var buf bytes.Buffer
+	require.NoError(t, target.Download(ctx, "birdnet-20250102-120000", &buf))
+	assert.Equal(t, encrypted, buf.Bytes())
+}
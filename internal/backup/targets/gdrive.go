@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -33,6 +34,8 @@ const (
 	quotaCacheDuration     = time.Minute            // How long to cache quota information
 )
 
+var _ backup.Downloader = (*GDriveTarget)(nil)
+
 // rateLimiter implements a token bucket rate limiter
 type rateLimiter struct {
 	tokens chan struct{}
@@ -682,6 +685,7 @@ func (t *GDriveTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 					Metadata: backup.Metadata{
 						ID:        file.Id,
 						Timestamp: createdTime,
+						Encrypted: isEncryptedArchive(file.Name),
 						Size:      file.Size,
 					},
 				})
@@ -731,6 +735,52 @@ func (t *GDriveTarget) Delete(ctx context.Context, id string) error {
 	})
 }
 
+// Download writes the archive of a stored backup to w. Backups are identified by
+// their Drive file ID. Only the request is retried, not the transfer, because part
+// of the archive may already have been written to w.
+func (t *GDriveTarget) Download(ctx context.Context, id string, w io.Writer) error {
+	if t.config.Debug {
+		t.logger.Printf("🔄 GDrive: Downloading backup %s", id)
+	}
+
+	// Refresh token if needed
+	if err := t.refreshTokenIfNeeded(ctx); err != nil {
+		return err
+	}
+
+	// Acquire rate limit token
+	if err := t.rateLimiter.acquire(ctx); err != nil {
+		return backup.NewError(backup.ErrCanceled, "gdrive: operation canceled while waiting for rate limit", err)
+	}
+
+	var resp *http.Response
+	if err := t.withRetry(ctx, func() error {
+		var err error
+		resp, err = t.service.Files.Get(id).Context(ctx).Download()
+		if err != nil {
+			return backup.NewError(backup.ErrIO, "gdrive: failed to download backup file", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			t.logger.Printf("gdrive: failed to close download of %s: %v", id, err)
+		}
+	}()
+
+	if _, err := io.Copy(w, &contextReader{ctx: ctx, r: resp.Body}); err != nil {
+		return backup.NewError(backup.ErrIO, "gdrive: failed to read backup file", err)
+	}
+
+	if t.config.Debug {
+		t.logger.Printf("✅ GDrive: Successfully downloaded backup %s", id)
+	}
+
+	return nil
+}
+
 // Validate performs comprehensive validation of the Google Drive target
 func (t *GDriveTarget) Validate() error {
 	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
@@ -0,0 +1,83 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// newTestGDriveTarget returns a Google Drive target whose service talks to a fake
+// Drive API serving the given file contents by file ID
+func newTestGDriveTarget(t *testing.T, files map[string][]byte) *GDriveTarget {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := filepath.Base(r.URL.Path)
+		data, ok := files[id]
+		if r.Method != http.MethodGet || r.URL.Query().Get("alt") != "media" || !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"File not found"}}`))
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	service, err := drive.NewService(context.Background(),
+		option.WithEndpoint(server.URL+"/drive/v3/"),
+		option.WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+
+	// A valid token avoids the OAuth refresh
+	tokenFile := filepath.Join(t.TempDir(), "token.json")
+	token, err := json.Marshal(&oauth2.Token{AccessToken: "test", Expiry: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(tokenFile, token, 0o600))
+
+	target := &GDriveTarget{
+		config: GDriveTargetConfig{
+			TokenFile:    tokenFile,
+			BasePath:     "birdnet-go",
+			MaxRetries:   1,
+			RetryBackoff: time.Millisecond,
+		},
+		logger:      backup.DefaultLogger(),
+		service:     service,
+		tempFiles:   make(map[string]bool),
+		rateLimiter: newRateLimiter(defaultRateLimitTokens, defaultRateLimitReset),
+		folderCache: newFolderCache(),
+	}
+	t.Cleanup(target.rateLimiter.stop)
+
+	return target
+}
+
+func TestGDriveTarget_Download(t *testing.T) {
+	t.Parallel()
+
+	data := bytes.Repeat([]byte("birdnet-go backup "), 512)
+	target := newTestGDriveTarget(t, map[string][]byte{"file-1": data})
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	require.NoError(t, target.Download(ctx, "file-1", &buf))
+	assert.Equal(t, data, buf.Bytes())
+
+	buf.Reset()
+	require.Error(t, target.Download(ctx, "file-2", &buf))
+	assert.Zero(t, buf.Len())
+}
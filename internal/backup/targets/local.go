@@ -569,8 +569,16 @@ func (t *LocalTarget) Delete(ctx context.Context, backupID string) error {
 		t.logger.Printf("🔄 Deleting backup %s from local target", backupID)
 	}
 
+	archiveName, err := t.archiveFileName(backupID)
+	if err != nil {
+		return err
+	}
+	if archiveName == "" {
+		archiveName = backupID
+	}
+
 	// Delete both the backup file and its metadata
-	backupPath := filepath.Join(t.path, backupID)
+	backupPath := filepath.Join(t.path, archiveName)
 	metadataPath := backupPath + ".meta"
 
 	// Delete backup file
@@ -602,6 +610,92 @@ func (t *LocalTarget) Delete(ctx context.Context, backupID string) error {
 	return nil
 }
 
+// Download writes the archive of a stored backup to w
+func (t *LocalTarget) Download(ctx context.Context, backupID string, w io.Writer) error {
+	archiveName, err := t.archiveFileName(backupID)
+	if err != nil {
+		return err
+	}
+	if archiveName == "" {
+		return errors.Newf("backup not found: %s", backupID).
+			Component("backup").
+			Category(errors.CategoryNotFound).
+			Context("operation", "download_backup").
+			Context("backup_id", backupID).
+			Build()
+	}
+
+	secureOp := backup.NewSecureFileOp("backup")
+	archiveFile, cleanPath, err := secureOp.SecureOpen(filepath.Join(t.path, archiveName))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := archiveFile.Close(); err != nil {
+			t.logger.Printf("local: failed to close backup file %s: %v", cleanPath, err)
+		}
+	}()
+
+	if _, err := io.Copy(w, &contextReader{ctx: ctx, r: archiveFile}); err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "download_backup").
+			Context("backup_id", backupID).
+			Context("path", cleanPath).
+			Build()
+	}
+	return nil
+}
+
+// archiveFileName returns the name of the archive file stored for a backup ID,
+// or an empty string if there is none. Archives are named after the backup ID
+// followed by their extensions, e.g. <id>.tar or <id>.tar.enc.
+func (t *LocalTarget) archiveFileName(backupID string) (string, error) {
+	if backupID == "" || backupID == "." || backupID == ".." || strings.ContainsAny(backupID, `/\`) {
+		return "", errors.Newf("invalid backup ID: %q", backupID).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "resolve_backup_file").
+			Build()
+	}
+
+	entries, err := os.ReadDir(t.path)
+	if err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryFileIO).
+			Context("operation", "resolve_backup_file").
+			Context("path", t.path).
+			Build()
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, ".meta") {
+			continue
+		}
+		if name == backupID || strings.HasPrefix(name, backupID+".") {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// contextReader stops reading once its context is canceled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read implements io.Reader
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
 // Validate checks if the target configuration is valid
 func (t *LocalTarget) Validate() error {
 	// Check if path is absolute
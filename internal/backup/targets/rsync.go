@@ -1,6 +1,7 @@
 package targets
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -27,6 +28,8 @@ const (
 	rsyncMetadataVersion = 1
 )
 
+var _ backup.Downloader = (*RsyncTarget)(nil)
+
 // RsyncMetadataV1 represents version 1 of the backup metadata format
 type RsyncMetadataV1 struct {
 	Version     int       `json:"version"`
@@ -353,48 +356,31 @@ func (t *RsyncTarget) Store(ctx context.Context, sourcePath string, metadata *ba
 		return backup.NewError(backup.ErrIO, "rsync: failed to marshal metadata", err)
 	}
 
-	// Create temporary metadata file
-	tempMetadataFile, err := os.CreateTemp("", "rsync-metadata-*")
+	// Upload the backup file with enhanced security
+	if err := t.atomicUpload(ctx, sourcePath); err != nil {
+		return err
+	}
+
+	// Write the metadata file under its final name, atomicUpload stores files by their base name
+	metadataFileName := filepath.Base(sourcePath) + rsyncMetadataFileExt
+	tempMetadataDir, err := os.MkdirTemp("", "rsync-metadata-*")
 	if err != nil {
-		return backup.NewError(backup.ErrIO, "rsync: failed to create temporary metadata file", err)
+		return backup.NewError(backup.ErrIO, "rsync: failed to create temporary metadata directory", err)
 	}
 	defer func() {
-		if err := os.Remove(tempMetadataFile.Name()); err != nil {
-			fmt.Printf("rsync: failed to remove temp metadata file: %v\n", err)
-		}
-	}()
-	defer func() {
-		if err := tempMetadataFile.Close(); err != nil {
-			fmt.Printf("rsync: failed to close temp metadata file: %v\n", err)
+		if err := os.RemoveAll(tempMetadataDir); err != nil {
+			fmt.Printf("rsync: failed to remove temp metadata directory: %v\n", err)
 		}
 	}()
 
-	if _, err := tempMetadataFile.Write(metadataBytes); err != nil {
+	tempMetadataPath := filepath.Join(tempMetadataDir, metadataFileName)
+	if err := os.WriteFile(tempMetadataPath, metadataBytes, 0o600); err != nil {
 		return backup.NewError(backup.ErrIO, "rsync: failed to write metadata", err)
 	}
 
-	// Upload the backup file with enhanced security
-	if err := t.atomicUpload(ctx, sourcePath); err != nil {
-		return err
-	}
-
-	// Upload the metadata file with .tar.gz.meta extension
-	metadataFileName := filepath.Base(sourcePath) + rsyncMetadataFileExt
-	tempMetadataPath := tempMetadataFile.Name() + ".tmp"
-	if err := os.Rename(tempMetadataFile.Name(), tempMetadataPath); err != nil {
-		return backup.NewError(backup.ErrIO, "rsync: failed to prepare metadata file", err)
-	}
-
-	// Rename the temporary file to match the final name before upload
 	if err := t.atomicUpload(ctx, tempMetadataPath); err != nil {
-		if err := os.Remove(tempMetadataPath); err != nil {
-			fmt.Printf("rsync: failed to remove temp metadata path: %v\n", err)
-		}
 		return backup.NewError(backup.ErrIO, fmt.Sprintf("rsync: failed to store metadata file %s", metadataFileName), err)
 	}
-	if err := os.Remove(tempMetadataPath); err != nil {
-		fmt.Printf("rsync: failed to remove temp metadata path: %v\n", err)
-	}
 
 	if t.config.Debug {
 		fmt.Printf("✅ Rsync: Successfully stored backup %s with metadata\n", filepath.Base(sourcePath))
@@ -683,6 +669,7 @@ func (t *RsyncTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 
 		backupInfo := backup.BackupInfo{
 			Metadata: backup.Metadata{
+				ID:         archiveBackupID(backupName),
 				Version:    metadata.Version,
 				Timestamp:  metadata.Timestamp,
 				Size:       metadata.Size,
@@ -691,6 +678,7 @@ func (t *RsyncTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 				IsDaily:    metadata.IsDaily,
 				ConfigHash: metadata.ConfigHash,
 				AppVersion: metadata.AppVersion,
+				Encrypted:  isEncryptedArchive(backupName),
 			},
 			Target: t.Name(),
 		}
@@ -738,51 +726,94 @@ func (t *RsyncTarget) downloadAndParseMetadata(ctx context.Context, metadataPath
 }
 
 // Delete implements the backup.Target interface with enhanced security
-func (t *RsyncTarget) Delete(ctx context.Context, target string) error {
+func (t *RsyncTarget) Delete(ctx context.Context, backupID string) error {
 	if t.config.Debug {
-		fmt.Printf("🔄 Rsync: Deleting backup %s from %s\n", target, t.config.Host)
+		fmt.Printf("🔄 Rsync: Deleting backup %s from %s\n", backupID, t.config.Host)
 	}
 
-	// Sanitize the target path
-	cleanTarget, err := t.sanitizePath(target)
+	// Delete both backup and metadata files
+	cleanPath, err := t.archivePath(ctx, backupID)
 	if err != nil {
 		return err
 	}
-
-	// Delete both backup and metadata files
-	targetPath := path.Join(t.config.BasePath, cleanTarget)
-	metadataPath := targetPath + rsyncMetadataFileExt
-	cleanPath, err := t.sanitizePath(targetPath)
+	cleanMetadataPath, err := t.sanitizePath(cleanPath + rsyncMetadataFileExt)
 	if err != nil {
 		return err
 	}
-	cleanMetadataPath, err := t.sanitizePath(metadataPath)
+
+	cmd := exec.CommandContext(ctx, t.sshPath, t.sshArgs(fmt.Sprintf("rm -f -- '%s' '%s'", cleanPath, cleanMetadataPath))...) // #nosec G204 -- sshPath validated during initialization, args constructed with sanitized paths
+	if err := t.executeCommand(ctx, cmd); err != nil {
+		return backup.NewError(backup.ErrIO, "rsync: failed to delete backup", err)
+	}
+
+	if t.config.Debug {
+		fmt.Printf("✅ Rsync: Successfully deleted backup %s\n", backupID)
+	}
+
+	return nil
+}
+
+// Download writes the archive of a stored backup to w
+func (t *RsyncTarget) Download(ctx context.Context, backupID string, w io.Writer) error {
+	cleanPath, err := t.archivePath(ctx, backupID)
 	if err != nil {
 		return err
 	}
 
-	// Build SSH command with proper escaping
-	sshArgs := []string{
-		"-p", fmt.Sprintf("%d", t.config.Port),
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.sshPath, t.sshArgs(fmt.Sprintf("cat -- '%s'", cleanPath))...) // #nosec G204 -- sshPath validated during initialization, args constructed with sanitized paths
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return backup.NewError(backup.ErrIO, "rsync: failed to download backup", &RsyncError{
+			Op:      "download",
+			Command: cmd.String(),
+			Output:  strings.TrimSpace(stderr.String()),
+			Err:     err,
+		})
 	}
-	if t.config.KeyFile != "" {
-		sshArgs = append(sshArgs, "-i", t.config.KeyFile)
+
+	return nil
+}
+
+// archivePath returns the sanitized remote path of the archive stored for a backup ID
+func (t *RsyncTarget) archivePath(ctx context.Context, backupID string) (string, error) {
+	if err := validateBackupID("rsync", backupID); err != nil {
+		return "", err
 	}
 
-	// Delete backup file
-	sshArgs = append(sshArgs, fmt.Sprintf("%s@%s", t.config.Username, t.config.Host),
-		fmt.Sprintf("rm -f -- '%s' '%s'", cleanPath, cleanMetadataPath))
+	cleanBasePath, err := t.sanitizePath(t.config.BasePath)
+	if err != nil {
+		return "", err
+	}
 
-	cmd := exec.CommandContext(ctx, t.sshPath, sshArgs...) // #nosec G204 -- sshPath validated during initialization, args constructed with sanitized paths
-	if err := t.executeCommand(ctx, cmd); err != nil {
-		return backup.NewError(backup.ErrIO, "rsync: failed to delete backup", err)
+	cmd := exec.CommandContext(ctx, t.sshPath, t.sshArgs(fmt.Sprintf("ls -1 -- '%s'", cleanBasePath))...) // #nosec G204 -- sshPath validated during initialization, args constructed with sanitized paths
+	output, err := cmd.Output()
+	if err != nil {
+		return "", backup.NewError(backup.ErrIO, "rsync: failed to list backups", err)
 	}
 
-	if t.config.Debug {
-		fmt.Printf("✅ Rsync: Successfully deleted backup %s\n", target)
+	for _, name := range strings.Split(string(output), "\n") {
+		// Remote paths are single-quoted in shell commands
+		if strings.HasPrefix(name, rsyncTempFilePrefix) || strings.ContainsRune(name, '\'') {
+			continue
+		}
+		if isBackupArchive(name, backupID, rsyncMetadataFileExt) {
+			return t.sanitizePath(path.Join(cleanBasePath, name))
+		}
 	}
+	return "", backupNotFoundError("rsync", backupID)
+}
 
-	return nil
+// sshArgs returns the ssh arguments for running a command on the remote host
+func (t *RsyncTarget) sshArgs(remoteCommand string) []string {
+	args := []string{
+		"-p", fmt.Sprintf("%d", t.config.Port),
+	}
+	if t.config.KeyFile != "" {
+		args = append(args, "-i", t.config.KeyFile)
+	}
+	return append(args, fmt.Sprintf("%s@%s", t.config.Username, t.config.Host), remoteCommand)
 }
 
 // Validate checks if the target configuration is valid
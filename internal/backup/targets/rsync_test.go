@@ -0,0 +1,86 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRsyncTarget returns an rsync target whose ssh and rsync commands are replaced
+// by scripts operating on a local directory that stands in for the remote host
+func newTestRsyncTarget(t *testing.T) (target *RsyncTarget, basePath string) {
+	t.Helper()
+
+	remoteDir := t.TempDir()
+	basePath = filepath.Join(remoteDir, "backups")
+	require.NoError(t, os.MkdirAll(basePath, 0o755))
+
+	scriptDir := t.TempDir()
+	writeScript := func(name, body string) string {
+		scriptPath := filepath.Join(scriptDir, name)
+		require.NoError(t, os.WriteFile(scriptPath, []byte("#!/bin/sh\n"+body), 0o700)) // #nosec G306 -- test script must be executable
+		return scriptPath
+	}
+
+	// ssh runs its last argument, the remote command, in the remote directory
+	sshPath := writeScript("ssh", fmt.Sprintf("for cmd; do :; done\ncd '%s' && exec sh -c \"$cmd\"\n", remoteDir))
+	// rsync copies its second to last argument to the remote path in its last argument
+	rsyncPath := writeScript("rsync", fmt.Sprintf("for arg; do src=$dest; dest=$arg; done\ncd '%s' && cp \"$src\" \"${dest#*:}\"\n", remoteDir))
+
+	target = &RsyncTarget{
+		config: RsyncTargetConfig{
+			Host:         "backup.example.com",
+			Port:         defaultRsyncPort,
+			Username:     "birdnet",
+			BasePath:     "backups",
+			Timeout:      5 * time.Second,
+			MaxRetries:   1,
+			RetryBackoff: time.Millisecond,
+		},
+		rsyncPath: rsyncPath,
+		sshPath:   sshPath,
+		tempFiles: make(map[string]bool),
+	}
+	return target, basePath
+}
+
+func TestRsyncTarget_StoreListDownloadDelete(t *testing.T) {
+	t.Parallel()
+
+	target, basePath := newTestRsyncTarget(t)
+	ctx := context.Background()
+
+	archivePath, metadata := writeTestArchive(t, "birdnet-20250101-120000", 4096)
+	require.NoError(t, target.Store(ctx, archivePath, metadata))
+	assert.FileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"))
+	assert.FileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"+rsyncMetadataFileExt))
+
+	backups, err := target.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "birdnet-20250101-120000", backups[0].ID)
+	assert.Equal(t, "rsync", backups[0].Target)
+
+	data, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, target.Download(ctx, "birdnet-20250101-120000", &buf))
+	assert.Equal(t, data, buf.Bytes())
+
+	buf.Reset()
+	require.Error(t, target.Download(ctx, "birdnet-20990101-000000", &buf))
+	require.Error(t, target.Download(ctx, "x'; rm -rf '", &buf))
+	assert.Zero(t, buf.Len())
+
+	require.NoError(t, target.Delete(ctx, "birdnet-20250101-120000"))
+	assert.NoFileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"))
+	assert.NoFileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"+rsyncMetadataFileExt))
+}
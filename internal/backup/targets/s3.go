@@ -0,0 +1,1121 @@
+// Package targets provides backup target implementations
+package targets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5" // #nosec G501 -- MD5 is required by the S3 Content-MD5 integrity header
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/backup"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	defaultS3Region   = "us-east-1"
+	defaultS3PartSize = 16 * 1024 * 1024 // Multipart part size, archives above this size use multipart upload
+	minS3PartSize     = 5 * 1024 * 1024  // Smallest part size accepted by S3 for all but the last part
+	maxS3Parts        = 10000            // Maximum number of parts in a multipart upload
+	maxS3ErrorBody    = 4096             // Maximum error response body read for error messages
+	s3ChecksumHeader  = "X-Amz-Meta-Sha256"
+	s3EmptyHash       = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3TimeFormat      = "20060102T150405Z"
+	s3DateFormat      = "20060102"
+	s3SigningAlgo     = "AWS4-HMAC-SHA256"
+)
+
+// S3TargetConfig holds configuration for the S3 target
+type S3TargetConfig struct {
+	Endpoint        string // Endpoint host or URL, empty for AWS S3
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Prefix          string // Key prefix under which backups are stored
+	UseSSL          bool   // Use HTTPS when the endpoint does not specify a scheme
+	ForcePathStyle  bool   // Address the bucket in the path instead of the host name
+	PartSize        int64  // Multipart part size in bytes
+	Timeout         time.Duration
+	MaxRetries      int
+	Debug           bool
+}
+
+// S3Target implements the backup.Target interface for Amazon S3 and
+// S3-compatible object stores such as MinIO
+type S3Target struct {
+	config  S3TargetConfig
+	logger  backup.Logger
+	client  *http.Client
+	baseURL *url.URL
+	signer  s3Signer
+}
+
+// Compile-time check that S3Target supports downloading backups
+var _ backup.Downloader = (*S3Target)(nil)
+
+// s3Object describes an object returned by ListObjectsV2
+type s3Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+}
+
+// s3ListResult is the ListObjectsV2 response body
+type s3ListResult struct {
+	Contents              []s3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+// s3InitiateResult is the CreateMultipartUpload response body
+type s3InitiateResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+// s3CompletedPart identifies an uploaded part when completing a multipart upload
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// s3CompleteRequest is the CompleteMultipartUpload request body
+type s3CompleteRequest struct {
+	XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+	Parts   []s3CompletedPart `xml:"Part"`
+}
+
+// s3ErrorResponse is the error document returned by S3
+type s3ErrorResponse struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+// NewS3Target creates a new S3 target with the given configuration
+func NewS3Target(config *S3TargetConfig, logger backup.Logger) (*S3Target, error) {
+	if config.Bucket == "" {
+		return nil, s3ConfigError("s3: bucket is required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, s3ConfigError("s3: access key ID and secret access key are required")
+	}
+
+	// Set defaults for optional fields
+	if config.Region == "" {
+		config.Region = defaultS3Region
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.PartSize == 0 {
+		config.PartSize = defaultS3PartSize
+	}
+	if config.PartSize < minS3PartSize {
+		config.PartSize = minS3PartSize
+	}
+	config.Prefix = strings.Trim(config.Prefix, "/")
+
+	baseURL, err := s3BaseURL(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if logger == nil {
+		logger = backup.DefaultLogger()
+	}
+
+	return &S3Target{
+		config:  *config,
+		logger:  logger,
+		client:  &http.Client{Timeout: config.Timeout},
+		baseURL: baseURL,
+		signer: s3Signer{
+			accessKeyID:     config.AccessKeyID,
+			secretAccessKey: config.SecretAccessKey,
+			region:          config.Region,
+		},
+	}, nil
+}
+
+// NewS3TargetFromMap creates a new S3 target from a map configuration
+func NewS3TargetFromMap(settings map[string]interface{}) (*S3Target, error) {
+	config := S3TargetConfig{UseSSL: true}
+
+	// Required settings
+	bucket, ok := settings["bucket"].(string)
+	if !ok {
+		return nil, s3ConfigError("s3: bucket is required")
+	}
+	config.Bucket = bucket
+
+	// Optional settings
+	if endpoint, ok := settings["endpoint"].(string); ok {
+		config.Endpoint = endpoint
+	}
+	if region, ok := settings["region"].(string); ok {
+		config.Region = region
+	}
+	if accessKeyID, ok := settings["accesskeyid"].(string); ok {
+		config.AccessKeyID = accessKeyID
+	}
+	if secretAccessKey, ok := settings["secretaccesskey"].(string); ok {
+		config.SecretAccessKey = secretAccessKey
+	}
+	if prefix, ok := settings["prefix"].(string); ok {
+		config.Prefix = prefix
+	}
+	if useSSL, ok := settings["usessl"].(bool); ok {
+		config.UseSSL = useSSL
+	}
+	// Custom endpoints such as MinIO generally require path-style addressing
+	config.ForcePathStyle = config.Endpoint != ""
+	if forcePathStyle, ok := settings["forcepathstyle"].(bool); ok {
+		config.ForcePathStyle = forcePathStyle
+	}
+	if partSize, ok := settings["partsize"].(int); ok {
+		config.PartSize = int64(partSize) * 1024 * 1024 // Configured in megabytes
+	}
+	if timeout, ok := settings["timeout"].(string); ok {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("backup").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "create_s3_target").
+				Context("timeout", timeout).
+				Build()
+		}
+		config.Timeout = duration
+	}
+	if debug, ok := settings["debug"].(bool); ok {
+		config.Debug = debug
+	}
+
+	var logger backup.Logger
+	if l, ok := settings["logger"].(backup.Logger); ok {
+		logger = l
+	}
+
+	return NewS3Target(&config, logger)
+}
+
+// s3BaseURL builds the service URL from the configured endpoint
+func s3BaseURL(config *S3TargetConfig) (*url.URL, error) {
+	scheme := "https"
+	if !config.UseSSL {
+		scheme = "http"
+	}
+
+	endpoint := strings.TrimRight(config.Endpoint, "/")
+	switch {
+	case endpoint == "":
+		endpoint = fmt.Sprintf("%s://s3.%s.amazonaws.com", scheme, config.Region)
+	case !strings.Contains(endpoint, "://"):
+		endpoint = scheme + "://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, errors.Newf("s3: invalid endpoint %q", config.Endpoint).
+			Component("backup").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_s3_target").
+			Build()
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+
+	if !config.ForcePathStyle {
+		u.Host = config.Bucket + "." + u.Host
+	}
+	return u, nil
+}
+
+// Name returns the name of this target
+func (t *S3Target) Name() string {
+	return "s3"
+}
+
+// objectKey returns the full object key for a backup file name
+func (t *S3Target) objectKey(name string) string {
+	if t.config.Prefix == "" {
+		return name
+	}
+	return t.config.Prefix + "/" + name
+}
+
+// Store uploads a backup archive and its metadata to the bucket. The archive
+// checksum is verified against the metadata and by the server while uploading.
+func (t *S3Target) Store(ctx context.Context, sourcePath string, metadata *backup.Metadata) error {
+	if err := ctx.Err(); err != nil {
+		return s3Error(err, errors.CategorySystem, "store_backup")
+	}
+
+	if t.config.Debug {
+		t.logger.Printf("🔄 S3: Storing backup %s to bucket %s", filepath.Base(sourcePath), t.config.Bucket)
+	}
+
+	file, err := os.Open(sourcePath)
+	if err != nil {
+		return s3Error(err, errors.CategoryFileIO, "open_backup_file")
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			t.logger.Printf("s3: failed to close backup file: %v", err)
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return s3Error(err, errors.CategoryFileIO, "stat_backup_file")
+	}
+
+	checksum, err := fileSHA256(file, info.Size())
+	if err != nil {
+		return s3Error(err, errors.CategoryFileIO, "checksum_backup_file")
+	}
+	if metadata.Checksum == "" {
+		metadata.Checksum = checksum
+	} else if !strings.EqualFold(metadata.Checksum, checksum) {
+		return errors.Newf("s3: backup file checksum does not match metadata").
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "verify_backup_checksum").
+			Context("expected", metadata.Checksum).
+			Context("actual", checksum).
+			Build()
+	}
+
+	key := t.objectKey(filepath.Base(sourcePath))
+	if info.Size() > t.config.PartSize {
+		err = t.multipartUpload(ctx, file, info.Size(), key, checksum)
+	} else {
+		err = t.putFile(ctx, file, info.Size(), key, checksum)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Confirm the stored object is complete before recording its metadata
+	if err := t.verifyObject(ctx, key, info.Size(), checksum); err != nil {
+		return err
+	}
+
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return s3Error(err, errors.CategorySystem, "marshal_metadata")
+	}
+	if err := t.putBytes(ctx, key+metadataFileExt, metadataBytes, "application/json"); err != nil {
+		return err
+	}
+
+	if t.config.Debug {
+		t.logger.Printf("✅ S3: Successfully stored backup %s with metadata", filepath.Base(sourcePath))
+	}
+
+	return nil
+}
+
+// putFile uploads a file with a single PUT request
+func (t *S3Target) putFile(ctx context.Context, file *os.File, size int64, key, checksum string) error {
+	section := io.NewSectionReader(file, 0, size)
+	md5sum, err := readerMD5(section)
+	if err != nil {
+		return s3Error(err, errors.CategoryFileIO, "checksum_backup_file")
+	}
+
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodPut, key, nil, io.NewSectionReader(file, 0, size))
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(md5sum))
+		req.Header.Set(s3ChecksumHeader, checksum)
+		return req, t.signer.sign(req, checksum, time.Now())
+	})
+	if err != nil {
+		return s3RequestError(err, "put_object", key)
+	}
+	drainAndClose(resp)
+
+	return verifyETag(resp.Header.Get("ETag"), md5sum, key)
+}
+
+// multipartUpload uploads a large file in parts, aborting the upload on failure
+func (t *S3Target) multipartUpload(ctx context.Context, file *os.File, size int64, key, checksum string) error {
+	partSize := t.config.PartSize
+	if (size+partSize-1)/partSize > maxS3Parts {
+		partSize = (size + maxS3Parts - 1) / maxS3Parts
+	}
+
+	uploadID, err := t.createMultipartUpload(ctx, key, checksum)
+	if err != nil {
+		return err
+	}
+
+	if t.config.Debug {
+		t.logger.Printf("🔄 S3: Started multipart upload of %s (%d bytes, %d byte parts)", key, size, partSize)
+	}
+
+	parts := make([]s3CompletedPart, 0, (size+partSize-1)/partSize)
+	for offset, partNumber := int64(0), 1; offset < size; offset, partNumber = offset+partSize, partNumber+1 {
+		length := min(partSize, size-offset)
+		etag, err := t.uploadPart(ctx, file, key, uploadID, partNumber, offset, length)
+		if err != nil {
+			t.abortMultipartUpload(key, uploadID)
+			return err
+		}
+		parts = append(parts, s3CompletedPart{PartNumber: partNumber, ETag: etag})
+	}
+
+	if err := t.completeMultipartUpload(ctx, key, uploadID, parts); err != nil {
+		t.abortMultipartUpload(key, uploadID)
+		return err
+	}
+
+	return nil
+}
+
+// createMultipartUpload starts a multipart upload and returns its upload ID
+func (t *S3Target) createMultipartUpload(ctx context.Context, key, checksum string) (string, error) {
+	query := url.Values{"uploads": {""}}
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodPost, key, query, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set(s3ChecksumHeader, checksum)
+		return req, t.signer.sign(req, s3EmptyHash, time.Now())
+	})
+	if err != nil {
+		return "", s3RequestError(err, "create_multipart_upload", key)
+	}
+
+	var result s3InitiateResult
+	if err := decodeXMLBody(resp, &result); err != nil {
+		return "", s3RequestError(err, "create_multipart_upload", key)
+	}
+	if result.UploadID == "" {
+		return "", s3RequestError(fmt.Errorf("response did not contain an upload ID"), "create_multipart_upload", key)
+	}
+	return result.UploadID, nil
+}
+
+// uploadPart uploads one part of a multipart upload and returns its verified ETag
+func (t *S3Target) uploadPart(ctx context.Context, file *os.File, key, uploadID string, partNumber int, offset, length int64) (string, error) {
+	partHash, md5sum, err := sectionChecksums(io.NewSectionReader(file, offset, length))
+	if err != nil {
+		return "", s3Error(err, errors.CategoryFileIO, "checksum_backup_part")
+	}
+
+	query := url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {uploadID},
+	}
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodPut, key, query, io.NewSectionReader(file, offset, length))
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = length
+		req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(md5sum))
+		return req, t.signer.sign(req, partHash, time.Now())
+	})
+	if err != nil {
+		return "", s3RequestError(err, "upload_part", key)
+	}
+	drainAndClose(resp)
+
+	etag := resp.Header.Get("ETag")
+	if err := verifyETag(etag, md5sum, key); err != nil {
+		return "", err
+	}
+	return etag, nil
+}
+
+// completeMultipartUpload assembles the uploaded parts into the final object
+func (t *S3Target) completeMultipartUpload(ctx context.Context, key, uploadID string, parts []s3CompletedPart) error {
+	body, err := xml.Marshal(s3CompleteRequest{Parts: parts})
+	if err != nil {
+		return s3Error(err, errors.CategorySystem, "marshal_complete_multipart")
+	}
+	bodyHash := sha256.Sum256(body)
+
+	query := url.Values{"uploadId": {uploadID}}
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodPost, key, query, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", "application/xml")
+		return req, t.signer.sign(req, hex.EncodeToString(bodyHash[:]), time.Now())
+	})
+	if err != nil {
+		return s3RequestError(err, "complete_multipart_upload", key)
+	}
+
+	// S3 can report a failure with a 200 status once the response has started
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxS3ErrorBody))
+	_ = resp.Body.Close()
+	if err != nil {
+		return s3RequestError(err, "complete_multipart_upload", key)
+	}
+	var s3Err s3ErrorResponse
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return s3RequestError(fmt.Errorf("%s: %s", s3Err.Code, s3Err.Message), "complete_multipart_upload", key)
+	}
+
+	return nil
+}
+
+// abortMultipartUpload discards the parts of a failed multipart upload
+func (t *S3Target) abortMultipartUpload(key, uploadID string) {
+	// Use a fresh context so cleanup also runs when the upload was canceled
+	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
+	defer cancel()
+
+	query := url.Values{"uploadId": {uploadID}}
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodDelete, key, query, nil)
+		if err != nil {
+			return nil, err
+		}
+		return req, t.signer.sign(req, s3EmptyHash, time.Now())
+	})
+	if err != nil {
+		t.logger.Printf("⚠️ S3: Failed to abort multipart upload of %s: %v", key, err)
+		return
+	}
+	drainAndClose(resp)
+}
+
+// verifyObject checks the size and checksum recorded on a stored object
+func (t *S3Target) verifyObject(ctx context.Context, key string, size int64, checksum string) error {
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodHead, key, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return req, t.signer.sign(req, s3EmptyHash, time.Now())
+	})
+	if err != nil {
+		return s3RequestError(err, "verify_object", key)
+	}
+	drainAndClose(resp)
+
+	if resp.ContentLength != size {
+		return errors.Newf("s3: stored object size %d does not match backup size %d", resp.ContentLength, size).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "verify_object").
+			Context("key", key).
+			Build()
+	}
+	if stored := resp.Header.Get(s3ChecksumHeader); stored != "" && !strings.EqualFold(stored, checksum) {
+		return errors.Newf("s3: stored object checksum does not match backup checksum").
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "verify_object").
+			Context("key", key).
+			Build()
+	}
+	return nil
+}
+
+// putBytes uploads a small in-memory object
+func (t *S3Target) putBytes(ctx context.Context, key string, data []byte, contentType string) error {
+	hash := sha256.Sum256(data)
+	md5sum := md5.Sum(data) // #nosec G401 -- Content-MD5 integrity header, not used for security
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodPut, key, nil, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(md5sum[:]))
+		return req, t.signer.sign(req, hex.EncodeToString(hash[:]), time.Now())
+	})
+	if err != nil {
+		return s3RequestError(err, "put_object", key)
+	}
+	drainAndClose(resp)
+	return nil
+}
+
+// getBytes downloads a small object into memory
+func (t *S3Target) getBytes(ctx context.Context, key string, limit int64) ([]byte, error) {
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodGet, key, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return req, t.signer.sign(req, s3EmptyHash, time.Now())
+	})
+	if err != nil {
+		return nil, s3RequestError(err, "get_object", key)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
+
+// listObjects returns all objects under the given key prefix
+func (t *S3Target) listObjects(ctx context.Context, prefix string) ([]s3Object, error) {
+	var objects []s3Object
+	continuationToken := ""
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		resp, err := t.do(ctx, func() (*http.Request, error) {
+			req, err := t.newRequest(ctx, http.MethodGet, "", query, nil)
+			if err != nil {
+				return nil, err
+			}
+			return req, t.signer.sign(req, s3EmptyHash, time.Now())
+		})
+		if err != nil {
+			return nil, s3RequestError(err, "list_objects", prefix)
+		}
+
+		var result s3ListResult
+		if err := decodeXMLBody(resp, &result); err != nil {
+			return nil, s3RequestError(err, "list_objects", prefix)
+		}
+		objects = append(objects, result.Contents...)
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// List returns the backups stored in the bucket, newest first
+func (t *S3Target) List(ctx context.Context) ([]backup.BackupInfo, error) {
+	if t.config.Debug {
+		t.logger.Printf("🔄 S3: Listing backups in bucket %s", t.config.Bucket)
+	}
+
+	listPrefix := ""
+	if t.config.Prefix != "" {
+		listPrefix = t.config.Prefix + "/"
+	}
+	objects, err := t.listObjects(ctx, listPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(objects))
+	for i := range objects {
+		keys[objects[i].Key] = true
+	}
+
+	backups := make([]backup.BackupInfo, 0, len(objects)/2)
+	for i := range objects {
+		metadataKey := objects[i].Key
+		if !strings.HasSuffix(metadataKey, metadataFileExt) {
+			continue
+		}
+		// Only consider objects directly under the prefix
+		if strings.Contains(strings.TrimPrefix(metadataKey, listPrefix), "/") {
+			continue
+		}
+
+		backupKey := strings.TrimSuffix(metadataKey, metadataFileExt)
+		if !keys[backupKey] {
+			if t.config.Debug {
+				t.logger.Printf("⚠️ S3: Skipping orphaned metadata object %s: backup object not found", metadataKey)
+			}
+			continue
+		}
+
+		data, err := t.getBytes(ctx, metadataKey, maxS3ErrorBody*16)
+		if err != nil {
+			t.logger.Printf("⚠️ S3: Skipping backup %s: %v", backupKey, err)
+			continue
+		}
+
+		var metadata backup.Metadata
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			t.logger.Printf("⚠️ S3: Invalid metadata in backup %s: %v", backupKey, err)
+			continue
+		}
+
+		backups = append(backups, backup.BackupInfo{
+			Metadata: metadata,
+			Target:   t.Name(),
+		})
+
+		if err := ctx.Err(); err != nil {
+			return nil, s3Error(err, errors.CategorySystem, "list_backups")
+		}
+	}
+
+	// Sort backups by timestamp (newest first)
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ID > backups[j].ID
+	})
+
+	return backups, nil
+}
+
+// Delete removes the archive and metadata objects of a backup
+func (t *S3Target) Delete(ctx context.Context, backupID string) error {
+	if t.config.Debug {
+		t.logger.Printf("🔄 S3: Deleting backup %s from bucket %s", backupID, t.config.Bucket)
+	}
+
+	keys, err := t.backupObjectKeys(ctx, backupID)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := t.deleteObject(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	if t.config.Debug {
+		t.logger.Printf("✅ S3: Successfully deleted backup %s", backupID)
+	}
+
+	return nil
+}
+
+// Download writes the archive of a stored backup to w, verifying its checksum
+func (t *S3Target) Download(ctx context.Context, backupID string, w io.Writer) error {
+	keys, err := t.backupObjectKeys(ctx, backupID)
+	if err != nil {
+		return err
+	}
+
+	key := ""
+	for _, k := range keys {
+		if !strings.HasSuffix(k, metadataFileExt) {
+			key = k
+			break
+		}
+	}
+	if key == "" {
+		return errors.Newf("s3: backup not found: %s", backupID).
+			Component("backup").
+			Category(errors.CategoryNotFound).
+			Context("operation", "download_backup").
+			Context("backup_id", backupID).
+			Build()
+	}
+
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodGet, key, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return req, t.signer.sign(req, s3EmptyHash, time.Now())
+	})
+	if err != nil {
+		return s3RequestError(err, "get_object", key)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, hash), resp.Body); err != nil {
+		return s3RequestError(err, "get_object", key)
+	}
+
+	if expected := resp.Header.Get(s3ChecksumHeader); expected != "" {
+		if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(expected, actual) {
+			return errors.Newf("s3: downloaded backup checksum mismatch").
+				Component("backup").
+				Category(errors.CategoryValidation).
+				Context("operation", "verify_download").
+				Context("key", key).
+				Context("expected", expected).
+				Context("actual", actual).
+				Build()
+		}
+	}
+
+	return nil
+}
+
+// backupObjectKeys returns the keys of the archive and metadata objects of a backup.
+// Archives are named after the backup ID plus their extensions.
+func (t *S3Target) backupObjectKeys(ctx context.Context, backupID string) ([]string, error) {
+	if backupID == "" || strings.Contains(backupID, "/") {
+		return nil, errors.Newf("s3: invalid backup ID %q", backupID).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "resolve_backup_objects").
+			Build()
+	}
+
+	objects, err := t.listObjects(ctx, t.objectKey(backupID))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, 2)
+	for i := range objects {
+		name := path.Base(objects[i].Key)
+		if name == backupID || strings.HasPrefix(name, backupID+".") {
+			keys = append(keys, objects[i].Key)
+		}
+	}
+	return keys, nil
+}
+
+// deleteObject removes a single object
+func (t *S3Target) deleteObject(ctx context.Context, key string) error {
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodDelete, key, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return req, t.signer.sign(req, s3EmptyHash, time.Now())
+	})
+	if err != nil {
+		return s3RequestError(err, "delete_object", key)
+	}
+	drainAndClose(resp)
+	return nil
+}
+
+// Validate checks that the bucket exists and is accessible with the configured credentials
+func (t *S3Target) Validate() error {
+	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
+	defer cancel()
+
+	resp, err := t.do(ctx, func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, http.MethodHead, "", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return req, t.signer.sign(req, s3EmptyHash, time.Now())
+	})
+	if err != nil {
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "validate_target").
+			Context("bucket", t.config.Bucket).
+			Context("endpoint", t.baseURL.Host).
+			Build()
+	}
+	drainAndClose(resp)
+	return nil
+}
+
+// newRequest builds a request for an object key, or for the bucket when key is empty
+func (t *S3Target) newRequest(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := *t.baseURL
+	objectPath := u.Path
+	if t.config.ForcePathStyle {
+		objectPath += "/" + t.config.Bucket
+	}
+	objectPath += "/" + key
+
+	u.Path = objectPath
+	u.RawPath = s3URIEncode(objectPath, false)
+	if query != nil {
+		u.RawQuery = s3CanonicalQuery(query)
+	}
+
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// do executes a request built by newReq, retrying transient failures.
+// Non-2xx responses are returned as errors.
+func (t *S3Target) do(ctx context.Context, newReq func() (*http.Request, error)) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt < t.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoffDuration(attempt)):
+			}
+		}
+
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.client.Do(req)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		lastErr = s3StatusError(resp)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, lastErr
+		}
+	}
+	return nil, lastErr
+}
+
+// s3StatusError converts an unsuccessful response into an error and closes its body
+func s3StatusError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxS3ErrorBody))
+	_ = resp.Body.Close()
+
+	var s3Err s3ErrorResponse
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return fmt.Errorf("unexpected status %d: %s: %s", resp.StatusCode, s3Err.Code, s3Err.Message)
+	}
+	return fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// decodeXMLBody decodes an XML response body and closes it
+func decodeXMLBody(resp *http.Response, v any) error {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	return xml.NewDecoder(resp.Body).Decode(v)
+}
+
+// drainAndClose discards the rest of a response body so the connection can be reused
+func drainAndClose(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxS3ErrorBody))
+	_ = resp.Body.Close()
+}
+
+// verifyETag compares an ETag with the MD5 of the uploaded data. ETags that are
+// not plain MD5 digests, such as with SSE-KMS encryption, are accepted because
+// the server already verified the Content-MD5 header.
+func verifyETag(etag string, md5sum []byte, key string) error {
+	etag = strings.Trim(etag, `"`)
+	if len(etag) != md5.Size*2 {
+		return nil
+	}
+	if !strings.EqualFold(etag, hex.EncodeToString(md5sum)) {
+		return errors.Newf("s3: uploaded data checksum mismatch").
+			Component("backup").
+			Category(errors.CategoryValidation).
+			Context("operation", "verify_etag").
+			Context("key", key).
+			Context("etag", etag).
+			Build()
+	}
+	return nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 checksum of the first size bytes of a file
+func fileSHA256(file *os.File, size int64) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(file, 0, size)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readerMD5 returns the MD5 digest of a reader
+func readerMD5(r io.Reader) ([]byte, error) {
+	h := md5.New() // #nosec G401 -- Content-MD5 integrity header, not used for security
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// sectionChecksums returns the hex SHA-256 and the MD5 digest of a reader
+func sectionChecksums(r io.Reader) (sha string, md5sum []byte, err error) {
+	shaHash := sha256.New()
+	md5Hash := md5.New() // #nosec G401 -- Content-MD5 integrity header, not used for security
+	if _, err := io.Copy(io.MultiWriter(shaHash, md5Hash), r); err != nil {
+		return "", nil, err
+	}
+	return hex.EncodeToString(shaHash.Sum(nil)), md5Hash.Sum(nil), nil
+}
+
+// s3ConfigError creates a configuration error for the S3 target
+func s3ConfigError(msg string) error {
+	return errors.Newf("%s", msg).
+		Component("backup").
+		Category(errors.CategoryConfiguration).
+		Context("operation", "create_s3_target").
+		Build()
+}
+
+// s3Error wraps an error from a local S3 target operation
+func s3Error(err error, category errors.ErrorCategory, operation string) error {
+	return errors.New(err).
+		Component("backup").
+		Category(category).
+		Context("operation", operation).
+		Context("target", "s3").
+		Build()
+}
+
+// s3RequestError wraps an error from an S3 API request
+func s3RequestError(err error, operation, key string) error {
+	return errors.New(err).
+		Component("backup").
+		Category(errors.CategoryNetwork).
+		Context("operation", operation).
+		Context("target", "s3").
+		Context("key", key).
+		Build()
+}
+
+// s3Signer signs requests with AWS Signature Version 4
+type s3Signer struct {
+	accessKeyID     string
+	secretAccessKey string
+	region          string
+}
+
+// sign adds the SigV4 Authorization header to a request. All headers set on the
+// request are signed together with the host.
+func (s *s3Signer) sign(req *http.Request, payloadHash string, now time.Time) error {
+	now = now.UTC()
+	amzDate := now.Format(s3TimeFormat)
+	date := now.Format(s3DateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "authorization" || lower == "user-agent" {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[lower] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3URIEncode(req.URL.Path, false),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3SigningAlgo,
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3SigningAlgo, s.accessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+// hmacSHA256 computes an HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3CanonicalQuery encodes query parameters sorted by key as required by SigV4
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, s3URIEncode(k, true)+"="+s3URIEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3URIEncode percent-encodes everything except RFC 3986 unreserved characters.
+// Slashes are kept unless encodeSlash is set.
+func s3URIEncode(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			sb.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
@@ -31,6 +31,8 @@ const (
 	sftpMetadataVersion     = 1
 )
 
+var _ backup.Downloader = (*SFTPTarget)(nil)
+
 // SFTPTargetConfig holds configuration for the SFTP target
 type SFTPTargetConfig struct {
 	Host          string
@@ -770,7 +772,9 @@ func (t *SFTPTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 				backups = append(backups, backup.BackupInfo{
 					Target: entry.Name(),
 					Metadata: backup.Metadata{
+						ID:        archiveBackupID(entry.Name()),
 						Timestamp: entry.ModTime(),
+						Encrypted: isEncryptedArchive(entry.Name()),
 						Size:      entry.Size(),
 					},
 				})
@@ -798,27 +802,28 @@ func (t *SFTPTarget) List(ctx context.Context) ([]backup.BackupInfo, error) {
 }
 
 // Delete implements the backup.Target interface
-func (t *SFTPTarget) Delete(ctx context.Context, target string) error {
+func (t *SFTPTarget) Delete(ctx context.Context, backupID string) error {
 	if t.config.Debug {
 		t.logger.Debug("SFTP: Deleting backup",
-			"target", target,
+			"backup_id", backupID,
 			"host", t.config.Host)
 	}
 
-	// Validate the target path
-	targetPath := path.Join(t.config.BasePath, target)
-	if err := t.validatePath(targetPath); err != nil {
+	if err := validateBackupID("sftp", backupID); err != nil {
 		return err
 	}
 
 	return t.withRetry(ctx, func(client *sftp.Client) error {
-		backupPath := path.Join(t.config.BasePath, target)
+		backupPath, err := t.archivePath(client, backupID)
+		if err != nil {
+			return err
+		}
 		if err := client.Remove(backupPath); err != nil {
 			return errors.New(err).
 				Component("backup").
 				Category(errors.CategoryNetwork).
 				Context("operation", "delete_backup").
-				Context("target", target).
+				Context("backup_id", backupID).
 				Build()
 		}
 
@@ -828,13 +833,89 @@ func (t *SFTPTarget) Delete(ctx context.Context, target string) error {
 
 		if t.config.Debug {
 			t.logger.Debug("SFTP: Successfully deleted backup",
-				"target", target)
+				"backup_id", backupID)
 		}
 
 		return nil
 	})
 }
 
+// Download writes the archive of a stored backup to w. The transfer is not retried
+// because part of the archive may already have been written to w.
+func (t *SFTPTarget) Download(ctx context.Context, backupID string, w io.Writer) error {
+	if err := validateBackupID("sftp", backupID); err != nil {
+		return err
+	}
+
+	var backupPath string
+	if err := t.withRetry(ctx, func(client *sftp.Client) error {
+		var err error
+		backupPath, err = t.archivePath(client, backupID)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	client, err := t.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := t.downloadFile(ctx, client, backupPath, w); err != nil {
+		if closeErr := client.Close(); closeErr != nil && t.config.Debug {
+			t.logger.Debug("SFTP: Failed to close connection after download error", "error", closeErr)
+		}
+		return errors.New(err).
+			Component("backup").
+			Category(errors.CategoryNetwork).
+			Context("operation", "download_backup").
+			Context("backup_id", backupID).
+			Context("path", backupPath).
+			Build()
+	}
+
+	t.returnConnection(client)
+	return nil
+}
+
+// downloadFile copies a remote file to w
+func (t *SFTPTarget) downloadFile(ctx context.Context, client *sftp.Client, remotePath string, w io.Writer) error {
+	file, err := client.Open(remotePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil && t.config.Debug {
+			t.logger.Debug("SFTP: Failed to close remote file", "path", remotePath, "error", err)
+		}
+	}()
+
+	_, err = io.Copy(w, &contextReader{ctx: ctx, r: file})
+	return err
+}
+
+// archivePath returns the remote path of the archive stored for a backup ID
+func (t *SFTPTarget) archivePath(client *sftp.Client, backupID string) (string, error) {
+	entries, err := client.ReadDir(t.config.BasePath)
+	if err != nil {
+		return "", errors.New(err).
+			Component("backup").
+			Category(errors.CategoryNetwork).
+			Context("operation", "resolve_backup_file").
+			Build()
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), "sftp-upload-") {
+			continue
+		}
+		if isBackupArchive(entry.Name(), backupID, sftpMetadataFileExt) {
+			return path.Join(t.config.BasePath, entry.Name()), nil
+		}
+	}
+	return "", backupNotFoundError("sftp", backupID)
+}
+
 // Validate checks if the target configuration is valid
 func (t *SFTPTarget) Validate() error {
 	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
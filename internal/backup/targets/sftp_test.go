@@ -0,0 +1,80 @@
+package targets
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSFTPTarget returns an SFTP target whose connection pool holds a client
+// connected to an in-process SFTP server over a pipe
+func newTestSFTPTarget(t *testing.T) (target *SFTPTarget, basePath string) {
+	t.Helper()
+
+	// The target only accepts paths relative to the server's working directory
+	workDir := t.TempDir()
+	basePath = filepath.Join(workDir, "backups")
+	require.NoError(t, os.MkdirAll(basePath, 0o755))
+
+	serverConn, clientConn := net.Pipe()
+	server, err := sftp.NewServer(serverConn, sftp.WithServerWorkingDirectory(workDir))
+	require.NoError(t, err)
+	go func() { _ = server.Serve() }()
+
+	client, err := sftp.NewClientPipe(clientConn, clientConn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+	})
+
+	target, err = NewSFTPTarget(map[string]any{
+		"host": "127.0.0.1",
+		"path": "backups",
+	}, slog.Default())
+	require.NoError(t, err)
+	target.connPool <- client
+
+	return target, basePath
+}
+
+func TestSFTPTarget_StoreListDownloadDelete(t *testing.T) {
+	t.Parallel()
+
+	target, basePath := newTestSFTPTarget(t)
+	ctx := context.Background()
+
+	archivePath, metadata := writeTestArchive(t, "birdnet-20250101-120000", 4096)
+	require.NoError(t, target.Store(ctx, archivePath, metadata))
+	assert.FileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"))
+	assert.FileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"+sftpMetadataFileExt))
+
+	backups, err := target.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "birdnet-20250101-120000", backups[0].ID)
+
+	data, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, target.Download(ctx, "birdnet-20250101-120000", &buf))
+	assert.Equal(t, data, buf.Bytes())
+
+	require.NoError(t, target.Delete(ctx, "birdnet-20250101-120000"))
+	assert.NoFileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"))
+	assert.NoFileExists(t, filepath.Join(basePath, "birdnet-20250101-120000.tar"+sftpMetadataFileExt))
+
+	buf.Reset()
+	require.Error(t, target.Download(ctx, "../escape", &buf))
+	require.Error(t, target.Download(ctx, "birdnet-20250101-120000", &buf))
+	assert.Zero(t, buf.Len())
+}
@@ -222,6 +222,7 @@ func (bn *BirdNET) ProcessChunkWithContext(ctx context.Context, chunk []float32,
 		processingTime := time.Since(start)
 		
 		note := observation.New(bn.Settings, predStart, predEnd, result.Species, float64(result.Confidence), source, clipName, processingTime, occurrence)
+		note.Model = bn.ModelInfo.ID
 		notes = append(notes, note)
 	}
 	return notes, nil
@@ -0,0 +1,220 @@
+// models.go contains the registry of classifier models used for audio analysis
+package birdnet
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// registeredModel is a classifier model in the registry together with its source routing
+type registeredModel struct {
+	bn      *BirdNET
+	sources map[string]bool // source keys routed to the model, empty for all sources
+}
+
+// ModelRegistry holds the classifier models used for analysis and routes audio
+// sources to them. The primary BirdNET model analyzes every source, additional
+// models analyze only the sources configured for them.
+type ModelRegistry struct {
+	mu      sync.RWMutex
+	primary *BirdNET
+	models  []*registeredModel // additional models in registration order
+}
+
+// NewModelRegistry creates a model registry around the primary BirdNET model
+func NewModelRegistry(primary *BirdNET) *ModelRegistry {
+	return &ModelRegistry{primary: primary}
+}
+
+// Primary returns the primary BirdNET model
+func (r *ModelRegistry) Primary() *BirdNET {
+	return r.primary
+}
+
+// Register adds an additional model to the registry. The model is identified by
+// its ModelInfo.ID which must be unique. Sources lists the audio source IDs, display
+// names or connection strings routed to the model, an empty list routes all sources.
+func (r *ModelRegistry) Register(bn *BirdNET, sources []string) error {
+	if bn == nil {
+		return errors.Newf("model cannot be nil").
+			Component("birdnet").
+			Category(errors.CategoryValidation).
+			Context("operation", "register_model").
+			Build()
+	}
+
+	id := bn.ModelInfo.ID
+	if id == "" {
+		return errors.Newf("model id cannot be empty").
+			Component("birdnet").
+			Category(errors.CategoryValidation).
+			Context("operation", "register_model").
+			Build()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lookup(id) != nil {
+		return errors.Newf("model %s is already registered", id).
+			Component("birdnet").
+			Category(errors.CategoryValidation).
+			Context("operation", "register_model").
+			Context("model_id", id).
+			Build()
+	}
+
+	model := &registeredModel{bn: bn, sources: make(map[string]bool, len(sources))}
+	for _, source := range sources {
+		if source != "" {
+			model.sources[source] = true
+		}
+	}
+	r.models = append(r.models, model)
+
+	return nil
+}
+
+// lookup returns the model with the given ID, the caller must hold r.mu
+func (r *ModelRegistry) lookup(id string) *BirdNET {
+	if r.primary != nil && r.primary.ModelInfo.ID == id {
+		return r.primary
+	}
+	for _, model := range r.models {
+		if model.bn.ModelInfo.ID == id {
+			return model.bn
+		}
+	}
+	return nil
+}
+
+// Get returns the model with the given ID
+func (r *ModelRegistry) Get(id string) (*BirdNET, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bn := r.lookup(id)
+	return bn, bn != nil
+}
+
+// IsPrimary reports whether the ID identifies the primary BirdNET model. An empty
+// ID is treated as the primary model for results produced before models were tagged.
+func (r *ModelRegistry) IsPrimary(id string) bool {
+	return id == "" || (r.primary != nil && r.primary.ModelInfo.ID == id)
+}
+
+// IDs returns the IDs of all registered models, primary model first
+func (r *ModelRegistry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.models)+1)
+	if r.primary != nil {
+		ids = append(ids, r.primary.ModelInfo.ID)
+	}
+	for _, model := range r.models {
+		ids = append(ids, model.bn.ModelInfo.ID)
+	}
+	return ids
+}
+
+// ModelsForSource returns the models that analyze an audio source, primary model
+// first. A source may be identified by several keys such as its ID, display name
+// and connection string, a model is selected if any of the keys is routed to it.
+func (r *ModelRegistry) ModelsForSource(keys ...string) []*BirdNET {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	models := make([]*BirdNET, 0, len(r.models)+1)
+	if r.primary != nil {
+		models = append(models, r.primary)
+	}
+	for _, model := range r.models {
+		if len(model.sources) == 0 {
+			models = append(models, model.bn)
+			continue
+		}
+		for _, key := range keys {
+			if model.sources[key] {
+				models = append(models, model.bn)
+				break
+			}
+		}
+	}
+	return models
+}
+
+// Delete releases the resources of all models in the registry
+func (r *ModelRegistry) Delete() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, model := range r.models {
+		model.bn.Delete()
+	}
+	r.models = nil
+	if r.primary != nil {
+		r.primary.Delete()
+	}
+}
+
+// LoadAdditionalModels loads the enabled additional models from settings and
+// registers them. Models are registered until the first failure.
+func (r *ModelRegistry) LoadAdditionalModels(settings *conf.Settings) error {
+	for i := range settings.BirdNET.Models {
+		cfg := &settings.BirdNET.Models[i]
+		if !cfg.Enabled {
+			continue
+		}
+
+		bn, err := NewAdditionalModel(settings, cfg)
+		if err != nil {
+			return err
+		}
+		if err := r.Register(bn, cfg.Sources); err != nil {
+			bn.Delete()
+			return err
+		}
+	}
+	return nil
+}
+
+// NewAdditionalModel loads a classifier model described by cfg. The model shares
+// the analysis settings of BirdNET but uses its own model and label files, and
+// is identified by the configured model ID.
+func NewAdditionalModel(settings *conf.Settings, cfg *conf.ModelConfig) (*BirdNET, error) {
+	if cfg.ID == "" || cfg.ModelPath == "" || cfg.LabelPath == "" {
+		return nil, errors.Newf("additional model requires an id, a model path and a label path").
+			Component("birdnet").
+			Category(errors.CategoryModelInit).
+			Context("model_id", cfg.ID).
+			Build()
+	}
+
+	// Work on a copy so that the model and label paths of the primary model are
+	// not changed, NewBirdNET also stores runtime values such as labels in settings
+	modelSettings := *settings
+	modelSettings.BirdNET.ModelPath = cfg.ModelPath
+	modelSettings.BirdNET.LabelPath = cfg.LabelPath
+	modelSettings.BirdNET.Labels = nil
+	if cfg.Threshold > 0 {
+		modelSettings.BirdNET.Threshold = cfg.Threshold
+	}
+
+	bn, err := NewBirdNET(&modelSettings)
+	if err != nil {
+		return nil, errors.New(fmt.Errorf("failed to load model %s: %w", cfg.ID, err)).
+			Component("birdnet").
+			Category(errors.CategoryModelInit).
+			ModelContext(cfg.ModelPath, cfg.ID).
+			Context("label_path", cfg.LabelPath).
+			Build()
+	}
+
+	bn.ModelInfo.ID = cfg.ID
+	bn.ModelInfo.Name = cfg.ID
+	return bn, nil
+}
@@ -0,0 +1,84 @@
+package birdnet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// newTestModel creates a model without interpreters for registry tests
+func newTestModel(id string) *BirdNET {
+	return &BirdNET{ModelInfo: ModelInfo{ID: id}}
+}
+
+func TestModelRegistry_Register(t *testing.T) {
+	registry := NewModelRegistry(newTestModel(DefaultModelVersion))
+
+	require.NoError(t, registry.Register(newTestModel("amphibians"), nil))
+
+	assert.Error(t, registry.Register(nil, nil), "nil model")
+	assert.Error(t, registry.Register(newTestModel(""), nil), "empty id")
+	assert.Error(t, registry.Register(newTestModel("amphibians"), nil), "duplicate id")
+	assert.Error(t, registry.Register(newTestModel(DefaultModelVersion), nil), "primary model id")
+
+	assert.Equal(t, []string{DefaultModelVersion, "amphibians"}, registry.IDs())
+
+	bn, ok := registry.Get("amphibians")
+	require.True(t, ok)
+	assert.Equal(t, "amphibians", bn.ModelInfo.ID)
+
+	_, ok = registry.Get("bats")
+	assert.False(t, ok)
+
+	assert.True(t, registry.IsPrimary(DefaultModelVersion))
+	assert.True(t, registry.IsPrimary(""))
+	assert.False(t, registry.IsPrimary("amphibians"))
+}
+
+func TestModelRegistry_ModelsForSource(t *testing.T) {
+	primary := newTestModel(DefaultModelVersion)
+	amphibians := newTestModel("amphibians")
+	bats := newTestModel("bats")
+
+	registry := NewModelRegistry(primary)
+	require.NoError(t, registry.Register(amphibians, []string{"rtsp://pond.local/stream"}))
+	require.NoError(t, registry.Register(bats, nil))
+
+	testCases := []struct {
+		name     string
+		keys     []string
+		expected []*BirdNET
+	}{
+		{"unrouted source", []string{"source-1", "Backyard"}, []*BirdNET{primary, bats}},
+		{"routed by connection", []string{"source-2", "Pond", "rtsp://pond.local/stream"}, []*BirdNET{primary, amphibians, bats}},
+		{"no keys", nil, []*BirdNET{primary, bats}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, registry.ModelsForSource(tc.keys...))
+		})
+	}
+}
+
+func TestNewAdditionalModel_RequiresPaths(t *testing.T) {
+	settings := &conf.Settings{}
+
+	testCases := []struct {
+		name string
+		cfg  conf.ModelConfig
+	}{
+		{"missing id", conf.ModelConfig{ModelPath: "model.tflite", LabelPath: "labels.txt"}},
+		{"missing model path", conf.ModelConfig{ID: "amphibians", LabelPath: "labels.txt"}},
+		{"missing label path", conf.ModelConfig{ID: "amphibians", ModelPath: "model.tflite"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewAdditionalModel(settings, &tc.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
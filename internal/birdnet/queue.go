@@ -14,6 +14,7 @@ type Results struct {
 	ElapsedTime time.Duration            // Time taken for analysis
 	ClipName    string                   // Name of the audio clip
 	Source      datastore.AudioSource    // Audio source with ID, SafeString, and DisplayName
+	Model       string                   // ID of the model that produced the results
 }
 
 // Default buffer size for the results queue
@@ -36,6 +37,7 @@ func (r Results) Copy() Results { //nolint:gocritic // This is a copy function,
 		ElapsedTime: r.ElapsedTime,
 		ClipName:    r.ClipName,
 		Source:      r.Source,
+		Model:       r.Model,
 	}
 
 	// Deep copy PCMdata
@@ -0,0 +1,249 @@
+// Package calibration derives per-species precision curves and confidence thresholds from
+// the detections users reviewed as correct or false positive.
+//
+// Reviews only cover detections that passed the threshold in use at the time, so the
+// precision below the current threshold is unknown. Suggestions therefore only raise
+// thresholds: the suggested threshold is the lowest threshold at which the reviewed
+// detections reach the target precision.
+package calibration
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// Defaults of the calibration settings, used for zero values
+const (
+	DefaultTargetPrecision = 0.95
+	DefaultMinReviews      = 20
+	DefaultMaxThreshold    = 0.95
+)
+
+// curveStep is the threshold step of the reported precision curves
+const curveStep = 0.05
+
+// Options control the threshold suggestions
+type Options struct {
+	TargetPrecision float64                       // Share of correct detections the suggestions aim for
+	MinReviews      int                           // Reviewed detections of a species required for a suggestion
+	MaxThreshold    float64                       // Suggestions are not higher than this
+	GlobalThreshold float64                       // Threshold of species without a custom threshold
+	Species         map[string]conf.SpeciesConfig // Custom species thresholds by lowercase common name
+}
+
+// OptionsFromSettings returns the options of the calibration and threshold settings
+func OptionsFromSettings(settings *conf.Settings) Options {
+	cal := &settings.Realtime.Calibration
+	opts := Options{
+		TargetPrecision: cal.TargetPrecision,
+		MinReviews:      cal.MinReviews,
+		MaxThreshold:    cal.MaxThreshold,
+		GlobalThreshold: settings.BirdNET.Threshold,
+		Species:         settings.Realtime.Species.Config,
+	}
+	if opts.TargetPrecision <= 0 {
+		opts.TargetPrecision = DefaultTargetPrecision
+	}
+	if opts.MinReviews <= 0 {
+		opts.MinReviews = DefaultMinReviews
+	}
+	if opts.MaxThreshold <= 0 {
+		opts.MaxThreshold = DefaultMaxThreshold
+	}
+	return opts
+}
+
+// threshold returns the confidence threshold in use for a species, like the processor a
+// species config overrides the global threshold
+func (o *Options) threshold(key string) (threshold float64, custom bool) {
+	if config, ok := o.Species[key]; ok {
+		return config.Threshold, true
+	}
+	return o.GlobalThreshold, false
+}
+
+// Point is a point of a precision curve
+type Point struct {
+	Threshold float64 `json:"threshold"`
+	Precision float64 `json:"precision"` // Share of the reviewed detections at or above the threshold that are correct
+	Reviewed  int     `json:"reviewed"`  // Reviewed detections at or above the threshold
+}
+
+// Species is the calibration of a species
+type Species struct {
+	CommonName          string   `json:"commonName"`
+	ScientificName      string   `json:"scientificName"`
+	Reviewed            int      `json:"reviewed"`
+	Correct             int      `json:"correct"`
+	FalsePositives      int      `json:"falsePositives"`
+	Threshold           float64  `json:"threshold"`                    // Threshold in use
+	CustomThreshold     bool     `json:"customThreshold"`              // The threshold is set in the species config
+	ExpectedPrecision   *float64 `json:"expectedPrecision,omitempty"`  // Precision at the threshold in use, nil without reviews at or above it
+	ReviewedAtThreshold int      `json:"reviewedAtThreshold"`          // Reviewed detections at or above the threshold in use
+	SuggestedThreshold  *float64 `json:"suggestedThreshold,omitempty"` // Suggested threshold, nil if the threshold in use is fine or there are too few reviews
+	SuggestedPrecision  *float64 `json:"suggestedPrecision,omitempty"` // Precision at the suggested threshold, nil without reviews at or above it
+	Curve               []Point  `json:"curve"`
+}
+
+// Key returns the species config key of the species
+func (s *Species) Key() string {
+	return speciesKey(s.CommonName, s.ScientificName)
+}
+
+// speciesKey returns the species config key of a species, the processor looks up species
+// configs by lowercase common name
+func speciesKey(commonName, scientificName string) string {
+	if commonName == "" {
+		return strings.ToLower(scientificName)
+	}
+	return strings.ToLower(commonName)
+}
+
+// reviews holds the reviewed detections of a species
+type reviews struct {
+	commonName     string
+	scientificName string
+	confidences    []float64
+	correct        []bool
+}
+
+// Calibrator collects reviewed detections and computes the species calibrations
+type Calibrator struct {
+	opts    Options
+	species map[string]*reviews
+}
+
+// NewCalibrator creates a calibrator
+func NewCalibrator(opts Options) *Calibrator {
+	return &Calibrator{opts: opts, species: make(map[string]*reviews)}
+}
+
+// Add adds a reviewed detection
+func (c *Calibrator) Add(commonName, scientificName string, confidence float64, correct bool) {
+	key := speciesKey(commonName, scientificName)
+	r, ok := c.species[key]
+	if !ok {
+		r = &reviews{commonName: commonName, scientificName: scientificName}
+		c.species[key] = r
+	}
+	r.confidences = append(r.confidences, confidence)
+	r.correct = append(r.correct, correct)
+}
+
+// Species returns the calibrations of the reviewed species ordered by common name
+func (c *Calibrator) Species() []Species {
+	result := make([]Species, 0, len(c.species))
+	for key, r := range c.species {
+		result = append(result, c.calibrate(key, r))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CommonName < result[j].CommonName
+	})
+	return result
+}
+
+// calibrate computes the calibration of a species
+func (c *Calibrator) calibrate(key string, r *reviews) Species {
+	curve := newPrecisionCurve(r.confidences, r.correct)
+	s := Species{
+		CommonName:     r.commonName,
+		ScientificName: r.scientificName,
+		Reviewed:       len(r.confidences),
+		Correct:        curve.correctAbove[0],
+	}
+	s.FalsePositives = s.Reviewed - s.Correct
+	s.Threshold, s.CustomThreshold = c.opts.threshold(key)
+	s.ExpectedPrecision, s.ReviewedAtThreshold = curve.at(s.Threshold)
+
+	for step := 1; float64(step)*curveStep < 1; step++ {
+		threshold := roundHundredths(float64(step) * curveStep)
+		if precision, reviewed := curve.at(threshold); precision != nil {
+			s.Curve = append(s.Curve, Point{Threshold: threshold, Precision: *precision, Reviewed: reviewed})
+		}
+	}
+
+	c.suggest(&s, curve)
+	return s
+}
+
+// suggest sets the lowest threshold above the one in use at which the reviewed detections
+// reach the target precision, or the maximum threshold if none does
+func (c *Calibrator) suggest(s *Species, curve *precisionCurve) {
+	if s.Reviewed < c.opts.MinReviews || s.FalsePositives == 0 {
+		return
+	}
+	if s.ExpectedPrecision != nil && *s.ExpectedPrecision >= c.opts.TargetPrecision {
+		return
+	}
+
+	first := int(math.Ceil(s.Threshold*100 - 1e-9))
+	last := int(math.Floor(c.opts.MaxThreshold*100 + 1e-9))
+	if first > last {
+		return
+	}
+	for h := first; h <= last; h++ {
+		threshold := float64(h) / 100
+		precision, _ := curve.at(threshold)
+		// Without reviews at or above the threshold all reviewed false positives are filtered
+		if precision == nil || *precision >= c.opts.TargetPrecision || h == last {
+			if threshold <= s.Threshold {
+				return
+			}
+			s.SuggestedThreshold = &threshold
+			s.SuggestedPrecision = precision
+			return
+		}
+	}
+}
+
+// roundHundredths rounds a threshold to two decimals
+func roundHundredths(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// precisionCurve answers precision queries over the reviewed detections of a species
+type precisionCurve struct {
+	confidences  []float64 // Ascending
+	correctAbove []int     // Correct reviews at or after index i, with a trailing 0
+}
+
+// newPrecisionCurve creates the precision curve of reviewed detections
+func newPrecisionCurve(confidences []float64, correct []bool) *precisionCurve {
+	order := make([]int, len(confidences))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return confidences[order[i]] < confidences[order[j]]
+	})
+
+	curve := &precisionCurve{
+		confidences:  make([]float64, len(order)),
+		correctAbove: make([]int, len(order)+1),
+	}
+	for i, idx := range order {
+		curve.confidences[i] = confidences[idx]
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		curve.correctAbove[i] = curve.correctAbove[i+1]
+		if correct[order[i]] {
+			curve.correctAbove[i]++
+		}
+	}
+	return curve
+}
+
+// at returns the precision of the reviewed detections at or above a threshold and their
+// number, the precision is nil if there are none
+func (pc *precisionCurve) at(threshold float64) (precision *float64, reviewed int) {
+	i := sort.SearchFloat64s(pc.confidences, threshold)
+	reviewed = len(pc.confidences) - i
+	if reviewed == 0 {
+		return nil, 0
+	}
+	p := float64(pc.correctAbove[i]) / float64(reviewed)
+	return &p, reviewed
+}
@@ -0,0 +1,145 @@
+package calibration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// addReviews adds reviewed detections of a species with the given confidences
+func addReviews(c *Calibrator, commonName string, correct bool, confidences ...float64) {
+	for _, confidence := range confidences {
+		c.Add(commonName, "", confidence, correct)
+	}
+}
+
+func TestCalibrator_Suggestion(t *testing.T) {
+	t.Parallel()
+
+	c := NewCalibrator(Options{TargetPrecision: 0.9, MinReviews: 10, MaxThreshold: 0.95, GlobalThreshold: 0.8})
+	addReviews(c, "Eurasian Wren", true, 0.81, 0.84, 0.88, 0.9, 0.91, 0.93, 0.95, 0.96)
+	addReviews(c, "Eurasian Wren", false, 0.8, 0.82, 0.83, 0.85)
+
+	species := c.Species()
+	require.Len(t, species, 1)
+	wren := species[0]
+	assert.Equal(t, 12, wren.Reviewed)
+	assert.Equal(t, 8, wren.Correct)
+	assert.Equal(t, 4, wren.FalsePositives)
+	assert.InDelta(t, 0.8, wren.Threshold, 1e-9)
+	assert.False(t, wren.CustomThreshold)
+	require.NotNil(t, wren.ExpectedPrecision)
+	assert.InDelta(t, 8.0/12, *wren.ExpectedPrecision, 1e-9)
+	assert.Equal(t, 12, wren.ReviewedAtThreshold)
+
+	// The last false positive is at 0.85, from 0.86 on all reviewed detections are correct
+	require.NotNil(t, wren.SuggestedThreshold)
+	assert.InDelta(t, 0.86, *wren.SuggestedThreshold, 1e-9)
+	require.NotNil(t, wren.SuggestedPrecision)
+	assert.InDelta(t, 1.0, *wren.SuggestedPrecision, 1e-9)
+
+	// The curve covers the thresholds with reviewed detections
+	require.NotEmpty(t, wren.Curve)
+	last := wren.Curve[len(wren.Curve)-1]
+	assert.InDelta(t, 0.95, last.Threshold, 1e-9)
+	assert.Equal(t, 2, last.Reviewed)
+}
+
+func TestCalibrator_NoSuggestion(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{
+		TargetPrecision: 0.9,
+		MinReviews:      5,
+		MaxThreshold:    0.95,
+		GlobalThreshold: 0.8,
+		Species:         map[string]conf.SpeciesConfig{"common raven": {Threshold: 0.7}},
+	}
+	c := NewCalibrator(opts)
+	// Too few reviews
+	addReviews(c, "Barred Owl", false, 0.85, 0.9)
+	// No false positives
+	addReviews(c, "Common Raven", true, 0.75, 0.8, 0.85, 0.9, 0.95)
+	// The threshold in use already reaches the target precision
+	addReviews(c, "Song Thrush", true, 0.85, 0.86, 0.87, 0.88, 0.89, 0.9, 0.91, 0.92, 0.93, 0.94)
+	addReviews(c, "Song Thrush", false, 0.81)
+
+	species := c.Species()
+	require.Len(t, species, 3)
+	for _, s := range species {
+		assert.Nil(t, s.SuggestedThreshold, s.CommonName)
+	}
+	assert.True(t, species[1].CustomThreshold)
+	assert.InDelta(t, 0.7, species[1].Threshold, 1e-9)
+}
+
+func TestCalibrator_MaxThreshold(t *testing.T) {
+	t.Parallel()
+
+	c := NewCalibrator(Options{TargetPrecision: 0.95, MinReviews: 4, MaxThreshold: 0.9, GlobalThreshold: 0.8})
+	addReviews(c, "House Sparrow", true, 0.82, 0.95)
+	addReviews(c, "House Sparrow", false, 0.85, 0.97)
+
+	species := c.Species()
+	require.Len(t, species, 1)
+	require.NotNil(t, species[0].SuggestedThreshold)
+	assert.InDelta(t, 0.9, *species[0].SuggestedThreshold, 1e-9)
+	require.NotNil(t, species[0].SuggestedPrecision)
+	assert.InDelta(t, 0.5, *species[0].SuggestedPrecision, 1e-9)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.8
+	original := map[string]conf.SpeciesConfig{
+		"eurasian wren": {Threshold: 0.75, Interval: 30, Actions: []conf.SpeciesAction{{Type: "ExecuteCommand", Command: "/bin/true"}}},
+		"common raven":  {Threshold: 0.9},
+	}
+	settings.Realtime.Species.Config = original
+
+	threshold := func(v float64) *float64 { return &v }
+	changes := Apply(settings, []Species{
+		{CommonName: "Eurasian Wren", SuggestedThreshold: threshold(0.86)},
+		{CommonName: "House Sparrow", SuggestedThreshold: threshold(0.88)},
+		{CommonName: "Common Raven", SuggestedThreshold: threshold(0.85)},
+		{CommonName: "Song Thrush"},
+	})
+
+	assert.Equal(t, []Change{
+		{CommonName: "Eurasian Wren", From: 0.75, To: 0.86},
+		{CommonName: "House Sparrow", From: 0.8, To: 0.88},
+	}, changes)
+
+	config := settings.Realtime.Species.Config
+	assert.InDelta(t, 0.86, config["eurasian wren"].Threshold, 1e-9)
+	assert.Equal(t, 30, config["eurasian wren"].Interval)
+	assert.Len(t, config["eurasian wren"].Actions, 1)
+	assert.InDelta(t, 0.88, config["house sparrow"].Threshold, 1e-9)
+	assert.InDelta(t, 0.9, config["common raven"].Threshold, 1e-9)
+
+	// The previous map is left untouched for concurrent readers
+	assert.InDelta(t, 0.75, original["eurasian wren"].Threshold, 1e-9)
+	assert.NotContains(t, original, "house sparrow")
+}
+
+func TestOptionsFromSettings(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.BirdNET.Threshold = 0.8
+	opts := OptionsFromSettings(settings)
+	assert.InDelta(t, DefaultTargetPrecision, opts.TargetPrecision, 1e-9)
+	assert.Equal(t, DefaultMinReviews, opts.MinReviews)
+	assert.InDelta(t, DefaultMaxThreshold, opts.MaxThreshold, 1e-9)
+	assert.InDelta(t, 0.8, opts.GlobalThreshold, 1e-9)
+
+	settings.Realtime.Calibration = conf.CalibrationSettings{TargetPrecision: 0.9, MinReviews: 5, MaxThreshold: 0.99}
+	opts = OptionsFromSettings(settings)
+	assert.InDelta(t, 0.9, opts.TargetPrecision, 1e-9)
+	assert.Equal(t, 5, opts.MinReviews)
+	assert.InDelta(t, 0.99, opts.MaxThreshold, 1e-9)
+}
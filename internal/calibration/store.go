@@ -0,0 +1,85 @@
+package calibration
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// loadBatchSize is the number of reviewed detections read from the datastore at a time
+const loadBatchSize = 1000
+
+// applyMutex serializes threshold updates of the species config
+var applyMutex sync.Mutex
+
+// Load computes the species calibrations from the reviewed detections of a datastore
+func Load(ctx context.Context, store datastore.Interface, opts Options) ([]Species, error) {
+	verified := true
+	filters := datastore.AdvancedSearchFilters{Verified: &verified}
+
+	calibrator := NewCalibrator(opts)
+	err := store.StreamNotesAdvanced(ctx, &filters, loadBatchSize, func(notes []datastore.Note) error {
+		for i := range notes {
+			note := &notes[i]
+			switch note.Verified {
+			case "correct":
+				calibrator.Add(note.CommonName, note.ScientificName, note.Confidence, true)
+			case "false_positive":
+				calibrator.Add(note.CommonName, note.ScientificName, note.Confidence, false)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return calibrator.Species(), nil
+}
+
+// Change is a species threshold raised to the suggested threshold
+type Change struct {
+	CommonName string  `json:"commonName"`
+	From       float64 `json:"from"`
+	To         float64 `json:"to"`
+}
+
+// Apply raises the thresholds of the species with a suggestion in the species config of
+// the settings and returns the changes. Intervals and actions of configured species are
+// kept. The caller saves the settings.
+func Apply(settings *conf.Settings, species []Species) []Change {
+	applyMutex.Lock()
+	defer applyMutex.Unlock()
+
+	var changes []Change
+	// Copy the config so that readers of the current map are not affected
+	config := make(map[string]conf.SpeciesConfig, len(settings.Realtime.Species.Config)+len(species))
+	for key, value := range settings.Realtime.Species.Config {
+		config[key] = value
+	}
+
+	for i := range species {
+		s := &species[i]
+		if s.SuggestedThreshold == nil {
+			continue
+		}
+		key := s.Key()
+		current := settings.BirdNET.Threshold
+		speciesConfig, ok := config[key]
+		if ok {
+			current = speciesConfig.Threshold
+		}
+		if *s.SuggestedThreshold <= current {
+			continue
+		}
+		speciesConfig.Threshold = *s.SuggestedThreshold
+		config[key] = speciesConfig
+		changes = append(changes, Change{CommonName: s.CommonName, From: current, To: *s.SuggestedThreshold})
+	}
+
+	if len(changes) > 0 {
+		settings.Realtime.Species.Config = config
+	}
+	return changes
+}
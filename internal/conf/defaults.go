@@ -32,6 +32,7 @@ func setDefaultConfig() {
 	viper.SetDefault("birdnet.modelpath", "")
 	viper.SetDefault("birdnet.labelpath", "")
 	viper.SetDefault("birdnet.usexnnpack", true)
+	viper.SetDefault("birdnet.models", []ModelConfig{})
 
 	// Range filter configuration
 	viper.SetDefault("birdnet.rangefilter.debug", false)
@@ -50,6 +51,10 @@ func setDefaultConfig() {
 	// Sound level monitoring configuration
 	viper.SetDefault("realtime.audio.soundlevel.enabled", false)
 	viper.SetDefault("realtime.audio.soundlevel.interval", 10)
+	viper.SetDefault("realtime.audio.soundlevel.history.enabled", false)
+	viper.SetDefault("realtime.audio.soundlevel.history.resolution", 300)
+	viper.SetDefault("realtime.audio.soundlevel.history.downsampledays", 7)
+	viper.SetDefault("realtime.audio.soundlevel.history.retentiondays", 365)
 
 	// Audio export configuration
 	viper.SetDefault("realtime.audio.export.debug", false)
@@ -75,6 +80,9 @@ func setDefaultConfig() {
 		},
 	})
 
+	// Per-source filter chains and gain, sources without an entry use the global equalizer
+	viper.SetDefault("realtime.audio.sourcefilters", []SourceAudioSettings{})
+
 	// Dashboard thumbnails configuration
 	viper.SetDefault("realtime.dashboard.thumbnails.debug", false)
 	viper.SetDefault("realtime.dashboard.thumbnails.summary", false)
@@ -84,6 +92,14 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.dashboard.summarylimit", 30)
 	viper.SetDefault("realtime.dashboard.locale", "en") // Default UI locale
 	viper.SetDefault("realtime.dashboard.newui", false) // Enable redirect from old HTMX UI to new Svelte UI
+	viper.SetDefault("realtime.dashboard.spectrogram.renderer", "auto")
+	viper.SetDefault("realtime.dashboard.spectrogram.format", "png")
+	viper.SetDefault("realtime.dashboard.spectrogram.fftsize", 1024)
+	viper.SetDefault("realtime.dashboard.spectrogram.hopsize", 0)
+	viper.SetDefault("realtime.dashboard.spectrogram.minfreq", 0)
+	viper.SetDefault("realtime.dashboard.spectrogram.maxfreq", 12000)
+	viper.SetDefault("realtime.dashboard.spectrogram.dynamicrange", 100.0)
+	viper.SetDefault("realtime.dashboard.spectrogram.colormap", "sox")
 
 	// Retention policy configuration
 	viper.SetDefault("realtime.audio.export.retention.enabled", true)
@@ -101,6 +117,25 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.dynamicthreshold.min", 0.20)
 	viper.SetDefault("realtime.dynamicthreshold.validhours", 24)
 
+	// Noise adaptive threshold configuration
+	viper.SetDefault("realtime.noisethreshold.enabled", false)
+	viper.SetDefault("realtime.noisethreshold.debug", false)
+	viper.SetDefault("realtime.noisethreshold.band", "birdsong")
+	viper.SetDefault("realtime.noisethreshold.quietlevel", -70.0)
+	viper.SetDefault("realtime.noisethreshold.noisylevel", -40.0)
+	viper.SetDefault("realtime.noisethreshold.maxraise", 0.15)
+	viper.SetDefault("realtime.noisethreshold.maxrelax", 0.05)
+
+	// Species threshold calibration from reviewed detections
+	viper.SetDefault("realtime.calibration.autoapply", false)
+	viper.SetDefault("realtime.calibration.targetprecision", 0.95)
+	viper.SetDefault("realtime.calibration.minreviews", 20)
+	viper.SetDefault("realtime.calibration.maxthreshold", 0.95)
+	viper.SetDefault("realtime.calibration.interval", 24)
+
+	// Suppression rules for recurring false positives
+	viper.SetDefault("realtime.suppression.rules", []SuppressionRule{})
+
 	// Log configuration
 	viper.SetDefault("realtime.log.enabled", false)
 	viper.SetDefault("realtime.log.path", "birdnet.txt")
@@ -116,6 +151,10 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.birdweather.retrysettings.initialdelay", 60)
 	viper.SetDefault("realtime.birdweather.retrysettings.maxdelay", 3600)
 	viper.SetDefault("realtime.birdweather.retrysettings.backoffmultiplier", 2.0)
+	viper.SetDefault("realtime.birdweather.outbox.enabled", true)
+	viper.SetDefault("realtime.birdweather.outbox.replayinterval", 5)
+	viper.SetDefault("realtime.birdweather.outbox.batchsize", 20)
+	viper.SetDefault("realtime.birdweather.outbox.maxattempts", 10)
 
 	// eBird configuration
 	viper.SetDefault("realtime.ebird.enabled", false)
@@ -163,6 +202,11 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.mqtt.debug", false)
 	viper.SetDefault("realtime.mqtt.broker", "tcp://localhost:1883")
 	viper.SetDefault("realtime.mqtt.topic", "birdnet")
+	viper.SetDefault("realtime.mqtt.topictemplate", "")
+	viper.SetDefault("realtime.mqtt.payloadprofile", "full")
+	viper.SetDefault("realtime.mqtt.payloadtemplate", "")
+	viper.SetDefault("realtime.mqtt.qos", 1)
+	viper.SetDefault("realtime.mqtt.newspeciestopic", "")
 	viper.SetDefault("realtime.mqtt.username", "")
 	viper.SetDefault("realtime.mqtt.password", "")
 	viper.SetDefault("realtime.mqtt.retain", false)
@@ -171,6 +215,24 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.mqtt.retrysettings.initialdelay", 30)
 	viper.SetDefault("realtime.mqtt.retrysettings.maxdelay", 3600)
 	viper.SetDefault("realtime.mqtt.retrysettings.backoffmultiplier", 2.0)
+	viper.SetDefault("realtime.mqtt.homeassistant.enabled", false)
+	viper.SetDefault("realtime.mqtt.homeassistant.discoveryprefix", "homeassistant")
+	viper.SetDefault("realtime.mqtt.homeassistant.devicename", "")
+	viper.SetDefault("realtime.mqtt.spool.enabled", false)
+	viper.SetDefault("realtime.mqtt.spool.path", "")
+	viper.SetDefault("realtime.mqtt.spool.maxmessages", 10000)
+	viper.SetDefault("realtime.mqtt.spool.maxsizemb", 50)
+	viper.SetDefault("realtime.mqtt.spool.maxage", 72)
+	viper.SetDefault("realtime.mqtt.commands.enabled", false)
+	viper.SetDefault("realtime.mqtt.commands.topic", "")
+	viper.SetDefault("realtime.mqtt.commands.token", "")
+	viper.SetDefault("realtime.mqtt.species.include", []string{})
+	viper.SetDefault("realtime.mqtt.species.exclude", []string{})
+	viper.SetDefault("realtime.mqtt.brokers", []MQTTBrokerSettings{})
+
+	// Webhook configuration
+	viper.SetDefault("realtime.webhooks.enabled", false)
+	viper.SetDefault("realtime.webhooks.endpoints", []WebhookEndpoint{})
 
 	// Privacy filter configuration
 	viper.SetDefault("realtime.privacyfilter.enabled", true)
@@ -269,6 +331,21 @@ func setDefaultConfig() {
 	viper.SetDefault("output.mysql.host", "localhost")
 	viper.SetDefault("output.mysql.port", 3306)
 
+	// Notification persistence configuration
+	viper.SetDefault("notification.persistence.enabled", true)
+	viper.SetDefault("notification.persistence.retentiondays", 30)
+	viper.SetDefault("notification.persistence.maxrecords", 10000)
+
+	// Notification push configuration
+	viper.SetDefault("notification.push.enabled", false)
+	viper.SetDefault("notification.push.debug", false)
+	viper.SetDefault("notification.push.retrysettings.enabled", true)
+	viper.SetDefault("notification.push.retrysettings.maxretries", 3)
+	viper.SetDefault("notification.push.retrysettings.initialdelay", 10)
+	viper.SetDefault("notification.push.retrysettings.maxdelay", 600)
+	viper.SetDefault("notification.push.retrysettings.backoffmultiplier", 2.0)
+	viper.SetDefault("notification.push.providers", []map[string]any{})
+
 	// Security configuration
 	viper.SetDefault("security.debug", false)
 	viper.SetDefault("security.host", "")
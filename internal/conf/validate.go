@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/url"
 	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/tphakala/birdnet-go/internal/errors"
 )
@@ -86,6 +89,16 @@ func ValidateSettings(settings *Settings) error {
 		ve.Errors = append(ve.Errors, err.Error())
 	}
 
+	// Validate notification persistence settings
+	if err := validateNotificationPersistenceSettings(&settings.Notification.Persistence); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
+	// Validate notification push settings
+	if err := validatePushSettings(&settings.Notification.Push); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
 	// If there are any errors, return the ValidationError
 	if len(ve.Errors) > 0 {
 		return ve
@@ -158,6 +171,9 @@ func validateBirdNETSettings(birdnetSettings *BirdNETConfig, settings *Settings)
 		birdnetSettings.Locale = normalizedLocale
 	}
 
+	// Validate additional models
+	errs = append(errs, validateModelConfigs(birdnetSettings.Models)...)
+
 	// If there are any errors, return them as a single error
 	if len(errs) > 0 {
 		return errors.New(fmt.Errorf("birdnet settings errors: %v", errs)).
@@ -169,6 +185,39 @@ func validateBirdNETSettings(birdnetSettings *BirdNETConfig, settings *Settings)
 	return nil
 }
 
+// validateModelConfigs validates the additional classifier models and returns the found problems
+func validateModelConfigs(models []ModelConfig) []string {
+	var errs []string
+	seen := make(map[string]bool)
+
+	for i := range models {
+		model := &models[i]
+		if model.ID == "" {
+			errs = append(errs, fmt.Sprintf("BirdNET model %d must have an id", i+1))
+			continue
+		}
+		if seen[model.ID] {
+			errs = append(errs, fmt.Sprintf("BirdNET model id '%s' is used more than once", model.ID))
+		}
+		seen[model.ID] = true
+
+		if !model.Enabled {
+			continue
+		}
+		if model.ModelPath == "" {
+			errs = append(errs, fmt.Sprintf("BirdNET model '%s' requires a model path", model.ID))
+		}
+		if model.LabelPath == "" {
+			errs = append(errs, fmt.Sprintf("BirdNET model '%s' requires a label path", model.ID))
+		}
+		if model.Threshold < 0 || model.Threshold > 1 {
+			errs = append(errs, fmt.Sprintf("BirdNET model '%s' threshold must be between 0 and 1", model.ID))
+		}
+	}
+
+	return errs
+}
+
 // validateWebServerSettings validates the WebServer-specific settings
 func validateWebServerSettings(settings *WebServerSettings) error {
 	if settings.Enabled {
@@ -282,15 +331,115 @@ func validateRealtimeSettings(settings *RealtimeSettings) error {
 		return err
 	}
 
+	// Validate noise adaptive threshold settings
+	if err := validateNoiseThresholdSettings(&settings.NoiseThreshold, &settings.Audio.SoundLevel); err != nil {
+		return err
+	}
+
 	// Validate species settings
 	if err := validateSpeciesConfigSettings(&settings.Species); err != nil {
 		return err
 	}
 
+	// Validate webhook endpoints and the species actions posting to them
+	if err := validateWebhookSettings(&settings.Webhooks, &settings.Species); err != nil {
+		return err
+	}
+
+	// Validate species threshold calibration settings
+	if err := validateCalibrationSettings(&settings.Calibration); err != nil {
+		return err
+	}
+
+	// Validate suppression rules
+	if err := validateSuppressionSettings(&settings.Suppression); err != nil {
+		return err
+	}
+
 	// Add more realtime settings validation as needed
 	return nil
 }
 
+// validateNotificationPersistenceSettings validates the notification persistence settings
+func validateNotificationPersistenceSettings(settings *NotificationPersistenceSettings) error {
+	if settings.RetentionDays < 0 {
+		return errors.New(fmt.Errorf("notification retention days must be non-negative, got %d", settings.RetentionDays)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "notification-retention-days").
+			Build()
+	}
+	if settings.MaxRecords < 0 {
+		return errors.New(fmt.Errorf("notification max records must be non-negative, got %d", settings.MaxRecords)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "notification-max-records").
+			Build()
+	}
+	return nil
+}
+
+// validatePushSettings validates the external push notification provider settings
+func validatePushSettings(settings *PushSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	names := make(map[string]bool, len(settings.Providers))
+	for i := range settings.Providers {
+		provider := &settings.Providers[i]
+
+		if provider.Name == "" {
+			return errors.New(fmt.Errorf("push provider #%d: name is required", i+1)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "push-provider-name").
+				Build()
+		}
+		if names[provider.Name] {
+			return errors.New(fmt.Errorf("push provider '%s': duplicate provider name", provider.Name)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "push-provider-duplicate").
+				Context("provider", provider.Name).
+				Build()
+		}
+		names[provider.Name] = true
+
+		if provider.Timeout < 0 {
+			return errors.New(fmt.Errorf("push provider '%s': timeout must be non-negative", provider.Name)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "push-provider-timeout").
+				Context("provider", provider.Name).
+				Build()
+		}
+
+		switch strings.ToLower(provider.Type) {
+		case "webhook", "ntfy", "gotify", "apprise":
+			if provider.URL == "" {
+				return errors.New(fmt.Errorf("push provider '%s': url is required for type %s", provider.Name, provider.Type)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "push-provider-url").
+					Context("provider", provider.Name).
+					Build()
+			}
+		case "email":
+			if provider.SMTP.Host == "" || len(provider.SMTP.To) == 0 {
+				return errors.New(fmt.Errorf("push provider '%s': smtp host and at least one recipient are required", provider.Name)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "push-provider-smtp").
+					Context("provider", provider.Name).
+					Build()
+			}
+		default:
+			return errors.New(fmt.Errorf("push provider '%s': unsupported type '%s'", provider.Name, provider.Type)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "push-provider-type").
+				Context("provider", provider.Name).
+				Context("type", provider.Type).
+				Build()
+		}
+	}
+
+	return nil
+}
+
 // validateMQTTSettings validates the MQTT-specific settings
 func validateMQTTSettings(settings *MQTTSettings) error {
 	if settings.Enabled {
@@ -314,29 +463,224 @@ func validateMQTTSettings(settings *MQTTSettings) error {
 		// No validation required for username/password - they can be empty for anonymous connections
 
 		// Validate retry settings if enabled
-		if settings.RetrySettings.Enabled {
-			if settings.RetrySettings.MaxRetries < 0 {
-				return errors.New(fmt.Errorf("MQTT max retries must be non-negative")).
+		if err := validateRetrySettings(&settings.RetrySettings, "MQTT"); err != nil {
+			return err
+		}
+
+		// Validate Home Assistant discovery prefix, it is the first level of the discovery topics
+		if settings.HomeAssistant.Enabled {
+			prefix := settings.HomeAssistant.DiscoveryPrefix
+			if prefix == "" || strings.ContainsAny(prefix, "#+") {
+				return errors.New(fmt.Errorf("Home Assistant discovery prefix must be a non-empty topic without wildcards")).
 					Category(errors.CategoryValidation).
-					Context("validation_type", "mqtt-max-retries").
+					Context("validation_type", "mqtt-homeassistant-prefix").
 					Build()
 			}
-			if settings.RetrySettings.InitialDelay < 0 {
-				return errors.New(fmt.Errorf("MQTT initial delay must be non-negative")).
+		}
+
+		// Validate spool limits, zero disables a limit
+		if settings.Spool.Enabled {
+			if settings.Spool.MaxMessages < 0 || settings.Spool.MaxSizeMB < 0 || settings.Spool.MaxAge < 0 {
+				return errors.New(fmt.Errorf("MQTT spool limits must be non-negative")).
 					Category(errors.CategoryValidation).
-					Context("validation_type", "mqtt-initial-delay").
+					Context("validation_type", "mqtt-spool-limits").
 					Build()
 			}
-			if settings.RetrySettings.MaxDelay < settings.RetrySettings.InitialDelay {
-				return errors.New(fmt.Errorf("MQTT max delay must be greater than or equal to initial delay")).
+		}
+
+		// Validate detection message templates and payload profile
+		if err := validateMQTTMessageSettings(settings); err != nil {
+			return err
+		}
+
+		// Commands control the station, they must be authenticated and must not use wildcards
+		if settings.Commands.Enabled {
+			if settings.Commands.Token == "" {
+				return errors.New(fmt.Errorf("MQTT commands require a token")).
 					Category(errors.CategoryValidation).
-					Context("validation_type", "mqtt-max-delay").
+					Context("validation_type", "mqtt-commands-token").
 					Build()
 			}
-			if settings.RetrySettings.BackoffMultiplier <= 0 {
-				return errors.New(fmt.Errorf("MQTT backoff multiplier must be positive")).
+			if strings.ContainsAny(settings.Commands.Topic, "#+") {
+				return errors.New(fmt.Errorf("MQTT command topic must not contain wildcards")).
 					Category(errors.CategoryValidation).
-					Context("validation_type", "mqtt-backoff-multiplier").
+					Context("validation_type", "mqtt-commands-topic").
+					Build()
+			}
+		}
+	}
+
+	// Additional brokers are enabled on their own
+	return validateMQTTBrokers(settings.Brokers)
+}
+
+// validateRetrySettings validates the retry settings of an integration, integration names
+// the integration in messages and validation types
+func validateRetrySettings(settings *RetrySettings, integration string) error {
+	if !settings.Enabled {
+		return nil
+	}
+	prefix := strings.ToLower(integration)
+	if settings.MaxRetries < 0 {
+		return errors.New(fmt.Errorf("%s max retries must be non-negative", integration)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", prefix+"-max-retries").
+			Build()
+	}
+	if settings.InitialDelay < 0 {
+		return errors.New(fmt.Errorf("%s initial delay must be non-negative", integration)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", prefix+"-initial-delay").
+			Build()
+	}
+	if settings.MaxDelay < settings.InitialDelay {
+		return errors.New(fmt.Errorf("%s max delay must be greater than or equal to initial delay", integration)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", prefix+"-max-delay").
+			Build()
+	}
+	if settings.BackoffMultiplier <= 0 {
+		return errors.New(fmt.Errorf("%s backoff multiplier must be positive", integration)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", prefix+"-backoff-multiplier").
+			Build()
+	}
+	return nil
+}
+
+// validateMQTTBrokers validates the additional MQTT brokers, names must be unique
+func validateMQTTBrokers(brokers []MQTTBrokerSettings) error {
+	names := make(map[string]bool, len(brokers))
+	for i := range brokers {
+		broker := &brokers[i]
+		if broker.Name == "" || names[strings.ToLower(broker.Name)] {
+			return errors.New(fmt.Errorf("MQTT broker %d must have a unique name", i+1)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "mqtt-broker-name").
+				Build()
+		}
+		names[strings.ToLower(broker.Name)] = true
+
+		if !broker.Enabled {
+			continue
+		}
+		if broker.Broker == "" || (broker.Topic == "" && broker.TopicTemplate == "") {
+			return errors.New(fmt.Errorf("MQTT broker '%s' requires a broker URL and a topic", broker.Name)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "mqtt-broker-required").
+				Context("broker_name", broker.Name).
+				Build()
+		}
+		if err := validateRetrySettings(&broker.RetrySettings, "MQTT"); err != nil {
+			return err
+		}
+		settings := broker.MQTTSettings(false)
+		if err := validateMQTTMessageSettings(&settings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mqttTemplateFuncs lists the functions of MQTT and webhook templates for parsing, the mqtt
+// package provides the implementations
+var mqttTemplateFuncs = template.FuncMap{
+	"json":  func(any) (string, error) { return "", nil },
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"slug":  strings.ToLower,
+}
+
+// validateMQTTMessageSettings validates the payload profile, QoS level and templates of detection messages
+func validateMQTTMessageSettings(settings *MQTTSettings) error {
+	switch settings.PayloadProfile {
+	case "", "full", "compact", "birdnetpi":
+	default:
+		return errors.New(fmt.Errorf("invalid MQTT payload profile '%s', must be full, compact or birdnetpi", settings.PayloadProfile)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "mqtt-payload-profile").
+			Build()
+	}
+
+	if settings.QoS < 0 || settings.QoS > 2 {
+		return errors.New(fmt.Errorf("MQTT QoS must be 0, 1 or 2, got %d", settings.QoS)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "mqtt-qos").
+			Build()
+	}
+
+	templates := []struct {
+		field string
+		text  string
+	}{
+		{"topicTemplate", settings.TopicTemplate},
+		{"payloadTemplate", settings.PayloadTemplate},
+		{"newSpeciesTopic", settings.NewSpeciesTopic},
+	}
+	for _, t := range templates {
+		if t.text == "" {
+			continue
+		}
+		if _, err := template.New(t.field).Funcs(mqttTemplateFuncs).Parse(t.text); err != nil {
+			return errors.New(fmt.Errorf("invalid MQTT %s: %w", t.field, err)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "mqtt-template").
+				Context("template_field", t.field).
+				Build()
+		}
+	}
+	return nil
+}
+
+// validateWebhookSettings validates the webhook endpoints, endpoint names must be unique and
+// Webhook species actions must refer to a configured endpoint
+func validateWebhookSettings(settings *WebhookSettings, species *SpeciesSettings) error {
+	names := make(map[string]bool, len(settings.Endpoints))
+	for i := range settings.Endpoints {
+		endpoint := &settings.Endpoints[i]
+		if endpoint.Name == "" || names[endpoint.Name] {
+			return errors.New(fmt.Errorf("webhook endpoint %d must have a unique name", i+1)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "webhook-name").
+				Build()
+		}
+		names[endpoint.Name] = true
+
+		if u, err := url.Parse(endpoint.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New(fmt.Errorf("webhook endpoint '%s' requires an http or https URL", endpoint.Name)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "webhook-url").
+				Context("endpoint", endpoint.Name).
+				Build()
+		}
+		if endpoint.MinConfidence < 0 || endpoint.MinConfidence > 1 || endpoint.Timeout < 0 {
+			return errors.New(fmt.Errorf("webhook endpoint '%s': min confidence must be between 0 and 1 and timeout non-negative", endpoint.Name)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "webhook-limits").
+				Context("endpoint", endpoint.Name).
+				Build()
+		}
+		if endpoint.Template != "" {
+			if _, err := template.New(endpoint.Name).Funcs(mqttTemplateFuncs).Parse(endpoint.Template); err != nil {
+				return errors.New(fmt.Errorf("webhook endpoint '%s': invalid template: %w", endpoint.Name, err)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "webhook-template").
+					Context("endpoint", endpoint.Name).
+					Build()
+			}
+		}
+		if err := validateRetrySettings(&endpoint.RetrySettings, "Webhook"); err != nil {
+			return err
+		}
+	}
+
+	for speciesName, config := range species.Config {
+		for _, action := range config.Actions {
+			if action.Type == "Webhook" && !names[action.Endpoint] {
+				return errors.New(fmt.Errorf("species config for '%s': unknown webhook endpoint '%s'", speciesName, action.Endpoint)).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "species-config-webhook-endpoint").
+					Context("species_name", speciesName).
 					Build()
 			}
 		}
@@ -357,6 +701,137 @@ func validateSoundLevelSettings(settings *SoundLevelSettings) error {
 				Context("minimum_interval", MinSoundLevelInterval).
 				Build()
 		}
+		if err := validateSoundLevelHistorySettings(&settings.History); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSoundLevelHistorySettings validates the sound level history settings. Stored
+// summaries are merged into hourly summaries, so the resolution must divide an hour.
+func validateSoundLevelHistorySettings(settings *SoundLevelHistorySettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+	switch {
+	case settings.Resolution < MinSoundLevelInterval || settings.Resolution > 3600 || 3600%settings.Resolution != 0:
+		return errors.New(fmt.Errorf("sound level history resolution must divide an hour and be at least %d seconds, got %d", MinSoundLevelInterval, settings.Resolution)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "sound-level-history-resolution").
+			Context("resolution", settings.Resolution).
+			Build()
+	case settings.DownsampleDays < 0:
+		return errors.New(fmt.Errorf("sound level history downsample days must be non-negative, got %d", settings.DownsampleDays)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "sound-level-history-downsample").
+			Context("downsample_days", settings.DownsampleDays).
+			Build()
+	case settings.RetentionDays < 0:
+		return errors.New(fmt.Errorf("sound level history retention days must be non-negative, got %d", settings.RetentionDays)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "sound-level-history-retention").
+			Context("retention_days", settings.RetentionDays).
+			Build()
+	}
+	return nil
+}
+
+// validateNoiseThresholdSettings validates the noise adaptive threshold settings, the ambient
+// levels come from sound level monitoring
+func validateNoiseThresholdSettings(settings *NoiseThresholdSettings, soundLevel *SoundLevelSettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+	var err error
+	switch {
+	case !soundLevel.Enabled:
+		err = fmt.Errorf("noise adaptive thresholds need sound level monitoring to be enabled")
+	case settings.Band != "broadband" && settings.Band != "birdsong":
+		err = fmt.Errorf("noise threshold band must be broadband or birdsong, got %q", settings.Band)
+	case settings.QuietLevel >= settings.NoisyLevel:
+		err = fmt.Errorf("noise threshold quiet level %.1f dB must be below the noisy level %.1f dB", settings.QuietLevel, settings.NoisyLevel)
+	case settings.MaxRaise < 0 || settings.MaxRaise > 1:
+		err = fmt.Errorf("noise threshold max raise must be between 0 and 1, got %f", settings.MaxRaise)
+	case settings.MaxRelax < 0 || settings.MaxRelax > 1:
+		err = fmt.Errorf("noise threshold max relax must be between 0 and 1, got %f", settings.MaxRelax)
+	}
+	if err != nil {
+		return errors.New(err).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "noise-threshold").
+			Build()
+	}
+	return nil
+}
+
+// validateCalibrationSettings validates the species threshold calibration settings, zero values
+// select the defaults
+func validateCalibrationSettings(settings *CalibrationSettings) error {
+	switch {
+	case settings.TargetPrecision < 0 || settings.TargetPrecision > 1:
+		return errors.New(fmt.Errorf("calibration target precision must be between 0 and 1, got %f", settings.TargetPrecision)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "calibration-target-precision").
+			Context("target_precision", settings.TargetPrecision).
+			Build()
+	case settings.MaxThreshold < 0 || settings.MaxThreshold > 1:
+		return errors.New(fmt.Errorf("calibration max threshold must be between 0 and 1, got %f", settings.MaxThreshold)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "calibration-max-threshold").
+			Context("max_threshold", settings.MaxThreshold).
+			Build()
+	case settings.MinReviews < 0:
+		return errors.New(fmt.Errorf("calibration min reviews must be non-negative, got %d", settings.MinReviews)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "calibration-min-reviews").
+			Context("min_reviews", settings.MinReviews).
+			Build()
+	case settings.Interval < 0:
+		return errors.New(fmt.Errorf("calibration interval must be non-negative, got %d", settings.Interval)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "calibration-interval").
+			Context("interval", settings.Interval).
+			Build()
+	}
+	return nil
+}
+
+// validateSuppressionSettings validates the suppression rules, rule IDs must be unique and
+// time windows must be complete
+func validateSuppressionSettings(settings *SuppressionSettings) error {
+	ids := make(map[string]bool, len(settings.Rules))
+	for i := range settings.Rules {
+		rule := &settings.Rules[i]
+		var err error
+		switch {
+		case rule.ID == "":
+			err = fmt.Errorf("suppression rule %d: id is required", i+1)
+		case ids[rule.ID]:
+			err = fmt.Errorf("suppression rule %q: duplicate id", rule.ID)
+		case rule.Species == "":
+			err = fmt.Errorf("suppression rule %q: species is required", rule.ID)
+		case (rule.Start == "") != (rule.End == ""):
+			err = fmt.Errorf("suppression rule %q: start and end must both be set or both be empty", rule.ID)
+		case rule.MaxConfidence < 0 || rule.MaxConfidence > 1:
+			err = fmt.Errorf("suppression rule %q: max confidence must be between 0 and 1, got %f", rule.ID, rule.MaxConfidence)
+		}
+		if err == nil && rule.Start != "" {
+			for _, clock := range []string{rule.Start, rule.End} {
+				if _, parseErr := time.Parse("15:04", clock); parseErr != nil {
+					err = fmt.Errorf("suppression rule %q: invalid time %q, expected HH:MM", rule.ID, clock)
+					break
+				}
+			}
+		}
+		if err != nil {
+			return errors.New(err).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "suppression-rule").
+				Context("rule_index", i).
+				Build()
+		}
+		ids[rule.ID] = true
 	}
 	return nil
 }
@@ -394,6 +869,14 @@ func validateBirdweatherSettings(settings *BirdweatherSettings) error {
 				Context("validation_type", "birdweather-location-accuracy").
 				Build()
 		}
+
+		// Check the outbox replay limits
+		if settings.Outbox.Enabled && (settings.Outbox.ReplayInterval < 1 || settings.Outbox.BatchSize < 1 || settings.Outbox.MaxAttempts < 1) {
+			return errors.New(fmt.Errorf("birdweather outbox replay interval, batch size and max attempts must be positive")).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "birdweather-outbox").
+				Build()
+		}
 	}
 	return nil
 }
@@ -425,6 +908,10 @@ func validateAudioSettings(settings *AudioSettings) error {
 		settings.SoxAudioTypes = formats
 	}
 
+	if err := validateSourceFilterSettings(settings.SourceFilters); err != nil {
+		return err
+	}
+
 	// Validate audio export settings
 	if settings.Export.Enabled {
 		if settings.FfmpegPath == "" {
@@ -473,6 +960,33 @@ func validateAudioSettings(settings *AudioSettings) error {
 	return nil
 }
 
+// validateSourceFilterSettings validates the per-source filter chains, each source may have
+// one entry. Filters are validated when the chains are built.
+func validateSourceFilterSettings(sourceFilters []SourceAudioSettings) error {
+	sources := make(map[string]bool, len(sourceFilters))
+	for i := range sourceFilters {
+		entry := &sourceFilters[i]
+		var err error
+		switch {
+		case entry.Source == "":
+			err = fmt.Errorf("source filter %d: source is required", i+1)
+		case sources[entry.Source]:
+			err = fmt.Errorf("source filter %d: duplicate entry for the source", i+1)
+		case entry.Gain < -40 || entry.Gain > 40:
+			err = fmt.Errorf("source filter %d: gain must be between -40 and 40 dB, got %.1f", i+1, entry.Gain)
+		}
+		if err != nil {
+			return errors.New(err).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "audio-source-filter").
+				Context("filter_index", i).
+				Build()
+		}
+		sources[entry.Source] = true
+	}
+	return nil
+}
+
 // Add this new function
 func validateDashboardSettings(settings *Dashboard) error {
 	// Validate SummaryLimit
@@ -501,6 +1015,37 @@ func validateDashboardSettings(settings *Dashboard) error {
 		}
 	}
 
+	return validateSpectrogramSettings(&settings.Spectrogram)
+}
+
+// validateSpectrogramSettings validates the spectrogram rendering settings, zero values
+// select the renderer defaults
+func validateSpectrogramSettings(settings *SpectrogramSettings) error {
+	var err error
+	switch {
+	case settings.Renderer != "" && settings.Renderer != "auto" && settings.Renderer != "native" && settings.Renderer != "external":
+		err = fmt.Errorf("spectrogram renderer must be auto, native or external, got %q", settings.Renderer)
+	case settings.Format != "" && settings.Format != "png" && settings.Format != "webp":
+		err = fmt.Errorf("spectrogram format must be png or webp, got %q", settings.Format)
+	case settings.FFTSize != 0 && (settings.FFTSize < 64 || settings.FFTSize > 16384 || settings.FFTSize&(settings.FFTSize-1) != 0):
+		err = fmt.Errorf("spectrogram FFT size must be a power of two between 64 and 16384, got %d", settings.FFTSize)
+	case settings.HopSize < 0:
+		err = fmt.Errorf("spectrogram hop size must not be negative, got %d", settings.HopSize)
+	case settings.MinFreq < 0 || settings.MaxFreq < 0:
+		err = fmt.Errorf("spectrogram frequency range must not be negative, got %d-%d Hz", settings.MinFreq, settings.MaxFreq)
+	case settings.MaxFreq > 0 && settings.MinFreq >= settings.MaxFreq:
+		err = fmt.Errorf("spectrogram minimum frequency %d Hz must be below the maximum frequency %d Hz", settings.MinFreq, settings.MaxFreq)
+	case settings.DynamicRange < 0 || settings.DynamicRange > 200:
+		err = fmt.Errorf("spectrogram dynamic range must be between 0 and 200 dB, got %f", settings.DynamicRange)
+	case settings.Colormap != "" && settings.Colormap != "sox" && settings.Colormap != "viridis" && settings.Colormap != "magma" && settings.Colormap != "grayscale":
+		err = fmt.Errorf("spectrogram colormap must be sox, viridis, magma or grayscale, got %q", settings.Colormap)
+	}
+	if err != nil {
+		return errors.New(err).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "dashboard-spectrogram").
+			Build()
+	}
 	return nil
 }
 
@@ -681,6 +1226,54 @@ func validateSpeciesConfigSettings(settings *SpeciesSettings) error {
 				Context("threshold", config.Threshold).
 				Build()
 		}
+
+		// Validate notification action templates so that syntax errors surface at load time
+		for i := range config.Actions {
+			if err := validateSendNotificationAction(speciesName, &config.Actions[i]); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
+
+// validateSendNotificationAction validates the template and priority settings of a SendNotification action
+func validateSendNotificationAction(speciesName string, action *SpeciesAction) error {
+	if action.Type != "SendNotification" {
+		return nil
+	}
+
+	templates := []struct {
+		field string
+		text  string
+	}{
+		{"title", action.Title},
+		{"message", action.Message},
+	}
+
+	for _, t := range templates {
+		if t.text == "" {
+			continue
+		}
+		if _, err := template.New(t.field).Parse(t.text); err != nil {
+			return errors.New(fmt.Errorf("species config for '%s': invalid notification %s template: %w", speciesName, t.field, err)).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "species-config-notification-template").
+				Context("species_name", speciesName).
+				Context("template_field", t.field).
+				Build()
+		}
+	}
+
+	switch strings.ToLower(action.Priority) {
+	case "", "low", "medium", "high", "critical":
+		return nil
+	default:
+		return errors.New(fmt.Errorf("species config for '%s': invalid notification priority '%s'", speciesName, action.Priority)).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "species-config-notification-priority").
+			Context("species_name", speciesName).
+			Context("priority", action.Priority).
+			Build()
+	}
+}
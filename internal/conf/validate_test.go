@@ -220,4 +220,536 @@ func BenchmarkValidateSoundLevelSettingsWithError(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = validateSoundLevelSettings(settings)
 	}
-}
\ No newline at end of file
+}
+
+func TestValidateSendNotificationAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  SpeciesAction
+		wantErr bool
+	}{
+		{
+			name:   "default templates",
+			action: SpeciesAction{Type: "SendNotification"},
+		},
+		{
+			name: "valid templates and priority",
+			action: SpeciesAction{
+				Type:     "SendNotification",
+				Title:    "{{.CommonName}} detected",
+				Message:  "{{.CommonName}} at {{.Source}} ({{.Confidence}}%)",
+				Priority: "Critical",
+			},
+		},
+		{
+			name:    "malformed title template",
+			action:  SpeciesAction{Type: "SendNotification", Title: "{{.CommonName"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown priority",
+			action:  SpeciesAction{Type: "SendNotification", Priority: "urgent"},
+			wantErr: true,
+		},
+		{
+			name:   "other action types are ignored",
+			action: SpeciesAction{Type: "ExecuteCommand", Title: "{{"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &SpeciesSettings{
+				Config: map[string]SpeciesConfig{
+					"northern goshawk": {Actions: []SpeciesAction{tt.action}},
+				},
+			}
+			err := validateSpeciesConfigSettings(settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSpeciesConfigSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePushSettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers []PushProviderConfig
+		wantErr   bool
+	}{
+		{
+			name: "valid providers",
+			providers: []PushProviderConfig{
+				{Name: "hook", Type: "webhook", URL: "https://example.com/hook"},
+				{Name: "phone", Type: "ntfy", URL: "https://ntfy.sh", Topic: "birds"},
+				{Name: "mail", Type: "email", SMTP: PushSMTPSettings{Host: "smtp.example.com", To: []string{"me@example.com"}}},
+				{Name: "apprise", Type: "apprise", URL: "gotifys://push.example.com/token"},
+			},
+		},
+		{
+			name:      "missing name",
+			providers: []PushProviderConfig{{Type: "webhook", URL: "https://example.com"}},
+			wantErr:   true,
+		},
+		{
+			name: "duplicate name",
+			providers: []PushProviderConfig{
+				{Name: "hook", Type: "webhook", URL: "https://example.com/a"},
+				{Name: "hook", Type: "webhook", URL: "https://example.com/b"},
+			},
+			wantErr: true,
+		},
+		{
+			name:      "missing url",
+			providers: []PushProviderConfig{{Name: "hook", Type: "webhook"}},
+			wantErr:   true,
+		},
+		{
+			name:      "email without recipients",
+			providers: []PushProviderConfig{{Name: "mail", Type: "email", SMTP: PushSMTPSettings{Host: "smtp.example.com"}}},
+			wantErr:   true,
+		},
+		{
+			name:      "unknown type",
+			providers: []PushProviderConfig{{Name: "pager", Type: "pagerduty", URL: "https://example.com"}},
+			wantErr:   true,
+		},
+		{
+			name:      "negative timeout",
+			providers: []PushProviderConfig{{Name: "hook", Type: "webhook", URL: "https://example.com", Timeout: -1}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePushSettings(&PushSettings{Enabled: true, Providers: tt.providers})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePushSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Disabled push settings are not validated
+	if err := validatePushSettings(&PushSettings{Providers: []PushProviderConfig{{Type: "bogus"}}}); err != nil {
+		t.Errorf("validatePushSettings() with push disabled returned error: %v", err)
+	}
+}
+
+func TestValidateNotificationPersistenceSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings NotificationPersistenceSettings
+		wantErr  bool
+	}{
+		{"defaults", NotificationPersistenceSettings{Enabled: true, RetentionDays: 30, MaxRecords: 10000}, false},
+		{"no limits", NotificationPersistenceSettings{Enabled: true}, false},
+		{"negative retention", NotificationPersistenceSettings{Enabled: true, RetentionDays: -1}, true},
+		{"negative max records", NotificationPersistenceSettings{Enabled: true, MaxRecords: -5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNotificationPersistenceSettings(&tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateNotificationPersistenceSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateModelConfigs(t *testing.T) {
+	valid := ModelConfig{ID: "amphibians", Enabled: true, ModelPath: "amphibians.tflite", LabelPath: "labels.txt", Threshold: 0.7}
+
+	tests := []struct {
+		name       string
+		models     []ModelConfig
+		wantErrors int
+	}{
+		{"no models", nil, 0},
+		{"valid model", []ModelConfig{valid}, 0},
+		{"disabled model without paths", []ModelConfig{{ID: "bats"}}, 0},
+		{"missing id", []ModelConfig{{Enabled: true, ModelPath: "m.tflite", LabelPath: "l.txt"}}, 1},
+		{"duplicate id", []ModelConfig{valid, valid}, 1},
+		{"missing paths", []ModelConfig{{ID: "bats", Enabled: true}}, 2},
+		{"threshold out of range", []ModelConfig{{ID: "bats", Enabled: true, ModelPath: "m.tflite", LabelPath: "l.txt", Threshold: 1.5}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateModelConfigs(tt.models)
+			if len(errs) != tt.wantErrors {
+				t.Errorf("validateModelConfigs() returned %d errors %v, want %d", len(errs), errs, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestValidateMQTTBrokers(t *testing.T) {
+	valid := MQTTBrokerSettings{Name: "research", Enabled: true, Broker: "ssl://mqtt.example.edu:8883", Topic: "birdnet", QoS: 1}
+
+	tests := []struct {
+		name    string
+		brokers func() []MQTTBrokerSettings
+		wantErr string
+	}{
+		{
+			name:    "valid broker",
+			brokers: func() []MQTTBrokerSettings { return []MQTTBrokerSettings{valid} },
+		},
+		{
+			name: "topic template without topic",
+			brokers: func() []MQTTBrokerSettings {
+				b := valid
+				b.Topic, b.TopicTemplate = "", "birds/{{.ScientificName | slug}}"
+				return []MQTTBrokerSettings{b}
+			},
+		},
+		{
+			name: "disabled broker is not validated",
+			brokers: func() []MQTTBrokerSettings {
+				return []MQTTBrokerSettings{{Name: "spare", QoS: 5}}
+			},
+		},
+		{
+			name: "duplicate name",
+			brokers: func() []MQTTBrokerSettings {
+				b := valid
+				b.Name = "Research"
+				return []MQTTBrokerSettings{valid, b}
+			},
+			wantErr: "mqtt-broker-name",
+		},
+		{
+			name: "missing broker URL",
+			brokers: func() []MQTTBrokerSettings {
+				b := valid
+				b.Broker = ""
+				return []MQTTBrokerSettings{b}
+			},
+			wantErr: "mqtt-broker-required",
+		},
+		{
+			name: "invalid QoS",
+			brokers: func() []MQTTBrokerSettings {
+				b := valid
+				b.QoS = 3
+				return []MQTTBrokerSettings{b}
+			},
+			wantErr: "mqtt-qos",
+		},
+		{
+			name: "invalid retry settings",
+			brokers: func() []MQTTBrokerSettings {
+				b := valid
+				b.RetrySettings = RetrySettings{Enabled: true, MaxRetries: 3, InitialDelay: 30, MaxDelay: 10, BackoffMultiplier: 2}
+				return []MQTTBrokerSettings{b}
+			},
+			wantErr: "mqtt-max-delay",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMQTTBrokers(tt.brokers())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateMQTTBrokers() unexpected error = %v", err)
+				}
+				return
+			}
+			var enhancedErr *errors.EnhancedError
+			if !stderrors.As(err, &enhancedErr) {
+				t.Fatalf("validateMQTTBrokers() error = %v, want validation error %s", err, tt.wantErr)
+			}
+			if got := enhancedErr.GetContext()["validation_type"]; got != tt.wantErr {
+				t.Errorf("validation_type = %v, want %s", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpeciesFilterAllows(t *testing.T) {
+	filter := SpeciesFilter{Include: []string{"eurasian eagle-owl", "Strix aluco"}, Exclude: []string{"Strix aluco"}}
+
+	if !filter.Allows("Eurasian Eagle-Owl", "Bubo bubo") {
+		t.Error("included common name should be allowed")
+	}
+	if filter.Allows("Tawny Owl", "Strix aluco") {
+		t.Error("exclude should win over include")
+	}
+	if filter.Allows("Great Tit", "Parus major") {
+		t.Error("species outside the include list should not be allowed")
+	}
+	if !(&SpeciesFilter{}).Allows("Great Tit", "Parus major") {
+		t.Error("empty filter should allow all species")
+	}
+}
+
+func TestValidateWebhookSettings(t *testing.T) {
+	valid := WebhookEndpoint{Name: "ops", Enabled: true, URL: "https://hooks.example.com/birds", MinConfidence: 0.8, Timeout: 5}
+
+	tests := []struct {
+		name      string
+		endpoints func() []WebhookEndpoint
+		actions   []SpeciesAction
+		wantErr   string
+	}{
+		{
+			name:      "valid endpoint",
+			endpoints: func() []WebhookEndpoint { return []WebhookEndpoint{valid} },
+			actions:   []SpeciesAction{{Type: "Webhook", Endpoint: "ops"}},
+		},
+		{
+			name: "duplicate name",
+			endpoints: func() []WebhookEndpoint {
+				return []WebhookEndpoint{valid, valid}
+			},
+			wantErr: "webhook-name",
+		},
+		{
+			name: "unsupported URL scheme",
+			endpoints: func() []WebhookEndpoint {
+				e := valid
+				e.URL = "ftp://hooks.example.com"
+				return []WebhookEndpoint{e}
+			},
+			wantErr: "webhook-url",
+		},
+		{
+			name: "confidence out of range",
+			endpoints: func() []WebhookEndpoint {
+				e := valid
+				e.MinConfidence = 80
+				return []WebhookEndpoint{e}
+			},
+			wantErr: "webhook-limits",
+		},
+		{
+			name: "invalid template",
+			endpoints: func() []WebhookEndpoint {
+				e := valid
+				e.Template = `{"species": {{json .CommonName}`
+				return []WebhookEndpoint{e}
+			},
+			wantErr: "webhook-template",
+		},
+		{
+			name: "invalid retry settings",
+			endpoints: func() []WebhookEndpoint {
+				e := valid
+				e.RetrySettings = RetrySettings{Enabled: true, MaxRetries: 3, InitialDelay: 1, MaxDelay: 10}
+				return []WebhookEndpoint{e}
+			},
+			wantErr: "webhook-backoff-multiplier",
+		},
+		{
+			name:      "species action with unknown endpoint",
+			endpoints: func() []WebhookEndpoint { return []WebhookEndpoint{valid} },
+			actions:   []SpeciesAction{{Type: "Webhook", Endpoint: "research"}},
+			wantErr:   "species-config-webhook-endpoint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &WebhookSettings{Enabled: true, Endpoints: tt.endpoints()}
+			species := &SpeciesSettings{Config: map[string]SpeciesConfig{"eurasian eagle-owl": {Actions: tt.actions}}}
+			err := validateWebhookSettings(settings, species)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateWebhookSettings() unexpected error = %v", err)
+				}
+				return
+			}
+			var enhancedErr *errors.EnhancedError
+			if !stderrors.As(err, &enhancedErr) {
+				t.Fatalf("validateWebhookSettings() error = %v, want validation error %s", err, tt.wantErr)
+			}
+			if got := enhancedErr.GetContext()["validation_type"]; got != tt.wantErr {
+				t.Errorf("validation_type = %v, want %s", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSuppressionSettings(t *testing.T) {
+	valid := SuppressionRule{ID: "rule-1", Species: "Strix aluco", Source: "garden", Start: "22:30", End: "01:30", MaxConfidence: 0.9}
+
+	tests := []struct {
+		name    string
+		rules   func() []SuppressionRule
+		wantErr bool
+	}{
+		{"no rules", func() []SuppressionRule { return nil }, false},
+		{"valid rule spanning midnight", func() []SuppressionRule { return []SuppressionRule{valid} }, false},
+		{"all day rule", func() []SuppressionRule {
+			r := valid
+			r.Start, r.End = "", ""
+			return []SuppressionRule{r}
+		}, false},
+		{"missing id", func() []SuppressionRule {
+			r := valid
+			r.ID = ""
+			return []SuppressionRule{r}
+		}, true},
+		{"duplicate id", func() []SuppressionRule { return []SuppressionRule{valid, valid} }, true},
+		{"missing species", func() []SuppressionRule {
+			r := valid
+			r.Species = ""
+			return []SuppressionRule{r}
+		}, true},
+		{"start without end", func() []SuppressionRule {
+			r := valid
+			r.End = ""
+			return []SuppressionRule{r}
+		}, true},
+		{"invalid time", func() []SuppressionRule {
+			r := valid
+			r.Start = "25:00"
+			return []SuppressionRule{r}
+		}, true},
+		{"confidence out of range", func() []SuppressionRule {
+			r := valid
+			r.MaxConfidence = 90
+			return []SuppressionRule{r}
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSuppressionSettings(&SuppressionSettings{Rules: tt.rules()})
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("validateSuppressionSettings() unexpected error = %v", err)
+				}
+				return
+			}
+			var enhancedErr *errors.EnhancedError
+			if !stderrors.As(err, &enhancedErr) {
+				t.Fatalf("validateSuppressionSettings() error = %v, want validation error", err)
+			}
+			if got := enhancedErr.GetContext()["validation_type"]; got != "suppression-rule" {
+				t.Errorf("validation_type = %v, want suppression-rule", got)
+			}
+		})
+	}
+}
+
+func TestValidateSpectrogramSettings(t *testing.T) {
+	valid := SpectrogramSettings{Renderer: "auto", Format: "webp", FFTSize: 1024, MaxFreq: 12000, DynamicRange: 100, Colormap: "viridis"}
+
+	tests := []struct {
+		name    string
+		modify  func(s *SpectrogramSettings)
+		wantErr bool
+	}{
+		{"valid settings", func(s *SpectrogramSettings) {}, false},
+		{"zero values select defaults", func(s *SpectrogramSettings) { *s = SpectrogramSettings{} }, false},
+		{"unknown renderer", func(s *SpectrogramSettings) { s.Renderer = "gpu" }, true},
+		{"unknown format", func(s *SpectrogramSettings) { s.Format = "jpeg" }, true},
+		{"FFT size not a power of two", func(s *SpectrogramSettings) { s.FFTSize = 1000 }, true},
+		{"FFT size too small", func(s *SpectrogramSettings) { s.FFTSize = 32 }, true},
+		{"negative hop size", func(s *SpectrogramSettings) { s.HopSize = -1 }, true},
+		{"inverted frequency range", func(s *SpectrogramSettings) { s.MinFreq = 12000 }, true},
+		{"minimum frequency without maximum", func(s *SpectrogramSettings) { s.MinFreq, s.MaxFreq = 500, 0 }, false},
+		{"dynamic range too large", func(s *SpectrogramSettings) { s.DynamicRange = 300 }, true},
+		{"unknown colormap", func(s *SpectrogramSettings) { s.Colormap = "jet" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := valid
+			tt.modify(&settings)
+			err := validateSpectrogramSettings(&settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSpectrogramSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNoiseThresholdSettings(t *testing.T) {
+	valid := NoiseThresholdSettings{Enabled: true, Band: "birdsong", QuietLevel: -70, NoisyLevel: -40, MaxRaise: 0.15, MaxRelax: 0.05}
+
+	tests := []struct {
+		name       string
+		modify     func(s *NoiseThresholdSettings)
+		soundLevel bool
+		wantErr    bool
+	}{
+		{"valid settings", func(s *NoiseThresholdSettings) {}, true, false},
+		{"disabled settings are not checked", func(s *NoiseThresholdSettings) { *s = NoiseThresholdSettings{} }, false, false},
+		{"sound level monitoring disabled", func(s *NoiseThresholdSettings) {}, false, true},
+		{"broadband level", func(s *NoiseThresholdSettings) { s.Band = "broadband" }, true, false},
+		{"unknown band", func(s *NoiseThresholdSettings) { s.Band = "treble" }, true, true},
+		{"quiet level above noisy level", func(s *NoiseThresholdSettings) { s.QuietLevel = -30 }, true, true},
+		{"negative raise", func(s *NoiseThresholdSettings) { s.MaxRaise = -0.1 }, true, true},
+		{"relax above one", func(s *NoiseThresholdSettings) { s.MaxRelax = 1.5 }, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := valid
+			tt.modify(&settings)
+			err := validateNoiseThresholdSettings(&settings, &SoundLevelSettings{Enabled: tt.soundLevel, Interval: 10})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateNoiseThresholdSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSourceFilterSettings(t *testing.T) {
+	camera := SourceAudioSettings{Source: "rtsp://192.168.1.20/stream", Gain: 6}
+	usb := SourceAudioSettings{Source: "hw:1,0", Gain: -3, Equalizer: EqualizerSettings{Enabled: true}}
+
+	tests := []struct {
+		name    string
+		filters []SourceAudioSettings
+		wantErr bool
+	}{
+		{"no source filters", nil, false},
+		{"one entry per source", []SourceAudioSettings{camera, usb}, false},
+		{"missing source", []SourceAudioSettings{{Gain: 3}}, true},
+		{"duplicate source", []SourceAudioSettings{camera, camera}, true},
+		{"gain too high", []SourceAudioSettings{{Source: "hw:1,0", Gain: 60}}, true},
+		{"gain too low", []SourceAudioSettings{{Source: "hw:1,0", Gain: -60}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSourceFilterSettings(tt.filters)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSourceFilterSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSoundLevelHistorySettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings SoundLevelHistorySettings
+		wantErr  bool
+	}{
+		{"disabled history is not validated", SoundLevelHistorySettings{Resolution: 7}, false},
+		{"defaults", SoundLevelHistorySettings{Enabled: true, Resolution: 300, DownsampleDays: 7, RetentionDays: 365}, false},
+		{"hourly resolution", SoundLevelHistorySettings{Enabled: true, Resolution: 3600}, false},
+		{"resolution not dividing an hour", SoundLevelHistorySettings{Enabled: true, Resolution: 420}, true},
+		{"resolution below minimum interval", SoundLevelHistorySettings{Enabled: true, Resolution: 4}, true},
+		{"resolution above an hour", SoundLevelHistorySettings{Enabled: true, Resolution: 7200}, true},
+		{"negative downsample days", SoundLevelHistorySettings{Enabled: true, Resolution: 300, DownsampleDays: -1}, true},
+		{"negative retention days", SoundLevelHistorySettings{Enabled: true, Resolution: 300, RetentionDays: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSoundLevelHistorySettings(&tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSoundLevelHistorySettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+// birdweather_outbox.go provides the database outbox of BirdWeather uploads
+package datastore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BirdWeather upload states
+const (
+	BirdweatherUploadPending = "pending" // Waiting to be replayed
+	BirdweatherUploadPosted  = "posted"  // Posted to BirdWeather
+	BirdweatherUploadFailed  = "failed"  // Replay gave up, see LastError
+)
+
+// birdweatherBackfillBatchSize is the number of notes queued per insert during a backfill
+const birdweatherBackfillBatchSize = 500
+
+// BirdweatherOutbox keeps track of BirdWeather uploads in the database. Detections that
+// could not be uploaded are queued for replay, posted detections are recorded so that
+// replays and backfills do not post them again until they are pruned.
+type BirdweatherOutbox struct {
+	db dbFunc
+}
+
+// BirdweatherOutboxCounts holds the number of uploads by state
+type BirdweatherOutboxCounts struct {
+	Pending int64
+	Posted  int64
+	Failed  int64
+}
+
+// NewBirdweatherOutbox creates an outbox using a database
+func NewBirdweatherOutbox(db *gorm.DB) *BirdweatherOutbox {
+	return &BirdweatherOutbox{db: fixedDB(db)}
+}
+
+// NewBirdweatherOutboxFor creates an outbox using the database of an opened SQLite or
+// MySQL datastore
+func NewBirdweatherOutboxFor(store Interface) (*BirdweatherOutbox, error) {
+	db := datastoreDB(store)
+	if db == nil {
+		return nil, errors.Newf("datastore does not provide an open database connection").
+			Component("datastore").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_birdweather_outbox").
+			Context("store_type", fmt.Sprintf("%T", store)).
+			Build()
+	}
+
+	return &BirdweatherOutbox{db: db}, nil
+}
+
+// MarkPosted records that a detection was posted to BirdWeather
+func (o *BirdweatherOutbox) MarkPosted(note *Note) error {
+	now := time.Now()
+	return o.update(note, "mark_birdweather_posted", func(upload *BirdweatherUpload) {
+		upload.Status = BirdweatherUploadPosted
+		upload.LastError = ""
+		upload.PostedAt = &now
+	})
+}
+
+// Enqueue queues a detection whose upload failed for replay. Detections that were
+// posted in the meantime are left as they are.
+func (o *BirdweatherOutbox) Enqueue(note *Note, cause error) error {
+	return o.update(note, "enqueue_birdweather_upload", func(upload *BirdweatherUpload) {
+		if upload.Status == BirdweatherUploadPosted {
+			return
+		}
+		upload.Status = BirdweatherUploadPending
+		upload.NextAttempt = time.Now()
+		if cause != nil {
+			upload.LastError = cause.Error()
+		}
+	})
+}
+
+// update applies fn to the upload of a note, creating the upload if the note has none
+func (o *BirdweatherOutbox) update(note *Note, operation string, fn func(*BirdweatherUpload)) error {
+	err := o.db().Transaction(func(tx *gorm.DB) error {
+		var upload BirdweatherUpload
+		if err := tx.Where("date = ? AND time = ? AND scientific_name = ?", note.Date, note.Time, note.ScientificName).
+			Limit(1).Find(&upload).Error; err != nil {
+			return err
+		}
+		if upload.ID == 0 {
+			upload = BirdweatherUpload{
+				Date:           note.Date,
+				Time:           note.Time,
+				ScientificName: note.ScientificName,
+				CommonName:     note.CommonName,
+				Confidence:     note.Confidence,
+				ClipName:       note.ClipName,
+			}
+		}
+		if upload.NoteID == 0 {
+			upload.NoteID = o.noteID(tx, note)
+		}
+		fn(&upload)
+		return tx.Save(&upload).Error
+	})
+	if err != nil {
+		return dbError(err, operation, errors.PriorityLow,
+			"table", "birdweather_uploads",
+			"species", note.ScientificName)
+	}
+	return nil
+}
+
+// noteID returns the database ID of a note, 0 if the note is not saved yet
+func (o *BirdweatherOutbox) noteID(tx *gorm.DB, note *Note) uint {
+	if note.ID != 0 {
+		return note.ID
+	}
+	var ids []uint
+	if err := tx.Model(&Note{}).
+		Where("date = ? AND time = ? AND scientific_name = ?", note.Date, note.Time, note.ScientificName).
+		Order("id DESC").Limit(1).Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return 0
+	}
+	return ids[0]
+}
+
+// Due returns up to limit pending uploads whose next attempt is due, oldest detections first
+func (o *BirdweatherOutbox) Due(now time.Time, limit int) ([]BirdweatherUpload, error) {
+	var uploads []BirdweatherUpload
+	if err := o.db().Where("status = ? AND next_attempt <= ?", BirdweatherUploadPending, now).
+		Order("date ASC, time ASC").
+		Limit(limit).
+		Find(&uploads).Error; err != nil {
+		return nil, dbError(err, "get_due_birdweather_uploads", errors.PriorityLow,
+			"table", "birdweather_uploads")
+	}
+	return uploads, nil
+}
+
+// Posted marks a replayed upload as posted
+func (o *BirdweatherOutbox) Posted(id uint) error {
+	return o.updateByID(id, "mark_birdweather_replay_posted", map[string]any{
+		"status":     BirdweatherUploadPosted,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": "",
+		"posted_at":  time.Now(),
+	})
+}
+
+// Reschedule records a failed replay, the upload is replayed again at next
+func (o *BirdweatherOutbox) Reschedule(id uint, cause error, next time.Time) error {
+	return o.updateByID(id, "reschedule_birdweather_upload", map[string]any{
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   cause.Error(),
+		"next_attempt": next,
+	})
+}
+
+// Fail records a failed replay and stops replaying the upload
+func (o *BirdweatherOutbox) Fail(id uint, cause error) error {
+	return o.updateByID(id, "fail_birdweather_upload", map[string]any{
+		"status":     BirdweatherUploadFailed,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": cause.Error(),
+	})
+}
+
+// PrunePosted deletes the uploads posted before the given time and returns how many were
+// deleted. Backfills reaching back past pruned uploads post those detections again.
+func (o *BirdweatherOutbox) PrunePosted(before time.Time) (int64, error) {
+	result := o.db().Where("status = ? AND posted_at < ?", BirdweatherUploadPosted, before).
+		Delete(&BirdweatherUpload{})
+	if result.Error != nil {
+		return 0, dbError(result.Error, "prune_birdweather_uploads", errors.PriorityLow,
+			"table", "birdweather_uploads")
+	}
+	return result.RowsAffected, nil
+}
+
+// updateByID updates the columns of an upload
+func (o *BirdweatherOutbox) updateByID(id uint, operation string, columns map[string]any) error {
+	if err := o.db().Model(&BirdweatherUpload{}).Where("id = ?", id).Updates(columns).Error; err != nil {
+		return dbError(err, operation, errors.PriorityLow,
+			"table", "birdweather_uploads",
+			"upload_id", id)
+	}
+	return nil
+}
+
+// Counts returns the number of uploads by state
+func (o *BirdweatherOutbox) Counts() (BirdweatherOutboxCounts, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := o.db().Model(&BirdweatherUpload{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return BirdweatherOutboxCounts{}, dbError(err, "count_birdweather_uploads", errors.PriorityLow,
+			"table", "birdweather_uploads")
+	}
+
+	var counts BirdweatherOutboxCounts
+	for _, row := range rows {
+		switch row.Status {
+		case BirdweatherUploadPending:
+			counts.Pending = row.Count
+		case BirdweatherUploadPosted:
+			counts.Posted = row.Count
+		case BirdweatherUploadFailed:
+			counts.Failed = row.Count
+		}
+	}
+	return counts, nil
+}
+
+// Backfill queues the detections between startDate and endDate (inclusive, YYYY-MM-DD)
+// with at least minConfidence and a saved audio clip for upload. Detections that already
+// have an upload are skipped. It returns the number of queued detections.
+func (o *BirdweatherOutbox) Backfill(startDate, endDate string, minConfidence float64) (int, error) {
+	queued := 0
+	var notes []Note
+	now := time.Now()
+	result := o.db().Model(&Note{}).
+		Select("id, date, time, scientific_name, common_name, confidence, clip_name").
+		Where("date >= ? AND date <= ? AND confidence >= ? AND clip_name <> ''", startDate, endDate, minConfidence).
+		Where("NOT EXISTS (SELECT 1 FROM birdweather_uploads u WHERE u.date = notes.date AND u.time = notes.time AND u.scientific_name = notes.scientific_name)").
+		Order("id ASC").
+		FindInBatches(&notes, birdweatherBackfillBatchSize, func(tx *gorm.DB, batch int) error {
+			uploads := make([]BirdweatherUpload, 0, len(notes))
+			for i := range notes {
+				uploads = append(uploads, BirdweatherUpload{
+					NoteID:         notes[i].ID,
+					Date:           notes[i].Date,
+					Time:           notes[i].Time,
+					ScientificName: notes[i].ScientificName,
+					CommonName:     notes[i].CommonName,
+					Confidence:     notes[i].Confidence,
+					ClipName:       notes[i].ClipName,
+					Status:         BirdweatherUploadPending,
+					NextAttempt:    now,
+				})
+			}
+			// Detections of the same second may share the key, the first one is kept
+			created := o.db().Clauses(clause.OnConflict{DoNothing: true}).Create(&uploads)
+			queued += int(created.RowsAffected)
+			return created.Error
+		})
+	if result.Error != nil {
+		return queued, dbError(result.Error, "backfill_birdweather_uploads", errors.PriorityMedium,
+			"table", "birdweather_uploads",
+			"start_date", startDate,
+			"end_date", endDate)
+	}
+	return queued, nil
+}
@@ -0,0 +1,128 @@
+package datastore
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupBirdweatherOutbox creates an outbox backed by a temporary SQLite database with notes
+func setupBirdweatherOutbox(t *testing.T) (*BirdweatherOutbox, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "outbox.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Note{}, &BirdweatherUpload{}))
+	return NewBirdweatherOutbox(db), db
+}
+
+func TestBirdweatherOutbox_EnqueueAndReplay(t *testing.T) {
+	t.Parallel()
+
+	outbox, db := setupBirdweatherOutbox(t)
+	note := Note{Date: "2024-05-01", Time: "06:30:00", ScientificName: "Bubo bubo", CommonName: "Eurasian Eagle-Owl", Confidence: 0.9, ClipName: "owl.wav"}
+	require.NoError(t, db.Create(&note).Error)
+
+	// The saved note is found by its detection key
+	live := note
+	live.ID = 0
+	require.NoError(t, outbox.Enqueue(&live, fmt.Errorf("connection refused")))
+	require.NoError(t, outbox.Enqueue(&live, fmt.Errorf("timeout")))
+
+	due, err := outbox.Due(time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, note.ID, due[0].NoteID)
+	assert.Equal(t, "owl.wav", due[0].ClipName)
+	assert.Equal(t, "timeout", due[0].LastError)
+
+	// Rescheduled uploads are not due until their next attempt
+	require.NoError(t, outbox.Reschedule(due[0].ID, fmt.Errorf("still offline"), time.Now().Add(time.Hour)))
+	due, err = outbox.Due(time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = outbox.Due(time.Now().Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+	require.NoError(t, outbox.Posted(due[0].ID))
+
+	// Posted detections are not queued again
+	require.NoError(t, outbox.Enqueue(&live, fmt.Errorf("late retry")))
+	counts, err := outbox.Counts()
+	require.NoError(t, err)
+	assert.Equal(t, BirdweatherOutboxCounts{Posted: 1}, counts)
+}
+
+func TestBirdweatherOutbox_PrunePosted(t *testing.T) {
+	t.Parallel()
+
+	outbox, db := setupBirdweatherOutbox(t)
+	owl := &Note{Date: "2024-05-01", Time: "06:30:00", ScientificName: "Bubo bubo"}
+	tit := &Note{Date: "2024-05-01", Time: "06:31:00", ScientificName: "Parus major"}
+	robin := &Note{Date: "2024-05-01", Time: "06:32:00", ScientificName: "Erithacus rubecula"}
+	require.NoError(t, outbox.MarkPosted(owl))
+	require.NoError(t, outbox.MarkPosted(tit))
+	require.NoError(t, outbox.Enqueue(robin, fmt.Errorf("offline")))
+
+	// The owl was posted long ago
+	month := time.Now().Add(-31 * 24 * time.Hour)
+	require.NoError(t, db.Model(&BirdweatherUpload{}).Where("scientific_name = ?", "Bubo bubo").
+		Update("posted_at", month).Error)
+
+	pruned, err := outbox.PrunePosted(time.Now().Add(-30 * 24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	// Recently posted and pending uploads are kept
+	counts, err := outbox.Counts()
+	require.NoError(t, err)
+	assert.Equal(t, BirdweatherOutboxCounts{Pending: 1, Posted: 1}, counts)
+}
+
+func TestBirdweatherOutbox_Backfill(t *testing.T) {
+	t.Parallel()
+
+	outbox, db := setupBirdweatherOutbox(t)
+	notes := []Note{
+		{Date: "2024-04-30", Time: "23:59:00", ScientificName: "Strix aluco", Confidence: 0.9, ClipName: "a.wav"},
+		{Date: "2024-05-01", Time: "05:00:00", ScientificName: "Strix aluco", Confidence: 0.9, ClipName: "b.wav"},
+		{Date: "2024-05-01", Time: "05:10:00", ScientificName: "Parus major", Confidence: 0.5, ClipName: "c.wav"},
+		{Date: "2024-05-02", Time: "06:00:00", ScientificName: "Parus major", Confidence: 0.8},
+		{Date: "2024-05-02", Time: "07:00:00", ScientificName: "Bubo bubo", Confidence: 0.95, ClipName: "d.wav"},
+		{Date: "2024-05-03", Time: "07:00:00", ScientificName: "Bubo bubo", Confidence: 0.95, ClipName: "e.wav"},
+	}
+	require.NoError(t, db.Create(&notes).Error)
+
+	// A detection posted live is not queued again
+	require.NoError(t, outbox.MarkPosted(&notes[4]))
+
+	queued, err := outbox.Backfill("2024-05-01", "2024-05-02", 0.7)
+	require.NoError(t, err)
+	assert.Equal(t, 1, queued, "only the confident detection with a clip that was not posted")
+
+	queued, err = outbox.Backfill("2024-05-01", "2024-05-02", 0.7)
+	require.NoError(t, err)
+	assert.Zero(t, queued, "backfills are idempotent")
+
+	counts, err := outbox.Counts()
+	require.NoError(t, err)
+	assert.Equal(t, BirdweatherOutboxCounts{Pending: 1, Posted: 1}, counts)
+
+	due, err := outbox.Due(time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "b.wav", due[0].ClipName)
+	require.NoError(t, outbox.Fail(due[0].ID, fmt.Errorf("clip missing")))
+
+	counts, err = outbox.Counts()
+	require.NoError(t, err)
+	assert.Equal(t, BirdweatherOutboxCounts{Posted: 1, Failed: 1}, counts)
+}
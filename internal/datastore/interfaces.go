@@ -53,6 +53,7 @@ type Interface interface {
 	GetAllDetectedSpecies() ([]Note, error)
 	SearchNotes(query string, sortAscending bool, limit int, offset int) ([]Note, error)
 	SearchNotesAdvanced(filters *AdvancedSearchFilters) ([]Note, int64, error)
+	StreamNotesAdvanced(ctx context.Context, filters *AdvancedSearchFilters, batchSize int, fn func([]Note) error) error
 	GetNoteClipPath(noteID string) (string, error)
 	DeleteNoteClipPath(noteID string) error
 	GetNoteReview(noteID string) (*NoteReview, error)
@@ -210,6 +211,23 @@ func (ds *DataStore) Save(note *Note, results []Results) error {
 	return ds.handleMaxRetriesExhausted(lastErr, txID, txStart, txLogger)
 }
 
+// SaveNotes stores notes without results in a single transaction, either all notes are
+// saved or none of them
+func (ds *DataStore) SaveNotes(notes []Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+	err := ds.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&notes, 100).Error
+	})
+	if err != nil {
+		return dbError(err, "save_notes", errors.PriorityMedium,
+			"table", "notes",
+			"count", fmt.Sprintf("%d", len(notes)))
+	}
+	return nil
+}
+
 // Get retrieves a note by its ID from the database.
 func (ds *DataStore) Get(id string) (Note, error) {
 	// Convert the id from string to integer
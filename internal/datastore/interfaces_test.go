@@ -3,6 +3,8 @@ package datastore
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tphakala/birdnet-go/internal/conf"
 )
 
@@ -30,3 +32,19 @@ func createDatabase(t *testing.T, settings *conf.Settings) Interface {
 
 	return dataStore
 }
+
+func TestSaveNotes_SingleTransaction(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{}).(*SQLiteStore)
+	frog := Note{Date: "2024-05-01", Time: "05:30:03", ScientificName: "Rana temporaria", CommonName: "Common Frog", Confidence: 0.91}
+
+	require.NoError(t, store.SaveNotes([]Note{frog, frog}))
+
+	// A failing note rolls back the notes saved before it
+	first, duplicate := frog, frog
+	duplicate.ID = 1
+	require.Error(t, store.SaveNotes([]Note{first, duplicate}))
+
+	var count int64
+	require.NoError(t, store.DB.Model(&Note{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
@@ -461,6 +461,9 @@ func migrateTables(db *gorm.DB, dbType string, lgr *slog.Logger) (int, error) {
 		{&HourlyWeather{}, "hourly_weather"},
 		{&NoteLock{}, "note_locks"},
 		{&ImageCache{}, "image_caches"},
+		{&NotificationRecord{}, "notification_records"},
+		{&BirdweatherUpload{}, "birdweather_uploads"},
+		{&SoundLevelRecord{}, "sound_level_records"},
 	}
 	
 	lgr.Info("Starting table migrations",
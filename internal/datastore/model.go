@@ -19,6 +19,7 @@ type Note struct {
 	Time       string `gorm:"index:idx_notes_time"`
 	//InputFile      string
 	Source      AudioSource `gorm:"-"` // Runtime only, not stored in database
+	SourceID    string      // ID of the audio source, empty for detections stored before it was recorded
 	BeginTime   time.Time
 	EndTime     time.Time
 	SpeciesCode string
@@ -32,6 +33,7 @@ type Note struct {
 	Sensitivity    float64
 	ClipName       string
 	ProcessingTime time.Duration
+	Model          string        `gorm:"index:idx_notes_model"`         // ID of the classifier model that produced the detection
 	Occurrence     float64       `gorm:"-" json:"occurrence,omitempty"` // Runtime only, occurrence probability (0-1) based on location/time
 	Results        []Results     `gorm:"foreignKey:NoteID;constraint:OnDelete:CASCADE"`
 	Review         *NoteReview   `gorm:"foreignKey:NoteID;constraint:OnDelete:CASCADE"` // One-to-one relationship with cascade delete
@@ -134,6 +136,59 @@ type ImageCache struct {
 	CachedAt       time.Time `gorm:"index"` // When the image was cached
 }
 
+// NotificationRecord represents a persisted system notification
+// GORM will automatically create table name as 'notification_records'
+type NotificationRecord struct {
+	ID        string     `gorm:"primaryKey;type:varchar(36)"` // Notification UUID
+	Type      string     `gorm:"type:varchar(20);index"`      // Values: "error", "warning", "info", "detection", "system"
+	Priority  string     `gorm:"type:varchar(20);index"`      // Values: "critical", "high", "medium", "low"
+	Status    string     `gorm:"type:varchar(20);index"`      // Values: "unread", "read", "acknowledged"
+	Title     string     `gorm:"type:text"`
+	Message   string     `gorm:"type:text"`
+	Component string     `gorm:"type:varchar(100);index"` // Source component, e.g. "database", "mqtt"
+	Metadata  string     `gorm:"type:text"`               // JSON encoded metadata
+	Timestamp time.Time  `gorm:"index"`                   // When the notification was created
+	ExpiresAt *time.Time `gorm:"index"`                   // When the notification expires, nil if it does not expire
+}
+
+// BirdweatherUpload records the BirdWeather upload of a detection. Uploads that failed are
+// replayed from the saved audio clip, posted uploads are kept so detections are not posted twice.
+// GORM will automatically create table name as 'birdweather_uploads'
+type BirdweatherUpload struct {
+	ID             uint   `gorm:"primaryKey"`
+	NoteID         uint   `gorm:"index"` // Note of the detection, 0 until the note is found in the database
+	Date           string `gorm:"type:varchar(10);uniqueIndex:idx_birdweather_uploads_detection"`
+	Time           string `gorm:"type:varchar(8);uniqueIndex:idx_birdweather_uploads_detection"`
+	ScientificName string `gorm:"type:varchar(100);uniqueIndex:idx_birdweather_uploads_detection"`
+	CommonName     string `gorm:"type:varchar(100)"`
+	Confidence     float64
+	ClipName       string     `gorm:"type:varchar(255)"`      // Saved audio clip the soundscape is replayed from
+	Status         string     `gorm:"type:varchar(10);index"` // Values: "pending", "posted", "failed"
+	Attempts       int        // Replay attempts so far
+	LastError      string     `gorm:"type:text"`
+	NextAttempt    time.Time  `gorm:"index"` // When a pending upload is replayed next
+	PostedAt       *time.Time // When the detection was posted, nil until posted
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// SoundLevelRecord is a summary of the sound level of an audio source over a period.
+// Summaries are stored at the configured resolution and merged into hourly summaries as
+// they age. Levels are in dB relative to full scale.
+// GORM will automatically create table name as 'sound_level_records'
+type SoundLevelRecord struct {
+	ID       uint      `gorm:"primaryKey"`
+	Source   string    `gorm:"type:varchar(255);index:idx_sound_level_records_source_start,priority:1"` // Source ID, e.g. "rtsp_87b89761"
+	Name     string    `gorm:"type:varchar(255)"`                                                       // Display name of the source
+	Start    time.Time `gorm:"index:idx_sound_level_records_source_start,priority:2"`                   // Start of the period
+	Duration int       // Length of the period in seconds
+	Measured int       // Seconds of the period that were measured
+	Leq      float64   // Equivalent continuous level of all bands
+	Lmin     float64   // Lowest 1-second level of all bands
+	Lmax     float64   // Highest 1-second level of all bands
+	Bands    string    `gorm:"type:text"` // JSON encoded SoundLevelBand values by band key
+}
+
 // ImageCacheQuery encapsulates parameters for querying the image cache.
 type ImageCacheQuery struct {
 	ScientificName string
@@ -0,0 +1,322 @@
+// notification_store.go provides a database-backed notification store
+package datastore
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/notification"
+	"gorm.io/gorm"
+)
+
+// NotificationStore persists notifications in the database so that they survive
+// restarts. It implements notification.NotificationStore and enforces retention
+// by age and count when expired notifications are cleaned up.
+type NotificationStore struct {
+	db         dbFunc
+	retention  time.Duration // Maximum age of stored notifications, 0 for no limit
+	maxRecords int           // Maximum number of stored notifications, 0 for no limit
+}
+
+// Compile-time check that NotificationStore implements notification.NotificationStore
+var _ notification.NotificationStore = (*NotificationStore)(nil)
+
+// NewNotificationStore creates a database-backed notification store.
+// retention and maxRecords limit how many notifications are kept, zero disables a limit.
+func NewNotificationStore(db *gorm.DB, retention time.Duration, maxRecords int) *NotificationStore {
+	return &NotificationStore{
+		db:         fixedDB(db),
+		retention:  retention,
+		maxRecords: maxRecords,
+	}
+}
+
+// NewNotificationStoreFor creates a notification store using the database of an
+// opened SQLite or MySQL datastore
+func NewNotificationStoreFor(store Interface, retention time.Duration, maxRecords int) (*NotificationStore, error) {
+	db := datastoreDB(store)
+	if db == nil {
+		return nil, errors.Newf("datastore does not provide an open database connection").
+			Component("datastore").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_notification_store").
+			Context("store_type", fmt.Sprintf("%T", store)).
+			Build()
+	}
+
+	return &NotificationStore{
+		db:         db,
+		retention:  retention,
+		maxRecords: maxRecords,
+	}, nil
+}
+
+// Save persists a notification. Toast notifications are ephemeral UI messages
+// delivered over SSE only and are not written to the database.
+func (s *NotificationStore) Save(notif *notification.Notification) error {
+	if isToastRecord(notif) {
+		return nil
+	}
+
+	record, err := toNotificationRecord(notif)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db().Create(record).Error; err != nil {
+		return dbError(err, "save_notification", errors.PriorityMedium,
+			"table", "notification_records",
+			"notification_id", notif.ID)
+	}
+	return nil
+}
+
+// Get retrieves a notification by ID
+func (s *NotificationStore) Get(id string) (*notification.Notification, error) {
+	var record NotificationRecord
+	if err := s.db().Where("id = ?", id).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notification.ErrNotificationNotFound
+		}
+		return nil, dbError(err, "get_notification", errors.PriorityLow,
+			"table", "notification_records",
+			"notification_id", id)
+	}
+	return fromNotificationRecord(&record), nil
+}
+
+// List returns notifications matching the filter, newest first
+func (s *NotificationStore) List(filter *notification.FilterOptions) ([]*notification.Notification, error) {
+	query := s.applyFilter(s.db().Model(&NotificationRecord{}), filter).Order("timestamp DESC")
+	if filter != nil {
+		if filter.Offset > 0 {
+			query = query.Offset(filter.Offset)
+		}
+		if filter.Limit > 0 {
+			query = query.Limit(filter.Limit)
+		}
+	}
+
+	var records []NotificationRecord
+	if err := query.Find(&records).Error; err != nil {
+		return nil, dbError(err, "list_notifications", errors.PriorityLow,
+			"table", "notification_records")
+	}
+
+	results := make([]*notification.Notification, 0, len(records))
+	for i := range records {
+		results = append(results, fromNotificationRecord(&records[i]))
+	}
+	return results, nil
+}
+
+// Count returns the number of notifications matching the filter, ignoring Limit and Offset
+func (s *NotificationStore) Count(filter *notification.FilterOptions) (int, error) {
+	var count int64
+	if err := s.applyFilter(s.db().Model(&NotificationRecord{}), filter).Count(&count).Error; err != nil {
+		return 0, dbError(err, "count_notifications", errors.PriorityLow,
+			"table", "notification_records")
+	}
+	return int(count), nil
+}
+
+// Update replaces a stored notification
+func (s *NotificationStore) Update(notif *notification.Notification) error {
+	record, err := toNotificationRecord(notif)
+	if err != nil {
+		return err
+	}
+
+	result := s.db().Model(&NotificationRecord{}).Where("id = ?", notif.ID).Select("*").Updates(record)
+	if result.Error != nil {
+		return dbError(result.Error, "update_notification", errors.PriorityMedium,
+			"table", "notification_records",
+			"notification_id", notif.ID)
+	}
+	if result.RowsAffected == 0 {
+		return notFoundError("notification", notif.ID)
+	}
+	return nil
+}
+
+// Delete removes a notification
+func (s *NotificationStore) Delete(id string) error {
+	if err := s.db().Where("id = ?", id).Delete(&NotificationRecord{}).Error; err != nil {
+		return dbError(err, "delete_notification", errors.PriorityMedium,
+			"table", "notification_records",
+			"notification_id", id)
+	}
+	return nil
+}
+
+// DeleteExpired removes expired notifications and prunes notifications that
+// exceed the configured retention age or maximum record count
+func (s *NotificationStore) DeleteExpired() error {
+	now := time.Now()
+
+	if err := s.db().Where("expires_at IS NOT NULL AND expires_at < ?", now).
+		Delete(&NotificationRecord{}).Error; err != nil {
+		return dbError(err, "delete_expired_notifications", errors.PriorityLow,
+			"table", "notification_records")
+	}
+
+	if s.retention > 0 {
+		if err := s.db().Where("timestamp < ?", now.Add(-s.retention)).
+			Delete(&NotificationRecord{}).Error; err != nil {
+			return dbError(err, "prune_notifications_by_age", errors.PriorityLow,
+				"table", "notification_records",
+				"retention", s.retention.String())
+		}
+	}
+
+	if s.maxRecords > 0 {
+		return s.pruneToMaxRecords()
+	}
+	return nil
+}
+
+// pruneToMaxRecords deletes the oldest notifications beyond the maximum record count
+func (s *NotificationStore) pruneToMaxRecords() error {
+	var count int64
+	if err := s.db().Model(&NotificationRecord{}).Count(&count).Error; err != nil {
+		return dbError(err, "count_notifications", errors.PriorityLow,
+			"table", "notification_records")
+	}
+	excess := int(count) - s.maxRecords
+	if excess <= 0 {
+		return nil
+	}
+
+	var ids []string
+	if err := s.db().Model(&NotificationRecord{}).
+		Order("timestamp ASC").
+		Limit(excess).
+		Pluck("id", &ids).Error; err != nil {
+		return dbError(err, "select_notifications_to_prune", errors.PriorityLow,
+			"table", "notification_records")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := s.db().Where("id IN ?", ids).Delete(&NotificationRecord{}).Error; err != nil {
+		return dbError(err, "prune_notifications_by_count", errors.PriorityLow,
+			"table", "notification_records",
+			"max_records", s.maxRecords)
+	}
+
+	getLogger().Debug("Pruned notifications exceeding max records",
+		"deleted", len(ids),
+		"max_records", s.maxRecords)
+	return nil
+}
+
+// GetUnreadCount returns the count of unread notifications
+func (s *NotificationStore) GetUnreadCount() (int, error) {
+	var count int64
+	if err := s.db().Model(&NotificationRecord{}).
+		Where("status = ?", string(notification.StatusUnread)).
+		Count(&count).Error; err != nil {
+		return 0, dbError(err, "count_unread_notifications", errors.PriorityLow,
+			"table", "notification_records")
+	}
+	return int(count), nil
+}
+
+// applyFilter adds the filter conditions to a query
+func (s *NotificationStore) applyFilter(query *gorm.DB, filter *notification.FilterOptions) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if len(filter.Priorities) > 0 {
+		query = query.Where("priority IN ?", filter.Priorities)
+	}
+	if len(filter.Status) > 0 {
+		query = query.Where("status IN ?", filter.Status)
+	}
+	if filter.Component != "" {
+		query = query.Where("component = ?", filter.Component)
+	}
+	if filter.Since != nil {
+		query = query.Where("timestamp >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("timestamp <= ?", *filter.Until)
+	}
+	return query
+}
+
+// isToastRecord reports whether a notification is a toast
+func isToastRecord(notif *notification.Notification) bool {
+	if notif == nil || notif.Metadata == nil {
+		return false
+	}
+	isToast, ok := notif.Metadata[notification.MetadataKeyIsToast].(bool)
+	return ok && isToast
+}
+
+// toNotificationRecord converts a notification into its database representation
+func toNotificationRecord(notif *notification.Notification) (*NotificationRecord, error) {
+	if notif == nil || notif.ID == "" {
+		return nil, validationError("notification ID cannot be empty", "notification_id", "")
+	}
+
+	record := &NotificationRecord{
+		ID:        notif.ID,
+		Type:      string(notif.Type),
+		Priority:  string(notif.Priority),
+		Status:    string(notif.Status),
+		Title:     notif.Title,
+		Message:   notif.Message,
+		Component: notif.Component,
+		Timestamp: notif.Timestamp,
+		ExpiresAt: notif.ExpiresAt,
+	}
+
+	if len(notif.Metadata) > 0 {
+		data, err := json.Marshal(notif.Metadata)
+		if err != nil {
+			return nil, errors.New(err).
+				Component("datastore").
+				Category(errors.CategoryValidation).
+				Context("operation", "encode_notification_metadata").
+				Context("notification_id", notif.ID).
+				Build()
+		}
+		record.Metadata = string(data)
+	}
+
+	return record, nil
+}
+
+// fromNotificationRecord converts a database record back into a notification
+func fromNotificationRecord(record *NotificationRecord) *notification.Notification {
+	notif := &notification.Notification{
+		ID:        record.ID,
+		Type:      notification.Type(record.Type),
+		Priority:  notification.Priority(record.Priority),
+		Status:    notification.Status(record.Status),
+		Title:     record.Title,
+		Message:   record.Message,
+		Component: record.Component,
+		Timestamp: record.Timestamp,
+		ExpiresAt: record.ExpiresAt,
+		Metadata:  make(map[string]any),
+	}
+
+	if record.Metadata != "" {
+		if err := json.Unmarshal([]byte(record.Metadata), &notif.Metadata); err != nil {
+			getLogger().Warn("Failed to decode notification metadata",
+				"notification_id", record.ID,
+				"error", err)
+		}
+	}
+
+	return notif
+}
@@ -0,0 +1,186 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/notification"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupNotificationStore creates a notification store backed by an in-memory SQLite database
+func setupNotificationStore(t *testing.T, retention time.Duration, maxRecords int) *NotificationStore {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&NotificationRecord{}))
+
+	return NewNotificationStore(db, retention, maxRecords)
+}
+
+func TestNotificationStore_SaveGetRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	store := setupNotificationStore(t, 0, 0)
+
+	notif := notification.NewNotification(notification.TypeError, notification.PriorityCritical,
+		"Database unavailable", "connection refused").
+		WithComponent("database").
+		WithMetadata("retries", 3).
+		WithExpiry(time.Hour)
+	require.NoError(t, store.Save(notif))
+
+	got, err := store.Get(notif.ID)
+	require.NoError(t, err)
+	assert.Equal(t, notif.Title, got.Title)
+	assert.Equal(t, notif.Message, got.Message)
+	assert.Equal(t, notification.TypeError, got.Type)
+	assert.Equal(t, notification.PriorityCritical, got.Priority)
+	assert.Equal(t, notification.StatusUnread, got.Status)
+	assert.Equal(t, "database", got.Component)
+	assert.InDelta(t, 3, got.Metadata["retries"], 0)
+	require.NotNil(t, got.ExpiresAt)
+
+	_, err = store.Get("missing")
+	require.ErrorIs(t, err, notification.ErrNotificationNotFound)
+}
+
+func TestNotificationStore_SkipsToasts(t *testing.T) {
+	t.Parallel()
+
+	store := setupNotificationStore(t, 0, 0)
+
+	toast := notification.NewNotification(notification.TypeInfo, notification.PriorityLow, "Saved", "Settings saved").
+		WithMetadata(notification.MetadataKeyIsToast, true)
+	require.NoError(t, store.Save(toast))
+
+	count, err := store.Count(nil)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestNotificationStore_UpdateAndUnreadCount(t *testing.T) {
+	t.Parallel()
+
+	store := setupNotificationStore(t, 0, 0)
+
+	first := notification.NewNotification(notification.TypeWarning, notification.PriorityHigh, "Disk almost full", "92% used")
+	second := notification.NewNotification(notification.TypeInfo, notification.PriorityLow, "Update available", "v1.2.3")
+	require.NoError(t, store.Save(first))
+	require.NoError(t, store.Save(second))
+
+	unread, err := store.GetUnreadCount()
+	require.NoError(t, err)
+	assert.Equal(t, 2, unread)
+
+	first.MarkAsAcknowledged()
+	require.NoError(t, store.Update(first))
+
+	unread, err = store.GetUnreadCount()
+	require.NoError(t, err)
+	assert.Equal(t, 1, unread)
+
+	got, err := store.Get(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, notification.StatusAcknowledged, got.Status)
+
+	missing := notification.NewNotification(notification.TypeInfo, notification.PriorityLow, "x", "y")
+	require.Error(t, store.Update(missing))
+
+	require.NoError(t, store.Delete(second.ID))
+	_, err = store.Get(second.ID)
+	require.ErrorIs(t, err, notification.ErrNotificationNotFound)
+}
+
+func TestNotificationStore_ListFilterAndPaging(t *testing.T) {
+	t.Parallel()
+
+	store := setupNotificationStore(t, 0, 0)
+
+	base := time.Now().Add(-time.Hour)
+	for i := range 10 {
+		notifType := notification.TypeInfo
+		if i%2 == 0 {
+			notifType = notification.TypeError
+		}
+		notif := notification.NewNotification(notifType, notification.PriorityMedium, fmt.Sprintf("n%d", i), "message").
+			WithComponent("mqtt")
+		notif.Timestamp = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, store.Save(notif))
+	}
+
+	filter := &notification.FilterOptions{Types: []notification.Type{notification.TypeError}, Limit: 2, Offset: 1}
+	page, err := store.List(filter)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	// Newest first: errors are n8, n6, n4, n2, n0; offset 1 skips n8
+	assert.Equal(t, "n6", page[0].Title)
+	assert.Equal(t, "n4", page[1].Title)
+
+	total, err := store.Count(filter)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total, "count ignores limit and offset")
+
+	since := base.Add(7 * time.Minute)
+	recent, err := store.List(&notification.FilterOptions{Since: &since, Component: "mqtt"})
+	require.NoError(t, err)
+	assert.Len(t, recent, 3)
+}
+
+func TestNotificationStore_DeleteExpiredAndRetention(t *testing.T) {
+	t.Parallel()
+
+	store := setupNotificationStore(t, 24*time.Hour, 3)
+
+	expired := notification.NewNotification(notification.TypeInfo, notification.PriorityLow, "expired", "gone")
+	past := time.Now().Add(-time.Minute)
+	expired.ExpiresAt = &past
+	require.NoError(t, store.Save(expired))
+
+	old := notification.NewNotification(notification.TypeError, notification.PriorityHigh, "old", "beyond retention")
+	old.Timestamp = time.Now().Add(-48 * time.Hour)
+	require.NoError(t, store.Save(old))
+
+	for i := range 5 {
+		notif := notification.NewNotification(notification.TypeSystem, notification.PriorityMedium, fmt.Sprintf("recent%d", i), "kept")
+		notif.Timestamp = time.Now().Add(-time.Duration(5-i) * time.Minute)
+		require.NoError(t, store.Save(notif))
+	}
+
+	require.NoError(t, store.DeleteExpired())
+
+	remaining, err := store.List(nil)
+	require.NoError(t, err)
+	require.Len(t, remaining, 3, "max records keeps only the newest notifications")
+	assert.Equal(t, "recent4", remaining[0].Title)
+	assert.Equal(t, "recent2", remaining[2].Title)
+}
+
+func TestNotificationStore_ServiceSetStoreMigratesNotifications(t *testing.T) {
+	t.Parallel()
+
+	service := notification.NewService(notification.DefaultServiceConfig())
+	defer service.Stop()
+
+	created, err := service.Create(notification.TypeSystem, notification.PriorityHigh, "Startup", "raised before the database opened")
+	require.NoError(t, err)
+
+	store := setupNotificationStore(t, 0, 0)
+	require.NoError(t, service.SetStore(store))
+
+	got, err := store.Get(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Startup", got.Title)
+
+	_, err = service.Create(notification.TypeInfo, notification.PriorityLow, "After", "stored in the database")
+	require.NoError(t, err)
+
+	total, err := service.Count(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+}
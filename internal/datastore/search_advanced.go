@@ -2,6 +2,7 @@
 package datastore
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -65,39 +66,8 @@ func (ds *DataStore) SearchNotesAdvanced(filters *AdvancedSearchFilters) ([]Note
 			return db.Order("created_at DESC")
 		})
 
-	// Apply text search if provided
-	if filters.TextQuery != "" {
-		query = query.Where("common_name LIKE ? OR scientific_name LIKE ?",
-			"%"+filters.TextQuery+"%", "%"+filters.TextQuery+"%")
-	}
-
-	// Apply confidence filter
-	query = applyConfidenceFilter(query, filters.Confidence)
-
-	// Apply date range filter
-	query = applyDateRangeFilter(query, filters.DateRange)
-
-	// Apply hour filter
-	query = applyHourFilter(query, filters.Hour)
-
-	// Apply time of day filter
-	query = applyTimeOfDayFilter(query, filters.TimeOfDay)
-
-	// Apply species filter
-	if len(filters.Species) > 0 {
-		query = query.Where("species_code IN ? OR scientific_name IN ?", filters.Species, filters.Species)
-	}
-
-	// Apply location/source filter
-	if len(filters.Location) > 0 {
-		query = query.Where("source IN ?", filters.Location)
-	}
-
-	// Apply verified filter
-	query = applyVerifiedFilter(query, filters.Verified)
-
-	// Apply locked filter
-	query = applyLockedFilter(query, filters.Locked)
+	// Apply search filters
+	query = applyAdvancedSearchFilters(query, filters)
 
 	// Count total results before pagination
 	var totalCount int64
@@ -138,6 +108,86 @@ func (ds *DataStore) SearchNotesAdvanced(filters *AdvancedSearchFilters) ([]Note
 	}
 
 	// Populate virtual fields
+	populateVirtualFields(notes)
+
+	return notes, totalCount, nil
+}
+
+// StreamNotesAdvanced calls fn with batches of notes matching the filters, in ascending
+// ID order. Only batchSize notes are held in memory at a time, which allows exporting
+// the whole database. Sorting, Limit and Offset of the filters are ignored.
+func (ds *DataStore) StreamNotesAdvanced(ctx context.Context, filters *AdvancedSearchFilters, batchSize int, fn func([]Note) error) error {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+
+	query := ds.DB.WithContext(ctx).Model(&Note{}).
+		Preload("Review").
+		Preload("Lock")
+	query = applyAdvancedSearchFilters(query, filters)
+
+	var batch []Note
+	result := query.FindInBatches(&batch, batchSize, func(tx *gorm.DB, batchNum int) error {
+		populateVirtualFields(batch)
+		return fn(batch)
+	})
+	if result.Error != nil {
+		return errors.Newf("failed to stream advanced search results: %w", result.Error).
+			Context("operation", "stream_advanced_search_notes").
+			Context("filters", fmt.Sprintf("%+v", filters)).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Build()
+	}
+
+	return nil
+}
+
+// applyAdvancedSearchFilters applies the filters of an advanced search to the query
+func applyAdvancedSearchFilters(query *gorm.DB, filters *AdvancedSearchFilters) *gorm.DB {
+	if filters == nil {
+		return query
+	}
+
+	// Apply text search if provided
+	if filters.TextQuery != "" {
+		query = query.Where("common_name LIKE ? OR scientific_name LIKE ?",
+			"%"+filters.TextQuery+"%", "%"+filters.TextQuery+"%")
+	}
+
+	// Apply confidence filter
+	query = applyConfidenceFilter(query, filters.Confidence)
+
+	// Apply date range filter
+	query = applyDateRangeFilter(query, filters.DateRange)
+
+	// Apply hour filter
+	query = applyHourFilter(query, filters.Hour)
+
+	// Apply time of day filter
+	query = applyTimeOfDayFilter(query, filters.TimeOfDay)
+
+	// Apply species filter
+	if len(filters.Species) > 0 {
+		query = query.Where("species_code IN ? OR scientific_name IN ?", filters.Species, filters.Species)
+	}
+
+	// Apply location/source filter
+	if len(filters.Location) > 0 {
+		query = query.Where("source IN ?", filters.Location)
+	}
+
+	// Apply verified filter
+	query = applyVerifiedFilter(query, filters.Verified)
+
+	// Apply locked filter
+	query = applyLockedFilter(query, filters.Locked)
+
+	return query
+}
+
+// populateVirtualFields sets the verified and locked fields from the preloaded relations
+func populateVirtualFields(notes []Note) {
 	for i := range notes {
 		note := &notes[i]
 		if note.Review != nil && note.Review.Verified != "" {
@@ -147,8 +197,6 @@ func (ds *DataStore) SearchNotesAdvanced(filters *AdvancedSearchFilters) ([]Note
 			note.Locked = true
 		}
 	}
-
-	return notes, totalCount, nil
 }
 
 // ParseDateShortcut converts date shortcuts like "today", "yesterday" to actual dates
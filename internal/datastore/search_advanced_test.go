@@ -0,0 +1,120 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupSearchTestDB creates an in-memory database with detections on three days
+func setupSearchTestDB(t *testing.T) *DataStore {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Note{}, &NoteReview{}, &NoteLock{}, &NoteComment{}))
+
+	for i := 1; i <= 25; i++ {
+		note := Note{
+			ID:             uint(i),
+			Date:           fmt.Sprintf("2024-05-%02d", 1+i%3),
+			Time:           "06:00:00",
+			ScientificName: "Erithacus rubecula",
+			CommonName:     "European Robin",
+			SpeciesCode:    "eurrob1",
+			Confidence:     0.5 + float64(i)/100,
+		}
+		if i%5 == 0 {
+			note.ScientificName = "Turdus merula"
+			note.CommonName = "Eurasian Blackbird"
+			note.SpeciesCode = "eurbla"
+		}
+		require.NoError(t, db.Create(&note).Error)
+	}
+	require.NoError(t, db.Create(&NoteReview{NoteID: 5, Verified: "correct"}).Error)
+	require.NoError(t, db.Create(&NoteLock{NoteID: 10, LockedAt: time.Now()}).Error)
+
+	return &DataStore{DB: db}
+}
+
+func TestStreamNotesAdvanced(t *testing.T) {
+	t.Parallel()
+
+	ds := setupSearchTestDB(t)
+
+	var batches []int
+	var ids []uint
+	err := ds.StreamNotesAdvanced(context.Background(), &AdvancedSearchFilters{}, 10, func(notes []Note) error {
+		batches = append(batches, len(notes))
+		for i := range notes {
+			ids = append(ids, notes[i].ID)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{10, 10, 5}, batches)
+	require.Len(t, ids, 25)
+	for i, id := range ids {
+		assert.Equal(t, uint(i+1), id, "notes are streamed in ID order")
+	}
+}
+
+func TestStreamNotesAdvanced_Filters(t *testing.T) {
+	t.Parallel()
+
+	ds := setupSearchTestDB(t)
+
+	filters := &AdvancedSearchFilters{
+		Species: []string{"eurbla"},
+		DateRange: &DateRange{
+			Start: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 5, 2, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	var notes []Note
+	err := ds.StreamNotesAdvanced(context.Background(), filters, 2, func(batch []Note) error {
+		notes = append(notes, batch...)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Blackbirds are notes 5, 10, 15, 20 and 25, on May 3, 2, 1, 3 and 2
+	require.Len(t, notes, 3)
+	assert.Equal(t, []uint{10, 15, 25}, []uint{notes[0].ID, notes[1].ID, notes[2].ID})
+	assert.True(t, notes[0].Locked, "virtual fields are populated")
+
+	// Errors returned by the callback stop the stream
+	errStop := fmt.Errorf("stop")
+	calls := 0
+	err = ds.StreamNotesAdvanced(context.Background(), filters, 1, func(batch []Note) error {
+		calls++
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestStreamNotesAdvanced_Verified(t *testing.T) {
+	t.Parallel()
+
+	ds := setupSearchTestDB(t)
+
+	verified := true
+	var notes []Note
+	err := ds.StreamNotesAdvanced(context.Background(), &AdvancedSearchFilters{Verified: &verified}, 10, func(batch []Note) error {
+		notes = append(notes, batch...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, uint(5), notes[0].ID)
+	assert.Equal(t, "correct", notes[0].Verified)
+}
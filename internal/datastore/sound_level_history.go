@@ -0,0 +1,316 @@
+// sound_level_history.go provides the database history of sound level summaries
+package datastore
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"gorm.io/gorm"
+)
+
+// soundLevelFloor is the level reported for periods without sound energy
+const soundLevelFloor = -100.0
+
+// SoundLevelBand is the level of a 1/3rd octave band over a sound level summary
+type SoundLevelBand struct {
+	Freq float64 `json:"freq"` // Center frequency in Hz
+	Mean float64 `json:"mean"` // Mean level, merged summaries average the band energy
+	Min  float64 `json:"min"`  // Lowest 1-second level
+	Max  float64 `json:"max"`  // Highest 1-second level
+}
+
+// BandLevels decodes the band levels of the summary
+func (r *SoundLevelRecord) BandLevels() (map[string]SoundLevelBand, error) {
+	bands := make(map[string]SoundLevelBand)
+	if r.Bands == "" {
+		return bands, nil
+	}
+	if err := json.Unmarshal([]byte(r.Bands), &bands); err != nil {
+		return nil, fmt.Errorf("invalid band levels of sound level record %d: %w", r.ID, err)
+	}
+	return bands, nil
+}
+
+// SetBandLevels encodes the band levels of the summary
+func (r *SoundLevelRecord) SetBandLevels(bands map[string]SoundLevelBand) error {
+	data, err := json.Marshal(bands)
+	if err != nil {
+		return err
+	}
+	r.Bands = string(data)
+	return nil
+}
+
+// SoundLevelPeriodStart returns the start of the period of a resolution that a time falls
+// in. Periods are aligned to the local wall clock, so hourly periods start on the hour
+// even in time zones with offsets of part of an hour.
+func SoundLevelPeriodStart(t time.Time, resolution time.Duration) time.Time {
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(resolution).Add(-shift)
+}
+
+// MergeSoundLevelRecords merges the summaries of a source into a summary of a period.
+// Levels are averaged by energy, weighted by the measured seconds of each summary.
+func MergeSoundLevelRecords(start time.Time, duration time.Duration, records []SoundLevelRecord) (SoundLevelRecord, error) {
+	merged := SoundLevelRecord{
+		Start:    start,
+		Duration: int(duration / time.Second),
+		Lmin:     math.Inf(1),
+		Lmax:     math.Inf(-1),
+	}
+	if len(records) == 0 {
+		return merged, fmt.Errorf("no sound level records to merge")
+	}
+
+	type bandSum struct {
+		band   SoundLevelBand
+		energy float64
+		weight float64
+	}
+	bandSums := make(map[string]*bandSum)
+	var energy, weight float64
+
+	for i := range records {
+		record := &records[i]
+		merged.Source = record.Source
+		merged.Name = record.Name
+		w := float64(record.Measured)
+		if w <= 0 {
+			w = float64(record.Duration)
+		}
+		merged.Measured += int(w)
+		energy += w * math.Pow(10, record.Leq/10)
+		weight += w
+		merged.Lmin = math.Min(merged.Lmin, record.Lmin)
+		merged.Lmax = math.Max(merged.Lmax, record.Lmax)
+
+		bands, err := record.BandLevels()
+		if err != nil {
+			return merged, err
+		}
+		for key, band := range bands {
+			sum, ok := bandSums[key]
+			if !ok {
+				sum = &bandSum{band: SoundLevelBand{Freq: band.Freq, Min: band.Min, Max: band.Max}}
+				bandSums[key] = sum
+			}
+			sum.energy += w * math.Pow(10, band.Mean/10)
+			sum.weight += w
+			sum.band.Min = math.Min(sum.band.Min, band.Min)
+			sum.band.Max = math.Max(sum.band.Max, band.Max)
+		}
+	}
+
+	merged.Leq = energyToLevel(energy, weight)
+	if math.IsInf(merged.Lmin, 0) || math.IsInf(merged.Lmax, 0) {
+		merged.Lmin, merged.Lmax = soundLevelFloor, soundLevelFloor
+	}
+	bands := make(map[string]SoundLevelBand, len(bandSums))
+	for key, sum := range bandSums {
+		sum.band.Mean = energyToLevel(sum.energy, sum.weight)
+		bands[key] = sum.band
+	}
+	return merged, merged.SetBandLevels(bands)
+}
+
+// energyToLevel returns the level of a weighted energy sum
+func energyToLevel(energy, weight float64) float64 {
+	if energy <= 0 || weight <= 0 {
+		return soundLevelFloor
+	}
+	return 10 * math.Log10(energy/weight)
+}
+
+// SoundLevelHistory stores sound level summaries in the database and keeps the history
+// small by merging old summaries into hourly summaries and deleting expired summaries
+type SoundLevelHistory struct {
+	db dbFunc
+}
+
+// NewSoundLevelHistory creates a sound level history using a database
+func NewSoundLevelHistory(db *gorm.DB) *SoundLevelHistory {
+	return &SoundLevelHistory{db: fixedDB(db)}
+}
+
+// NewSoundLevelHistoryFor creates a sound level history using the database of an opened
+// SQLite or MySQL datastore
+func NewSoundLevelHistoryFor(store Interface) (*SoundLevelHistory, error) {
+	db := datastoreDB(store)
+	if db == nil {
+		return nil, errors.Newf("datastore does not provide an open database connection").
+			Component("datastore").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "create_sound_level_history").
+			Context("store_type", fmt.Sprintf("%T", store)).
+			Build()
+	}
+
+	return &SoundLevelHistory{db: db}, nil
+}
+
+// Save stores a sound level summary. Start times are stored in UTC so that they compare
+// correctly across time zone changes.
+func (h *SoundLevelHistory) Save(record *SoundLevelRecord) error {
+	record.Start = record.Start.UTC()
+	if err := h.db().Create(record).Error; err != nil {
+		return errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "save_sound_level_record").
+			Context("source", record.Source).
+			Build()
+	}
+	return nil
+}
+
+// Query returns the summaries of a source, or of all sources if source is empty, that
+// start within [from, to), ordered by start time
+func (h *SoundLevelHistory) Query(source string, from, to time.Time) ([]SoundLevelRecord, error) {
+	query := h.db().Where("start >= ? AND start < ?", from.UTC(), to.UTC())
+	if source != "" {
+		query = query.Where("source = ?", source)
+	}
+
+	var records []SoundLevelRecord
+	if err := query.Order("start ASC, source ASC").Find(&records).Error; err != nil {
+		return nil, errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "query_sound_level_records").
+			Context("source", source).
+			Build()
+	}
+	return records, nil
+}
+
+// Downsample merges the summaries that start before a time and are shorter than a
+// resolution into summaries of that resolution. It returns the number of merged summaries
+// created.
+func (h *SoundLevelHistory) Downsample(before time.Time, resolution time.Duration) (int, error) {
+	cutoff := SoundLevelPeriodStart(before, resolution).UTC()
+	seconds := int(resolution / time.Second)
+
+	var records []SoundLevelRecord
+	if err := h.db().Where("start < ? AND duration < ?", cutoff, seconds).
+		Order("source ASC, start ASC").Find(&records).Error; err != nil {
+		return 0, h.downsampleError(err, before)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	// Group the summaries by source and period
+	type periodKey struct {
+		source string
+		start  time.Time
+	}
+	groups := make(map[periodKey][]SoundLevelRecord)
+	var keys []periodKey
+	for i := range records {
+		key := periodKey{records[i].Source, SoundLevelPeriodStart(records[i].Start.In(time.Local), resolution).UTC()}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], records[i])
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].source != keys[j].source {
+			return keys[i].source < keys[j].source
+		}
+		return keys[i].start.Before(keys[j].start)
+	})
+
+	err := h.db().Transaction(func(tx *gorm.DB) error {
+		for _, key := range keys {
+			group := groups[key]
+			merged, err := MergeSoundLevelRecords(key.start, resolution, group)
+			if err != nil {
+				return err
+			}
+			ids := make([]uint, len(group))
+			for i := range group {
+				ids[i] = group[i].ID
+			}
+			if err := tx.Where("id IN ?", ids).Delete(&SoundLevelRecord{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&merged).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, h.downsampleError(err, before)
+	}
+	return len(keys), nil
+}
+
+// downsampleError wraps a downsampling error
+func (h *SoundLevelHistory) downsampleError(err error, before time.Time) error {
+	return errors.New(err).
+		Component("datastore").
+		Category(errors.CategoryDatabase).
+		Context("operation", "downsample_sound_level_records").
+		Context("before", before.Format(time.RFC3339)).
+		Build()
+}
+
+// Prune deletes the summaries that start before a time and returns the number deleted
+func (h *SoundLevelHistory) Prune(before time.Time) (int64, error) {
+	result := h.db().Where("start < ?", before.UTC()).Delete(&SoundLevelRecord{})
+	if result.Error != nil {
+		return 0, errors.New(result.Error).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "prune_sound_level_records").
+			Context("before", before.Format(time.RFC3339)).
+			Build()
+	}
+	return result.RowsAffected, nil
+}
+
+// HourlyDetectionCounts returns the number of detections in each local hour within
+// [from, to), optionally of one audio source and of one species by common or scientific
+// name. Detections stored before their source was recorded are only counted for all sources.
+func (h *SoundLevelHistory) HourlyDetectionCounts(from, to time.Time, source, species string) (map[time.Time]int, error) {
+	query := h.db().Model(&Note{}).
+		Select("date, SUBSTR(time, 1, 2) AS hour, COUNT(*) AS count").
+		Where("date BETWEEN ? AND ?", from.In(time.Local).Format("2006-01-02"), to.In(time.Local).Format("2006-01-02"))
+	if source != "" {
+		query = query.Where("source_id = ?", source)
+	}
+	if species != "" {
+		query = query.Where("(common_name = ? OR scientific_name = ?)", species, species)
+	}
+
+	var rows []struct {
+		Date  string
+		Hour  string
+		Count int
+	}
+	if err := query.Group("date, hour").Find(&rows).Error; err != nil {
+		return nil, errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "count_hourly_detections").
+			Context("source", source).
+			Context("species", species).
+			Build()
+	}
+
+	counts := make(map[time.Time]int, len(rows))
+	for _, row := range rows {
+		hour, err := time.ParseInLocation("2006-01-02 15", row.Date+" "+row.Hour, time.Local)
+		if err != nil || hour.Before(SoundLevelPeriodStart(from, time.Hour)) || !hour.Before(to) {
+			continue
+		}
+		counts[hour] += row.Count
+	}
+	return counts, nil
+}
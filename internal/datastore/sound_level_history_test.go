@@ -0,0 +1,160 @@
+package datastore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupSoundLevelHistory creates a sound level history backed by a temporary SQLite database
+func setupSoundLevelHistory(t *testing.T) (*SoundLevelHistory, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "soundlevels.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Note{}, &SoundLevelRecord{}))
+	return NewSoundLevelHistory(db), db
+}
+
+// testSoundLevelRecord returns a summary with one band at the broadband levels
+func testSoundLevelRecord(t *testing.T, source string, start time.Time, seconds int, leq, lmin, lmax float64) SoundLevelRecord {
+	t.Helper()
+	record := SoundLevelRecord{Source: source, Name: source, Start: start, Duration: seconds, Measured: seconds, Leq: leq, Lmin: lmin, Lmax: lmax}
+	require.NoError(t, record.SetBandLevels(map[string]SoundLevelBand{
+		"1.0_kHz": {Freq: 1000, Mean: leq, Min: lmin, Max: lmax},
+	}))
+	return record
+}
+
+func TestMergeSoundLevelRecords(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 1, 5, 0, 0, 0, time.UTC)
+	records := []SoundLevelRecord{
+		testSoundLevelRecord(t, "rtsp_1", start, 300, -40, -45, -35),
+		testSoundLevelRecord(t, "rtsp_1", start.Add(5*time.Minute), 300, -20, -30, -10),
+	}
+	// A summary with twice the measured time counts twice
+	records = append(records, testSoundLevelRecord(t, "rtsp_1", start.Add(10*time.Minute), 600, -40, -50, -38))
+
+	merged, err := MergeSoundLevelRecords(start, time.Hour, records)
+	require.NoError(t, err)
+	assert.Equal(t, "rtsp_1", merged.Source)
+	assert.Equal(t, 3600, merged.Duration)
+	assert.Equal(t, 1200, merged.Measured)
+	// Energy mean of one quarter at -20 dB and three quarters at -40 dB
+	assert.InDelta(t, -25.89, merged.Leq, 0.01)
+	assert.InDelta(t, -50, merged.Lmin, 0.001)
+	assert.InDelta(t, -10, merged.Lmax, 0.001)
+
+	bands, err := merged.BandLevels()
+	require.NoError(t, err)
+	require.Contains(t, bands, "1.0_kHz")
+	assert.InDelta(t, merged.Leq, bands["1.0_kHz"].Mean, 0.001)
+	assert.InDelta(t, -50, bands["1.0_kHz"].Min, 0.001)
+	assert.InDelta(t, 1000, bands["1.0_kHz"].Freq, 0.001)
+
+	_, err = MergeSoundLevelRecords(start, time.Hour, nil)
+	require.Error(t, err)
+}
+
+func TestSoundLevelHistory_DownsampleAndPrune(t *testing.T) {
+	t.Parallel()
+
+	history, _ := setupSoundLevelHistory(t)
+	start := SoundLevelPeriodStart(time.Now().Add(-48*time.Hour), time.Hour)
+	for i := 0; i < 12; i++ {
+		for _, source := range []string{"rtsp_1", "malgo_2"} {
+			record := testSoundLevelRecord(t, source, start.Add(time.Duration(i)*5*time.Minute), 300, -30, -35, -25)
+			require.NoError(t, history.Save(&record))
+		}
+	}
+	recent := testSoundLevelRecord(t, "rtsp_1", time.Now().Add(-time.Hour), 300, -30, -35, -25)
+	require.NoError(t, history.Save(&recent))
+
+	// Summaries older than a day are merged into one hourly summary per source
+	merged, err := history.Downsample(time.Now().Add(-24*time.Hour), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, merged)
+
+	records, err := history.Query("rtsp_1", start.Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.True(t, start.Equal(records[0].Start), "hourly summary starts on the hour")
+	assert.Equal(t, 3600, records[0].Duration)
+	assert.Equal(t, 3600, records[0].Measured)
+	assert.InDelta(t, -30, records[0].Leq, 0.001)
+	assert.Equal(t, 300, records[1].Duration, "recent summaries keep their resolution")
+
+	// Hourly summaries are not merged again
+	merged, err = history.Downsample(time.Now().Add(-24*time.Hour), time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, merged)
+
+	// Expired summaries are deleted
+	deleted, err := history.Prune(time.Now().Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	records, err = history.Query("", start.Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "rtsp_1", records[0].Source)
+}
+
+func TestSoundLevelHistory_HourlyDetectionCounts(t *testing.T) {
+	t.Parallel()
+
+	history, db := setupSoundLevelHistory(t)
+	notes := []Note{
+		{Date: "2024-05-01", Time: "04:59:59", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird"},
+		{Date: "2024-05-01", Time: "05:10:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird"},
+		{Date: "2024-05-01", Time: "05:20:00", ScientificName: "Erithacus rubecula", CommonName: "European Robin"},
+		{Date: "2024-05-01", Time: "06:00:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird"},
+		{Date: "2024-05-02", Time: "05:00:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird"},
+	}
+	require.NoError(t, db.Create(&notes).Error)
+
+	from := time.Date(2024, 5, 1, 5, 0, 0, 0, time.Local)
+	to := time.Date(2024, 5, 1, 7, 0, 0, 0, time.Local)
+	counts, err := history.HourlyDetectionCounts(from, to, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Time]int{from: 2, from.Add(time.Hour): 1}, counts)
+
+	counts, err = history.HourlyDetectionCounts(from, to, "", "Turdus merula")
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Time]int{from: 1, from.Add(time.Hour): 1}, counts)
+}
+
+func TestSoundLevelHistory_HourlyDetectionCountsBySource(t *testing.T) {
+	t.Parallel()
+
+	history, db := setupSoundLevelHistory(t)
+	notes := []Note{
+		{Date: "2024-05-01", Time: "05:10:00", SourceID: "garden", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird"},
+		{Date: "2024-05-01", Time: "05:20:00", SourceID: "garden", ScientificName: "Erithacus rubecula", CommonName: "European Robin"},
+		{Date: "2024-05-01", Time: "05:30:00", SourceID: "rtsp_1", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird"},
+		{Date: "2024-05-01", Time: "06:00:00", SourceID: "rtsp_1", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird"},
+		{Date: "2024-05-01", Time: "06:10:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird"},
+	}
+	require.NoError(t, db.Create(&notes).Error)
+
+	from := time.Date(2024, 5, 1, 5, 0, 0, 0, time.Local)
+	to := time.Date(2024, 5, 1, 7, 0, 0, 0, time.Local)
+	counts, err := history.HourlyDetectionCounts(from, to, "garden", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Time]int{from: 2}, counts)
+
+	counts, err = history.HourlyDetectionCounts(from, to, "rtsp_1", "Turdus merula")
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Time]int{from: 1, from.Add(time.Hour): 1}, counts)
+
+	// Detections without a source are only counted for all sources
+	counts, err = history.HourlyDetectionCounts(from, to, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Time]int{from: 3, from.Add(time.Hour): 2}, counts)
+}
@@ -0,0 +1,28 @@
+package datastore
+
+import "gorm.io/gorm"
+
+// dbFunc returns the database a store runs its queries on
+type dbFunc func() *gorm.DB
+
+// fixedDB returns a dbFunc always returning the same database
+func fixedDB(db *gorm.DB) dbFunc {
+	return func() *gorm.DB { return db }
+}
+
+// datastoreDB returns a dbFunc looking up the database of an opened SQLite or MySQL
+// datastore on every call, so that stores keep working when the datastore is reopened,
+// e.g. after a backup has been restored. It returns nil if the datastore has no database.
+func datastoreDB(store Interface) dbFunc {
+	switch s := store.(type) {
+	case *SQLiteStore:
+		if s.DB != nil {
+			return func() *gorm.DB { return s.DB }
+		}
+	case *MySQLStore:
+		if s.DB != nil {
+			return func() *gorm.DB { return s.DB }
+		}
+	}
+	return nil
+}
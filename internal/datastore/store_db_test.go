@@ -0,0 +1,41 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/notification"
+)
+
+// TestStoresFollowReopenedDatastore tests that stores created for a datastore keep working
+// after the datastore is closed and opened again, as happens when a backup is restored
+func TestStoresFollowReopenedDatastore(t *testing.T) {
+	store := createDatabase(t, &conf.Settings{})
+
+	notifications, err := NewNotificationStoreFor(store, 0, 0)
+	require.NoError(t, err)
+	outbox, err := NewBirdweatherOutboxFor(store)
+	require.NoError(t, err)
+	history, err := NewSoundLevelHistoryFor(store)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Close())
+	require.NoError(t, store.Open())
+
+	require.NoError(t, notifications.Save(notification.NewNotification(notification.TypeInfo, notification.PriorityLow, "Restored", "backup restored")))
+	note := Note{Date: "2024-05-01", Time: "06:30:00", ScientificName: "Bubo bubo", CommonName: "Eurasian Eagle-Owl", Confidence: 0.9}
+	require.NoError(t, outbox.Enqueue(&note, fmt.Errorf("offline")))
+	require.NoError(t, history.Save(&SoundLevelRecord{Source: "rtsp_1", Start: time.Now().Add(-time.Hour), Duration: 300, Measured: 300, Leq: -40}))
+
+	// The writes are visible through the reopened database
+	db := store.(*SQLiteStore).DB
+	for _, model := range []any{&NotificationRecord{}, &BirdweatherUpload{}, &SoundLevelRecord{}} {
+		var count int64
+		require.NoError(t, db.Model(model).Count(&count).Error)
+		assert.Equal(t, int64(1), count, "%T", model)
+	}
+}
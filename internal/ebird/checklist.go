@@ -0,0 +1,235 @@
+package ebird
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Checklist protocols of the eBird Record Format
+const (
+	ProtocolStationary = "Stationary"
+	ProtocolIncidental = "Incidental"
+	ProtocolTraveling  = "Traveling"
+)
+
+// ChecklistLocation is the location of a checklist
+type ChecklistLocation struct {
+	Name      string  // Location name shown in eBird
+	Latitude  float64 // Decimal degrees
+	Longitude float64 // Decimal degrees
+	State     string  // State or province code, e.g. "CA" or "ON", optional
+	Country   string  // Two letter country code, e.g. "US", optional
+}
+
+// ChecklistOptions describes the observation effort of a checklist
+type ChecklistOptions struct {
+	Location     ChecklistLocation
+	Start        time.Time // Start of the observation window
+	End          time.Time // End of the observation window
+	Protocol     string    // Defaults to ProtocolStationary
+	Observers    int       // Defaults to 1
+	Complete     bool      // All observations reported
+	ReportCounts bool      // Report the number of detections instead of presence ("X")
+	Comments     string    // Checklist comments
+}
+
+// ChecklistEntry is a species of a checklist
+type ChecklistEntry struct {
+	SpeciesCode    string  `json:"speciesCode"`
+	CommonName     string  `json:"commonName"`
+	ScientificName string  `json:"scientificName"`
+	Count          int     `json:"count"`         // Number of detections
+	MaxConfidence  float64 `json:"maxConfidence"` // Highest confidence of the detections
+	taxonOrder     float64
+}
+
+// Checklist aggregates detections into an eBird checklist. Species are mapped to the
+// eBird taxonomy by scientific name, subspecies and forms are reported as their species.
+type Checklist struct {
+	Options   ChecklistOptions
+	entries   map[string]*ChecklistEntry // By species code
+	unmatched map[string]int             // Detections by scientific name without a taxonomy entry
+
+	bySciName  map[string]*TaxonomyEntry
+	byCode     map[string]*TaxonomyEntry
+	byComName  map[string]*TaxonomyEntry
+	detections int
+}
+
+// NewChecklist creates an empty checklist using the eBird taxonomy for species mapping
+func NewChecklist(taxonomy []TaxonomyEntry, opts ChecklistOptions) (*Checklist, error) {
+	if len(taxonomy) == 0 {
+		return nil, errors.Newf("eBird taxonomy is required to build a checklist").
+			Component("ebird").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "new_checklist").
+			Build()
+	}
+	if !opts.End.After(opts.Start) {
+		return nil, errors.Newf("checklist end time must be after the start time").
+			Component("ebird").
+			Category(errors.CategoryValidation).
+			Context("operation", "new_checklist").
+			Build()
+	}
+	if opts.Protocol == "" {
+		opts.Protocol = ProtocolStationary
+	}
+	if opts.Observers <= 0 {
+		opts.Observers = 1
+	}
+
+	c := &Checklist{
+		Options:   opts,
+		entries:   make(map[string]*ChecklistEntry),
+		unmatched: make(map[string]int),
+		bySciName: make(map[string]*TaxonomyEntry, len(taxonomy)),
+		byCode:    make(map[string]*TaxonomyEntry, len(taxonomy)),
+		byComName: make(map[string]*TaxonomyEntry, len(taxonomy)),
+	}
+	for i := range taxonomy {
+		entry := &taxonomy[i]
+		c.bySciName[strings.ToLower(entry.ScientificName)] = entry
+		c.byCode[entry.SpeciesCode] = entry
+		c.byComName[strings.ToLower(entry.CommonName)] = entry
+	}
+	return c, nil
+}
+
+// Add adds a detection of a species to the checklist
+func (c *Checklist) Add(scientificName, commonName string, confidence float64) {
+	c.detections++
+	taxon := c.lookup(scientificName, commonName)
+	if taxon == nil {
+		c.unmatched[scientificName]++
+		return
+	}
+
+	entry, ok := c.entries[taxon.SpeciesCode]
+	if !ok {
+		entry = &ChecklistEntry{
+			SpeciesCode:    taxon.SpeciesCode,
+			CommonName:     taxon.CommonName,
+			ScientificName: taxon.ScientificName,
+			taxonOrder:     taxon.TaxonOrder,
+		}
+		c.entries[taxon.SpeciesCode] = entry
+	}
+	entry.Count++
+	entry.MaxConfidence = math.Max(entry.MaxConfidence, confidence)
+}
+
+// lookup returns the taxonomy entry a species is reported as, nil if it is not in the taxonomy
+func (c *Checklist) lookup(scientificName, commonName string) *TaxonomyEntry {
+	taxon, ok := c.bySciName[strings.ToLower(scientificName)]
+	if !ok {
+		// BirdNET labels of renamed species may still match by common name
+		if taxon, ok = c.byComName[strings.ToLower(commonName)]; !ok {
+			return nil
+		}
+	}
+	if taxon.ReportAs != "" {
+		if species, ok := c.byCode[taxon.ReportAs]; ok {
+			return species
+		}
+	}
+	return taxon
+}
+
+// Entries returns the species of the checklist in taxonomic order
+func (c *Checklist) Entries() []ChecklistEntry {
+	entries := make([]ChecklistEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].taxonOrder < entries[j].taxonOrder
+	})
+	return entries
+}
+
+// Unmatched returns the number of detections by scientific name of species that are not
+// in the eBird taxonomy and were left out of the checklist
+func (c *Checklist) Unmatched() map[string]int {
+	return c.unmatched
+}
+
+// Detections returns the number of detections added to the checklist
+func (c *Checklist) Detections() int {
+	return c.detections
+}
+
+// DurationMinutes returns the length of the observation window in whole minutes
+func (c *Checklist) DurationMinutes() int {
+	return max(1, int(math.Round(c.Options.End.Sub(c.Options.Start).Minutes())))
+}
+
+// WriteCSV writes the checklist in the eBird Record Format (Extended), one row per species
+// without a header row, ready for the eBird CSV import. Species are reported as present
+// unless ReportCounts is set: one bird singing for an hour is detected many times, so the
+// number of detections overstates the number of birds.
+func (c *Checklist) WriteCSV(w io.Writer) error {
+	opts := &c.Options
+	date := opts.Start.Format("01/02/2006")
+	startTime := opts.Start.Format("15:04")
+	complete := "N"
+	if opts.Complete {
+		complete = "Y"
+	}
+
+	cw := csv.NewWriter(w)
+	for _, entry := range c.Entries() {
+		genus, species, _ := strings.Cut(entry.ScientificName, " ")
+		number := "X"
+		if opts.ReportCounts {
+			number = strconv.Itoa(entry.Count)
+		}
+		comment := fmt.Sprintf("BirdNET-Go: %d verified detection(s), highest confidence %.0f%%",
+			entry.Count, entry.MaxConfidence*100)
+
+		if err := cw.Write([]string{
+			entry.CommonName,
+			genus,
+			species,
+			number,
+			comment,
+			opts.Location.Name,
+			strconv.FormatFloat(opts.Location.Latitude, 'f', 6, 64),
+			strconv.FormatFloat(opts.Location.Longitude, 'f', 6, 64),
+			date,
+			startTime,
+			opts.Location.State,
+			opts.Location.Country,
+			opts.Protocol,
+			strconv.Itoa(opts.Observers),
+			strconv.Itoa(c.DurationMinutes()),
+			complete,
+			"", // Effort distance miles, stationary counts have none
+			"", // Effort area acres
+			opts.Comments,
+		}); err != nil {
+			return errors.New(err).
+				Component("ebird").
+				Category(errors.CategoryFileIO).
+				Context("operation", "write_checklist_csv").
+				Build()
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return errors.New(err).
+			Component("ebird").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_checklist_csv").
+			Build()
+	}
+	return nil
+}
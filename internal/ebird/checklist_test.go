@@ -0,0 +1,97 @@
+package ebird
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestChecklist(t *testing.T, opts ChecklistOptions) *Checklist {
+	t.Helper()
+
+	var taxonomy []TaxonomyEntry
+	require.NoError(t, json.Unmarshal([]byte(loadTestData(t, "taxonomy.json")), &taxonomy))
+	checklist, err := NewChecklist(taxonomy, opts)
+	require.NoError(t, err)
+	return checklist
+}
+
+func TestChecklist_WriteCSV(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 4, 5, 30, 0, 0, time.UTC)
+	checklist := newTestChecklist(t, ChecklistOptions{
+		Location:     ChecklistLocation{Name: "Backyard", Latitude: 45.5152, Longitude: -122.6784, State: "OR", Country: "US"},
+		Start:        start,
+		End:          start.Add(90 * time.Minute),
+		Comments:     "Dawn chorus",
+		ReportCounts: true,
+	})
+
+	checklist.Add("Turdus migratorius", "American Robin", 0.81)
+	checklist.Add("Turdus migratorius nigrideus", "American Robin (nigrideus)", 0.93)
+	checklist.Add("Corvus corax", "Common Raven", 0.7)
+	checklist.Add("Strix varia", "Barred Owl", 0.9)
+
+	assert.Equal(t, 4, checklist.Detections())
+	assert.Equal(t, map[string]int{"Strix varia": 1}, checklist.Unmatched())
+
+	var sb strings.Builder
+	require.NoError(t, checklist.WriteCSV(&sb))
+	rows, err := csv.NewReader(strings.NewReader(sb.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	// Species are in taxonomic order and subspecies are reported as their species
+	assert.Equal(t, []string{
+		"Common Raven", "Corvus", "corax", "1", "BirdNET-Go: 1 verified detection(s), highest confidence 70%",
+		"Backyard", "45.515200", "-122.678400", "05/04/2024", "05:30", "OR", "US",
+		"Stationary", "1", "90", "N", "", "", "Dawn chorus",
+	}, rows[0])
+	assert.Equal(t, []string{"American Robin", "Turdus", "migratorius", "2"}, rows[1][:4])
+	assert.Contains(t, rows[1][4], "highest confidence 93%")
+}
+
+func TestChecklist_Presence(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 4, 5, 30, 0, 0, time.UTC)
+	checklist := newTestChecklist(t, ChecklistOptions{
+		Start:     start,
+		End:       start.Add(time.Hour),
+		Protocol:  ProtocolIncidental,
+		Observers: 2,
+		Complete:  true,
+	})
+	// Species are also matched by common name
+	checklist.Add("Corvus corax principalis", "Common Raven", 0.8)
+	checklist.Add("Corvus corax", "Common Raven", 0.9)
+
+	entries := checklist.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "comrav", entries[0].SpeciesCode)
+
+	var sb strings.Builder
+	require.NoError(t, checklist.WriteCSV(&sb))
+	row, err := csv.NewReader(strings.NewReader(sb.String())).Read()
+	require.NoError(t, err)
+	assert.Equal(t, "X", row[3], "species are reported as present by default")
+	assert.Equal(t, []string{"Incidental", "2", "60", "Y"}, row[12:16])
+}
+
+func TestNewChecklist_Validation(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 4, 5, 30, 0, 0, time.UTC)
+	_, err := NewChecklist(nil, ChecklistOptions{Start: start, End: start.Add(time.Hour)})
+	require.Error(t, err)
+
+	taxonomy := []TaxonomyEntry{{ScientificName: "Corvus corax", CommonName: "Common Raven", SpeciesCode: "comrav"}}
+	_, err = NewChecklist(taxonomy, ChecklistOptions{Start: start, End: start})
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
@@ -24,6 +25,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
 	"github.com/tphakala/birdnet-go/internal/logging"
+	"github.com/tphakala/birdnet-go/internal/spectrogram"
 )
 
 // MaxClipNameLength is the maximum allowed length for a clip name
@@ -474,7 +476,7 @@ func (h *Handlers) ServeSpectrogram(c echo.Context) error {
 
 		// Try to create the spectrogram
 		generationStartTime := time.Now()
-		if err := createSpectrogramWithSoX(fullPath, spectrogramPath, spectrogramWidth); err != nil {
+		if err := renderSpectrogram(c.Request().Context(), fullPath, spectrogramPath, spectrogramWidth); err != nil {
 			generationDuration := time.Since(generationStartTime)
 			logger.Debug("Spectrogram generation failed, serving placeholder",
 				slog.String("audio_path", fullPath),
@@ -664,6 +666,22 @@ func fileExists(filename string) (bool, error) {
 	return !info.IsDir(), nil
 }
 
+// renderSpectrogram generates a PNG spectrogram for an audio file with the native renderer,
+// falling back to SoX and FFmpeg when the spectrogram settings allow it. Like the SoX
+// spectrograms, images narrower than 800 pixels are drawn without axes.
+func renderSpectrogram(ctx context.Context, audioClipPath, spectrogramPath string, width int) error {
+	settings := conf.Setting()
+	renderSettings := &settings.Realtime.Dashboard.Spectrogram
+	if spectrogram.UseNative(renderSettings) {
+		opts := spectrogram.OptionsFromSettings(renderSettings, width, width < 800)
+		err := spectrogram.RenderFile(ctx, audioClipPath, spectrogramPath, opts, settings.Realtime.Audio.FfmpegPath)
+		if err == nil || !spectrogram.UseExternal(renderSettings) {
+			return err
+		}
+	}
+	return createSpectrogramWithSoX(audioClipPath, spectrogramPath, width)
+}
+
 // createSpectrogramWithSoX generates a spectrogram for an audio file using ffmpeg and SoX.
 // It supports various audio formats by using ffmpeg to pipe the audio to SoX when necessary.
 func createSpectrogramWithSoX(audioClipPath, spectrogramPath string, width int) error {
@@ -168,6 +168,17 @@ func (h *Handlers) SaveSettings(c echo.Context) error {
 		}
 	}
 
+	// Check if per-source filter chains have changed
+	if !reflect.DeepEqual(oldSettings.Realtime.Audio.SourceFilters, settings.Realtime.Audio.SourceFilters) {
+		if err := myaudio.UpdateSourceFilters(settings); err != nil {
+			h.SSE.SendNotification(Notification{
+				Message: fmt.Sprintf("Error updating audio source filters: %v", err),
+				Type:    "error",
+			})
+			return h.NewHandlerError(err, "Failed to update audio source filters", http.StatusInternalServerError)
+		}
+	}
+
 	// Save settings to YAML file
 	if err := conf.SaveSettings(); err != nil {
 		h.SSE.SendNotification(Notification{
@@ -861,6 +872,12 @@ func soundLevelSettingsChanged(oldSettings, currentSettings *conf.Settings) bool
 		return true
 	}
 
+	// Check for changes in history (only if enabled), the history recorder is started with the publishers
+	if currentSettings.Realtime.Audio.SoundLevel.Enabled &&
+		oldSettings.Realtime.Audio.SoundLevel.History != currentSettings.Realtime.Audio.SoundLevel.History {
+		return true
+	}
+
 	return false
 }
 
@@ -182,6 +182,9 @@ func (m *mockStore) SearchNotes(query string, asc bool, limit, offset int) ([]da
 func (m *mockStore) SearchNotesAdvanced(filters *datastore.AdvancedSearchFilters) ([]datastore.Note, int64, error) {
 	return nil, 0, nil
 }
+func (m *mockStore) StreamNotesAdvanced(ctx context.Context, filters *datastore.AdvancedSearchFilters, batchSize int, fn func([]datastore.Note) error) error {
+	return nil
+}
 func (m *mockStore) GetNoteClipPath(noteID string) (string, error) { return "", nil }
 func (m *mockStore) DeleteNoteClipPath(noteID string) error        { return nil }
 func (m *mockStore) GetClipsQualifyingForRemoval(minHours, minClips int) ([]datastore.ClipForRemoval, error) {
@@ -35,7 +35,15 @@ type client struct {
 	reconnectTimer  *time.Timer
 	reconnectStop   chan struct{}
 	metrics         *metrics.MQTTMetrics
-	controlChan     chan string // Channel for control signals
+	controlChan     chan string             // Channel for control signals
+	spool           *Spool                  // Offline message spool, nil if disabled
+	subscriptions   map[string]subscription // Topic subscriptions, restored on every connect
+}
+
+// subscription holds the QoS and handler of a topic subscription
+type subscription struct {
+	qos     byte
+	handler MessageHandler
 }
 
 // NewClient creates a new MQTT client with the provided configuration.
@@ -88,9 +96,40 @@ func NewClient(settings *conf.Settings, observabilityMetrics *observability.Metr
 		reconnectStop: make(chan struct{}),
 		metrics:       observabilityMetrics.MQTT,
 		controlChan:   nil, // Will be set externally when needed
+		subscriptions: make(map[string]subscription),
 	}, nil
 }
 
+// ClientOptions holds the optional features of a long-lived client. Temporary clients,
+// such as the ones testing a connection, must not use them.
+type ClientOptions struct {
+	// Spool queues messages on disk while the broker is unreachable, they are published
+	// in order after reconnecting. The client keeps reconnecting in the background if the
+	// initial connection fails.
+	Spool *Spool
+	// AvailabilityTopic receives the online/offline state of the station, see Config
+	AvailabilityTopic string
+	// ClientID replaces the node name as client ID, clients of the same station on
+	// different brokers use their own ID
+	ClientID string
+}
+
+// NewClientWithOptions creates a new MQTT client with the optional features of opts.
+func NewClientWithOptions(settings *conf.Settings, observabilityMetrics *observability.Metrics, opts ClientOptions) (Client, error) {
+	c, err := NewClient(settings, observabilityMetrics)
+	if err != nil {
+		return nil, err
+	}
+	impl := c.(*client)
+	impl.spool = opts.Spool
+	impl.config.AvailabilityTopic = opts.AvailabilityTopic
+	if opts.ClientID != "" {
+		impl.config.ClientID = opts.ClientID
+	}
+	mqttLogger.Info("MQTT client options applied", "spool", opts.Spool != nil, "availability_topic", opts.AvailabilityTopic)
+	return impl, nil
+}
+
 // SetControlChannel sets the control channel for the client
 func (c *client) SetControlChannel(ch chan string) {
 	c.mu.Lock()
@@ -127,9 +166,24 @@ func (c *client) SetDebug(debug bool) {
 }
 
 // Connect attempts to establish a connection to the MQTT broker.
+// With a spool, a failed attempt starts the background reconnect so that the
+// queued messages are delivered once the broker is reachable.
+func (c *client) Connect(ctx context.Context) error {
+	err := c.connect(ctx)
+	if err != nil && c.spool != nil {
+		select {
+		case <-c.reconnectStop:
+		default:
+			c.startReconnectTimer()
+		}
+	}
+	return err
+}
+
+// connect performs a single connection attempt.
 // It holds the mutex only while checking state and creating the client instance,
 // releasing it before blocking network operations.
-func (c *client) Connect(ctx context.Context) error {
+func (c *client) connect(ctx context.Context) error {
 	if err := ctx.Err(); err != nil { // Check context early
 		mqttLogger.Warn("Connect context already cancelled", "error", err)
 		return err
@@ -187,6 +241,10 @@ func (c *client) Connect(ctx context.Context) error {
 	opts.SetPingTimeout(10 * time.Second)
 	opts.SetWriteTimeout(10 * time.Second)
 	opts.SetConnectTimeout(c.config.ConnectTimeout) // Use config timeout for initial connection attempt
+	if c.config.AvailabilityTopic != "" {
+		// The broker marks the station offline if the connection is lost without a disconnect
+		opts.SetWill(c.config.AvailabilityTopic, AvailabilityOffline, defaultQoS, true)
+	}
 
 	// Configure TLS if enabled
 	if c.config.TLS.Enabled {
@@ -393,6 +451,41 @@ func (c *client) Connect(ctx context.Context) error {
 
 // Publish sends a message to the specified topic on the MQTT broker.
 func (c *client) Publish(ctx context.Context, topic, payload string) error {
+	c.mu.RLock()
+	opts := PublishOptions{QoS: defaultQoS, Retain: c.config.Retain}
+	c.mu.RUnlock()
+	return c.PublishWithOptions(ctx, topic, payload, opts)
+}
+
+// PublishWithOptions sends a message to the specified topic with the given QoS and retain flag.
+// With a spool, messages are queued instead of failing while the broker is unreachable.
+func (c *client) PublishWithOptions(ctx context.Context, topic, payload string, opts PublishOptions) error {
+	if c.spool == nil {
+		return c.publish(ctx, topic, payload, opts)
+	}
+
+	// Queued messages are published first to keep the order
+	if c.spool.Len() == 0 && c.IsConnected() {
+		err := c.publish(ctx, topic, payload, opts)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		mqttLogger.Warn("Publish failed, queueing message in spool", "topic", topic, "error", err)
+	}
+
+	if err := c.spool.Enqueue(&SpooledMessage{Topic: topic, Payload: payload, QoS: opts.QoS, Retain: opts.Retain}); err != nil {
+		return err
+	}
+	mqttLogger.Debug("Message queued in spool", "topic", topic, "depth", c.spool.Len())
+
+	if c.IsConnected() {
+		go c.drainSpool()
+	}
+	return nil
+}
+
+// publish sends a message to the broker without spooling.
+func (c *client) publish(ctx context.Context, topic, payload string, opts PublishOptions) error {
 	// Check context before acquiring lock
 	if err := ctx.Err(); err != nil {
 		mqttLogger.Warn("Publish context already cancelled", "topic", topic, "error", err)
@@ -417,17 +510,16 @@ func (c *client) Publish(ctx context.Context, topic, payload string) error {
 	}
 	mqttLogger.Debug("Client is connected, continuing")
 	clientToPublish := c.internalClient // Get client instance under lock
-	currentRetain := c.config.Retain    // Get config value under lock
 	c.mu.Unlock()                       // Unlock before blocking publish call
 
-	logger := mqttLogger.With("topic", topic, "qos", defaultQoS, "retain", currentRetain)
+	logger := mqttLogger.With("topic", topic, "qos", opts.QoS, "retain", opts.Retain)
 	timer := c.metrics.StartPublishTimer()
 	defer timer.ObserveDuration()
 
 	logger.Debug("Attempting to publish message", "payload_size", len(payload))
 
 	// Perform the publish operation directly
-	token := clientToPublish.Publish(topic, defaultQoS, currentRetain, payload)
+	token := clientToPublish.Publish(topic, opts.QoS, opts.Retain, payload)
 
 	// Wait directly on the token with timeout
 	if !token.WaitTimeout(c.config.PublishTimeout) {
@@ -463,8 +555,8 @@ func (c *client) Publish(ctx context.Context, topic, payload string) error {
 			Context("client_id", c.config.ClientID).
 			Context("topic", topic).
 			Context("payload_size", len(payload)).
-			Context("qos", defaultQoS).
-			Context("retain", currentRetain).
+			Context("qos", opts.QoS).
+			Context("retain", opts.Retain).
 			Context("operation", "publish_error").
 			Build()
 		return enhancedErr
@@ -477,6 +569,59 @@ func (c *client) Publish(ctx context.Context, topic, payload string) error {
 	return nil
 }
 
+// Subscribe registers a handler for a topic. The subscription is stored and made again
+// on every connect, if the client is not connected it is made once the connection is up.
+func (c *client) Subscribe(ctx context.Context, topic string, qos byte, handler MessageHandler) error {
+	c.mu.Lock()
+	c.subscriptions[topic] = subscription{qos: qos, handler: handler}
+	clientToSubscribe := c.internalClient
+	c.mu.Unlock()
+
+	logger := mqttLogger.With("topic", topic, "qos", qos)
+	if clientToSubscribe == nil || !clientToSubscribe.IsConnected() {
+		logger.Debug("Client not connected, subscribing after connect")
+		return nil
+	}
+
+	token := clientToSubscribe.Subscribe(topic, qos, messageCallback(handler))
+	select {
+	case <-token.Done():
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(c.config.SubscribeTimeout):
+		return errors.Newf("subscribe timeout after %v", c.config.SubscribeTimeout).
+			Component("mqtt").
+			Category(errors.CategoryMQTTConnection).
+			Context("broker", c.config.Broker).
+			Context("topic", topic).
+			Context("operation", "subscribe_timeout").
+			Build()
+	}
+	if err := token.Error(); err != nil {
+		logger.Error("MQTT subscribe failed", "error", err)
+		return errors.New(err).
+			Component("mqtt").
+			Category(errors.CategoryMQTTConnection).
+			Context("broker", c.config.Broker).
+			Context("topic", topic).
+			Context("qos", qos).
+			Context("operation", "subscribe").
+			Build()
+	}
+	logger.Info("Subscribed to MQTT topic")
+	return nil
+}
+
+// messageCallback adapts a MessageHandler to Paho. The handler runs in its own goroutine,
+// Paho delivers messages from a single goroutine and a handler waiting on a publish
+// would block it.
+func messageCallback(handler MessageHandler) mqtt.MessageHandler {
+	return func(_ mqtt.Client, msg mqtt.Message) {
+		topic, payload := msg.Topic(), msg.Payload()
+		go handler(topic, payload)
+	}
+}
+
 // IsConnected returns true if the client is currently connected to the MQTT broker.
 func (c *client) IsConnected() bool {
 	// RLock is sufficient for read-only check
@@ -517,6 +662,13 @@ func (c *client) Disconnect() {
 		// Check connection status *outside* lock to avoid potential deadlock
 		// if IsConnected internally needs a lock (though it uses RLock)
 		if clientToDisconnect.IsConnected() {
+			// A clean disconnect does not trigger the last will, announce it explicitly
+			if c.config.AvailabilityTopic != "" {
+				token := clientToDisconnect.Publish(c.config.AvailabilityTopic, defaultQoS, true, AvailabilityOffline)
+				if !token.WaitTimeout(c.config.DisconnectTimeout) || token.Error() != nil {
+					logger.Debug("Failed to publish offline availability", "topic", c.config.AvailabilityTopic)
+				}
+			}
 			disconnectTimeoutMs := uint(c.config.DisconnectTimeout.Milliseconds()) // #nosec G115 -- timeout value conversion safe
 			logger.Debug("Sending disconnect signal to Paho client", "timeout_ms", disconnectTimeoutMs)
 			clientToDisconnect.Disconnect(disconnectTimeoutMs) // Perform disconnect outside lock
@@ -537,6 +689,32 @@ func (c *client) onConnect(client mqtt.Client) {
 	// Log using the package-level logger
 	mqttLogger.Info("Connected to MQTT broker", "broker", c.config.Broker, "client_id", c.config.ClientID)
 	c.metrics.UpdateConnectionStatus(true)
+
+	// Replace the retained last will with the online state. The handler must not wait
+	// for the token, Paho delivers the publish after the handler returns.
+	if c.config.AvailabilityTopic != "" {
+		client.Publish(c.config.AvailabilityTopic, defaultQoS, true, AvailabilityOnline)
+	}
+
+	// Clean sessions drop the subscriptions on the broker, make them again. As above,
+	// the tokens are only checked in the background.
+	c.mu.RLock()
+	for topic, sub := range c.subscriptions {
+		token := client.Subscribe(topic, sub.qos, messageCallback(sub.handler))
+		go func(topic string, token mqtt.Token) {
+			if token.WaitTimeout(c.config.SubscribeTimeout) && token.Error() == nil {
+				mqttLogger.Info("Subscribed to MQTT topic", "topic", topic)
+				return
+			}
+			mqttLogger.Error("Failed to subscribe to MQTT topic", "topic", topic, "error", token.Error())
+		}(topic, token)
+	}
+	c.mu.RUnlock()
+
+	// Deliver the messages queued while the broker was unreachable
+	if c.spool != nil && c.spool.Len() > 0 {
+		go c.drainSpool()
+	}
 	// Reset reconnect attempts on successful connection - might be handled by Connect logic resetting lastConnAttempt implicitly
 }
 
@@ -610,7 +788,7 @@ func (c *client) reconnectWithBackoff() {
 		// Proceed with connect attempt
 	}
 
-	if err := c.Connect(ctx); err != nil {
+	if err := c.connect(ctx); err != nil {
 		logger.Error("Reconnect attempt failed", "error", err)
 
 		// Extract error category for metrics
@@ -637,6 +815,53 @@ func (c *client) reconnectWithBackoff() {
 	}
 }
 
+// drainSpool publishes the spooled messages in order while the client is connected.
+// Only one drain of a spool runs at a time, also across clients replaced by a reconfiguration.
+// A failed publish stops it until the next connect or message.
+func (c *client) drainSpool() {
+	for {
+		if !c.spool.drainMu.TryLock() {
+			return
+		}
+		emptied := c.drainSpoolOnce()
+		c.spool.drainMu.Unlock()
+
+		// Messages queued while the drain was finishing would otherwise wait for the next trigger
+		if !emptied || c.spool.Len() == 0 || !c.IsConnected() {
+			return
+		}
+	}
+}
+
+// drainSpoolOnce publishes spooled messages until the spool is empty or a publish fails.
+// Returns true if the spool was emptied. Must be called with the spool drainMu held.
+func (c *client) drainSpoolOnce() bool {
+	published := 0
+	defer func() {
+		if published > 0 {
+			mqttLogger.Info("Published spooled messages", "count", published, "remaining", c.spool.Len())
+		}
+	}()
+
+	for c.IsConnected() {
+		msg, seq, ok := c.spool.Peek()
+		if !ok {
+			return true
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.config.PublishTimeout)
+		err := c.publish(ctx, msg.Topic, msg.Payload, PublishOptions{QoS: msg.QoS, Retain: msg.Retain})
+		cancel()
+		if err != nil {
+			mqttLogger.Warn("Failed to publish spooled message, keeping it queued", "topic", msg.Topic, "error", err)
+			return false
+		}
+		c.spool.Remove(seq)
+		published++
+	}
+	return false
+}
+
 // createTLSConfig creates a TLS configuration based on the client settings
 func (c *client) createTLSConfig() (*tls.Config, error) {
 	// Extract hostname from broker URL for ServerName
@@ -800,3 +800,34 @@ func TestIsIPAddress(t *testing.T) {
 		})
 	}
 }
+
+// TestSubscribeBeforeConnect verifies that a subscription made while disconnected is
+// kept for the next connect instead of failing
+func TestSubscribeBeforeConnect(t *testing.T) {
+	metrics, err := observability.NewMetrics()
+	if err != nil {
+		t.Fatalf("Failed to create metrics: %v", err)
+	}
+	settings := &conf.Settings{}
+	settings.Realtime.MQTT.Broker = localTestBroker
+	settings.Realtime.MQTT.Topic = testTopic
+
+	c, err := NewClient(settings, metrics)
+	if err != nil {
+		t.Fatalf("Failed to create MQTT client: %v", err)
+	}
+	defer c.Disconnect()
+
+	topic := testTopic + "/command"
+	if err := c.Subscribe(context.Background(), topic, testQoS, func(string, []byte) {}); err != nil {
+		t.Fatalf("Subscribe while disconnected returned error: %v", err)
+	}
+
+	impl := c.(*client)
+	impl.mu.RLock()
+	sub, ok := impl.subscriptions[topic]
+	impl.mu.RUnlock()
+	if !ok || sub.qos != testQoS || sub.handler == nil {
+		t.Errorf("subscription for %s not stored: %+v", topic, sub)
+	}
+}
@@ -0,0 +1,259 @@
+// homeassistant.go: Home Assistant MQTT discovery payloads and state topics
+package mqtt
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Topic levels below the configured base topic
+const (
+	availabilitySubtopic = "status"
+	statsSubtopic        = "stats"
+	speciesSubtopic      = "species"
+	soundLevelSubtopic   = "soundlevel"
+	sourcesSubtopic      = "sources"
+)
+
+// Audio source health states published to the sources topic
+const (
+	HASourcesOK      = "ok"
+	HASourcesProblem = "problem"
+)
+
+// AvailabilityTopic returns the availability topic below the base topic
+func AvailabilityTopic(baseTopic string) string {
+	return strings.TrimSuffix(baseTopic, "/") + "/" + availabilitySubtopic
+}
+
+// HAObjectID converts a name to a Home Assistant object id, lower case letters,
+// digits and underscores
+func HAObjectID(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// HADevice describes the station in discovery payloads. Entities with the same
+// device identifiers are grouped under one device in Home Assistant.
+type HADevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model"`
+	SWVersion    string   `json:"sw_version,omitempty"`
+}
+
+// HAEntityConfig is the discovery payload of a sensor or binary sensor
+type HAEntityConfig struct {
+	Name                string   `json:"name"`
+	UniqueID            string   `json:"unique_id"`
+	ObjectID            string   `json:"object_id"`
+	StateTopic          string   `json:"state_topic"`
+	ValueTemplate       string   `json:"value_template,omitempty"`
+	JSONAttributesTopic string   `json:"json_attributes_topic,omitempty"`
+	AvailabilityTopic   string   `json:"availability_topic"`
+	UnitOfMeasurement   string   `json:"unit_of_measurement,omitempty"`
+	DeviceClass         string   `json:"device_class,omitempty"`
+	StateClass          string   `json:"state_class,omitempty"`
+	Icon                string   `json:"icon,omitempty"`
+	PayloadOn           string   `json:"payload_on,omitempty"`
+	PayloadOff          string   `json:"payload_off,omitempty"`
+	Device              HADevice `json:"device"`
+}
+
+// HADiscoveryMessage is a discovery config message, published retained
+type HADiscoveryMessage struct {
+	Topic   string
+	Payload string
+}
+
+// HAStationState is the payload of the stats topic
+type HAStationState struct {
+	LastDetection   string  `json:"last_detection"`
+	ScientificName  string  `json:"scientific_name"`
+	Confidence      float64 `json:"confidence"`
+	DetectedAt      string  `json:"detected_at"`
+	Source          string  `json:"source"`
+	DetectionsToday int     `json:"detections_today"`
+	SpeciesToday    int     `json:"species_today"`
+}
+
+// HASpeciesState is the payload of a per-species topic
+type HASpeciesState struct {
+	CommonName     string  `json:"common_name"`
+	ScientificName string  `json:"scientific_name"`
+	Confidence     float64 `json:"confidence"`
+	DetectedAt     string  `json:"detected_at"`
+	CountToday     int     `json:"count_today"`
+}
+
+// HASoundLevelState is the payload of a per-source sound level topic
+type HASoundLevelState struct {
+	Level     float64 `json:"level"`
+	Name      string  `json:"name"`
+	Timestamp string  `json:"timestamp"`
+}
+
+// HASourceHealth is the health of a single audio source
+type HASourceHealth struct {
+	Healthy   bool   `json:"healthy"`
+	Receiving bool   `json:"receiving"`
+	LastData  string `json:"last_data,omitempty"`
+	Restarts  int    `json:"restarts"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HASourcesState is the payload of the sources topic
+type HASourcesState struct {
+	Status  string                    `json:"status"`
+	Sources map[string]HASourceHealth `json:"sources"`
+}
+
+// HomeAssistant builds the discovery payloads and state topics of a station
+type HomeAssistant struct {
+	Prefix    string   // discovery prefix configured in Home Assistant
+	BaseTopic string   // base topic of the state topics
+	NodeID    string   // node id of the discovery topics
+	Device    HADevice // device the entities belong to
+}
+
+// NewHomeAssistant creates the discovery builder for a station. The node name
+// identifies the device, deviceName is shown in Home Assistant.
+func NewHomeAssistant(prefix, baseTopic, nodeName, deviceName, version string) *HomeAssistant {
+	nodeID := HAObjectID(nodeName)
+	if nodeID == "" {
+		nodeID = "birdnet_go"
+	}
+	if deviceName == "" {
+		deviceName = nodeName
+	}
+	if deviceName == "" {
+		deviceName = "BirdNET-Go"
+	}
+
+	return &HomeAssistant{
+		Prefix:    strings.TrimSuffix(prefix, "/"),
+		BaseTopic: strings.TrimSuffix(baseTopic, "/"),
+		NodeID:    nodeID,
+		Device: HADevice{
+			Identifiers:  []string{"birdnet-go_" + nodeID},
+			Name:         deviceName,
+			Manufacturer: "BirdNET-Go",
+			Model:        "BirdNET-Go",
+			SWVersion:    version,
+		},
+	}
+}
+
+// AvailabilityTopic returns the topic with the online/offline state of the station
+func (h *HomeAssistant) AvailabilityTopic() string {
+	return AvailabilityTopic(h.BaseTopic)
+}
+
+// StatsTopic returns the topic of the last detection and daily counts
+func (h *HomeAssistant) StatsTopic() string {
+	return h.BaseTopic + "/" + statsSubtopic
+}
+
+// SpeciesTopic returns the state topic of a species
+func (h *HomeAssistant) SpeciesTopic(commonName string) string {
+	return h.BaseTopic + "/" + speciesSubtopic + "/" + HAObjectID(commonName)
+}
+
+// SoundLevelTopic returns the state topic of the sound level of an audio source
+func (h *HomeAssistant) SoundLevelTopic(sourceID string) string {
+	return h.BaseTopic + "/" + soundLevelSubtopic + "/" + HAObjectID(sourceID)
+}
+
+// SourcesTopic returns the topic of the audio source health
+func (h *HomeAssistant) SourcesTopic() string {
+	return h.BaseTopic + "/" + sourcesSubtopic
+}
+
+// StationConfigs returns the discovery messages of the station entities
+func (h *HomeAssistant) StationConfigs() []HADiscoveryMessage {
+	stats := h.StatsTopic()
+	return []HADiscoveryMessage{
+		h.message("sensor", &HAEntityConfig{
+			Name:                "Last detection",
+			ObjectID:            "last_detection",
+			StateTopic:          stats,
+			ValueTemplate:       "{{ value_json.last_detection }}",
+			JSONAttributesTopic: stats,
+			Icon:                "mdi:bird",
+		}),
+		h.message("sensor", &HAEntityConfig{
+			Name:              "Detections today",
+			ObjectID:          "detections_today",
+			StateTopic:        stats,
+			ValueTemplate:     "{{ value_json.detections_today }}",
+			UnitOfMeasurement: "detections",
+			StateClass:        "total_increasing",
+			Icon:              "mdi:counter",
+		}),
+		h.message("sensor", &HAEntityConfig{
+			Name:              "Species today",
+			ObjectID:          "species_today",
+			StateTopic:        stats,
+			ValueTemplate:     "{{ value_json.species_today }}",
+			UnitOfMeasurement: "species",
+			StateClass:        "measurement",
+			Icon:              "mdi:feather",
+		}),
+		h.message("binary_sensor", &HAEntityConfig{
+			Name:                "Audio source problem",
+			ObjectID:            "audio_source_problem",
+			StateTopic:          h.SourcesTopic(),
+			ValueTemplate:       "{{ value_json.status }}",
+			JSONAttributesTopic: h.SourcesTopic(),
+			DeviceClass:         "problem",
+			PayloadOn:           HASourcesProblem,
+			PayloadOff:          HASourcesOK,
+		}),
+	}
+}
+
+// SoundLevelConfig returns the discovery message of the sound level sensor of an audio source
+func (h *HomeAssistant) SoundLevelConfig(sourceID, name string) HADiscoveryMessage {
+	if name == "" {
+		name = sourceID
+	}
+	topic := h.SoundLevelTopic(sourceID)
+	return h.message("sensor", &HAEntityConfig{
+		Name:                "Sound level " + name,
+		ObjectID:            "sound_level_" + HAObjectID(sourceID),
+		StateTopic:          topic,
+		ValueTemplate:       "{{ value_json.level }}",
+		JSONAttributesTopic: topic,
+		UnitOfMeasurement:   "dB",
+		DeviceClass:         "sound_pressure",
+		StateClass:          "measurement",
+	})
+}
+
+// message completes an entity config with the device and availability of the station
+func (h *HomeAssistant) message(component string, cfg *HAEntityConfig) HADiscoveryMessage {
+	cfg.UniqueID = h.Device.Identifiers[0] + "_" + cfg.ObjectID
+	cfg.AvailabilityTopic = h.AvailabilityTopic()
+	cfg.Device = h.Device
+
+	// The payload only contains strings and cannot fail to marshal
+	payload, _ := json.Marshal(cfg)
+
+	return HADiscoveryMessage{
+		Topic:   h.Prefix + "/" + component + "/" + h.NodeID + "/" + cfg.ObjectID + "/config",
+		Payload: string(payload),
+	}
+}
@@ -0,0 +1,101 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHAObjectID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"European Robin", "european_robin"},
+		{"Black-capped Chickadee", "black_capped_chickadee"},
+		{"  Mixed  CASE 42 ", "mixed_case_42"},
+		{"rtsp_abc123", "rtsp_abc123"},
+		{"Grünfink", "gr_nfink"},
+		{"---", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, HAObjectID(tt.input), "input %q", tt.input)
+	}
+}
+
+func TestHomeAssistant_Topics(t *testing.T) {
+	t.Parallel()
+
+	ha := NewHomeAssistant("homeassistant/", "birdnet/", "Backyard Station", "", "1.2.3")
+
+	assert.Equal(t, "backyard_station", ha.NodeID)
+	assert.Equal(t, "Backyard Station", ha.Device.Name, "device name defaults to the node name")
+	assert.Equal(t, "birdnet/status", ha.AvailabilityTopic())
+	assert.Equal(t, "birdnet/stats", ha.StatsTopic())
+	assert.Equal(t, "birdnet/species/european_robin", ha.SpeciesTopic("European Robin"))
+	assert.Equal(t, "birdnet/soundlevel/malgo_0", ha.SoundLevelTopic("malgo:0"))
+	assert.Equal(t, "birdnet/sources", ha.SourcesTopic())
+	assert.Equal(t, AvailabilityTopic("birdnet"), ha.AvailabilityTopic())
+}
+
+func TestHomeAssistant_StationConfigs(t *testing.T) {
+	t.Parallel()
+
+	ha := NewHomeAssistant("homeassistant", "birdnet", "station", "Garden birds", "1.2.3")
+	msgs := ha.StationConfigs()
+	require.Len(t, msgs, 4)
+
+	topics := make(map[string]HAEntityConfig)
+	for _, msg := range msgs {
+		var cfg HAEntityConfig
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), &cfg), msg.Topic)
+		topics[msg.Topic] = cfg
+
+		// Every entity belongs to the station device and follows its availability
+		assert.Equal(t, []string{"birdnet-go_station"}, cfg.Device.Identifiers)
+		assert.Equal(t, "Garden birds", cfg.Device.Name)
+		assert.Equal(t, "1.2.3", cfg.Device.SWVersion)
+		assert.Equal(t, "birdnet/status", cfg.AvailabilityTopic)
+		assert.Equal(t, "birdnet-go_station_"+cfg.ObjectID, cfg.UniqueID)
+	}
+
+	last, ok := topics["homeassistant/sensor/station/last_detection/config"]
+	require.True(t, ok)
+	assert.Equal(t, "birdnet/stats", last.StateTopic)
+	assert.Equal(t, "birdnet/stats", last.JSONAttributesTopic)
+	assert.Equal(t, "{{ value_json.last_detection }}", last.ValueTemplate)
+
+	count, ok := topics["homeassistant/sensor/station/detections_today/config"]
+	require.True(t, ok)
+	assert.Equal(t, "total_increasing", count.StateClass)
+
+	_, ok = topics["homeassistant/sensor/station/species_today/config"]
+	assert.True(t, ok)
+
+	problem, ok := topics["homeassistant/binary_sensor/station/audio_source_problem/config"]
+	require.True(t, ok)
+	assert.Equal(t, "problem", problem.DeviceClass)
+	assert.Equal(t, HASourcesProblem, problem.PayloadOn)
+	assert.Equal(t, HASourcesOK, problem.PayloadOff)
+}
+
+func TestHomeAssistant_SoundLevelConfig(t *testing.T) {
+	t.Parallel()
+
+	ha := NewHomeAssistant("homeassistant", "birdnet", "station", "", "")
+	msg := ha.SoundLevelConfig("malgo:0", "USB Microphone")
+
+	assert.Equal(t, "homeassistant/sensor/station/sound_level_malgo_0/config", msg.Topic)
+
+	var cfg HAEntityConfig
+	require.NoError(t, json.Unmarshal([]byte(msg.Payload), &cfg))
+	assert.Equal(t, "Sound level USB Microphone", cfg.Name)
+	assert.Equal(t, "birdnet/soundlevel/malgo_0", cfg.StateTopic)
+	assert.Equal(t, "dB", cfg.UnitOfMeasurement)
+	assert.Equal(t, "sound_pressure", cfg.DeviceClass)
+}
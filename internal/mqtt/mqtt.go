@@ -22,6 +22,15 @@ type Client interface {
 	// It returns an error if the publish operation fails.
 	Publish(ctx context.Context, topic string, payload string) error
 
+	// PublishWithOptions sends a message with the given QoS and retain flag instead
+	// of the configured defaults.
+	PublishWithOptions(ctx context.Context, topic string, payload string, opts PublishOptions) error
+
+	// Subscribe registers a handler for the messages received on a topic. The subscription
+	// is made when the client connects and restored after every reconnect.
+	// It returns an error if the client is connected and the broker rejects the subscription.
+	Subscribe(ctx context.Context, topic string, qos byte, handler MessageHandler) error
+
 	// IsConnected returns true if the client is currently connected to the MQTT broker.
 	IsConnected() bool
 
@@ -39,24 +48,44 @@ type Client interface {
 
 // Config holds the configuration for the MQTT client.
 type Config struct {
-	Broker            string
-	Debug             bool
-	ClientID          string
-	Username          string
-	Password          string
-	Topic             string // Default topic for publishing messages
-	Retain            bool   // true to retain messages at the broker
+	Broker   string
+	Debug    bool
+	ClientID string
+	Username string
+	Password string
+	Topic    string // Default topic for publishing messages
+	Retain   bool   // true to retain messages at the broker
+	// AvailabilityTopic receives a retained "online" message on connect and is set to
+	// "offline" on disconnect or, as last will, when the connection is lost. Empty disables it.
+	AvailabilityTopic string
 	ReconnectCooldown time.Duration
 	ReconnectDelay    time.Duration
 	// Connection timeouts
 	ConnectTimeout    time.Duration
 	ReconnectTimeout  time.Duration
 	PublishTimeout    time.Duration
+	SubscribeTimeout  time.Duration
 	DisconnectTimeout time.Duration
 	// TLS configuration
 	TLS TLSConfig
 }
 
+// PublishOptions holds the delivery options of a single message.
+type PublishOptions struct {
+	QoS    byte // Quality of Service level, 0, 1 or 2
+	Retain bool // true to retain the message at the broker
+}
+
+// MessageHandler is called with the topic and payload of a received message. Handlers
+// run in their own goroutine and may publish.
+type MessageHandler func(topic string, payload []byte)
+
+// Availability payloads published to Config.AvailabilityTopic
+const (
+	AvailabilityOnline  = "online"
+	AvailabilityOffline = "offline"
+)
+
 // TLSConfig holds TLS/SSL configuration for secure MQTT connections
 type TLSConfig struct {
 	Enabled            bool   // true to enable TLS (auto-detected from broker URL)
@@ -125,6 +154,7 @@ func DefaultConfig() Config {
 		ConnectTimeout:    30 * time.Second,
 		ReconnectTimeout:  5 * time.Second,
 		PublishTimeout:    10 * time.Second,
+		SubscribeTimeout:  10 * time.Second,
 		DisconnectTimeout: 250 * time.Millisecond,
 	}
 }
@@ -0,0 +1,255 @@
+// spool.go: Disk-backed queue for messages that could not be published
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// spoolFileSuffix is the file name suffix of spooled messages, the file name is the
+// zero padded sequence number of the message
+const spoolFileSuffix = ".msg"
+
+// SpoolConfig holds the location and limits of a message spool. Zero limits disable the limit.
+type SpoolConfig struct {
+	Dir         string        // directory of the message files
+	MaxMessages int           // maximum number of queued messages, oldest are dropped first
+	MaxBytes    int64         // maximum total size of the message files
+	MaxAge      time.Duration // messages older than this are discarded
+}
+
+// SpooledMessage is a message waiting to be published
+type SpooledMessage struct {
+	Topic    string    `json:"topic"`
+	Payload  string    `json:"payload"`
+	QoS      byte      `json:"qos"`
+	Retain   bool      `json:"retain"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// SpoolStats describes the state of a spool
+type SpoolStats struct {
+	Depth   int        `json:"depth"`            // number of queued messages
+	Bytes   int64      `json:"bytes"`            // total size of the message files
+	Dropped int64      `json:"dropped"`          // messages discarded by the size and age limits since startup
+	Oldest  *time.Time `json:"oldest,omitempty"` // queue time of the oldest message
+}
+
+// spoolEntry is the in-memory index entry of a message file
+type spoolEntry struct {
+	seq      uint64
+	size     int64
+	queuedAt time.Time
+}
+
+// Spool is a FIFO queue of messages stored as one file per message. Messages survive
+// restarts and are returned in the order they were queued.
+type Spool struct {
+	mu      sync.Mutex
+	cfg     SpoolConfig
+	entries []spoolEntry // queued messages, oldest first
+	bytes   int64
+	nextSeq uint64
+	dropped int64
+
+	drainMu sync.Mutex // held by the client publishing the queued messages
+}
+
+// OpenSpool opens the spool directory, creating it if needed, and indexes the messages
+// left from a previous run
+func OpenSpool(cfg SpoolConfig) (*Spool, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		return nil, spoolError(err, "create_spool_dir", cfg.Dir)
+	}
+
+	files, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, spoolError(err, "read_spool_dir", cfg.Dir)
+	}
+
+	s := &Spool{cfg: cfg, nextSeq: 1}
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, spoolFileSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolFileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		// Files are written once, the modification time is the queue time
+		s.entries = append(s.entries, spoolEntry{seq: seq, size: info.Size(), queuedAt: info.ModTime()})
+		s.bytes += info.Size()
+		if seq >= s.nextSeq {
+			s.nextSeq = seq + 1
+		}
+	}
+	sort.Slice(s.entries, func(i, j int) bool { return s.entries[i].seq < s.entries[j].seq })
+
+	s.mu.Lock()
+	s.enforceLimits(time.Now())
+	s.mu.Unlock()
+
+	if len(s.entries) > 0 {
+		mqttLogger.Info("Opened MQTT spool with queued messages", "dir", cfg.Dir, "depth", len(s.entries), "bytes", s.bytes)
+	}
+	return s, nil
+}
+
+// SetLimits changes the size and age limits, the directory cannot be changed
+func (s *Spool) SetLimits(maxMessages int, maxBytes int64, maxAge time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg.MaxMessages = maxMessages
+	s.cfg.MaxBytes = maxBytes
+	s.cfg.MaxAge = maxAge
+	s.enforceLimits(time.Now())
+}
+
+// Enqueue stores a message at the end of the queue. The oldest messages are dropped
+// when the queue exceeds its limits.
+func (s *Spool) Enqueue(msg *SpooledMessage) error {
+	if msg.QueuedAt.IsZero() {
+		msg.QueuedAt = time.Now()
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return spoolError(err, "marshal_spool_message", s.cfg.Dir)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seq := s.nextSeq
+	path := s.path(seq)
+
+	// Write to a temporary file first so that a crash never leaves a partial message
+	tmp := filepath.Join(s.cfg.Dir, "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return spoolError(err, "write_spool_message", s.cfg.Dir)
+	}
+	if err := os.Chtimes(tmp, msg.QueuedAt, msg.QueuedAt); err != nil {
+		mqttLogger.Debug("Failed to set spooled message time", "error", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return spoolError(err, "rename_spool_message", s.cfg.Dir)
+	}
+
+	s.nextSeq++
+	s.entries = append(s.entries, spoolEntry{seq: seq, size: int64(len(data)), queuedAt: msg.QueuedAt})
+	s.bytes += int64(len(data))
+	s.enforceLimits(time.Now())
+	return nil
+}
+
+// Peek returns the oldest queued message and its sequence number without removing it.
+// ok is false if the queue is empty. Expired and unreadable messages are discarded.
+func (s *Spool) Peek() (msg *SpooledMessage, seq uint64, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enforceLimits(time.Now())
+	for len(s.entries) > 0 {
+		e := s.entries[0]
+		data, err := os.ReadFile(s.path(e.seq))
+		if err == nil {
+			var m SpooledMessage
+			if err = json.Unmarshal(data, &m); err == nil {
+				return &m, e.seq, true
+			}
+		}
+		mqttLogger.Warn("Discarding unreadable spooled message", "seq", e.seq, "error", err)
+		s.removeHead()
+		s.dropped++
+	}
+	return nil, 0, false
+}
+
+// Remove deletes a message returned by Peek after it has been published
+func (s *Spool) Remove(seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) > 0 && s.entries[0].seq == seq {
+		s.removeHead()
+	}
+}
+
+// Len returns the number of queued messages
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Stats returns the queue depth, size and dropped message count
+func (s *Spool) Stats() SpoolStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := SpoolStats{Depth: len(s.entries), Bytes: s.bytes, Dropped: s.dropped}
+	if len(s.entries) > 0 {
+		oldest := s.entries[0].queuedAt
+		stats.Oldest = &oldest
+	}
+	return stats
+}
+
+// enforceLimits drops expired messages and the oldest messages over the count and
+// size limits. Must be called with mu held.
+func (s *Spool) enforceLimits(now time.Time) {
+	dropped := 0
+	for len(s.entries) > 0 {
+		e := s.entries[0]
+		expired := s.cfg.MaxAge > 0 && now.Sub(e.queuedAt) > s.cfg.MaxAge
+		overCount := s.cfg.MaxMessages > 0 && len(s.entries) > s.cfg.MaxMessages
+		overSize := s.cfg.MaxBytes > 0 && s.bytes > s.cfg.MaxBytes
+		if !expired && !overCount && !overSize {
+			break
+		}
+		s.removeHead()
+		dropped++
+	}
+	if dropped > 0 {
+		s.dropped += int64(dropped)
+		mqttLogger.Warn("Dropped spooled MQTT messages over the spool limits", "dropped", dropped, "depth", len(s.entries))
+	}
+}
+
+// removeHead deletes the oldest message. Must be called with mu held.
+func (s *Spool) removeHead() {
+	e := s.entries[0]
+	if err := os.Remove(s.path(e.seq)); err != nil && !os.IsNotExist(err) {
+		mqttLogger.Warn("Failed to delete spooled message", "seq", e.seq, "error", err)
+	}
+	s.entries = s.entries[1:]
+	s.bytes -= e.size
+}
+
+// path returns the file of a message
+func (s *Spool) path(seq uint64) string {
+	return filepath.Join(s.cfg.Dir, fmt.Sprintf("%020d%s", seq, spoolFileSuffix))
+}
+
+// spoolError wraps a spool file error
+func spoolError(err error, operation, dir string) error {
+	return errors.New(err).
+		Component("mqtt").
+		Category(errors.CategoryFileIO).
+		Context("operation", operation).
+		Context("spool_dir", dir).
+		Build()
+}
@@ -0,0 +1,179 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/observability"
+)
+
+// drainTestSpool returns the topics of all queued messages in order and removes them
+func drainTestSpool(t *testing.T, s *Spool) []string {
+	t.Helper()
+	var topics []string
+	for {
+		msg, seq, ok := s.Peek()
+		if !ok {
+			return topics
+		}
+		topics = append(topics, msg.Topic)
+		s.Remove(seq)
+	}
+}
+
+func TestSpool_Order(t *testing.T) {
+	t.Parallel()
+
+	s, err := OpenSpool(SpoolConfig{Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	for i := range 3 {
+		require.NoError(t, s.Enqueue(&SpooledMessage{Topic: fmt.Sprintf("t%d", i), Payload: "p", QoS: 1, Retain: i == 1}))
+	}
+	assert.Equal(t, 3, s.Len())
+
+	// Peek does not remove the message
+	msg, seq, ok := s.Peek()
+	require.True(t, ok)
+	assert.Equal(t, "t0", msg.Topic)
+	assert.Equal(t, byte(1), msg.QoS)
+	again, _, _ := s.Peek()
+	assert.Equal(t, msg.Topic, again.Topic)
+
+	s.Remove(seq)
+	msg, _, _ = s.Peek()
+	assert.True(t, msg.Retain, "flags are kept")
+
+	assert.Equal(t, []string{"t1", "t2"}, drainTestSpool(t, s))
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestSpool_Persistence(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	s, err := OpenSpool(SpoolConfig{Dir: dir})
+	require.NoError(t, err)
+	for i := range 12 {
+		require.NoError(t, s.Enqueue(&SpooledMessage{Topic: fmt.Sprintf("t%02d", i), Payload: "p"}))
+	}
+	_, seq, _ := s.Peek()
+	s.Remove(seq)
+
+	// A reopened spool continues in order after the remaining messages
+	reopened, err := OpenSpool(SpoolConfig{Dir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, 11, reopened.Len())
+	require.NoError(t, reopened.Enqueue(&SpooledMessage{Topic: "new"}))
+
+	topics := drainTestSpool(t, reopened)
+	require.Len(t, topics, 12)
+	assert.Equal(t, "t01", topics[0])
+	assert.Equal(t, "new", topics[11])
+}
+
+func TestSpool_Limits(t *testing.T) {
+	t.Parallel()
+
+	t.Run("message count", func(t *testing.T) {
+		t.Parallel()
+		s, err := OpenSpool(SpoolConfig{Dir: t.TempDir(), MaxMessages: 2})
+		require.NoError(t, err)
+		for i := range 4 {
+			require.NoError(t, s.Enqueue(&SpooledMessage{Topic: fmt.Sprintf("t%d", i)}))
+		}
+		stats := s.Stats()
+		assert.Equal(t, 2, stats.Depth)
+		assert.Equal(t, int64(2), stats.Dropped)
+		assert.Equal(t, []string{"t2", "t3"}, drainTestSpool(t, s), "the oldest messages are dropped")
+	})
+
+	t.Run("size", func(t *testing.T) {
+		t.Parallel()
+		s, err := OpenSpool(SpoolConfig{Dir: t.TempDir(), MaxBytes: 250})
+		require.NoError(t, err)
+		for i := range 5 {
+			require.NoError(t, s.Enqueue(&SpooledMessage{Topic: fmt.Sprintf("t%d", i), Payload: "0123456789"}))
+		}
+		stats := s.Stats()
+		assert.LessOrEqual(t, stats.Bytes, int64(250))
+		assert.Positive(t, stats.Dropped)
+		topics := drainTestSpool(t, s)
+		assert.Equal(t, "t4", topics[len(topics)-1])
+	})
+
+	t.Run("age", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		s, err := OpenSpool(SpoolConfig{Dir: dir, MaxAge: time.Hour})
+		require.NoError(t, err)
+		require.NoError(t, s.Enqueue(&SpooledMessage{Topic: "old", QueuedAt: time.Now().Add(-2 * time.Hour)}))
+		require.NoError(t, s.Enqueue(&SpooledMessage{Topic: "recent"}))
+		assert.Equal(t, []string{"recent"}, drainTestSpool(t, s))
+
+		// Lowering the age limit drops queued messages
+		require.NoError(t, s.Enqueue(&SpooledMessage{Topic: "old", QueuedAt: time.Now().Add(-30 * time.Minute)}))
+		s.SetLimits(0, 0, 10*time.Minute)
+		assert.Equal(t, 0, s.Len())
+	})
+}
+
+func TestSpool_UnreadableMessage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	s, err := OpenSpool(SpoolConfig{Dir: dir})
+	require.NoError(t, err)
+	require.NoError(t, s.Enqueue(&SpooledMessage{Topic: "broken"}))
+	require.NoError(t, s.Enqueue(&SpooledMessage{Topic: "ok"}))
+	require.NoError(t, os.WriteFile(s.path(1), []byte("{"), 0o600))
+
+	assert.Equal(t, []string{"ok"}, drainTestSpool(t, s))
+	assert.Equal(t, int64(1), s.Stats().Dropped)
+
+	// Temporary and unrelated files are ignored when opening
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".00000000000000000009.msg.tmp"), []byte("{}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600))
+	reopened, err := OpenSpool(SpoolConfig{Dir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, 0, reopened.Len())
+}
+
+// TestClientSpool_QueuesWhileDisconnected verifies that a client with a spool queues
+// messages instead of failing when it is not connected
+func TestClientSpool_QueuesWhileDisconnected(t *testing.T) {
+	t.Parallel()
+
+	metrics, err := observability.NewMetrics()
+	require.NoError(t, err)
+	settings := &conf.Settings{}
+	settings.Realtime.MQTT.Broker = "tcp://localhost:1883"
+	settings.Realtime.MQTT.Topic = "birdnet"
+
+	spool, err := OpenSpool(SpoolConfig{Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	spooled, err := NewClientWithOptions(settings, metrics, ClientOptions{Spool: spool})
+	require.NoError(t, err)
+	defer spooled.Disconnect()
+
+	require.NoError(t, spooled.Publish(context.Background(), "birdnet", "first"))
+	require.NoError(t, spooled.PublishWithOptions(context.Background(), "birdnet/species/robin", "second", PublishOptions{QoS: 2, Retain: true}))
+	assert.Equal(t, 2, spool.Len())
+
+	msg, _, ok := spool.Peek()
+	require.True(t, ok)
+	assert.Equal(t, "first", msg.Payload)
+
+	// Without a spool the publish fails
+	plain, err := NewClient(settings, metrics)
+	require.NoError(t, err)
+	require.Error(t, plain.Publish(context.Background(), "birdnet", "lost"))
+}
@@ -0,0 +1,192 @@
+// templates.go: Topic and payload templates for detection messages
+package mqtt
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Payload profiles of detection messages
+const (
+	PayloadProfileFull      = "full"      // the note with the bird image, the original message format
+	PayloadProfileCompact   = "compact"   // flat fields of DetectionMessage
+	PayloadProfileBirdNETPi = "birdnetpi" // columns of the BirdNET-Pi detections table
+)
+
+// DetectionMessage holds the flat fields of a detection that are available to the topic and
+// payload templates. It is also the payload of the compact profile.
+type DetectionMessage struct {
+	Station            string    `json:"station"`
+	Source             string    `json:"source"`
+	SourceID           string    `json:"sourceId"`
+	CommonName         string    `json:"commonName"`
+	ScientificName     string    `json:"scientificName"`
+	SpeciesCode        string    `json:"speciesCode,omitempty"`
+	Confidence         float64   `json:"confidence"`
+	Timestamp          time.Time `json:"timestamp"`
+	ClipName           string    `json:"clipName,omitempty"`
+	Latitude           float64   `json:"latitude,omitempty"`
+	Longitude          float64   `json:"longitude,omitempty"`
+	Threshold          float64   `json:"threshold,omitempty"`
+	Sensitivity        float64   `json:"sensitivity,omitempty"`
+	Overlap            float64   `json:"-"` // analysis overlap, used by the BirdNET-Pi profile
+	NewSpecies         bool      `json:"newSpecies,omitempty"`
+	DaysSinceFirstSeen int       `json:"daysSinceFirstSeen,omitempty"`
+}
+
+// BirdNETPiPayload is the payload of the BirdNET-Pi profile, the field names follow the
+// detections table of BirdNET-Pi so that existing consumers keep working
+type BirdNETPiPayload struct {
+	Date           string  `json:"Date"`
+	Time           string  `json:"Time"`
+	ScientificName string  `json:"Sci_Name"`
+	CommonName     string  `json:"Com_Name"`
+	Confidence     float64 `json:"Confidence"`
+	Lat            float64 `json:"Lat"`
+	Lon            float64 `json:"Lon"`
+	Cutoff         float64 `json:"Cutoff"`
+	Week           int     `json:"Week"` // ISO week of the detection
+	Sens           float64 `json:"Sens"`
+	Overlap        float64 `json:"Overlap"`
+	FileName       string  `json:"File_Name"`
+}
+
+// templateFuncs are the functions available in topic and payload templates
+var templateFuncs = template.FuncMap{
+	"json":  templateJSON,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"slug":  HAObjectID,
+}
+
+// templateJSON renders a value as JSON, strings are quoted and escaped
+func templateJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// topicReplacer replaces the characters with a meaning in topics from template values
+var topicReplacer = strings.NewReplacer("/", "_", "+", "_", "#", "_")
+
+// MessageTemplates renders the topic and payload of detection messages
+type MessageTemplates struct {
+	topic   *template.Template
+	payload *template.Template // nil to use the profile
+	profile string
+}
+
+// NewMessageTemplates parses the topic and payload templates. An empty payload template
+// selects the payload of the profile, an empty profile is the full profile.
+func NewMessageTemplates(topic, payload, profile string) (*MessageTemplates, error) {
+	t := &MessageTemplates{profile: profile}
+	if t.profile == "" {
+		t.profile = PayloadProfileFull
+	}
+	switch t.profile {
+	case PayloadProfileFull, PayloadProfileCompact, PayloadProfileBirdNETPi:
+	default:
+		return nil, templateError(errors.NewStd("unknown payload profile "+profile), "payload_profile")
+	}
+
+	var err error
+	if t.topic, err = ParseTemplate("topic", topic); err != nil {
+		return nil, err
+	}
+	if payload != "" {
+		if t.payload, err = ParseTemplate("payload", payload); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// ParseTemplate parses a topic or payload template with the template functions
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, templateError(err, "parse_"+name+"_template")
+	}
+	return tmpl, nil
+}
+
+// Topic renders the topic of a message. Slashes and wildcards in the message fields are
+// replaced so that a value cannot add topic levels.
+func (t *MessageTemplates) Topic(msg *DetectionMessage) (string, error) {
+	safe := *msg
+	for _, field := range []*string{&safe.Station, &safe.Source, &safe.SourceID, &safe.CommonName, &safe.ScientificName, &safe.SpeciesCode, &safe.ClipName} {
+		*field = topicReplacer.Replace(*field)
+	}
+
+	topic, err := execute(t.topic, &safe)
+	if err != nil {
+		return "", err
+	}
+	if topic == "" {
+		return "", templateError(errors.NewStd("topic template rendered an empty topic"), "render_topic")
+	}
+	return topic, nil
+}
+
+// Payload renders the payload of a message. full is marshaled by the full profile.
+func (t *MessageTemplates) Payload(msg *DetectionMessage, full any) (string, error) {
+	if t.payload != nil {
+		return execute(t.payload, msg)
+	}
+
+	var v any
+	switch t.profile {
+	case PayloadProfileCompact:
+		v = msg
+	case PayloadProfileBirdNETPi:
+		v = NewBirdNETPiPayload(msg)
+	default:
+		v = full
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", templateError(err, "marshal_payload")
+	}
+	return string(data), nil
+}
+
+// NewBirdNETPiPayload converts a message to the BirdNET-Pi format
+func NewBirdNETPiPayload(msg *DetectionMessage) BirdNETPiPayload {
+	_, week := msg.Timestamp.ISOWeek()
+	return BirdNETPiPayload{
+		Date:           msg.Timestamp.Format("2006-01-02"),
+		Time:           msg.Timestamp.Format("15:04:05"),
+		ScientificName: msg.ScientificName,
+		CommonName:     msg.CommonName,
+		Confidence:     msg.Confidence,
+		Lat:            msg.Latitude,
+		Lon:            msg.Longitude,
+		Cutoff:         msg.Threshold,
+		Week:           week,
+		Sens:           msg.Sensitivity,
+		Overlap:        msg.Overlap,
+		FileName:       msg.ClipName,
+	}
+}
+
+// execute renders a template
+func execute(tmpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", templateError(err, "render_"+tmpl.Name())
+	}
+	return buf.String(), nil
+}
+
+// templateError wraps a template error
+func templateError(err error, operation string) error {
+	return errors.New(err).
+		Component("mqtt").
+		Category(errors.CategoryConfiguration).
+		Context("operation", operation).
+		Build()
+}
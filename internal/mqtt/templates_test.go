@@ -0,0 +1,130 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDetectionMessage returns a message with the common template fields
+func testDetectionMessage() *DetectionMessage {
+	return &DetectionMessage{
+		Station:        "garden",
+		Source:         "cam/1+",
+		CommonName:     "Eurasian Wren",
+		ScientificName: "Troglodytes troglodytes",
+		Confidence:     0.87,
+		Timestamp:      time.Date(2024, 6, 3, 5, 12, 30, 0, time.UTC),
+		ClipName:       "clips/wren.wav",
+		Latitude:       60.17,
+		Longitude:      24.94,
+		Threshold:      0.8,
+		Sensitivity:    1.0,
+		Overlap:        1.5,
+	}
+}
+
+func TestMessageTemplates_Topic(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"static topic", "birdnet", "birdnet"},
+		{"fields", "birds/{{.Station}}/{{.ScientificName}}", "birds/garden/Troglodytes troglodytes"},
+		{"functions", "birds/{{.CommonName | slug}}/{{.Station | upper}}", "birds/eurasian_wren/GARDEN"},
+		{"levels from values are replaced", "birds/{{.Source}}", "birds/cam_1_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			templates, err := NewMessageTemplates(tt.template, "", "")
+			require.NoError(t, err)
+			topic, err := templates.Topic(testDetectionMessage())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, topic)
+		})
+	}
+}
+
+func TestMessageTemplates_Payload(t *testing.T) {
+	t.Parallel()
+
+	full := map[string]string{"format": "full"}
+
+	t.Run("full", func(t *testing.T) {
+		t.Parallel()
+		templates, err := NewMessageTemplates("birdnet", "", PayloadProfileFull)
+		require.NoError(t, err)
+		payload, err := templates.Payload(testDetectionMessage(), full)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"format":"full"}`, payload)
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		t.Parallel()
+		templates, err := NewMessageTemplates("birdnet", "", PayloadProfileCompact)
+		require.NoError(t, err)
+		payload, err := templates.Payload(testDetectionMessage(), full)
+		require.NoError(t, err)
+		var msg DetectionMessage
+		require.NoError(t, json.Unmarshal([]byte(payload), &msg))
+		assert.Equal(t, "Eurasian Wren", msg.CommonName)
+		assert.Zero(t, msg.Overlap, "overlap is only in the BirdNET-Pi profile")
+	})
+
+	t.Run("birdnetpi", func(t *testing.T) {
+		t.Parallel()
+		templates, err := NewMessageTemplates("birdnet", "", PayloadProfileBirdNETPi)
+		require.NoError(t, err)
+		payload, err := templates.Payload(testDetectionMessage(), full)
+		require.NoError(t, err)
+		var row map[string]any
+		require.NoError(t, json.Unmarshal([]byte(payload), &row))
+		assert.Equal(t, "2024-06-03", row["Date"])
+		assert.Equal(t, "05:12:30", row["Time"])
+		assert.Equal(t, "Troglodytes troglodytes", row["Sci_Name"])
+		assert.Equal(t, "Eurasian Wren", row["Com_Name"])
+		assert.InDelta(t, 23, row["Week"], 0)
+		assert.InDelta(t, 0.8, row["Cutoff"], 0.001)
+		assert.InDelta(t, 1.5, row["Overlap"], 0.001)
+		assert.Equal(t, "clips/wren.wav", row["File_Name"])
+	})
+
+	t.Run("template", func(t *testing.T) {
+		t.Parallel()
+		templates, err := NewMessageTemplates("birdnet", `{"species":{{json .CommonName}},"confidence":{{.Confidence}}}`, PayloadProfileCompact)
+		require.NoError(t, err)
+		payload, err := templates.Payload(testDetectionMessage(), full)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"species":"Eurasian Wren","confidence":0.87}`, payload)
+	})
+}
+
+func TestMessageTemplates_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewMessageTemplates("birdnet", "", "verbose")
+	require.Error(t, err, "unknown profile")
+
+	_, err = NewMessageTemplates("birds/{{.Station", "", "")
+	require.Error(t, err, "unclosed action")
+
+	// Unknown fields fail when rendering
+	templates, err := NewMessageTemplates("birds/{{.Genus}}", "", "")
+	require.NoError(t, err)
+	_, err = templates.Topic(testDetectionMessage())
+	require.Error(t, err)
+
+	// An empty topic is not published
+	templates, err = NewMessageTemplates("{{.SpeciesCode}}", "", "")
+	require.NoError(t, err)
+	_, err = templates.Topic(testDetectionMessage())
+	require.Error(t, err)
+}
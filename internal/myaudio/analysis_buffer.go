@@ -579,7 +579,8 @@ func AnalysisBufferExists(sourceID string) bool {
 }
 
 // AnalysisBufferMonitor monitors the buffer and processes audio data when enough data is present.
-func AnalysisBufferMonitor(wg *sync.WaitGroup, bn *birdnet.BirdNET, quitChan chan struct{}, sourceID string) {
+// Each chunk of audio data is analyzed by every model the source is routed to.
+func AnalysisBufferMonitor(wg *sync.WaitGroup, models *birdnet.ModelRegistry, quitChan chan struct{}, sourceID string) {
 	// preRecordingTime is the time to subtract from the current time to get the start time of the detection
 	const preRecordingTime = -5000 * time.Millisecond
 
@@ -588,6 +589,9 @@ func AnalysisBufferMonitor(wg *sync.WaitGroup, bn *birdnet.BirdNET, quitChan cha
 		wg.Done()
 	}()
 
+	// Sources are registered before their monitors are started
+	routingKeys := sourceRoutingKeys(sourceID)
+
 	// Creating a ticker that ticks every 100ms
 	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
@@ -630,16 +634,16 @@ func AnalysisBufferMonitor(wg *sync.WaitGroup, bn *birdnet.BirdNET, quitChan cha
 
 				// DEBUG
 				//log.Printf("Processing data for source ID %s", sourceID)
-				err := ProcessData(bn, data, startTime, sourceID)
+				for _, bn := range models.ModelsForSource(routingKeys...) {
+					if err := ProcessData(bn, data, startTime, sourceID); err != nil {
+						log.Printf("❌ Error processing data for source ID %s with model %s: %v", sourceID, bn.ModelInfo.ID, err)
+					}
+				}
 
 				if m := getAnalysisMetrics(); m != nil {
 					processingDuration := time.Since(processingStart).Seconds()
 					m.RecordAnalysisBufferProcessingDuration(sourceID, processingDuration)
 				}
-
-				if err != nil {
-					log.Printf("❌ Error processing data for source ID %s: %v", sourceID, err)
-				}
 			} else if m := getAnalysisMetrics(); m != nil {
 				m.RecordAnalysisBufferPoll(sourceID, "insufficient_data")
 			}
@@ -647,6 +651,26 @@ func AnalysisBufferMonitor(wg *sync.WaitGroup, bn *birdnet.BirdNET, quitChan cha
 	}
 }
 
+// sourceRoutingKeys returns the keys identifying a source for model routing: the
+// source ID, and when the source is registered, its display name and connection string.
+func sourceRoutingKeys(sourceID string) []string {
+	keys := []string{sourceID}
+
+	registry := GetRegistry()
+	if registry == nil {
+		return keys
+	}
+	source, exists := registry.GetSourceByID(sourceID)
+	if !exists {
+		return keys
+	}
+	keys = append(keys, source.DisplayName)
+	if connection, err := source.GetConnectionString(); err == nil {
+		keys = append(keys, connection)
+	}
+	return keys
+}
+
 /*func validatePCMData(data []byte) error {
 	// Check if the data size is a multiple of the sample size (e.g., 2 bytes for 16-bit audio)
 	if len(data)%2 != 0 {
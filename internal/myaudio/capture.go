@@ -268,6 +268,11 @@ func CaptureAudio(settings *conf.Settings, wg *sync.WaitGroup, quitChan, restart
 		return
 	}
 
+	// Build the per-source filter chains before any source starts capturing
+	if err := UpdateSourceFilters(settings); err != nil {
+		log.Printf("❌ Error initializing audio source filters: %v", err)
+	}
+
 	// Initialize RTSP sources - the FFmpegManager will handle buffer initialization
 	if len(settings.Realtime.RTSP.URLs) > 0 {
 		for _, url := range settings.Realtime.RTSP.URLs {
@@ -591,8 +596,8 @@ func processAudioFrame(
 	}
 	// --- End Buffer Safety Handling ---
 
-	// Apply audio EQ filters if enabled (use the safe bufferToUse)
-	if settings.Realtime.Audio.Equalizer.Enabled {
+	// Apply the filters of the source, or the global audio EQ filters if enabled (use the safe bufferToUse)
+	if !ApplySourceFilters(sourceID, bufferToUse) && settings.Realtime.Audio.Equalizer.Enabled {
 		if eqErr := ApplyFilters(bufferToUse); eqErr != nil {
 			log.Printf("❌ Error applying audio EQ filters: %v", eqErr)
 			// Non-fatal, just log
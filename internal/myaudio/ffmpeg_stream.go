@@ -841,6 +841,9 @@ func (s *FFmpegStream) processAudio() error {
 
 // handleAudioData processes a chunk of audio data
 func (s *FFmpegStream) handleAudioData(data []byte) error {
+	// Apply the filters of the source so that analysis, clips and levels use processed audio
+	ApplySourceFilters(s.source.ID, data)
+
 	// Write to analysis buffer using source ID
 	if err := WriteToAnalysisBuffer(s.source.ID, data); err != nil {
 		return errors.New(fmt.Errorf("failed to write to analysis buffer: %w", err)).
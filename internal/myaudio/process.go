@@ -124,8 +124,8 @@ func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source s
 
 	// Check if processing time exceeds effective buffer duration
 	if elapsedTime > effectiveBufferDuration {
-		log.Printf("WARNING: BirdNET processing time (%v) exceeded buffer length (%v) for source %s",
-			elapsedTime, effectiveBufferDuration, source)
+		log.Printf("WARNING: %s processing time (%v) exceeded buffer length (%v) for source %s",
+			bn.ModelInfo.ID, elapsedTime, effectiveBufferDuration, source)
 	}
 
 	// Get AudioSource struct from registry for the Results message
@@ -170,6 +170,7 @@ func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source s
 		PCMdata:     data,
 		Results:     results,
 		Source:      audioSource,
+		Model:       bn.ModelInfo.ID,
 	}
 
 	// Send the results to the queue
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +32,15 @@ type AudioInfo struct {
 	BitDepth     int
 }
 
+// SupportedAudioFormats are the extensions of the audio files that can be analyzed. WAV and
+// FLAC files are decoded natively, compressed formats need FFmpeg.
+var SupportedAudioFormats = []string{".wav", ".flac", ".mp3", ".ogg", ".oga", ".opus", ".m4a"}
+
+// IsSupportedAudioFile reports whether a file has the extension of a supported audio format
+func IsSupportedAudioFile(path string) bool {
+	return slices.Contains(SupportedAudioFormats, strings.ToLower(filepath.Ext(path)))
+}
+
 // GetTotalChunks calculates the total number of chunks for a given audio file
 func GetTotalChunks(sampleRate, totalSamples int, overlap float64) int {
 	chunkSamples := 3 * sampleRate                          // samples in 3 seconds
@@ -109,13 +119,15 @@ func GetAudioInfo(filePath string) (AudioInfo, error) {
 		info, err = readWAVInfo(file)
 	case ".flac":
 		info, err = readFLACInfo(file)
+	case ".mp3", ".ogg", ".oga", ".opus", ".m4a":
+		info, err = readCompressedInfo(file, ext)
 	default:
 		enhancedErr := errors.Newf("unsupported audio format: %s", ext).
 			Component("myaudio").
 			Category(errors.CategoryValidation).
 			Context("operation", "get_audio_info").
 			Context("file_extension", ext).
-			Context("supported_formats", "wav,flac").
+			Context("supported_formats", strings.Join(SupportedAudioFormats, ",")).
 			Build()
 
 		if m := getFileMetrics(); m != nil {
@@ -231,13 +243,15 @@ func ReadAudioFileBuffered(settings *conf.Settings, callback AudioChunkCallback)
 		err = readWAVBuffered(file, settings, callback)
 	case ".flac":
 		err = readFLACBuffered(file, settings, callback)
+	case ".mp3", ".ogg", ".oga", ".opus", ".m4a":
+		err = readCompressedBuffered(file, settings, callback)
 	default:
 		enhancedErr := errors.Newf("unsupported audio format: %s", ext).
 			Component("myaudio").
 			Category(errors.CategoryValidation).
 			Context("operation", "read_audio_file_buffered").
 			Context("file_extension", ext).
-			Context("supported_formats", "wav,flac").
+			Context("supported_formats", strings.Join(SupportedAudioFormats, ",")).
 			Build()
 
 		if m := getFileMetrics(); m != nil {
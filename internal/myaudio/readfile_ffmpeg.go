@@ -0,0 +1,154 @@
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// decodedBitDepth is the bit depth compressed audio files are decoded to
+const decodedBitDepth = 16
+
+// compressedInfoReaders read the format of compressed audio files by extension. The
+// formats are parsed natively from the container headers while the audio itself is
+// decoded with FFmpeg.
+var compressedInfoReaders = map[string]func(*os.File) (AudioInfo, error){
+	".mp3":  readMP3Info,
+	".ogg":  readOGGInfo,
+	".oga":  readOGGInfo,
+	".opus": readOGGInfo,
+	".m4a":  readM4AInfo,
+}
+
+// readCompressedInfo reads the format of a compressed audio file
+func readCompressedInfo(file *os.File, ext string) (AudioInfo, error) {
+	readInfo, ok := compressedInfoReaders[ext]
+	if !ok {
+		return AudioInfo{}, fmt.Errorf("unsupported audio format: %s", ext)
+	}
+	return readInfo(file)
+}
+
+// readCompressedBuffered decodes a compressed audio file with FFmpeg and processes the
+// audio in chunks like the WAV and FLAC readers. FFmpeg streams mono PCM at the sample
+// rate of the file, which is resampled to the BirdNET sample rate.
+func readCompressedBuffered(file *os.File, settings *conf.Settings, callback AudioChunkCallback) error {
+	ext := strings.ToLower(filepath.Ext(file.Name()))
+	info, err := readCompressedInfo(file, ext)
+	if err != nil {
+		return err
+	}
+
+	ffmpegPath := settings.Realtime.Audio.FfmpegPath
+	if ffmpegPath == "" {
+		return fmt.Errorf("FFmpeg is required to decode %s files", strings.TrimPrefix(ext, "."))
+	}
+
+	if settings.Debug {
+		fmt.Println("Sample rate:", info.SampleRate)
+		fmt.Println("Channels:", info.NumChannels)
+		fmt.Println("Total samples:", info.TotalSamples)
+	}
+
+	// The sample rate is passed explicitly so that FFmpeg output matches the header even
+	// for codecs such as HE-AAC whose decoders may choose another rate
+	sampleRate, numChannels, format := getFFmpegFormat(info.SampleRate, 1, decodedBitDepth)
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-nostdin",
+		"-i", file.Name(),
+		"-vn",
+		"-f", format,
+		"-ar", sampleRate,
+		"-ac", numChannels,
+		"pipe:1",
+	}
+
+	// Cancelling the context stops FFmpeg if processing ends before the end of the file
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("error creating FFmpeg output pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting FFmpeg: %w", err)
+	}
+	waited := false
+	defer func() {
+		if !waited {
+			cancel()
+			_ = cmd.Wait()
+		}
+	}()
+
+	doResample := info.SampleRate != conf.SampleRate
+	step := int((3 - settings.BirdNET.Overlap) * conf.SampleRate)
+	secondsSamples := int(3 * conf.SampleRate)
+
+	// Read one second of decoded audio at a time
+	buf := make([]byte, info.SampleRate*decodedBitDepth/8)
+	var currentChunk []float32
+
+	for {
+		n, readErr := io.ReadFull(stdout, buf)
+		// The resampler needs at least four samples, shorter tails are dropped
+		if n >= 8 {
+			floatChunk, err := convertPCMToFloat32(buf[:n/2*2], 2, 1, 32768.0)
+			if err != nil {
+				return err
+			}
+
+			if doResample {
+				floatChunk, err = ResampleAudio(floatChunk, info.SampleRate, conf.SampleRate)
+				if err != nil {
+					return fmt.Errorf("error resampling audio: %w", err)
+				}
+			}
+
+			currentChunk = append(currentChunk, floatChunk...)
+
+			// Process complete 3-second chunks
+			for len(currentChunk) >= secondsSamples {
+				if err := callback(currentChunk[:secondsSamples], false); err != nil {
+					return err
+				}
+				currentChunk = currentChunk[step:]
+			}
+		}
+
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		} else if readErr != nil {
+			return fmt.Errorf("error reading FFmpeg output: %w", readErr)
+		}
+	}
+
+	// A decoding error must not be reported as the end of the file
+	waited = true
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("FFmpeg decoding failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Handle the last chunk and signal EOF
+	if len(currentChunk) > 0 {
+		if len(currentChunk) < secondsSamples {
+			padding := make([]float32, secondsSamples-len(currentChunk))
+			currentChunk = append(currentChunk, padding...)
+		}
+		return callback(currentChunk, true)
+	}
+	// Signal EOF even if there's no final chunk to process
+	return callback(nil, true)
+}
@@ -0,0 +1,190 @@
+package myaudio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// writeTestMP3 writes an MP3 file of silent 128 kbit/s MPEG-1 layer III mono frames at
+// 44.1 kHz between an ID3v2 tag and an ID3v1 tag
+func writeTestMP3(t *testing.T, path string, frames int) {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.Write([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 20})
+	buf.Write(make([]byte, 20))
+	for i := 0; i < frames; i++ {
+		frame := make([]byte, 417)
+		copy(frame, []byte{0xff, 0xfb, 0x90, 0xc0})
+		buf.Write(frame)
+	}
+	buf.WriteString("TAG")
+	buf.Write(make([]byte, 125))
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+}
+
+// testOggPage returns an Ogg page with a body of less than 255 bytes
+func testOggPage(serial uint32, granule int64, body []byte) []byte {
+	header := make([]byte, oggPageHeaderSize)
+	copy(header, "OggS")
+	binary.LittleEndian.PutUint64(header[6:], uint64(granule)) //nolint:gosec // G115: test granule positions
+	binary.LittleEndian.PutUint32(header[14:], serial)
+	header[26] = 1
+	page := append(header, byte(len(body)))
+	return append(page, body...)
+}
+
+// testMP4Box returns an MP4 box with its contents
+func testMP4Box(kind string, contents ...[]byte) []byte {
+	body := bytes.Join(contents, nil)
+	box := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(box, uint32(8+len(body))) //nolint:gosec // G115: small test boxes
+	copy(box[4:], kind)
+	return append(box, body...)
+}
+
+// testM4ATrack returns a track box with a media header, handler and sample description
+func testM4ATrack(handler string, timescale, duration uint32, channels uint16, sampleRate uint32) []byte {
+	mdhd := make([]byte, 24)
+	binary.BigEndian.PutUint32(mdhd[12:], timescale)
+	binary.BigEndian.PutUint32(mdhd[16:], duration)
+	hdlr := make([]byte, 24)
+	copy(hdlr[8:], handler)
+	entry := make([]byte, 28)
+	binary.BigEndian.PutUint16(entry[16:], channels)
+	binary.BigEndian.PutUint32(entry[24:], sampleRate<<16)
+	stsd := append([]byte{0, 0, 0, 0, 0, 0, 0, 1}, testMP4Box("mp4a", entry)...)
+	return testMP4Box("trak",
+		testMP4Box("mdia",
+			testMP4Box("mdhd", mdhd),
+			testMP4Box("hdlr", hdlr),
+			testMP4Box("minf", testMP4Box("stbl", testMP4Box("stsd", stsd)))))
+}
+
+func TestGetAudioInfo_CompressedFormats(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	mp3Path := filepath.Join(dir, "phone.mp3")
+	writeTestMP3(t, mp3Path, 100)
+
+	vorbisID := append([]byte("\x01vorbis\x00\x00\x00\x00\x02"), 0x22, 0x56, 0, 0)
+	vorbisID = append(vorbisID, make([]byte, 16)...)
+	vorbisPath := filepath.Join(dir, "volunteer.ogg")
+	require.NoError(t, os.WriteFile(vorbisPath, bytes.Join([][]byte{
+		testOggPage(7, 0, vorbisID),
+		testOggPage(7, 22050, make([]byte, 100)),
+		testOggPage(9, 999999, make([]byte, 100)), // another logical stream
+		testOggPage(7, 44100, make([]byte, 100)),
+		testOggPage(7, -1, make([]byte, 100)), // no finished packet
+	}, nil), 0o600))
+
+	opusHead := append([]byte("OpusHead\x01\x01"), 0x38, 0x01, 0x80, 0x3e, 0, 0, 0, 0, 0)
+	opusPath := filepath.Join(dir, "volunteer.opus")
+	require.NoError(t, os.WriteFile(opusPath, bytes.Join([][]byte{
+		testOggPage(1, 0, opusHead),
+		testOggPage(1, 96000+312, make([]byte, 100)),
+	}, nil), 0o600))
+
+	m4aPath := filepath.Join(dir, "phone.m4a")
+	require.NoError(t, os.WriteFile(m4aPath, bytes.Join([][]byte{
+		testMP4Box("ftyp", []byte("M4A \x00\x00\x00\x00")),
+		testMP4Box("moov",
+			testM4ATrack("vide", 600, 6000, 0, 0),
+			testM4ATrack("soun", 44100, 441000, 2, 44100)),
+		testMP4Box("mdat", make([]byte, 64)),
+	}, nil), 0o600))
+
+	tests := []struct {
+		name string
+		path string
+		want AudioInfo
+	}{
+		{"mp3 counts frames between tags", mp3Path, AudioInfo{SampleRate: 44100, TotalSamples: 115200, NumChannels: 1, BitDepth: 16}},
+		{"ogg vorbis uses last granule of first stream", vorbisPath, AudioInfo{SampleRate: 22050, TotalSamples: 44100, NumChannels: 2, BitDepth: 16}},
+		{"ogg opus subtracts pre-skip", opusPath, AudioInfo{SampleRate: 48000, TotalSamples: 96000, NumChannels: 1, BitDepth: 16}},
+		{"m4a uses first audio track", m4aPath, AudioInfo{SampleRate: 44100, TotalSamples: 441000, NumChannels: 2, BitDepth: 16}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			info, err := GetAudioInfo(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, info)
+		})
+	}
+
+	// Files without audio are rejected
+	emptyPath := filepath.Join(dir, "empty.mp3")
+	require.NoError(t, os.WriteFile(emptyPath, make([]byte, 1000), 0o600))
+	_, err := GetAudioInfo(emptyPath)
+	require.Error(t, err)
+}
+
+func TestReadAudioFileBuffered_FFmpeg(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("mock FFmpeg is a shell script")
+	}
+	dir := t.TempDir()
+
+	mp3Path := filepath.Join(dir, "phone.mp3")
+	writeTestMP3(t, mp3Path, 200)
+
+	// The mock FFmpeg writes 5 seconds of 16-bit mono silence at 44.1 kHz
+	decoder := filepath.Join(dir, "ffmpeg")
+	require.NoError(t, os.WriteFile(decoder, []byte("#!/bin/sh\nhead -c 441000 /dev/zero\n"), 0o700)) //nolint:gosec // G306: test script must be executable
+	failing := filepath.Join(dir, "ffmpeg-failing")
+	require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\necho 'Invalid data found' >&2\nexit 1\n"), 0o700)) //nolint:gosec // G306: test script must be executable
+
+	newSettings := func(ffmpegPath string) *conf.Settings {
+		settings := &conf.Settings{}
+		settings.Input.Path = mp3Path
+		settings.Realtime.Audio.FfmpegPath = ffmpegPath
+		return settings
+	}
+
+	// Decoded audio is resampled to 48 kHz and split into 3-second chunks
+	var chunks []int
+	var eofs []bool
+	err := ReadAudioFileBuffered(newSettings(decoder), func(chunk []float32, isEOF bool) error {
+		chunks = append(chunks, len(chunk))
+		eofs = append(eofs, isEOF)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{3 * conf.SampleRate, 3 * conf.SampleRate}, chunks)
+	assert.Equal(t, []bool{false, true}, eofs)
+
+	// FFmpeg errors are reported instead of the end of the file
+	err = ReadAudioFileBuffered(newSettings(failing), func(chunk []float32, isEOF bool) error {
+		assert.False(t, isEOF)
+		return nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid data found")
+
+	// Compressed formats need FFmpeg
+	err = ReadAudioFileBuffered(newSettings(""), func(chunk []float32, isEOF bool) error {
+		return nil
+	})
+	require.Error(t, err)
+}
+
+func TestIsSupportedAudioFile(t *testing.T) {
+	t.Parallel()
+	for _, path := range []string{"a.wav", "b.FLAC", "c.mp3", "d.ogg", "e.opus", "f.M4A"} {
+		assert.True(t, IsSupportedAudioFile(path), path)
+	}
+	for _, path := range []string{"a.txt", "b", "c.mp3.processing"} {
+		assert.False(t, IsSupportedAudioFile(path), path)
+	}
+}
@@ -0,0 +1,163 @@
+package myaudio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// mp4Box is the position of an MP4 box in a file
+type mp4Box struct {
+	kind   string
+	offset int64 // offset of the box contents
+	size   int64 // size of the box contents
+}
+
+// readMP4Boxes reads the headers of the boxes between start and end
+func readMP4Boxes(r io.ReaderAt, start, end int64) ([]mp4Box, error) {
+	var boxes []mp4Box
+	header := make([]byte, 16)
+	for pos := start; pos+8 <= end; {
+		if _, err := r.ReadAt(header[:8], pos); err != nil {
+			return nil, err
+		}
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		headerSize := int64(8)
+		switch size {
+		case 0:
+			// The box extends to the end of its parent
+			size = end - pos
+		case 1:
+			// A 64-bit size follows the box type
+			if _, err := r.ReadAt(header[8:16], pos+8); err != nil {
+				return nil, err
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16])) //nolint:gosec // G115: box sizes fit in int64
+			headerSize = 16
+		}
+		if size < headerSize || pos+size > end {
+			return nil, fmt.Errorf("invalid MP4 box %q size %d", header[4:8], size)
+		}
+		boxes = append(boxes, mp4Box{kind: string(header[4:8]), offset: pos + headerSize, size: size - headerSize})
+		pos += size
+	}
+	return boxes, nil
+}
+
+// findMP4Box returns the first box of a kind
+func findMP4Box(boxes []mp4Box, kind string) (mp4Box, bool) {
+	for _, box := range boxes {
+		if box.kind == kind {
+			return box, true
+		}
+	}
+	return mp4Box{}, false
+}
+
+// findMP4Path returns the first box at a path of box kinds below a parent box
+func findMP4Path(r io.ReaderAt, parent mp4Box, path ...string) (mp4Box, bool) {
+	box := parent
+	for _, kind := range path {
+		children, err := readMP4Boxes(r, box.offset, box.offset+box.size)
+		if err != nil {
+			return mp4Box{}, false
+		}
+		var found bool
+		if box, found = findMP4Box(children, kind); !found {
+			return mp4Box{}, false
+		}
+	}
+	return box, true
+}
+
+// readM4AInfo reads the format and length of the first audio track of an M4A file from
+// the track's media header and sample description
+func readM4AInfo(file *os.File) (AudioInfo, error) {
+	stat, err := file.Stat()
+	if err != nil {
+		return AudioInfo{}, err
+	}
+	boxes, err := readMP4Boxes(file, 0, stat.Size())
+	if err != nil {
+		return AudioInfo{}, fmt.Errorf("invalid M4A file: %w", err)
+	}
+	moov, ok := findMP4Box(boxes, "moov")
+	if !ok {
+		return AudioInfo{}, errors.New("invalid M4A file: no movie box found")
+	}
+
+	tracks, err := readMP4Boxes(file, moov.offset, moov.offset+moov.size)
+	if err != nil {
+		return AudioInfo{}, fmt.Errorf("invalid M4A file: %w", err)
+	}
+	for _, trak := range tracks {
+		if trak.kind != "trak" {
+			continue
+		}
+		if info, ok := readM4ATrackInfo(file, trak); ok {
+			return info, nil
+		}
+	}
+	return AudioInfo{}, errors.New("invalid M4A file: no audio track found")
+}
+
+// readM4ATrackInfo returns the format of a track, ok is false if it is not an audio track
+func readM4ATrackInfo(r io.ReaderAt, trak mp4Box) (info AudioInfo, ok bool) {
+	// The handler type follows the version, flags and predefined fields
+	hdlr, ok := findMP4Path(r, trak, "mdia", "hdlr")
+	if !ok || hdlr.size < 12 {
+		return AudioInfo{}, false
+	}
+	handler := make([]byte, 4)
+	if _, err := r.ReadAt(handler, hdlr.offset+8); err != nil || string(handler) != "soun" {
+		return AudioInfo{}, false
+	}
+
+	// Version 0 media headers have 32-bit times and version 1 headers 64-bit times
+	mdhd, ok := findMP4Path(r, trak, "mdia", "mdhd")
+	if !ok || mdhd.size < 24 {
+		return AudioInfo{}, false
+	}
+	header := make([]byte, min(mdhd.size, 32))
+	if _, err := r.ReadAt(header, mdhd.offset); err != nil {
+		return AudioInfo{}, false
+	}
+	var timescale, duration uint64
+	if header[0] == 1 {
+		if len(header) < 32 {
+			return AudioInfo{}, false
+		}
+		timescale = uint64(binary.BigEndian.Uint32(header[20:24]))
+		duration = binary.BigEndian.Uint64(header[24:32])
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(header[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(header[16:20]))
+	}
+	if timescale == 0 {
+		return AudioInfo{}, false
+	}
+
+	// The sample description holds an audio sample entry, whose sample rate is a 16.16
+	// fixed point number
+	stsd, ok := findMP4Path(r, trak, "mdia", "minf", "stbl", "stsd")
+	if !ok || stsd.size < 8+8+28 {
+		return AudioInfo{}, false
+	}
+	entry := make([]byte, 28)
+	if _, err := r.ReadAt(entry, stsd.offset+8+8); err != nil {
+		return AudioInfo{}, false
+	}
+	info.NumChannels = int(binary.BigEndian.Uint16(entry[16:18]))
+	info.SampleRate = int(binary.BigEndian.Uint32(entry[24:28]) >> 16)
+	if info.SampleRate == 0 {
+		info.SampleRate = int(timescale) //nolint:gosec // G115: timescale is a 32-bit value
+	}
+	if info.NumChannels == 0 {
+		return AudioInfo{}, false
+	}
+	info.BitDepth = decodedBitDepth
+	info.TotalSamples = int(duration * uint64(info.SampleRate) / timescale) //nolint:gosec // G115: sample counts of audio files fit in int
+	return info, true
+}
@@ -0,0 +1,158 @@
+package myaudio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// MPEG audio versions as coded in the frame header
+const (
+	mpegVersion25 = 0
+	mpegVersion2  = 2
+	mpegVersion1  = 3
+)
+
+// mp3Bitrates are the bitrates in kbit/s by MPEG-1 and MPEG-2/2.5, layer and bitrate index
+var mp3Bitrates = [2][3][16]int{
+	{ // MPEG-1
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	},
+	{ // MPEG-2 and MPEG-2.5
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	},
+}
+
+// mp3SampleRates are the sample rates of MPEG-1, the rates of MPEG-2 are halved and those
+// of MPEG-2.5 quartered
+var mp3SampleRates = [3]int{44100, 48000, 32000}
+
+// mp3Frame is a parsed MPEG audio frame header
+type mp3Frame struct {
+	version    int
+	layer      int // 1 to 3
+	sampleRate int
+	channels   int
+	samples    int // samples per channel in the frame
+	size       int // frame size in bytes including the header
+}
+
+// parseMP3FrameHeader parses a 4-byte MPEG audio frame header, ok is false if the bytes
+// are not a valid header. Free format frames have no size in the header and are rejected.
+func parseMP3FrameHeader(h []byte) (frame mp3Frame, ok bool) {
+	if h[0] != 0xff || h[1]&0xe0 != 0xe0 {
+		return mp3Frame{}, false
+	}
+	version := int(h[1]>>3) & 0x03
+	layerBits := int(h[1]>>1) & 0x03
+	bitrateIndex := int(h[2] >> 4)
+	rateIndex := int(h[2]>>2) & 0x03
+	if version == 1 || layerBits == 0 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+
+	frame.version = version
+	frame.layer = 4 - layerBits
+	frame.sampleRate = mp3SampleRates[rateIndex]
+	table := 0
+	switch version {
+	case mpegVersion2:
+		frame.sampleRate /= 2
+		table = 1
+	case mpegVersion25:
+		frame.sampleRate /= 4
+		table = 1
+	}
+	frame.channels = 2
+	if h[3]>>6 == 3 {
+		frame.channels = 1
+	}
+
+	bitrate := mp3Bitrates[table][frame.layer-1][bitrateIndex] * 1000
+	padding := int(h[2]>>1) & 0x01
+	switch {
+	case frame.layer == 1:
+		frame.samples = 384
+		frame.size = (12*bitrate/frame.sampleRate + padding) * 4
+	case frame.layer == 3 && version != mpegVersion1:
+		frame.samples = 576
+		frame.size = 72*bitrate/frame.sampleRate + padding
+	default:
+		frame.samples = 1152
+		frame.size = 144*bitrate/frame.sampleRate + padding
+	}
+	return frame, true
+}
+
+// skipID3v2 skips an ID3v2 tag at the start of a file
+func skipID3v2(r *bufio.Reader) error {
+	header, err := r.Peek(10)
+	if err != nil || string(header[:3]) != "ID3" {
+		return nil
+	}
+	// The tag size is a 28-bit synchsafe integer excluding the header and the footer
+	size := int(header[6]&0x7f)<<21 | int(header[7]&0x7f)<<14 | int(header[8]&0x7f)<<7 | int(header[9]&0x7f)
+	if header[5]&0x10 != 0 {
+		size += 10
+	}
+	_, err = r.Discard(10 + size)
+	return err
+}
+
+// readMP3Info reads the format of an MP3 file and counts its samples by walking the frame
+// headers, which also gives exact lengths for variable bitrate files
+func readMP3Info(file *os.File) (AudioInfo, error) {
+	r := bufio.NewReaderSize(file, 64*1024)
+	if err := skipID3v2(r); err != nil {
+		return AudioInfo{}, fmt.Errorf("invalid ID3 tag: %w", err)
+	}
+
+	var first mp3Frame
+	frames, totalSamples := 0, 0
+	for {
+		header, err := r.Peek(4)
+		if err != nil {
+			break
+		}
+		frame, ok := parseMP3FrameHeader(header)
+		// After the first frame only frames of the same stream are counted, other bytes
+		// such as trailing tags or damaged data are skipped until the next frame
+		if ok && frames > 0 && (frame.version != first.version || frame.layer != first.layer || frame.sampleRate != first.sampleRate) {
+			ok = false
+		}
+		if !ok {
+			if _, err := r.Discard(1); err != nil {
+				break
+			}
+			continue
+		}
+		if frames == 0 {
+			first = frame
+		}
+		frames++
+		totalSamples += frame.samples
+		if _, err := r.Discard(frame.size); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return AudioInfo{}, err
+		}
+	}
+
+	if frames == 0 {
+		return AudioInfo{}, errors.New("invalid MP3 file: no MPEG audio frames found")
+	}
+
+	return AudioInfo{
+		SampleRate:   first.sampleRate,
+		TotalSamples: totalSamples,
+		NumChannels:  first.channels,
+		BitDepth:     decodedBitDepth,
+	}, nil
+}
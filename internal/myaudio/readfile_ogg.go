@@ -0,0 +1,103 @@
+package myaudio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// oggPageHeaderSize is the size of an Ogg page header without its segment table
+const oggPageHeaderSize = 27
+
+// opusSampleRate is the rate Opus streams are decoded at whatever their input rate was
+const opusSampleRate = 48000
+
+// oggPage is the header of an Ogg page
+type oggPage struct {
+	granule  int64
+	serial   uint32
+	bodySize int
+}
+
+// readOggPage reads the header and segment table of the next Ogg page, leaving the reader
+// at the page body
+func readOggPage(r *bufio.Reader) (oggPage, error) {
+	header := make([]byte, oggPageHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return oggPage{}, err
+	}
+	if string(header[:4]) != "OggS" || header[4] != 0 {
+		return oggPage{}, errors.New("invalid Ogg page header")
+	}
+	segments := make([]byte, header[26])
+	if _, err := io.ReadFull(r, segments); err != nil {
+		return oggPage{}, err
+	}
+
+	page := oggPage{
+		granule: int64(binary.LittleEndian.Uint64(header[6:14])), //nolint:gosec // G115: granule positions are signed
+		serial:  binary.LittleEndian.Uint32(header[14:18]),
+	}
+	for _, size := range segments {
+		page.bodySize += int(size)
+	}
+	return page, nil
+}
+
+// readOGGInfo reads the format of an Ogg Vorbis or Ogg Opus file from its identification
+// header. The length is the granule position of the last page, which counts samples for
+// both codecs.
+func readOGGInfo(file *os.File) (AudioInfo, error) {
+	r := bufio.NewReaderSize(file, 64*1024)
+
+	first, err := readOggPage(r)
+	if err != nil {
+		return AudioInfo{}, fmt.Errorf("invalid Ogg file: %w", err)
+	}
+	body := make([]byte, first.bodySize)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return AudioInfo{}, fmt.Errorf("invalid Ogg file: %w", err)
+	}
+
+	var info AudioInfo
+	var preSkip int64
+	switch {
+	case len(body) >= 16 && string(body[:7]) == "\x01vorbis":
+		info.NumChannels = int(body[11])
+		info.SampleRate = int(binary.LittleEndian.Uint32(body[12:16]))
+	case len(body) >= 19 && string(body[:8]) == "OpusHead":
+		info.NumChannels = int(body[9])
+		info.SampleRate = opusSampleRate
+		preSkip = int64(binary.LittleEndian.Uint16(body[10:12]))
+	default:
+		return AudioInfo{}, errors.New("unsupported Ogg codec, only Vorbis and Opus are supported")
+	}
+	if info.NumChannels == 0 || info.SampleRate <= 0 {
+		return AudioInfo{}, fmt.Errorf("invalid Ogg stream: %d channels at %d Hz", info.NumChannels, info.SampleRate)
+	}
+	info.BitDepth = decodedBitDepth
+
+	// Only pages of the first logical stream count, a file may multiplex several
+	granule := int64(0)
+	for {
+		page, err := readOggPage(r)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		} else if err != nil {
+			return AudioInfo{}, fmt.Errorf("invalid Ogg file: %w", err)
+		}
+		// Header pages and pages without a finished packet have no position
+		if page.serial == first.serial && page.granule > 0 {
+			granule = page.granule
+		}
+		if _, err := r.Discard(page.bodySize); err != nil {
+			break
+		}
+	}
+
+	info.TotalSamples = int(max(granule-preSkip, 0))
+	return info, nil
+}
@@ -0,0 +1,111 @@
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-audio/wav"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// ReadAudioFilePCM reads an audio clip as 16-bit mono PCM at the BirdNET sample rate, the
+// format of the capture buffers. WAV files in that format are read directly, other files
+// are decoded with FFmpeg, ffmpegPath may be empty if only such WAV files are read.
+func ReadAudioFilePCM(ctx context.Context, path, ffmpegPath string) ([]byte, error) {
+	if strings.EqualFold(filepath.Ext(path), ".wav") {
+		pcm, ok, err := readWAVPCM(path)
+		if err != nil || ok {
+			return pcm, err
+		}
+	}
+
+	if ffmpegPath == "" {
+		return nil, errors.Newf("FFmpeg is required to decode %s", filepath.Base(path)).
+			Component("myaudio").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "read_audio_pcm").
+			Context("file_extension", filepath.Ext(path)).
+			Build()
+	}
+	return decodePCMWithFFmpeg(ctx, path, ffmpegPath)
+}
+
+// readWAVPCM returns the PCM data of a WAV file, ok is false if the file is valid but not
+// in the capture format
+func readWAVPCM(path string) (pcm []byte, ok bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_audio_pcm").
+			Build()
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	decoder := wav.NewDecoder(file)
+	decoder.ReadInfo()
+	if !decoder.IsValidFile() {
+		return nil, false, errors.Newf("invalid WAV file format").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "read_audio_pcm").
+			Build()
+	}
+	if int(decoder.SampleRate) != conf.SampleRate || int(decoder.BitDepth) != conf.BitDepth || int(decoder.NumChans) != conf.NumChannels {
+		return nil, false, nil
+	}
+
+	if err := decoder.FwdToPCM(); err != nil {
+		return nil, false, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_audio_pcm").
+			Build()
+	}
+	pcm, err = io.ReadAll(io.LimitReader(decoder.PCMChunk, int64(decoder.PCMSize)))
+	if err != nil {
+		return nil, false, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_audio_pcm").
+			Build()
+	}
+	return pcm, true, nil
+}
+
+// decodePCMWithFFmpeg decodes an audio file to PCM in the capture format with FFmpeg
+func decodePCMWithFFmpeg(ctx context.Context, path, ffmpegPath string) ([]byte, error) {
+	sampleRate, numChannels, format := getFFmpegFormat(conf.SampleRate, conf.NumChannels, conf.BitDepth)
+	args := []string{
+		"-hide_banner", "-loglevel", "error",
+		"-i", path,
+		"-f", format,
+		"-ar", sampleRate,
+		"-ac", numChannels,
+		"pipe:1",
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.New(fmt.Errorf("FFmpeg decoding failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))).
+			Component("myaudio").
+			Category(errors.CategoryAudio).
+			Context("operation", "read_audio_pcm").
+			Context("file_extension", filepath.Ext(path)).
+			Build()
+	}
+	return stdout.Bytes(), nil
+}
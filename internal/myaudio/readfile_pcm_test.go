@@ -0,0 +1,36 @@
+package myaudio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestReadAudioFilePCM(t *testing.T) {
+	t.Parallel()
+
+	pcm := make([]byte, conf.SampleRate*2)
+	for i := range pcm {
+		pcm[i] = byte(i % 251)
+	}
+	wav, err := EncodePCMtoWAVWithContext(context.Background(), pcm)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "clip.wav")
+	require.NoError(t, os.WriteFile(path, wav.Bytes(), 0o600))
+
+	// WAV files in the capture format are read without FFmpeg
+	got, err := ReadAudioFilePCM(context.Background(), path, "")
+	require.NoError(t, err)
+	assert.Equal(t, pcm, got)
+
+	// Other formats need FFmpeg
+	_, err = ReadAudioFilePCM(context.Background(), filepath.Join(dir, "clip.flac"), "")
+	require.Error(t, err)
+}
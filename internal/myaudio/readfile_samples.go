@@ -0,0 +1,120 @@
+package myaudio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-audio/wav"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/flac"
+)
+
+// errNotNativeFormat reports an audio file that is not decoded in-process
+var errNotNativeFormat = errors.New("audio format is not decoded natively")
+
+// wavFormatPCM is the WAV audio format of integer PCM samples
+const wavFormatPCM = 1
+
+// ReadAudioFileSamples reads an audio clip as mono samples in the range [-1, 1] and
+// returns them with their sample rate. PCM WAV and FLAC files are decoded in-process at
+// the sample rate of the file, other files are decoded with FFmpeg at the BirdNET sample
+// rate, ffmpegPath may be empty if only WAV and FLAC files are read.
+func ReadAudioFileSamples(ctx context.Context, path, ffmpegPath string) (samples []float32, sampleRate int, err error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		samples, sampleRate, err = readWAVSamples(path)
+	case ".flac":
+		samples, sampleRate, err = readFLACSamples(path)
+	default:
+		err = errNotNativeFormat
+	}
+	if !errors.Is(err, errNotNativeFormat) {
+		return samples, sampleRate, err
+	}
+
+	pcm, err := ReadAudioFilePCM(ctx, path, ffmpegPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	samples, err = convertPCMToFloat32(pcm[:len(pcm)/2*2], 2, 1, 32768.0)
+	return samples, conf.SampleRate, err
+}
+
+// readWAVSamples decodes an integer PCM WAV file, other WAV encodings are left to FFmpeg
+func readWAVSamples(path string) ([]float32, int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	decoder := wav.NewDecoder(file)
+	decoder.ReadInfo()
+	if !decoder.IsValidFile() {
+		return nil, 0, fmt.Errorf("invalid WAV file format")
+	}
+	if decoder.WavAudioFormat != wavFormatPCM {
+		return nil, 0, errNotNativeFormat
+	}
+
+	divisor, err := getAudioDivisor(int(decoder.BitDepth))
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := decoder.FwdToPCM(); err != nil {
+		return nil, 0, fmt.Errorf("error reading WAV data: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(decoder.PCMChunk, int64(decoder.PCMSize)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading WAV data: %w", err)
+	}
+
+	frameSize := int(decoder.BitDepth/8) * int(decoder.NumChans)
+	samples, err := convertPCMToFloat32(data[:len(data)/frameSize*frameSize], int(decoder.BitDepth/8), int(decoder.NumChans), divisor)
+	return samples, int(decoder.SampleRate), err
+}
+
+// readFLACSamples decodes a FLAC file
+func readFLACSamples(path string) ([]float32, int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	decoder, err := flac.NewDecoder(file)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid FLAC file: %w", err)
+	}
+
+	divisor, err := getAudioDivisor(decoder.BitsPerSample)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var samples []float32
+	for {
+		frame, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, 0, err
+		}
+
+		chunk, err := convertPCMToFloat32(frame, decoder.BitsPerSample/8, decoder.NChannels, divisor)
+		if err != nil {
+			return nil, 0, err
+		}
+		samples = append(samples, chunk...)
+	}
+	return samples, decoder.SampleRate, nil
+}
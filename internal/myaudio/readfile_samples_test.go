@@ -0,0 +1,43 @@
+package myaudio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAudioFileSamples(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "clip.wav")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+
+	// A stereo 24-bit clip is mixed down to mono at its own sample rate
+	encoder := wav.NewEncoder(file, 44100, 24, 2, 1)
+	require.NoError(t, encoder.Write(&audio.IntBuffer{
+		Format: &audio.Format{SampleRate: 44100, NumChannels: 2},
+		Data:   []int{4194304, 0, -8388608, -8388608, 0, 2097152},
+	}))
+	require.NoError(t, encoder.Close())
+	require.NoError(t, file.Close())
+
+	samples, sampleRate, err := ReadAudioFileSamples(context.Background(), path, "")
+	require.NoError(t, err)
+	assert.Equal(t, 44100, sampleRate)
+	require.Len(t, samples, 3)
+	assert.InDelta(t, 0.25, samples[0], 1e-6)
+	assert.InDelta(t, -1.0, samples[1], 1e-6)
+	assert.InDelta(t, 0.125, samples[2], 1e-6)
+
+	// Compressed formats need FFmpeg
+	_, _, err = ReadAudioFileSamples(context.Background(), filepath.Join(dir, "clip.mp3"), "")
+	require.Error(t, err)
+}
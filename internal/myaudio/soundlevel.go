@@ -31,6 +31,9 @@ type SoundLevelData struct {
 	Source      string                    `json:"source"`
 	Name        string                    `json:"name"`
 	Duration    int                       `json:"duration_seconds"`
+	Leq         float64                   `json:"leq_db"`  // Equivalent continuous level of all bands over the interval
+	Lmin        float64                   `json:"lmin_db"` // Lowest 1-second level of all bands
+	Lmax        float64                   `json:"lmax_db"` // Highest 1-second level of all bands
 	OctaveBands map[string]OctaveBandData `json:"octave_bands"`
 }
 
@@ -64,6 +67,9 @@ type soundLevelProcessor struct {
 	intervalBuffer *intervalAggregator
 	interval       int // interval in seconds
 
+	// Most recent completed interval measurement, used as the ambient level of the source
+	latest *SoundLevelData
+
 	mutex sync.RWMutex
 }
 
@@ -416,6 +422,7 @@ func (p *soundLevelProcessor) ProcessAudioData(samples []byte) (*SoundLevelData,
 		}
 
 		p.resetIntervalBuffer()
+		p.latest = soundLevelData
 		return soundLevelData, nil
 	}
 
@@ -493,15 +500,78 @@ func (p *soundLevelProcessor) generateSoundLevelData() *SoundLevelData {
 		}
 	}
 
+	leq, lmin, lmax := p.broadbandLevels()
+
 	return &SoundLevelData{
 		Timestamp:   time.Now(),
 		Source:      p.source,
 		Name:        p.name,
 		Duration:    p.interval, // Use configured interval
+		Leq:         leq,
+		Lmin:        lmin,
+		Lmax:        lmax,
 		OctaveBands: octaveBands,
 	}
 }
 
+// broadbandLevels returns the equivalent continuous level and the lowest and highest
+// 1-second levels of all bands combined over the interval. The level of a second is the
+// energetic sum of its band levels.
+func (p *soundLevelProcessor) broadbandLevels() (leq, lmin, lmax float64) {
+	var energy float64
+	seconds := 0
+	lmin, lmax = math.Inf(1), math.Inf(-1)
+	for _, secondMeasurement := range p.intervalBuffer.secondMeasurements {
+		var power float64
+		for _, val := range secondMeasurement {
+			if !math.IsInf(val, 0) && !math.IsNaN(val) {
+				power += math.Pow(10, val/10)
+			}
+		}
+		if power == 0 {
+			continue
+		}
+		level := 10 * math.Log10(power)
+		energy += power
+		lmin = math.Min(lmin, level)
+		lmax = math.Max(lmax, level)
+		seconds++
+	}
+	if seconds == 0 {
+		return -100.0, -100.0, -100.0
+	}
+	return 10 * math.Log10(energy/float64(seconds)), lmin, lmax
+}
+
+// BandLevel returns the energetic sum of the mean levels of the octave bands with a center
+// frequency within a frequency range, or -100 dB if no band falls within the range
+func (d *SoundLevelData) BandLevel(minFreq, maxFreq float64) float64 {
+	return d.bandLevel(minFreq, maxFreq, func(band OctaveBandData) float64 { return band.Mean })
+}
+
+// BandMinLevel returns the energetic sum of the minimum levels of the octave bands with a
+// center frequency within a frequency range, or -100 dB if no band falls within the range.
+// Unlike BandLevel it describes the background noise, without short sounds such as calls.
+func (d *SoundLevelData) BandMinLevel(minFreq, maxFreq float64) float64 {
+	return d.bandLevel(minFreq, maxFreq, func(band OctaveBandData) float64 { return band.Min })
+}
+
+// bandLevel returns the energetic sum of a level of the octave bands within a frequency range
+func (d *SoundLevelData) bandLevel(minFreq, maxFreq float64, level func(OctaveBandData) float64) float64 {
+	var power float64
+	for _, band := range d.OctaveBands {
+		l := level(band)
+		if band.CenterFreq < minFreq || band.CenterFreq > maxFreq || math.IsInf(l, 0) || math.IsNaN(l) {
+			continue
+		}
+		power += math.Pow(10, l/10)
+	}
+	if power == 0 {
+		return -100.0
+	}
+	return 10 * math.Log10(power)
+}
+
 // resetIntervalBuffer resets the interval aggregation buffer
 func (p *soundLevelProcessor) resetIntervalBuffer() {
 	p.intervalBuffer.startTime = time.Now()
@@ -632,3 +702,21 @@ func ProcessSoundLevelData(source string, audioData []byte) (*SoundLevelData, er
 
 	return processor.ProcessAudioData(audioData)
 }
+
+// LatestSoundLevel returns the most recent interval measurement of a source, false if the
+// source has no processor or has not completed an interval yet
+func LatestSoundLevel(source string) (SoundLevelData, bool) {
+	soundLevelProcessorMutex.RLock()
+	processor, exists := soundLevelProcessors[source]
+	soundLevelProcessorMutex.RUnlock()
+	if !exists {
+		return SoundLevelData{}, false
+	}
+
+	processor.mutex.RLock()
+	defer processor.mutex.RUnlock()
+	if processor.latest == nil {
+		return SoundLevelData{}, false
+	}
+	return *processor.latest, true
+}
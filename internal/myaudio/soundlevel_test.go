@@ -362,4 +362,77 @@ func TestProcessAudioData_OddByteCount(t *testing.T) {
 	assert.True(t, err == nil || errors.Is(err, ErrIntervalIncomplete), "should return no error or ErrIntervalIncomplete")
 	// Result will be nil because we haven't completed an interval
 	assert.Nil(t, result)
-}
\ No newline at end of file
+}
+
+// TestSoundLevelProcessor_BroadbandLevels tests the broadband levels of an interval
+func TestSoundLevelProcessor_BroadbandLevels(t *testing.T) {
+	processor := &soundLevelProcessor{
+		intervalBuffer: &intervalAggregator{
+			secondMeasurements: []map[string]float64{
+				{"1000.0_Hz": -20, "2000.0_Hz": -20}, // -16.99 dB combined
+				{"1000.0_Hz": -30},
+				{}, // no measurement
+			},
+		},
+	}
+
+	leq, lmin, lmax := processor.broadbandLevels()
+	assert.InDelta(t, -19.79, leq, 0.01, "Leq is the energetic mean of the measured seconds")
+	assert.InDelta(t, -30, lmin, 0.01)
+	assert.InDelta(t, -16.99, lmax, 0.01)
+
+	processor.intervalBuffer.secondMeasurements = []map[string]float64{{}}
+	leq, lmin, lmax = processor.broadbandLevels()
+	assert.Equal(t, []float64{-100, -100, -100}, []float64{leq, lmin, lmax}, "intervals without measurements are at the noise floor")
+}
+
+// TestSoundLevelData_BandLevel tests the combined level of a frequency range
+func TestSoundLevelData_BandLevel(t *testing.T) {
+	data := SoundLevelData{
+		OctaveBands: map[string]OctaveBandData{
+			"500.0_Hz": {CenterFreq: 500, Min: -30, Mean: -10},
+			"1.0_kHz":  {CenterFreq: 1000, Min: -40, Mean: -20},
+			"2.0_kHz":  {CenterFreq: 2000, Min: -40, Mean: -20},
+		},
+	}
+
+	assert.InDelta(t, -16.99, data.BandLevel(1000, 8000), 0.01, "bands outside the range are left out")
+	assert.InDelta(t, -9.21, data.BandLevel(0, 20000), 0.01)
+	assert.InDelta(t, -100, data.BandLevel(10000, 20000), 0.01, "ranges without bands are at the noise floor")
+
+	assert.InDelta(t, -36.99, data.BandMinLevel(1000, 8000), 0.01, "band minimums describe the background")
+	assert.InDelta(t, -29.21, data.BandMinLevel(0, 20000), 0.01)
+	assert.InDelta(t, -100, data.BandMinLevel(10000, 20000), 0.01)
+}
+
+// TestLatestSoundLevel tests that the last completed interval of a source is kept
+func TestLatestSoundLevel(t *testing.T) {
+	settings := conf.Setting()
+	if settings == nil {
+		t.Skip("Settings not available for test")
+	}
+
+	originalInterval := settings.Realtime.Audio.SoundLevel.Interval
+	settings.Realtime.Audio.SoundLevel.Interval = 5
+	defer func() {
+		settings.Realtime.Audio.SoundLevel.Interval = originalInterval
+	}()
+
+	require.NoError(t, RegisterSoundLevelProcessor("latest-test-source", "Latest Test"))
+	defer UnregisterSoundLevelProcessor("latest-test-source")
+
+	_, ok := LatestSoundLevel("latest-test-source")
+	assert.False(t, ok, "no level before the first interval completes")
+	_, ok = LatestSoundLevel("unknown-source")
+	assert.False(t, ok)
+
+	oneSecondData := make([]byte, conf.SampleRate*2)
+	for range 5 {
+		_, _ = ProcessSoundLevelData("latest-test-source", oneSecondData)
+	}
+
+	latest, ok := LatestSoundLevel("latest-test-source")
+	require.True(t, ok)
+	assert.Equal(t, "latest-test-source", latest.Source)
+	assert.Equal(t, 5, latest.Duration)
+}
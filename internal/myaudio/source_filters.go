@@ -0,0 +1,134 @@
+package myaudio
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/myaudio/equalizer"
+)
+
+// sourceFilter is the filter chain and gain of one audio source. Filters keep state
+// between buffers, so each source has chains of its own.
+type sourceFilter struct {
+	chain *equalizer.FilterChain // empty if the source has no filters
+	gain  float64                // linear gain applied after the filters
+}
+
+// Per-source filters by the source as configured, an RTSP URL or a sound card
+var (
+	sourceFilters      map[string]*sourceFilter
+	sourceFiltersMutex sync.RWMutex
+)
+
+// UpdateSourceFilters builds the per-source filter chains of the settings and replaces the
+// current chains. The current chains are kept if any chain fails to build.
+func UpdateSourceFilters(settings *conf.Settings) error {
+	if settings == nil {
+		return errors.Newf("settings parameter is nil").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "update_source_filters").
+			Build()
+	}
+
+	filters := make(map[string]*sourceFilter, len(settings.Realtime.Audio.SourceFilters))
+	for i := range settings.Realtime.Audio.SourceFilters {
+		entry := &settings.Realtime.Audio.SourceFilters[i]
+		filter, err := newSourceFilter(entry)
+		if err != nil {
+			return errors.New(err).
+				Component("myaudio").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "update_source_filters").
+				Context("source_index", i).
+				Build()
+		}
+		filters[entry.Source] = filter
+	}
+
+	sourceFiltersMutex.Lock()
+	sourceFilters = filters
+	sourceFiltersMutex.Unlock()
+	return nil
+}
+
+// newSourceFilter builds the filter chain of a source, disabled filters are skipped
+func newSourceFilter(entry *conf.SourceAudioSettings) (*sourceFilter, error) {
+	filter := &sourceFilter{
+		chain: equalizer.NewFilterChain(),
+		gain:  math.Pow(10, entry.Gain/20),
+	}
+	if !entry.Equalizer.Enabled {
+		return filter, nil
+	}
+	for i, filterConfig := range entry.Equalizer.Filters {
+		eqFilter, err := createFilter(filterConfig, float64(conf.SampleRate))
+		if errors.Is(err, ErrFilterDisabled) {
+			continue
+		} else if err != nil {
+			return nil, errors.New(err).
+				Component("myaudio").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "create_source_filter").
+				Context("filter_index", i).
+				Context("filter_type", filterConfig.Type).
+				Build()
+		}
+		if err := filter.chain.AddFilter(eqFilter); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// lookupSourceFilter returns the filter of a source ID. Filters are configured by the
+// connection string of the source, the source ID is accepted as well.
+func lookupSourceFilter(sourceID string) *sourceFilter {
+	sourceFiltersMutex.RLock()
+	defer sourceFiltersMutex.RUnlock()
+	if len(sourceFilters) == 0 {
+		return nil
+	}
+	if registry := GetRegistry(); registry != nil {
+		if source, ok := registry.GetSourceByID(sourceID); ok {
+			if filter, ok := sourceFilters[source.connectionString]; ok {
+				return filter
+			}
+		}
+	}
+	return sourceFilters[sourceID]
+}
+
+// ApplySourceFilters applies the filter chain and gain of a source to 16-bit PCM samples
+// in place, a trailing odd byte is left as is. It returns false without changing the
+// samples if the source has no filters configured, in which case the global equalizer
+// applies.
+func ApplySourceFilters(sourceID string, samples []byte) bool {
+	filter := lookupSourceFilter(sourceID)
+	if filter == nil {
+		return false
+	}
+	if filter.chain.Length() == 0 && filter.gain == 1 {
+		return true
+	}
+
+	floatSamples := make([]float64, len(samples)/2)
+	for i := range floatSamples {
+		floatSamples[i] = float64(int16(binary.LittleEndian.Uint16(samples[i*2:]))) / 32768.0 //nolint:gosec // G115: audio sample conversion within 16-bit range
+	}
+
+	// Hot reloads replace the filters of a source instead of changing them, so only the
+	// goroutine capturing the source uses its chain
+	if filter.chain.Length() > 0 {
+		filter.chain.ApplyBatch(floatSamples)
+	}
+
+	for i, sample := range floatSamples {
+		sample = math.Max(-1, math.Min(1, sample*filter.gain))
+		binary.LittleEndian.PutUint16(samples[i*2:], uint16(int16(sample*32767.0))) //nolint:gosec // G115: audio sample conversion within 16-bit range
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package myaudio
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// pcmSamples encodes samples as 16-bit little-endian PCM
+func pcmSamples(samples ...int16) []byte {
+	data := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(s)) //nolint:gosec // G115: test samples
+	}
+	return data
+}
+
+// pcmSample decodes the sample at an index of 16-bit little-endian PCM
+func pcmSample(data []byte, i int) int16 {
+	return int16(binary.LittleEndian.Uint16(data[i*2:])) //nolint:gosec // G115: test samples
+}
+
+func TestApplySourceFilters(t *testing.T) {
+	const cameraURL = "rtsp://source-filters.local/camera"
+	camera := GetRegistry().GetOrCreateSource(cameraURL, SourceTypeRTSP)
+	require.NotNil(t, camera)
+	t.Cleanup(func() {
+		_ = GetRegistry().RemoveSource(camera.ID)
+		sourceFiltersMutex.Lock()
+		sourceFilters = nil
+		sourceFiltersMutex.Unlock()
+	})
+
+	settings := &conf.Settings{}
+	settings.Realtime.Audio.SourceFilters = []conf.SourceAudioSettings{
+		{Source: cameraURL, Gain: 6.0206}, // doubles the amplitude
+		{Source: "hw:1,0", Equalizer: conf.EqualizerSettings{
+			Enabled: true,
+			Filters: []conf.EqualizerFilter{{Type: "HighPass", Frequency: 1000, Q: 0.7, Passes: 1}},
+		}},
+	}
+	require.NoError(t, UpdateSourceFilters(settings))
+
+	// Filters are found by the connection string of the source, gain is clamped
+	data := pcmSamples(1000, -1000, 30000)
+	require.True(t, ApplySourceFilters(camera.ID, data))
+	assert.InDelta(t, 2000, pcmSample(data, 0), 2)
+	assert.InDelta(t, -2000, pcmSample(data, 1), 2)
+	assert.Equal(t, int16(32767), pcmSample(data, 2))
+
+	// Sources without an entry are left to the global equalizer
+	data = pcmSamples(1000, 1000)
+	assert.False(t, ApplySourceFilters("unconfigured", data))
+	assert.Equal(t, pcmSamples(1000, 1000), data)
+
+	// A high-pass filter removes a constant offset, sources without a registry entry are
+	// matched by the configured value
+	offset := make([]int16, 4800)
+	for i := range offset {
+		offset[i] = 10000
+	}
+	data = pcmSamples(offset...)
+	require.True(t, ApplySourceFilters("hw:1,0", data))
+	assert.InDelta(t, 0, pcmSample(data, len(offset)-1), 50)
+
+	// Invalid filters keep the current chains
+	settings.Realtime.Audio.SourceFilters = []conf.SourceAudioSettings{{
+		Source:    cameraURL,
+		Equalizer: conf.EqualizerSettings{Enabled: true, Filters: []conf.EqualizerFilter{{Type: "Unknown", Passes: 1}}},
+	}}
+	require.Error(t, UpdateSourceFilters(settings))
+	data = pcmSamples(1000)
+	require.True(t, ApplySourceFilters(camera.ID, data))
+	assert.InDelta(t, 2000, pcmSample(data, 0), 2)
+
+	// Removing all entries restores the global equalizer
+	settings.Realtime.Audio.SourceFilters = nil
+	require.NoError(t, UpdateSourceFilters(settings))
+	assert.False(t, ApplySourceFilters(camera.ID, pcmSamples(1000)))
+}
@@ -79,7 +79,7 @@ func (c *DetectionNotificationConsumer) ProcessDetectionEvent(event events.Detec
 
 	// Add the notification through the service
 	// First save to store
-	if err := c.service.store.Save(notification); err != nil {
+	if err := c.service.saveToStore(notification); err != nil {
 		c.logger.Error("failed to save new species notification",
 			"species", event.GetSpeciesName(),
 			"error", err,
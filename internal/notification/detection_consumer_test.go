@@ -1,7 +1,9 @@
 package notification
 
 import (
+	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -212,3 +214,38 @@ func TestDetectionNotificationConsumer_PreSanitizedLocations(t *testing.T) {
 		})
 	}
 }
+
+// TestDetectionNotificationConsumer_StoreReplaced checks that notifications saved while the
+// store is replaced end up in the new store
+func TestDetectionNotificationConsumer_StoreReplaced(t *testing.T) {
+	t.Parallel()
+
+	service := NewService(&ServiceConfig{
+		MaxNotifications:   1000,
+		CleanupInterval:    5 * time.Minute,
+		RateLimitWindow:    1 * time.Minute,
+		RateLimitMaxEvents: 1000,
+	})
+	defer service.Stop()
+	consumer := NewDetectionNotificationConsumer(service)
+
+	const detections = 50
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < detections; i++ {
+			event, err := events.NewDetectionEvent(fmt.Sprintf("Species %d", i), "Genus species", 0.9, "garden", true, 0)
+			assert.NoError(t, err)
+			assert.NoError(t, consumer.ProcessDetectionEvent(event))
+		}
+	}()
+
+	store := NewInMemoryStore(1000)
+	require.NoError(t, service.SetStore(store))
+	wg.Wait()
+
+	count, err := store.Count(nil)
+	require.NoError(t, err)
+	assert.Equal(t, detections, count, "no notification is lost while the store is replaced")
+}
@@ -0,0 +1,282 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/analysis/jobqueue"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// PushDispatcher forwards notifications from the notification service to
+// external push providers. Deliveries are executed through a job queue so
+// that failed attempts are retried with exponential backoff.
+type PushDispatcher struct {
+	service *Service
+	targets []*pushTarget
+	queue   *jobqueue.JobQueue
+	retry   jobqueue.RetryConfig
+	logger  *slog.Logger
+	debug   bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// pushTarget couples a provider with its filter
+type pushTarget struct {
+	provider PushProvider
+	filter   PushFilter
+}
+
+// PushProviderInfo describes a configured push provider
+type PushProviderInfo struct {
+	Name   string                  `json:"name"`
+	Type   string                  `json:"type"`
+	Filter conf.PushFilterSettings `json:"filter"`
+}
+
+// pushJobAction is the job queue action delivering one notification to one provider
+type pushJobAction struct {
+	target *pushTarget
+	notif  *Notification
+	ctx    context.Context
+}
+
+// Execute sends the notification to the provider
+func (a *pushJobAction) Execute(data any) error {
+	return a.target.provider.Send(a.ctx, a.notif)
+}
+
+// GetDescription returns a description used for job queue statistics
+func (a *pushJobAction) GetDescription() string {
+	return fmt.Sprintf("Push notification via %s (%s)", a.target.provider.Name(), a.target.provider.Type())
+}
+
+var (
+	pushDispatcher   *PushDispatcher
+	pushDispatcherMu sync.RWMutex
+)
+
+// NewPushDispatcher creates a dispatcher for the enabled providers in settings.
+// Providers that fail to initialize are logged and skipped.
+func NewPushDispatcher(service *Service, settings *conf.PushSettings) (*PushDispatcher, error) {
+	if service == nil {
+		return nil, errors.Newf("notification service is required for push delivery").
+			Component("notification").
+			Category(errors.CategoryConfiguration).
+			Build()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &PushDispatcher{
+		service: service,
+		queue:   jobqueue.NewJobQueue(),
+		retry: jobqueue.RetryConfig{
+			Enabled:      settings.RetrySettings.Enabled,
+			MaxRetries:   settings.RetrySettings.MaxRetries,
+			InitialDelay: time.Duration(settings.RetrySettings.InitialDelay) * time.Second,
+			MaxDelay:     time.Duration(settings.RetrySettings.MaxDelay) * time.Second,
+			Multiplier:   settings.RetrySettings.BackoffMultiplier,
+		},
+		logger: getFileLogger(settings.Debug),
+		debug:  settings.Debug,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	for i := range settings.Providers {
+		cfg := &settings.Providers[i]
+		if !cfg.Enabled {
+			continue
+		}
+		provider, err := NewPushProvider(cfg)
+		if err != nil {
+			d.logger.Error("failed to initialize push provider",
+				"provider", cfg.Name,
+				"type", cfg.Type,
+				"error", err)
+			continue
+		}
+		d.targets = append(d.targets, &pushTarget{
+			provider: provider,
+			filter:   NewPushFilter(&cfg.Filter),
+		})
+	}
+
+	return d, nil
+}
+
+// Start subscribes to the notification service and begins delivering notifications
+func (d *PushDispatcher) Start() {
+	d.queue.StartWithContext(d.ctx)
+
+	ch, subCtx := d.service.Subscribe()
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer d.service.Unsubscribe(ch)
+		for {
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-subCtx.Done():
+				return
+			case notif, ok := <-ch:
+				if !ok {
+					return
+				}
+				d.dispatch(notif)
+			}
+		}
+	}()
+
+	d.logger.Info("push notification dispatcher started",
+		"providers", len(d.targets),
+		"retry_enabled", d.retry.Enabled,
+		"max_retries", d.retry.MaxRetries)
+}
+
+// Stop stops delivering notifications and shuts down the job queue
+func (d *PushDispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+	if err := d.queue.StopWithTimeout(5 * time.Second); err != nil {
+		d.logger.Warn("push job queue did not stop cleanly", "error", err)
+	}
+}
+
+// dispatch enqueues a delivery job for every provider whose filter matches
+func (d *PushDispatcher) dispatch(notif *Notification) {
+	// Toasts are transient UI messages and are not pushed externally
+	if notif == nil || isToastNotification(notif) {
+		return
+	}
+
+	for _, target := range d.targets {
+		if !target.filter.Matches(notif) {
+			if d.debug {
+				d.logger.Debug("push provider filter skipped notification",
+					"provider", target.provider.Name(),
+					"notification_id", notif.ID,
+					"type", notif.Type,
+					"priority", notif.Priority)
+			}
+			continue
+		}
+
+		// Queue a copy so status changes in the store do not race with delivery
+		notifCopy := *notif
+		action := &pushJobAction{target: target, notif: &notifCopy, ctx: d.ctx}
+		if _, err := d.queue.Enqueue(d.ctx, action, nil, d.retry); err != nil {
+			d.logger.Error("failed to enqueue push notification",
+				"provider", target.provider.Name(),
+				"notification_id", notif.ID,
+				"error", err)
+			continue
+		}
+
+		if d.debug {
+			d.logger.Debug("push notification enqueued",
+				"provider", target.provider.Name(),
+				"notification_id", notif.ID)
+		}
+	}
+}
+
+// Providers returns information about the active providers
+func (d *PushDispatcher) Providers() []PushProviderInfo {
+	infos := make([]PushProviderInfo, 0, len(d.targets))
+	for _, target := range d.targets {
+		filter := conf.PushFilterSettings{}
+		for _, t := range target.filter.Types {
+			filter.Types = append(filter.Types, string(t))
+		}
+		for _, p := range target.filter.Priorities {
+			filter.Priorities = append(filter.Priorities, string(p))
+		}
+		filter.Components = append(filter.Components, target.filter.Components...)
+
+		infos = append(infos, PushProviderInfo{
+			Name:   target.provider.Name(),
+			Type:   target.provider.Type(),
+			Filter: filter,
+		})
+	}
+	return infos
+}
+
+// TestProvider sends a test notification directly to the named provider,
+// bypassing filters and the retry queue so the caller gets the result immediately
+func (d *PushDispatcher) TestProvider(ctx context.Context, name string) error {
+	for _, target := range d.targets {
+		if target.provider.Name() != name {
+			continue
+		}
+
+		notif := NewNotification(TypeInfo, PriorityLow,
+			"BirdNET-Go test notification",
+			fmt.Sprintf("This is a test notification for push provider %q.", name)).
+			WithComponent("notification")
+
+		if err := target.provider.Send(ctx, notif); err != nil {
+			d.logger.Warn("push provider test failed", "provider", name, "error", err)
+			return err
+		}
+
+		d.logger.Info("push provider test succeeded", "provider", name)
+		return nil
+	}
+
+	return ErrPushProviderNotFound
+}
+
+// ErrPushProviderNotFound is returned when a named push provider is not configured
+var ErrPushProviderNotFound = errors.Newf("push provider not found").
+	Component("notification").
+	Category(errors.CategoryNotFound).
+	Build()
+
+// InitializePushDispatcher creates and starts the global push dispatcher for the
+// global notification service. It is a no-op when push delivery is disabled.
+func InitializePushDispatcher(settings *conf.PushSettings) error {
+	if settings == nil || !settings.Enabled {
+		return nil
+	}
+
+	service := GetService()
+	if service == nil {
+		return errors.Newf("notification service not initialized").
+			Component("notification").
+			Category(errors.CategorySystem).
+			Context("operation", "initialize_push_dispatcher").
+			Build()
+	}
+
+	pushDispatcherMu.Lock()
+	defer pushDispatcherMu.Unlock()
+
+	if pushDispatcher != nil {
+		return nil
+	}
+
+	d, err := NewPushDispatcher(service, settings)
+	if err != nil {
+		return err
+	}
+	d.Start()
+	pushDispatcher = d
+
+	return nil
+}
+
+// GetPushDispatcher returns the global push dispatcher, or nil when push delivery is disabled
+func GetPushDispatcher() *PushDispatcher {
+	pushDispatcherMu.RLock()
+	defer pushDispatcherMu.RUnlock()
+	return pushDispatcher
+}
@@ -0,0 +1,164 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// defaultSMTPPort is the SMTP submission port used when none is configured
+const defaultSMTPPort = 587
+
+// emailProvider delivers notifications by email over SMTP
+type emailProvider struct {
+	name     string
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	to       []string
+	tls      bool
+	timeout  time.Duration
+}
+
+// newEmailProvider creates an SMTP email provider
+func newEmailProvider(cfg *conf.PushProviderConfig, timeout time.Duration) (*emailProvider, error) {
+	if cfg.SMTP.Host == "" || len(cfg.SMTP.To) == 0 {
+		return nil, errors.Newf("email provider requires an SMTP host and at least one recipient").
+			Component("notification").
+			Category(errors.CategoryConfiguration).
+			Context("provider", cfg.Name).
+			Build()
+	}
+
+	p := &emailProvider{
+		name:     cfg.Name,
+		host:     cfg.SMTP.Host,
+		port:     cfg.SMTP.Port,
+		username: cfg.SMTP.Username,
+		password: cfg.SMTP.Password,
+		from:     cfg.SMTP.From,
+		to:       cfg.SMTP.To,
+		tls:      cfg.SMTP.TLS,
+		timeout:  timeout,
+	}
+	if p.port == 0 {
+		p.port = defaultSMTPPort
+	}
+	if p.from == "" {
+		p.from = p.username
+	}
+
+	return p, nil
+}
+
+// Name returns the provider name
+func (p *emailProvider) Name() string { return p.name }
+
+// Type returns the provider type
+func (p *emailProvider) Type() string { return PushProviderEmail }
+
+// Send delivers the notification as a plain text email
+func (p *emailProvider) Send(ctx context.Context, notif *Notification) error {
+	addr := net.JoinHostPort(p.host, strconv.Itoa(p.port))
+
+	deadline := time.Now().Add(p.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	var err error
+	if p.tls {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: p.host, MinVersion: tls.VersionTLS12})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return newPushError(err, p, "smtp_dial")
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		_ = conn.Close()
+		return newPushError(err, p, "smtp_client")
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	// Upgrade plain connections when the server offers STARTTLS
+	if !p.tls {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: p.host, MinVersion: tls.VersionTLS12}); err != nil {
+				return newPushError(err, p, "smtp_starttls")
+			}
+		}
+	}
+
+	if p.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", p.username, p.password, p.host)); err != nil {
+			return newPushError(err, p, "smtp_auth")
+		}
+	}
+
+	if err := client.Mail(p.from); err != nil {
+		return newPushError(err, p, "smtp_mail")
+	}
+	for _, rcpt := range p.to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return newPushError(err, p, "smtp_rcpt")
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return newPushError(err, p, "smtp_data")
+	}
+	if _, err := w.Write(p.buildMessage(notif)); err != nil {
+		_ = w.Close()
+		return newPushError(err, p, "smtp_write")
+	}
+	if err := w.Close(); err != nil {
+		return newPushError(err, p, "smtp_write")
+	}
+
+	return client.Quit()
+}
+
+// buildMessage formats the notification as an RFC 5322 message
+func (p *emailProvider) buildMessage(notif *Notification) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "From: %s\r\n", sanitizeHeaderValue(p.from))
+	fmt.Fprintf(&sb, "To: %s\r\n", sanitizeHeaderValue(strings.Join(p.to, ", ")))
+	fmt.Fprintf(&sb, "Subject: [BirdNET-Go] %s\r\n", sanitizeHeaderValue(notif.Title))
+	fmt.Fprintf(&sb, "Date: %s\r\n", notif.Timestamp.Format(time.RFC1123Z))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	sb.WriteString("\r\n")
+
+	sb.WriteString(strings.ReplaceAll(notif.Message, "\n", "\r\n"))
+	sb.WriteString("\r\n\r\n")
+	fmt.Fprintf(&sb, "Type: %s\r\nPriority: %s\r\n", notif.Type, notif.Priority)
+	if notif.Component != "" {
+		fmt.Fprintf(&sb, "Component: %s\r\n", notif.Component)
+	}
+
+	return []byte(sb.String())
+}
+
+// sanitizeHeaderValue strips line breaks to prevent header injection
+func sanitizeHeaderValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
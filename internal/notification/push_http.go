@@ -0,0 +1,304 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// maxPushResponseBody limits how much of an error response body is included in errors
+const maxPushResponseBody = 512
+
+// pushTemplateData holds the fields available to webhook body templates
+type pushTemplateData struct {
+	ID        string
+	Type      Type
+	Priority  Priority
+	Title     string
+	Message   string
+	Component string
+	Timestamp time.Time
+	Metadata  map[string]any
+}
+
+// webhookPayload is the default JSON body sent by the webhook provider
+type webhookPayload struct {
+	ID        string         `json:"id"`
+	Type      Type           `json:"type"`
+	Priority  Priority       `json:"priority"`
+	Title     string         `json:"title"`
+	Message   string         `json:"message"`
+	Component string         `json:"component,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// pushTemplateFuncs are the helper functions available to webhook templates.
+// The json function encodes a value as JSON, which makes it safe to embed
+// arbitrary strings in a JSON body: {"text": {{json .Message}}}
+var pushTemplateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	},
+}
+
+// webhookProvider posts notifications as JSON to an arbitrary HTTP endpoint
+type webhookProvider struct {
+	name     string
+	url      string
+	method   string
+	headers  map[string]string
+	template *template.Template
+	client   *http.Client
+}
+
+// newWebhookProvider creates a generic JSON webhook provider
+func newWebhookProvider(cfg *conf.PushProviderConfig, client *http.Client) (*webhookProvider, error) {
+	p := &webhookProvider{
+		name:    cfg.Name,
+		url:     cfg.URL,
+		method:  strings.ToUpper(cfg.Method),
+		headers: cfg.Headers,
+		client:  client,
+	}
+	if p.method == "" {
+		p.method = http.MethodPost
+	}
+
+	if cfg.Template != "" {
+		tmpl, err := template.New(cfg.Name).Funcs(pushTemplateFuncs).Option("missingkey=zero").Parse(cfg.Template)
+		if err != nil {
+			return nil, errors.New(fmt.Errorf("invalid webhook template: %w", err)).
+				Component("notification").
+				Category(errors.CategoryConfiguration).
+				Context("provider", cfg.Name).
+				Build()
+		}
+		p.template = tmpl
+	}
+
+	return p, nil
+}
+
+// Name returns the provider name
+func (p *webhookProvider) Name() string { return p.name }
+
+// Type returns the provider type
+func (p *webhookProvider) Type() string { return PushProviderWebhook }
+
+// Send renders the body and posts it to the webhook endpoint
+func (p *webhookProvider) Send(ctx context.Context, notif *Notification) error {
+	body, err := p.renderBody(notif)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, p.method, p.url, bytes.NewReader(body))
+	if err != nil {
+		return newPushError(err, p, "webhook_request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+
+	return doPushRequest(p.client, req, p)
+}
+
+// renderBody builds the JSON request body, using the template when configured
+func (p *webhookProvider) renderBody(notif *Notification) ([]byte, error) {
+	if p.template == nil {
+		return json.Marshal(webhookPayload{
+			ID:        notif.ID,
+			Type:      notif.Type,
+			Priority:  notif.Priority,
+			Title:     notif.Title,
+			Message:   notif.Message,
+			Component: notif.Component,
+			Timestamp: notif.Timestamp,
+			Metadata:  notif.Metadata,
+		})
+	}
+
+	data := pushTemplateData{
+		ID:        notif.ID,
+		Type:      notif.Type,
+		Priority:  notif.Priority,
+		Title:     notif.Title,
+		Message:   notif.Message,
+		Component: notif.Component,
+		Timestamp: notif.Timestamp,
+		Metadata:  notif.Metadata,
+	}
+
+	var buf bytes.Buffer
+	if err := p.template.Execute(&buf, &data); err != nil {
+		return nil, errors.New(fmt.Errorf("failed to render webhook template: %w", err)).
+			Component("notification").
+			Category(errors.CategoryValidation).
+			Context("provider", p.name).
+			Build()
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, errors.Newf("webhook template did not produce valid JSON").
+			Component("notification").
+			Category(errors.CategoryValidation).
+			Context("provider", p.name).
+			Build()
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ntfyProvider publishes notifications to an ntfy server topic
+type ntfyProvider struct {
+	name    string
+	url     string
+	token   string
+	headers map[string]string
+	client  *http.Client
+}
+
+// newNtfyProvider creates an ntfy provider
+func newNtfyProvider(cfg *conf.PushProviderConfig, client *http.Client) (*ntfyProvider, error) {
+	if cfg.Topic == "" {
+		return nil, errors.Newf("ntfy provider requires a topic").
+			Component("notification").
+			Category(errors.CategoryConfiguration).
+			Context("provider", cfg.Name).
+			Build()
+	}
+
+	return &ntfyProvider{
+		name:    cfg.Name,
+		url:     strings.TrimRight(cfg.URL, "/") + "/" + strings.TrimLeft(cfg.Topic, "/"),
+		token:   cfg.Token,
+		headers: cfg.Headers,
+		client:  client,
+	}, nil
+}
+
+// Name returns the provider name
+func (p *ntfyProvider) Name() string { return p.name }
+
+// Type returns the provider type
+func (p *ntfyProvider) Type() string { return PushProviderNtfy }
+
+// Send publishes the notification message to the ntfy topic
+func (p *ntfyProvider) Send(ctx context.Context, notif *Notification) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, strings.NewReader(notif.Message))
+	if err != nil {
+		return newPushError(err, p, "ntfy_request")
+	}
+
+	req.Header.Set("Title", notif.Title)
+	// ntfy priorities range from 1 (min) to 5 (max)
+	req.Header.Set("Priority", fmt.Sprintf("%d", pushPriorityLevel(notif.Priority)+1))
+	req.Header.Set("Tags", string(notif.Type))
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	return doPushRequest(p.client, req, p)
+}
+
+// gotifyProvider sends notifications to a Gotify server
+type gotifyProvider struct {
+	name   string
+	url    string
+	token  string
+	client *http.Client
+}
+
+// gotifyMessage is the request body of the Gotify message API
+type gotifyMessage struct {
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+}
+
+// newGotifyProvider creates a Gotify provider
+func newGotifyProvider(cfg *conf.PushProviderConfig, client *http.Client) (*gotifyProvider, error) {
+	if cfg.Token == "" {
+		return nil, errors.Newf("gotify provider requires an application token").
+			Component("notification").
+			Category(errors.CategoryConfiguration).
+			Context("provider", cfg.Name).
+			Build()
+	}
+
+	return &gotifyProvider{
+		name:   cfg.Name,
+		url:    strings.TrimRight(cfg.URL, "/") + "/message",
+		token:  cfg.Token,
+		client: client,
+	}, nil
+}
+
+// Name returns the provider name
+func (p *gotifyProvider) Name() string { return p.name }
+
+// Type returns the provider type
+func (p *gotifyProvider) Type() string { return PushProviderGotify }
+
+// Send posts the notification to the Gotify message API
+func (p *gotifyProvider) Send(ctx context.Context, notif *Notification) error {
+	// Gotify priorities range from 0 to 10, clients typically alert from 8 upwards
+	priorities := map[int]int{1: 2, 2: 5, 3: 8, 4: 10}
+
+	body, err := json.Marshal(gotifyMessage{
+		Title:    notif.Title,
+		Message:  notif.Message,
+		Priority: priorities[pushPriorityLevel(notif.Priority)],
+	})
+	if err != nil {
+		return newPushError(err, p, "gotify_marshal")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return newPushError(err, p, "gotify_request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", p.token)
+
+	return doPushRequest(p.client, req, p)
+}
+
+// doPushRequest executes an HTTP request and treats non-2xx responses as errors
+func doPushRequest(client *http.Client, req *http.Request, provider PushProvider) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return newPushError(err, provider, "push_send")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxPushResponseBody))
+		return newPushError(
+			fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet))),
+			provider, "push_send")
+	}
+
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxPushResponseBody))
+	return nil
+}
@@ -0,0 +1,142 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Supported push provider types
+const (
+	PushProviderWebhook = "webhook"
+	PushProviderNtfy    = "ntfy"
+	PushProviderGotify  = "gotify"
+	PushProviderEmail   = "email"
+	PushProviderApprise = "apprise"
+)
+
+// defaultPushTimeout is used when a provider does not configure a timeout
+const defaultPushTimeout = 10 * time.Second
+
+// PushProvider delivers notifications to an external service
+type PushProvider interface {
+	// Name returns the configured, unique name of the provider
+	Name() string
+	// Type returns the provider type (webhook, ntfy, gotify, email)
+	Type() string
+	// Send delivers a notification to the external service
+	Send(ctx context.Context, notif *Notification) error
+}
+
+// PushFilter decides which notifications are delivered to a provider.
+// Empty criteria match everything.
+type PushFilter struct {
+	Types      []Type
+	Priorities []Priority
+	Components []string
+}
+
+// NewPushFilter creates a PushFilter from provider filter settings
+func NewPushFilter(settings *conf.PushFilterSettings) PushFilter {
+	filter := PushFilter{}
+	for _, t := range settings.Types {
+		filter.Types = append(filter.Types, Type(strings.ToLower(strings.TrimSpace(t))))
+	}
+	for _, p := range settings.Priorities {
+		filter.Priorities = append(filter.Priorities, Priority(strings.ToLower(strings.TrimSpace(p))))
+	}
+	for _, c := range settings.Components {
+		filter.Components = append(filter.Components, strings.ToLower(strings.TrimSpace(c)))
+	}
+	return filter
+}
+
+// Matches reports whether the notification passes the filter
+func (f *PushFilter) Matches(notif *Notification) bool {
+	if notif == nil {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, notif.Type) {
+		return false
+	}
+	if len(f.Priorities) > 0 && !slices.Contains(f.Priorities, notif.Priority) {
+		return false
+	}
+	if len(f.Components) > 0 && !slices.Contains(f.Components, strings.ToLower(notif.Component)) {
+		return false
+	}
+	return true
+}
+
+// NewPushProvider creates a push provider from its configuration.
+// Apprise URLs are resolved to the matching native provider.
+func NewPushProvider(cfg *conf.PushProviderConfig) (PushProvider, error) {
+	timeout := defaultPushTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	switch strings.ToLower(cfg.Type) {
+	case PushProviderWebhook:
+		return newWebhookProvider(cfg, client)
+	case PushProviderNtfy:
+		return newNtfyProvider(cfg, client)
+	case PushProviderGotify:
+		return newGotifyProvider(cfg, client)
+	case PushProviderEmail:
+		return newEmailProvider(cfg, timeout)
+	case PushProviderApprise:
+		resolved, err := ParseAppriseURL(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+		// Keep the user-facing settings of the apprise entry
+		resolved.Name = cfg.Name
+		resolved.Enabled = cfg.Enabled
+		resolved.Timeout = cfg.Timeout
+		resolved.Filter = cfg.Filter
+		if resolved.Template == "" {
+			resolved.Template = cfg.Template
+		}
+		return NewPushProvider(resolved)
+	default:
+		return nil, errors.Newf("unsupported push provider type: %s", cfg.Type).
+			Component("notification").
+			Category(errors.CategoryConfiguration).
+			Context("provider", cfg.Name).
+			Context("type", cfg.Type).
+			Build()
+	}
+}
+
+// pushPriorityLevel maps a notification priority onto a numeric scale
+// from 1 (lowest) to 4 (highest) used by provider specific mappings
+func pushPriorityLevel(priority Priority) int {
+	switch priority {
+	case PriorityCritical:
+		return 4
+	case PriorityHigh:
+		return 3
+	case PriorityMedium:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// newPushError builds an enhanced error for a failed push delivery
+func newPushError(err error, provider PushProvider, operation string) error {
+	return errors.New(err).
+		Component("notification").
+		Category(errors.CategoryNetwork).
+		Context("operation", operation).
+		Context("provider", provider.Name()).
+		Context("provider_type", provider.Type()).
+		Build()
+}
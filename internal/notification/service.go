@@ -21,6 +21,7 @@ type Subscriber struct {
 // Service manages notifications and provides rate limiting
 type Service struct {
 	store         NotificationStore
+	storeMu       sync.RWMutex
 	subscribers   []*Subscriber
 	subscribersMu sync.RWMutex
 	rateLimiter   *RateLimiter
@@ -129,7 +130,7 @@ func (s *Service) Create(notifType Type, priority Priority, title, message strin
 	}
 
 	// Save to store
-	if err := s.store.Save(notification); err != nil {
+	if err := s.saveToStore(notification); err != nil {
 		return nil, errors.New(err).
 			Component("notification").
 			Category(errors.CategorySystem).
@@ -163,7 +164,7 @@ func (s *Service) CreateWithComponent(notifType Type, priority Priority, title,
 		WithComponent(component)
 
 	// Save to store
-	if err := s.store.Save(notification); err != nil {
+	if err := s.saveToStore(notification); err != nil {
 		return nil, errors.New(err).
 			Component("notification").
 			Category(errors.CategorySystem).
@@ -179,12 +180,87 @@ func (s *Service) CreateWithComponent(notifType Type, priority Priority, title,
 
 // Get retrieves a notification by ID
 func (s *Service) Get(id string) (*Notification, error) {
-	return s.store.Get(id)
+	return s.getStore().Get(id)
 }
 
 // List returns notifications based on filter options
 func (s *Service) List(filter *FilterOptions) ([]*Notification, error) {
-	return s.store.List(filter)
+	return s.getStore().List(filter)
+}
+
+// Count returns the number of notifications matching the filter, ignoring Limit and Offset
+func (s *Service) Count(filter *FilterOptions) (int, error) {
+	return s.getStore().Count(filter)
+}
+
+// getStore returns the active notification store
+func (s *Service) getStore() NotificationStore {
+	s.storeMu.RLock()
+	defer s.storeMu.RUnlock()
+	return s.store
+}
+
+// saveToStore saves a notification to the active store. The store lock is held during the
+// write so that a notification saved while SetStore migrates the store is not lost.
+func (s *Service) saveToStore(notification *Notification) error {
+	s.storeMu.RLock()
+	defer s.storeMu.RUnlock()
+	return s.store.Save(notification)
+}
+
+// updateInStore updates a notification in the active store, see saveToStore
+func (s *Service) updateInStore(notification *Notification) error {
+	s.storeMu.RLock()
+	defer s.storeMu.RUnlock()
+	return s.store.Update(notification)
+}
+
+// SetStore replaces the notification store, for example with a persistent
+// database-backed store once the datastore has been opened. Notifications held
+// by the current store that have not expired are copied into the new store so
+// nothing raised during startup is lost. Toast notifications are not copied.
+func (s *Service) SetStore(store NotificationStore) error {
+	if store == nil {
+		return errors.Newf("notification store cannot be nil").
+			Component("notification").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+
+	existing, err := s.store.List(nil)
+	if err != nil {
+		return errors.New(err).
+			Component("notification").
+			Category(errors.CategorySystem).
+			Context("operation", "list_notifications_for_store_migration").
+			Build()
+	}
+
+	migrated := 0
+	for _, notif := range existing {
+		if notif.IsExpired() {
+			continue
+		}
+		if err := store.Save(notif); err != nil {
+			return errors.New(err).
+				Component("notification").
+				Category(errors.CategorySystem).
+				Context("operation", "migrate_notification_store").
+				Context("notification_id", notif.ID).
+				Build()
+		}
+		migrated++
+	}
+
+	s.store = store
+	s.logger.Info("notification store replaced",
+		"store_type", fmt.Sprintf("%T", store),
+		"migrated_notifications", migrated)
+
+	return nil
 }
 
 // MarkAsRead updates a notification's status to read
@@ -196,13 +272,13 @@ func (s *Service) MarkAsRead(id string) error {
 			Build()
 	}
 
-	notification, err := s.store.Get(id)
+	notification, err := s.getStore().Get(id)
 	if err != nil {
 		return err
 	}
 
 	notification.MarkAsRead()
-	return s.store.Update(notification)
+	return s.updateInStore(notification)
 }
 
 // MarkAsAcknowledged updates a notification's status to acknowledged
@@ -214,13 +290,13 @@ func (s *Service) MarkAsAcknowledged(id string) error {
 			Build()
 	}
 
-	notification, err := s.store.Get(id)
+	notification, err := s.getStore().Get(id)
 	if err != nil {
 		return err
 	}
 
 	notification.MarkAsAcknowledged()
-	return s.store.Update(notification)
+	return s.updateInStore(notification)
 }
 
 // Delete removes a notification
@@ -232,7 +308,7 @@ func (s *Service) Delete(id string) error {
 			Build()
 	}
 
-	return s.store.Delete(id)
+	return s.getStore().Delete(id)
 }
 
 // Subscribe creates a channel to receive real-time notifications.
@@ -311,7 +387,7 @@ func (s *Service) Unsubscribe(ch <-chan *Notification) {
 
 // GetUnreadCount returns the number of unread notifications
 func (s *Service) GetUnreadCount() (int, error) {
-	return s.store.GetUnreadCount()
+	return s.getStore().GetUnreadCount()
 }
 
 // CreateErrorNotification creates a notification from an error
@@ -417,7 +493,7 @@ func (s *Service) cleanupLoop() {
 			if s.config.Debug {
 				// Count expired notifications before cleanup
 				filter := &FilterOptions{}
-				notifications, _ := s.store.List(filter)
+				notifications, _ := s.getStore().List(filter)
 				var expiredCount int
 				for _, n := range notifications {
 					if n.IsExpired() {
@@ -431,7 +507,7 @@ func (s *Service) cleanupLoop() {
 				}
 			}
 			
-			if err := s.store.DeleteExpired(); err != nil {
+			if err := s.getStore().DeleteExpired(); err != nil {
 				// Log error but don't stop the cleanup loop
 				if s.logger != nil {
 					s.logger.Error("error cleaning up expired notifications", "error", err)
@@ -248,4 +248,40 @@ func TestInMemoryStoreDelete(t *testing.T) {
 	// Delete unread notification - count should decrease
 	mustDeleteNotification(t, store, notif1.ID)
 	assertUnreadCount(t, store, 0, "Unread count after deleting unread notification")
-}
\ No newline at end of file
+}
+
+func TestInMemoryStoreCount(t *testing.T) {
+	t.Parallel()
+
+	store := NewInMemoryStore(100)
+	for i := 0; i < 6; i++ {
+		notifType := TypeInfo
+		if i%3 == 0 {
+			notifType = TypeError
+		}
+		if err := store.Save(NewNotification(notifType, PriorityLow, "Test", "Message")); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	toast := NewNotification(TypeInfo, PriorityLow, "Toast", "Message").WithMetadata(MetadataKeyIsToast, true)
+	if err := store.Save(toast); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	total, err := store.Count(nil)
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if total != 6 {
+		t.Errorf("expected 6 notifications excluding toasts, got %d", total)
+	}
+
+	// Limit and offset do not affect the count
+	errorCount, err := store.Count(&FilterOptions{Types: []Type{TypeError}, Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if errorCount != 2 {
+		t.Errorf("expected 2 error notifications, got %d", errorCount)
+	}
+}
@@ -171,6 +171,8 @@ type NotificationStore interface {
 	DeleteExpired() error
 	// GetUnreadCount returns the count of unread notifications
 	GetUnreadCount() (int, error)
+	// Count returns the number of notifications matching the filter, ignoring Limit and Offset
+	Count(filter *FilterOptions) (int, error)
 }
 
 // FilterOptions provides filtering capabilities for listing notifications
@@ -397,6 +399,20 @@ func (s *InMemoryStore) matchesFilter(notif *Notification, filter *FilterOptions
 	return true
 }
 
+// Count returns the number of notifications matching the filter, ignoring Limit and Offset
+func (s *InMemoryStore) Count(filter *FilterOptions) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, notif := range s.notifications {
+		if s.matchesFilter(notif, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
 // GetUnreadCount returns the count of unread notifications
 func (s *InMemoryStore) GetUnreadCount() (int, error) {
 	s.mu.RLock()
@@ -0,0 +1,222 @@
+package observation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// Frequency range analyzed by BirdNET, used as the selection bounds in Raven and Audacity
+const (
+	SelectionLowFreq  = 0
+	SelectionHighFreq = 15000
+)
+
+// Output file suffixes for the Raven selection table and Audacity label track
+const (
+	RavenFileSuffix    = ".selections.txt"
+	AudacityFileSuffix = ".labels.txt"
+)
+
+// ravenHeader is the header of a Raven Pro selection table. Begin Date and Begin Clock Time
+// are Raven measurements which allow matching selections to recordings by wall clock time.
+const ravenHeader = "Selection\tView\tChannel\tBegin Time (s)\tEnd Time (s)\tLow Freq (Hz)\tHigh Freq (Hz)\t" +
+	"Begin File\tBegin Date\tBegin Clock Time\tSpecies Code\tScientific Name\tCommon Name\tConfidence\n"
+
+// SelectionOffset returns the position of t in seconds relative to origin.
+// Notes from file analysis are relative to the zero time, so origin is time.Time{} for them.
+func SelectionOffset(t, origin time.Time) float64 {
+	offset := t.Sub(origin).Seconds()
+	if offset < 0 {
+		return 0
+	}
+	return offset
+}
+
+// NoteTimeRange returns the begin and end time of a note, falling back to the stored
+// date and time when the note has no begin time
+func NoteTimeRange(note *datastore.Note) (begin, end time.Time) {
+	begin, end = note.BeginTime, note.EndTime
+	if begin.IsZero() && note.Date != "" {
+		if t, err := time.ParseInLocation("2006-01-02 15:04:05", note.Date+" "+note.Time, time.Local); err == nil {
+			begin = t
+		}
+	}
+	if end.Before(begin) {
+		end = begin.Add(3 * time.Second)
+	}
+	return begin, end
+}
+
+// TabSeparatedField removes tabs and newlines which would break tab separated output
+func TabSeparatedField(s string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(s)
+}
+
+// selectionFile returns the audio file of a note. Notes of a file analysis carry the analyzed
+// file as their source, stored detections only keep the name of their audio clip.
+func selectionFile(note *datastore.Note) string {
+	if note.Source.SafeString != "" {
+		return note.Source.SafeString
+	}
+	if note.ClipName != "" {
+		return filepath.Base(note.ClipName)
+	}
+	return ""
+}
+
+// SelectionWriter writes notes as a Raven Pro selection table or an Audacity label track.
+// Notes can be written in several batches, selections are numbered across all of them.
+type SelectionWriter struct {
+	w         *bufio.Writer
+	origin    time.Time
+	raven     bool
+	selection int
+}
+
+// NewRavenSelectionWriter returns a writer for a Raven Pro selection table. Begin and end
+// times are seconds relative to origin, which should be the start of the recording.
+func NewRavenSelectionWriter(w io.Writer, origin time.Time) (*SelectionWriter, error) {
+	sw := &SelectionWriter{w: bufio.NewWriter(w), origin: origin, raven: true}
+	if _, err := sw.w.WriteString(ravenHeader); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	return sw, nil
+}
+
+// NewAudacityLabelWriter returns a writer for an Audacity label track. Each label carries
+// the common name and confidence, followed by a frequency line for spectral selections.
+func NewAudacityLabelWriter(w io.Writer, origin time.Time) *SelectionWriter {
+	return &SelectionWriter{w: bufio.NewWriter(w), origin: origin}
+}
+
+// Write writes a batch of notes
+func (sw *SelectionWriter) Write(notes []datastore.Note) error {
+	for i := range notes {
+		sw.selection++
+
+		var line string
+		if sw.raven {
+			line = sw.ravenLine(&notes[i])
+		} else {
+			line = sw.audacityLine(&notes[i])
+		}
+
+		if _, err := sw.w.WriteString(line); err != nil {
+			return fmt.Errorf("failed to write selection: %w", err)
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer
+func (sw *SelectionWriter) Flush() error {
+	if err := sw.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write selections: %w", err)
+	}
+	return nil
+}
+
+// ravenLine formats a note as a selection table row
+func (sw *SelectionWriter) ravenLine(note *datastore.Note) string {
+	begin, end := NoteTimeRange(note)
+
+	// Date and clock time are only meaningful for notes with a real timestamp
+	var beginDate, beginClock string
+	if begin.Year() > 1 {
+		beginDate = begin.Format("2006/01/02")
+		beginClock = begin.Format("15:04:05.000")
+	}
+
+	return fmt.Sprintf("%d\tSpectrogram 1\t1\t%.3f\t%.3f\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%.4f\n",
+		sw.selection, SelectionOffset(begin, sw.origin), SelectionOffset(end, sw.origin),
+		SelectionLowFreq, SelectionHighFreq,
+		TabSeparatedField(selectionFile(note)), beginDate, beginClock,
+		TabSeparatedField(note.SpeciesCode), TabSeparatedField(note.ScientificName),
+		TabSeparatedField(note.CommonName), note.Confidence)
+}
+
+// audacityLine formats a note as a label followed by its frequency range
+func (sw *SelectionWriter) audacityLine(note *datastore.Note) string {
+	begin, end := NoteTimeRange(note)
+
+	return fmt.Sprintf("%.3f\t%.3f\t%s (%.2f)\n\\\t%d\t%d\n",
+		SelectionOffset(begin, sw.origin), SelectionOffset(end, sw.origin),
+		TabSeparatedField(note.CommonName), note.Confidence,
+		SelectionLowFreq, SelectionHighFreq)
+}
+
+// WriteRavenSelectionTable writes notes as a Raven Pro selection table
+func WriteRavenSelectionTable(w io.Writer, notes []datastore.Note, origin time.Time) error {
+	sw, err := NewRavenSelectionWriter(w, origin)
+	if err != nil {
+		return err
+	}
+	if err := sw.Write(notes); err != nil {
+		return err
+	}
+	return sw.Flush()
+}
+
+// WriteAudacityLabels writes notes as an Audacity label track
+func WriteAudacityLabels(w io.Writer, notes []datastore.Note, origin time.Time) error {
+	sw := NewAudacityLabelWriter(w, origin)
+	if err := sw.Write(notes); err != nil {
+		return err
+	}
+	return sw.Flush()
+}
+
+// WriteNotesRaven writes the notes above the confidence threshold of a file analysis as
+// a Raven Pro selection table. If the filename is an empty string, it writes to stdout.
+func WriteNotesRaven(settings *conf.Settings, notes []datastore.Note, filename string) error {
+	return writeNotesSelections(settings, notes, filename, RavenFileSuffix, WriteRavenSelectionTable)
+}
+
+// WriteNotesAudacity writes the notes above the confidence threshold of a file analysis
+// as an Audacity label track. If the filename is an empty string, it writes to stdout.
+func WriteNotesAudacity(settings *conf.Settings, notes []datastore.Note, filename string) error {
+	return writeNotesSelections(settings, notes, filename, AudacityFileSuffix, WriteAudacityLabels)
+}
+
+// writeNotesSelections filters the notes by threshold and writes them to the file with
+// the given suffix or to stdout
+func writeNotesSelections(settings *conf.Settings, notes []datastore.Note, filename, suffix string,
+	write func(io.Writer, []datastore.Note, time.Time) error) error {
+	filtered := make([]datastore.Note, 0, len(notes))
+	for i := range notes {
+		if notes[i].Confidence > settings.BirdNET.Threshold {
+			filtered = append(filtered, notes[i])
+		}
+	}
+
+	if filename == "" {
+		return write(os.Stdout, filtered, time.Time{})
+	}
+
+	if !strings.HasSuffix(filename, suffix) {
+		filename += suffix
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+
+	if err := write(file, filtered, time.Time{}); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file %s: %w", filename, err)
+	}
+
+	fmt.Println("Output written to", filename)
+	return nil
+}
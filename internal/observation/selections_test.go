@@ -0,0 +1,103 @@
+package observation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestWriteRavenSelectionTable(t *testing.T) {
+	notes := []datastore.Note{
+		{
+			BeginTime:      time.Time{}.Add(3 * time.Second),
+			EndTime:        time.Time{}.Add(6 * time.Second),
+			Source:         datastore.AudioSource{SafeString: "rec.wav"},
+			SpeciesCode:    "eurrob1",
+			ScientificName: "Erithacus rubecula",
+			CommonName:     "European Robin",
+			Confidence:     0.87,
+		},
+		{
+			BeginTime:      time.Time{}.Add(7500 * time.Millisecond),
+			EndTime:        time.Time{}.Add(10500 * time.Millisecond),
+			ScientificName: "Turdus merula",
+			CommonName:     "Eurasian\tBlackbird",
+			Confidence:     0.5,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteRavenSelectionTable(&buf, notes, time.Time{}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, strings.TrimSuffix(ravenHeader, "\n"), lines[0])
+
+	fields := strings.Split(lines[1], "\t")
+	assert.Len(t, fields, strings.Count(ravenHeader, "\t")+1)
+	assert.Equal(t, []string{"1", "Spectrogram 1", "1", "3.000", "6.000", "0", "15000", "rec.wav", "", "",
+		"eurrob1", "Erithacus rubecula", "European Robin", "0.8700"}, fields)
+
+	fields = strings.Split(lines[2], "\t")
+	assert.Equal(t, "2", fields[0])
+	assert.Equal(t, "7.500", fields[3])
+	assert.Equal(t, "Eurasian Blackbird", fields[12], "tabs in names are replaced")
+}
+
+func TestSelectionWriter_StoredDetections(t *testing.T) {
+	origin := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	notes := []datastore.Note{
+		{
+			BeginTime:  origin.Add(5*time.Hour + 30*time.Minute),
+			EndTime:    origin.Add(5*time.Hour + 30*time.Minute + 3*time.Second),
+			CommonName: "European Robin",
+			Confidence: 0.87,
+			ClipName:   "clips/2024/05/erithacus_rubecula_87p_20240501T053000Z.wav",
+		},
+		{
+			// Without a begin time the stored date and time are used
+			Date:       "2024-05-01",
+			Time:       "06:00:00",
+			CommonName: "Eurasian Blackbird",
+			Confidence: 0.75,
+		},
+	}
+
+	var buf bytes.Buffer
+	sw, err := NewRavenSelectionWriter(&buf, origin)
+	require.NoError(t, err)
+	require.NoError(t, sw.Write(notes[:1]))
+	require.NoError(t, sw.Write(notes[1:]))
+	require.NoError(t, sw.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	first := strings.Split(lines[1], "\t")
+	assert.Equal(t, "19800.000", first[3])
+	assert.Equal(t, "erithacus_rubecula_87p_20240501T053000Z.wav", first[7], "stored detections refer to their clip")
+	assert.Equal(t, "2024/05/01", first[8])
+	assert.Equal(t, "05:30:00.000", first[9])
+
+	second := strings.Split(lines[2], "\t")
+	assert.Equal(t, "2", second[0], "selections are numbered across batches")
+	assert.Equal(t, "21600.000", second[3])
+	assert.Equal(t, "21603.000", second[4])
+}
+
+func TestWriteAudacityLabels(t *testing.T) {
+	notes := []datastore.Note{{
+		BeginTime:  time.Time{}.Add(3 * time.Second),
+		EndTime:    time.Time{}.Add(6 * time.Second),
+		CommonName: "European Robin",
+		Confidence: 0.87,
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAudacityLabels(&buf, notes, time.Time{}))
+	assert.Equal(t, "3.000\t6.000\tEuropean Robin (0.87)\n\\\t0\t15000\n", buf.String())
+}
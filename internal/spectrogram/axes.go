@@ -0,0 +1,143 @@
+package spectrogram
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// Glyph metrics of the label font in unscaled pixels
+const (
+	glyphWidth   = 3
+	glyphHeight  = 5
+	glyphAdvance = glyphWidth + 1
+	tickLength   = 3
+)
+
+var (
+	axisColor  = color.RGBA{200, 200, 200, 255}
+	background = color.RGBA{0, 0, 0, 255}
+)
+
+// glyphs is a 3x5 pixel font of the characters used in axis labels, each row is a bit mask
+// with the leftmost pixel in the highest bit
+var glyphs = map[rune][glyphHeight]uint8{
+	'0': {0b111, 0b101, 0b101, 0b101, 0b111},
+	'1': {0b010, 0b110, 0b010, 0b010, 0b111},
+	'2': {0b111, 0b001, 0b111, 0b100, 0b111},
+	'3': {0b111, 0b001, 0b111, 0b001, 0b111},
+	'4': {0b101, 0b101, 0b111, 0b001, 0b001},
+	'5': {0b111, 0b100, 0b111, 0b001, 0b111},
+	'6': {0b111, 0b100, 0b111, 0b101, 0b111},
+	'7': {0b111, 0b001, 0b010, 0b010, 0b010},
+	'8': {0b111, 0b101, 0b111, 0b101, 0b111},
+	'9': {0b111, 0b101, 0b111, 0b001, 0b111},
+	'k': {0b100, 0b101, 0b110, 0b101, 0b101},
+	's': {0b011, 0b100, 0b010, 0b001, 0b110},
+}
+
+// labelScale returns the pixel scale of the label font for an image width
+func labelScale(width int) int {
+	if width >= 800 {
+		return 2
+	}
+	return 1
+}
+
+// plotArea returns the part of an image left for the spectrogram when axes are drawn,
+// leaving room for frequency labels on the left and time labels at the bottom. The area is
+// empty if the image is too small.
+func plotArea(bounds image.Rectangle, width int) image.Rectangle {
+	scale := labelScale(width)
+	left := 4*glyphAdvance*scale + tickLength + 2
+	bottom := glyphHeight*scale + tickLength + 2
+	top := glyphHeight * scale / 2
+	right := 2 * glyphAdvance * scale
+	if bounds.Dx() <= left+right || bounds.Dy() <= top+bottom {
+		return image.Rectangle{}
+	}
+	return image.Rect(bounds.Min.X+left, bounds.Min.Y+top, bounds.Max.X-right, bounds.Max.Y-bottom)
+}
+
+// drawAxes draws the frequency axis on the left and the time axis below the plot area
+func drawAxes(img *image.RGBA, plot image.Rectangle, duration, minFreq, maxFreq float64) {
+	scale := labelScale(img.Bounds().Dx())
+
+	for y := plot.Min.Y; y <= plot.Max.Y; y++ {
+		img.SetRGBA(plot.Min.X-1, y, axisColor)
+	}
+	for x := plot.Min.X - 1; x < plot.Max.X; x++ {
+		img.SetRGBA(x, plot.Max.Y, axisColor)
+	}
+
+	// Frequency ticks, labeled in Hz below 1 kHz steps and in kHz otherwise
+	maxTicks := max(plot.Dy()/(3*glyphHeight*scale), 1)
+	step := niceStep(maxFreq-minFreq, maxTicks, []float64{100, 200, 500, 1000, 2000, 5000, 10000, 20000})
+	for f := math.Ceil(minFreq/step) * step; f <= maxFreq; f += step {
+		y := plot.Max.Y - 1 - int(math.Round((f-minFreq)/(maxFreq-minFreq)*float64(plot.Dy()-1)))
+		for x := plot.Min.X - 1 - tickLength; x < plot.Min.X-1; x++ {
+			img.SetRGBA(x, y, axisColor)
+		}
+		label := strconv.Itoa(int(f))
+		if int(f)%1000 == 0 && f > 0 {
+			label = strconv.Itoa(int(f)/1000) + "k"
+		}
+		labelWidth := len(label)*glyphAdvance*scale - scale
+		drawLabel(img, plot.Min.X-2-tickLength-labelWidth, y-glyphHeight*scale/2, label, scale)
+	}
+
+	// Time ticks in seconds
+	if duration < 1 {
+		return
+	}
+	maxTicks = max(plot.Dx()/(5*glyphAdvance*scale), 1)
+	step = niceStep(duration, maxTicks, []float64{1, 2, 5, 10, 15, 30, 60, 120, 300, 600})
+	for t := 0.0; t <= duration; t += step {
+		x := plot.Min.X + int(math.Round(t/duration*float64(plot.Dx()-1)))
+		for y := plot.Max.Y + 1; y <= plot.Max.Y+tickLength; y++ {
+			img.SetRGBA(x, y, axisColor)
+		}
+		label := strconv.Itoa(int(t)) + "s"
+		labelWidth := len(label)*glyphAdvance*scale - scale
+		drawLabel(img, x-labelWidth/2, plot.Max.Y+tickLength+2, label, scale)
+	}
+}
+
+// niceStep returns the smallest step that divides a span into at most maxTicks ticks
+func niceStep(span float64, maxTicks int, steps []float64) float64 {
+	for _, step := range steps {
+		if span/step <= float64(maxTicks) {
+			return step
+		}
+	}
+	return steps[len(steps)-1]
+}
+
+// drawLabel draws text with its top left corner at x, y, pixels outside the image are
+// skipped
+func drawLabel(img *image.RGBA, x, y int, text string, scale int) {
+	for _, r := range text {
+		glyph, ok := glyphs[r]
+		if !ok {
+			x += glyphAdvance * scale
+			continue
+		}
+		for row, bits := range glyph {
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						p := image.Pt(x+col*scale+dx, y+row*scale+dy)
+						if p.In(img.Bounds()) {
+							img.SetRGBA(p.X, p.Y, axisColor)
+						}
+					}
+				}
+			}
+		}
+		x += glyphAdvance * scale
+	}
+}
@@ -0,0 +1,126 @@
+package spectrogram
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// Image formats of rendered spectrograms
+const (
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// Renderer modes of the spectrogram settings
+const (
+	RendererAuto     = "auto"     // native renderer with the external tools as fallback
+	RendererNative   = "native"   // native renderer only
+	RendererExternal = "external" // SoX or FFmpeg only
+)
+
+// OptionsFromSettings returns the render options of the spectrogram settings for an image
+// width, raw spectrograms are drawn without axes
+func OptionsFromSettings(settings *conf.SpectrogramSettings, width int, raw bool) Options {
+	return Options{
+		Width:        width,
+		Height:       width / 2,
+		FFTSize:      settings.FFTSize,
+		HopSize:      settings.HopSize,
+		MinFreq:      float64(settings.MinFreq),
+		MaxFreq:      float64(settings.MaxFreq),
+		DynamicRange: settings.DynamicRange,
+		Colormap:     settings.Colormap,
+		Axes:         !raw,
+	}
+}
+
+// UseNative reports whether the settings select the native renderer
+func UseNative(settings *conf.SpectrogramSettings) bool {
+	return settings.Renderer != RendererExternal
+}
+
+// UseExternal reports whether the settings allow the SoX and FFmpeg renderers
+func UseExternal(settings *conf.SpectrogramSettings) bool {
+	return settings.Renderer != RendererNative
+}
+
+// Extension returns the file extension of spectrograms rendered with the settings, the
+// external tools always render PNG images
+func Extension(settings *conf.SpectrogramSettings) string {
+	if settings.Format == FormatWebP && UseNative(settings) {
+		return "." + FormatWebP
+	}
+	return "." + FormatPNG
+}
+
+// Encode writes an image in a format
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatPNG, "":
+		encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+		return encoder.Encode(w, img)
+	case FormatWebP:
+		return EncodeWebP(w, img)
+	default:
+		return fmt.Errorf("unsupported image format %q", format)
+	}
+}
+
+// RenderFile renders the spectrogram of an audio file to an image file whose format
+// follows its extension. WAV and FLAC files are decoded in-process, other formats need
+// FFmpeg. The image is written to a temporary file first so that readers never see a
+// partial image.
+func RenderFile(ctx context.Context, audioPath, outputPath string, opts Options, ffmpegPath string) error {
+	samples, sampleRate, err := myaudio.ReadAudioFileSamples(ctx, audioPath, ffmpegPath)
+	if err != nil {
+		return fmt.Errorf("error reading audio file: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	img, err := Render(samples, sampleRate, opts)
+	if err != nil {
+		return err
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(outputPath)), ".")
+	tmp, err := os.CreateTemp(filepath.Dir(outputPath), ".spectrogram-*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating spectrogram file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	bw := bufio.NewWriter(tmp)
+	if err := Encode(bw, img, format); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error encoding spectrogram: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing spectrogram: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing spectrogram: %w", err)
+	}
+	// Temporary files are private, spectrograms are readable like the audio clips
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("error writing spectrogram: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), outputPath); err != nil {
+		return fmt.Errorf("error writing spectrogram: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+// Package spectrogram renders spectrograms of audio clips in-process. Stations render the
+// same images whichever SoX or FFmpeg build they have installed, and the FFT size,
+// frequency range, dynamic range and colormap are configurable.
+package spectrogram
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/cmplx"
+)
+
+// Renderer defaults, they mirror the SoX spectrograms rendered before the native renderer
+const (
+	DefaultFFTSize      = 1024
+	DefaultMaxFreq      = 12000
+	DefaultDynamicRange = 100.0
+	DefaultColormap     = "sox"
+)
+
+// powerFloor keeps the logarithm of silent bins finite
+const powerFloor = 1e-20
+
+// silenceLevel is the level in dB of silent bins
+var silenceLevel = 10 * math.Log10(powerFloor)
+
+// Options controls how a spectrogram is rendered
+type Options struct {
+	Width        int     // image width in pixels
+	Height       int     // image height in pixels, half the width if 0
+	FFTSize      int     // samples per FFT frame, a power of two
+	HopSize      int     // samples between frames, 0 spreads the frames over the image width
+	MinFreq      float64 // lowest frequency shown in Hz
+	MaxFreq      float64 // highest frequency shown in Hz, 0 or above the Nyquist frequency for half the sample rate
+	DynamicRange float64 // dB below the loudest bin shown
+	Colormap     string  // colormap name, see Colormaps
+	Axes         bool    // true to draw frequency and time axes with labels
+}
+
+// withDefaults returns the options with zero values replaced by the defaults
+func (o Options) withDefaults() Options {
+	if o.Height <= 0 {
+		o.Height = o.Width / 2
+	}
+	if o.FFTSize <= 0 {
+		o.FFTSize = DefaultFFTSize
+	}
+	if o.DynamicRange <= 0 {
+		o.DynamicRange = DefaultDynamicRange
+	}
+	if o.Colormap == "" {
+		o.Colormap = DefaultColormap
+	}
+	return o
+}
+
+// Render renders the spectrogram of mono samples at a sample rate
+func Render(samples []float32, sampleRate int, opts Options) (*image.RGBA, error) {
+	opts = opts.withDefaults()
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return nil, fmt.Errorf("invalid spectrogram size %dx%d", opts.Width, opts.Height)
+	}
+	if opts.FFTSize < 2 || opts.FFTSize&(opts.FFTSize-1) != 0 {
+		return nil, fmt.Errorf("FFT size must be a power of two, got %d", opts.FFTSize)
+	}
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+	palette, ok := colormaps[opts.Colormap]
+	if !ok {
+		return nil, fmt.Errorf("unknown colormap %q", opts.Colormap)
+	}
+
+	nyquist := float64(sampleRate) / 2
+	maxFreq := opts.MaxFreq
+	if maxFreq <= 0 || maxFreq > nyquist {
+		maxFreq = nyquist
+	}
+	minFreq := math.Max(0, opts.MinFreq)
+	if minFreq >= maxFreq {
+		return nil, fmt.Errorf("invalid frequency range %.0f-%.0f Hz", minFreq, maxFreq)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))
+	plot := img.Bounds()
+	if opts.Axes {
+		draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+		plot = plotArea(plot, opts.Width)
+		if plot.Empty() {
+			return nil, fmt.Errorf("spectrogram size %dx%d is too small for axes", opts.Width, opts.Height)
+		}
+	}
+
+	levels := computeLevels(samples, plot.Dx(), plot.Dy(), sampleRate, minFreq, maxFreq, opts)
+
+	// Scale the levels to the dynamic range below the loudest bin
+	peak := math.Inf(-1)
+	for _, level := range levels {
+		peak = math.Max(peak, level)
+	}
+	floor := peak - opts.DynamicRange
+	for i, level := range levels {
+		x, y := i%plot.Dx(), i/plot.Dx()
+		v := (level - floor) / opts.DynamicRange
+		if level <= silenceLevel {
+			v = 0
+		}
+		img.SetRGBA(plot.Min.X+x, plot.Max.Y-1-y, palette.at(v))
+	}
+
+	if opts.Axes {
+		drawAxes(img, plot, float64(len(samples))/float64(sampleRate), minFreq, maxFreq)
+	}
+	return img, nil
+}
+
+// computeLevels returns the levels in dB of a width x height grid, row 0 is the lowest
+// frequency. Each column shows the loudest of its frames and each row the loudest of its
+// FFT bins.
+func computeLevels(samples []float32, width, height, sampleRate int, minFreq, maxFreq float64, opts Options) []float64 {
+	fftSize := opts.FFTSize
+	frames, hop := frameLayout(len(samples), width, fftSize, opts.HopSize)
+
+	window := hannWindow(fftSize)
+	buf := make([]complex128, fftSize)
+	binWidth := float64(sampleRate) / float64(fftSize)
+	maxBin := fftSize / 2
+
+	columns := make([][]float64, width)
+	for x := range columns {
+		columns[x] = make([]float64, height)
+		for y := range columns[x] {
+			columns[x][y] = math.Inf(-1)
+		}
+	}
+
+	for frame := 0; frame < frames; frame++ {
+		start := frame * hop
+		for i := range buf {
+			var s float64
+			if start+i < len(samples) {
+				s = float64(samples[start+i])
+			}
+			buf[i] = complex(s*window[i], 0)
+		}
+		fft(buf)
+
+		// Frames are spread evenly over the columns, several frames may share a column
+		// and a frame may cover several columns
+		x0 := frame * width / frames
+		x1 := max((frame+1)*width/frames, x0+1)
+		for y := 0; y < height; y++ {
+			f0 := minFreq + (maxFreq-minFreq)*float64(y)/float64(height)
+			f1 := minFreq + (maxFreq-minFreq)*float64(y+1)/float64(height)
+			b0 := min(int(f0/binWidth), maxBin)
+			b1 := min(max(int(math.Ceil(f1/binWidth)), b0+1), maxBin+1)
+			power := powerFloor
+			for b := b0; b < b1; b++ {
+				// Scale by the FFT size so that levels do not depend on it
+				p := cmplx.Abs(buf[b]) / float64(fftSize)
+				power = math.Max(power, p*p)
+			}
+			level := 10 * math.Log10(power)
+			for x := x0; x < x1 && x < width; x++ {
+				columns[x][y] = math.Max(columns[x][y], level)
+			}
+		}
+	}
+
+	levels := make([]float64, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			levels = append(levels, columns[x][y])
+		}
+	}
+	return levels
+}
+
+// frameLayout returns the number of FFT frames and the samples between them. Without a hop
+// size the frames are spread over the clip so that there is one frame per column.
+func frameLayout(numSamples, width, fftSize, hopSize int) (frames, hop int) {
+	span := numSamples - fftSize
+	if span <= 0 {
+		return 1, max(hopSize, 1)
+	}
+	if hopSize > 0 {
+		return 1 + span/hopSize, hopSize
+	}
+	if width <= 1 {
+		return 1, span
+	}
+	hop = max(span/(width-1), 1)
+	return 1 + span/hop, hop
+}
+
+// hannWindow returns a Hann window of n samples
+func hannWindow(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n))
+	}
+	return w
+}
+
+// fft transforms x in place with an iterative radix-2 FFT, len(x) must be a power of two
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// colormap is a 256 color lookup table
+type colormap [256]color.RGBA
+
+// at returns the color of a value in [0, 1], values outside are clamped
+func (c *colormap) at(v float64) color.RGBA {
+	i := int(math.Round(v * 255))
+	return c[min(max(i, 0), 255)]
+}
+
+// newColormap interpolates a colormap between evenly spaced colors
+func newColormap(stops ...color.RGBA) *colormap {
+	var c colormap
+	for i := range c {
+		pos := float64(i) / 255 * float64(len(stops)-1)
+		lo := min(int(pos), len(stops)-2)
+		t := pos - float64(lo)
+		a, b := stops[lo], stops[lo+1]
+		lerp := func(x, y uint8) uint8 {
+			return uint8(math.Round(float64(x) + t*(float64(y)-float64(x))))
+		}
+		c[i] = color.RGBA{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), 255}
+	}
+	return &c
+}
+
+// colormaps are the supported colormaps by name
+var colormaps = map[string]*colormap{
+	// sox resembles the default palette of the SoX spectrogram effect
+	"sox": newColormap(
+		color.RGBA{0, 0, 0, 255},
+		color.RGBA{52, 0, 98, 255},
+		color.RGBA{140, 0, 150, 255},
+		color.RGBA{220, 30, 70, 255},
+		color.RGBA{255, 120, 0, 255},
+		color.RGBA{255, 210, 40, 255},
+		color.RGBA{255, 255, 255, 255},
+	),
+	"viridis": newColormap(
+		color.RGBA{68, 1, 84, 255},
+		color.RGBA{59, 82, 139, 255},
+		color.RGBA{33, 145, 140, 255},
+		color.RGBA{94, 201, 98, 255},
+		color.RGBA{253, 231, 37, 255},
+	),
+	"magma": newColormap(
+		color.RGBA{0, 0, 4, 255},
+		color.RGBA{81, 18, 124, 255},
+		color.RGBA{183, 55, 121, 255},
+		color.RGBA{252, 137, 97, 255},
+		color.RGBA{252, 253, 191, 255},
+	),
+	"grayscale": newColormap(
+		color.RGBA{0, 0, 0, 255},
+		color.RGBA{255, 255, 255, 255},
+	),
+}
+
+// Colormaps returns the names of the supported colormaps
+func Colormaps() []string {
+	return []string{"sox", "viridis", "magma", "grayscale"}
+}
@@ -0,0 +1,181 @@
+package spectrogram
+
+import (
+	"context"
+	"image"
+	"image/png"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sine returns n samples of a sine wave
+func sine(freq float64, sampleRate, n int) []float32 {
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(0.5 * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+	}
+	return samples
+}
+
+// mustRender renders a spectrogram and fails the test on error
+func mustRender(t *testing.T, samples []float32, sampleRate int, opts Options) *image.RGBA {
+	t.Helper()
+	img, err := Render(samples, sampleRate, opts)
+	require.NoError(t, err)
+	return img
+}
+
+func TestFFT(t *testing.T) {
+	t.Parallel()
+
+	x := make([]complex128, 16)
+	for i := range x {
+		x[i] = complex(math.Sin(float64(i)*0.7)+float64(i%3), 0)
+	}
+	want := make([]complex128, len(x))
+	for k := range want {
+		for n, v := range x {
+			want[k] += v * cmplx.Exp(complex(0, -2*math.Pi*float64(k*n)/float64(len(x))))
+		}
+	}
+
+	fft(x)
+	for k := range x {
+		assert.InDelta(t, real(want[k]), real(x[k]), 1e-9)
+		assert.InDelta(t, imag(want[k]), imag(x[k]), 1e-9)
+	}
+}
+
+// loudestRow returns the row of the brightest pixel in a column
+func loudestRow(img *image.RGBA, x int) int {
+	row, best := -1, -1
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		c := img.RGBAAt(x, y)
+		if v := int(c.R) + int(c.G) + int(c.B); v > best {
+			row, best = y, v
+		}
+	}
+	return row
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	// A 3 kHz tone shown from 0 to 12 kHz lies a quarter up from the bottom
+	img := mustRender(t, sine(3000, 48000, 48000), 48000, Options{Width: 200, MaxFreq: 12000, Colormap: "grayscale"})
+	require.Equal(t, image.Rect(0, 0, 200, 100), img.Bounds())
+	for _, x := range []int{0, 100, 199} {
+		assert.InDelta(t, 75, loudestRow(img, x), 1, "column %d", x)
+	}
+
+	// The frequency range moves the tone
+	img = mustRender(t, sine(3000, 48000, 48000), 48000, Options{Width: 200, MinFreq: 2000, MaxFreq: 4000, FFTSize: 4096, HopSize: 1024})
+	assert.InDelta(t, 50, loudestRow(img, 100), 2)
+
+	// Silence is drawn in the lowest color
+	img = mustRender(t, make([]float32, 4800), 48000, Options{Width: 40, Colormap: "magma"})
+	assert.Equal(t, colormaps["magma"][0], img.RGBAAt(20, 10))
+
+	// Clips shorter than a frame are zero padded
+	mustRender(t, sine(1000, 48000, 100), 48000, Options{Width: 40})
+}
+
+func TestRender_Axes(t *testing.T) {
+	t.Parallel()
+
+	img := mustRender(t, sine(3000, 48000, 15*48000), 48000, Options{Width: 400, Axes: true})
+	plot := plotArea(img.Bounds(), 400)
+	require.True(t, plot.In(img.Bounds()))
+	assert.Less(t, plot.Dx(), 400)
+
+	// The axes are drawn next to the plot
+	assert.Equal(t, axisColor, img.RGBAAt(plot.Min.X-1, plot.Min.Y+10))
+	assert.Equal(t, axisColor, img.RGBAAt(plot.Min.X+10, plot.Max.Y))
+
+	// Labels are drawn in the margins
+	labelPixels := 0
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < plot.Min.X-1-tickLength; x++ {
+			if img.RGBAAt(x, y) == axisColor {
+				labelPixels++
+			}
+		}
+	}
+	assert.Positive(t, labelPixels)
+
+	_, err := Render(sine(3000, 48000, 4800), 48000, Options{Width: 20, Axes: true})
+	require.Error(t, err)
+}
+
+func TestRender_InvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	samples := sine(1000, 48000, 4800)
+	for name, opts := range map[string]Options{
+		"no width":           {},
+		"FFT size":           {Width: 100, FFTSize: 1000},
+		"colormap":           {Width: 100, Colormap: "rainbow"},
+		"frequency range":    {Width: 100, MinFreq: 30000},
+		"inverted frequency": {Width: 100, MinFreq: 5000, MaxFreq: 4000},
+	} {
+		_, err := Render(samples, 48000, opts)
+		assert.Error(t, err, name)
+	}
+	_, err := Render(samples, 0, Options{Width: 100})
+	assert.Error(t, err)
+
+	for _, name := range Colormaps() {
+		assert.Contains(t, colormaps, name)
+	}
+}
+
+func TestRenderFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	audioPath := filepath.Join(dir, "clip.wav")
+	file, err := os.Create(audioPath)
+	require.NoError(t, err)
+	data := make([]int, 3*48000)
+	for i, s := range sine(2000, 48000, len(data)) {
+		data[i] = int(s * 32767)
+	}
+	encoder := wav.NewEncoder(file, 48000, 16, 1, 1)
+	require.NoError(t, encoder.Write(&audio.IntBuffer{Format: &audio.Format{SampleRate: 48000, NumChannels: 1}, Data: data}))
+	require.NoError(t, encoder.Close())
+	require.NoError(t, file.Close())
+
+	// No FFmpeg is needed for WAV files
+	pngPath := filepath.Join(dir, "clip_400px.png")
+	require.NoError(t, RenderFile(context.Background(), audioPath, pngPath, Options{Width: 400}, ""))
+	f, err := os.Open(pngPath)
+	require.NoError(t, err)
+	defer func() {
+		_ = f.Close()
+	}()
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(400, 200), img.Bounds().Size())
+
+	webpPath := filepath.Join(dir, "clip_400px.webp")
+	require.NoError(t, RenderFile(context.Background(), audioPath, webpPath, Options{Width: 400}, ""))
+	webp, err := os.ReadFile(webpPath)
+	require.NoError(t, err)
+	decoded := decodeTestWebP(t, webp)
+	assert.Equal(t, image.Pt(400, 200), decoded.Bounds().Size())
+
+	// Temporary files are cleaned up
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	require.Error(t, RenderFile(context.Background(), filepath.Join(dir, "missing.wav"), pngPath, Options{Width: 400}, ""))
+}
@@ -0,0 +1,336 @@
+package spectrogram
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+)
+
+// The encoder writes lossless WebP (VP8L) images without transforms, color cache or
+// backward references. Each color channel is entropy coded with its own prefix code, which
+// keeps the encoder small while spectrograms, with their limited palettes, still compress
+// well.
+
+const (
+	vp8lSignature     = 0x2f
+	vp8lMaxDimension  = 1 << 14
+	vp8lMaxCodeLength = 15
+	// Code lengths of the prefix codes are themselves prefix coded with codes of at most 7 bits
+	vp8lMaxCodeLengthCodeLength = 7
+	// The green alphabet also holds the 24 backward reference length prefixes
+	vp8lGreenAlphabet    = 256 + 24
+	vp8lDistanceAlphabet = 40
+	vp8lCodeLengthCodes  = 19
+)
+
+// vp8lCodeLengthOrder is the order in which the code length code lengths are written
+var vp8lCodeLengthOrder = [vp8lCodeLengthCodes]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP writes an image as a lossless WebP image
+func EncodeWebP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	if b.Dx() <= 0 || b.Dy() <= 0 || b.Dx() > vp8lMaxDimension || b.Dy() > vp8lMaxDimension {
+		return fmt.Errorf("invalid WebP image size %dx%d", b.Dx(), b.Dy())
+	}
+
+	// Collect the non-premultiplied pixels and the channel histograms
+	pixels := make([][4]uint8, 0, b.Dx()*b.Dy())
+	var histograms [4][]int // green, red, blue, alpha
+	histograms[0] = make([]int, vp8lGreenAlphabet)
+	for i := 1; i < 4; i++ {
+		histograms[i] = make([]int, 256)
+	}
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := nrgba(img, x, y)
+			pixels = append(pixels, [4]uint8{g, r, bl, a})
+			histograms[0][g]++
+			histograms[1][r]++
+			histograms[2][bl]++
+			histograms[3][a]++
+			opaque = opaque && a == 255
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.writeBits(vp8lSignature, 8)
+	bw.writeBits(uint32(b.Dx()-1), 14) //nolint:gosec // G115: size checked above
+	bw.writeBits(uint32(b.Dy()-1), 14) //nolint:gosec // G115: size checked above
+	if opaque {
+		bw.writeBits(0, 1)
+	} else {
+		bw.writeBits(1, 1)
+	}
+	bw.writeBits(0, 3) // version
+	bw.writeBits(0, 1) // no transforms
+	bw.writeBits(0, 1) // no color cache
+	bw.writeBits(0, 1) // a single set of prefix codes for the whole image
+
+	var codes [4]prefixCode
+	for i := range histograms {
+		codes[i] = writePrefixCode(bw, histograms[i])
+	}
+	// The distance code is unused, a single symbol code takes no bits
+	writePrefixCode(bw, make([]int, vp8lDistanceAlphabet))
+
+	for _, p := range pixels {
+		for i, v := range p {
+			codes[i].write(bw, int(v))
+		}
+	}
+	bw.flush()
+
+	payload := bw.buf
+	padding := len(payload) & 1
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+len(payload)+padding)) //nolint:gosec // G115: image size is limited
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(payload))) //nolint:gosec // G115: image size is limited
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	if padding > 0 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+// nrgba returns the non-premultiplied color of a pixel
+func nrgba(img image.Image, x, y int) (r, g, b, a uint8) {
+	switch src := img.(type) {
+	case *image.RGBA:
+		if c := src.RGBAAt(x, y); c.A == 255 {
+			return c.R, c.G, c.B, c.A
+		}
+	case *image.NRGBA:
+		c := src.NRGBAAt(x, y)
+		return c.R, c.G, c.B, c.A
+	}
+	r32, g32, b32, a32 := img.At(x, y).RGBA()
+	if a32 == 0 {
+		return 0, 0, 0, 0
+	}
+	return uint8(r32 * 0xffff / a32 >> 8), uint8(g32 * 0xffff / a32 >> 8), uint8(b32 * 0xffff / a32 >> 8), uint8(a32 >> 8) //nolint:gosec // G115: 16-bit color to 8 bits
+}
+
+// bitWriter packs bits least significant bit first
+type bitWriter struct {
+	buf  []byte
+	bits uint64
+	n    uint
+}
+
+// writeBits writes the n low bits of v, n is at most 32
+func (w *bitWriter) writeBits(v uint32, n uint) {
+	w.bits |= uint64(v) << w.n
+	w.n += n
+	for w.n >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.n -= 8
+	}
+}
+
+// flush writes the remaining bits padded with zeros
+func (w *bitWriter) flush() {
+	if w.n > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits, w.n = 0, 0
+	}
+}
+
+// prefixCode is a canonical prefix code with the codes bit reversed for writing
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+// write writes the code of a symbol
+func (c *prefixCode) write(w *bitWriter, symbol int) {
+	if n := c.lengths[symbol]; n > 0 {
+		w.writeBits(c.codes[symbol], uint(n))
+	}
+}
+
+// newPrefixCode assigns canonical codes to code lengths
+func newPrefixCode(lengths []uint8) prefixCode {
+	var count [vp8lMaxCodeLength + 1]uint32
+	for _, n := range lengths {
+		count[n]++
+	}
+	count[0] = 0
+	var next [vp8lMaxCodeLength + 1]uint32
+	code := uint32(0)
+	for n := 1; n <= vp8lMaxCodeLength; n++ {
+		code = (code + count[n-1]) << 1
+		next[n] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for symbol, n := range lengths {
+		if n == 0 {
+			continue
+		}
+		// Codes are read one bit at a time starting with the most significant bit
+		c := next[n]
+		next[n]++
+		var reversed uint32
+		for i := uint8(0); i < n; i++ {
+			reversed = reversed<<1 | c>>i&1
+		}
+		codes[symbol] = reversed
+	}
+	return prefixCode{lengths: lengths, codes: codes}
+}
+
+// writePrefixCode writes the prefix code of a histogram and returns it
+func writePrefixCode(w *bitWriter, histogram []int) prefixCode {
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	// Codes of up to two symbols below 256 are written as simple codes
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		lengths := make([]uint8, len(histogram))
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		w.writeBits(1, 1)
+		w.writeBits(uint32(len(used)-1), 1) //nolint:gosec // G115: one or two symbols
+		if used[0] < 2 {
+			w.writeBits(0, 1)
+			w.writeBits(uint32(used[0]), 1) //nolint:gosec // G115: symbol below 2
+		} else {
+			w.writeBits(1, 1)
+			w.writeBits(uint32(used[0]), 8) //nolint:gosec // G115: symbol below 256
+		}
+		if len(used) == 2 {
+			w.writeBits(uint32(used[1]), 8) //nolint:gosec // G115: symbol below 256
+			lengths[used[0]], lengths[used[1]] = 1, 1
+		}
+		return newPrefixCode(lengths)
+	}
+
+	lengths := codeLengths(histogram, vp8lMaxCodeLength)
+
+	// The code lengths are written with a code length code, a code with a single symbol
+	// would take no bits, so it always has at least two symbols
+	lengthHistogram := make([]int, vp8lCodeLengthCodes)
+	for _, n := range lengths {
+		lengthHistogram[n]++
+	}
+	symbols := 0
+	for _, count := range lengthHistogram {
+		if count > 0 {
+			symbols++
+		}
+	}
+	if symbols < 2 {
+		if lengthHistogram[0] == 0 {
+			lengthHistogram[0] = 1
+		} else {
+			lengthHistogram[1] = 1
+		}
+	}
+	lengthCodeLengths := codeLengths(lengthHistogram, vp8lMaxCodeLengthCodeLength)
+	lengthCode := newPrefixCode(lengthCodeLengths)
+
+	numCodes := 4
+	for i, symbol := range vp8lCodeLengthOrder {
+		if lengthCodeLengths[symbol] > 0 {
+			numCodes = max(numCodes, i+1)
+		}
+	}
+	w.writeBits(0, 1)
+	w.writeBits(uint32(numCodes-4), 4) //nolint:gosec // G115: at most 19 codes
+	for _, symbol := range vp8lCodeLengthOrder[:numCodes] {
+		w.writeBits(uint32(lengthCodeLengths[symbol]), 3)
+	}
+	w.writeBits(0, 1) // code lengths for the whole alphabet follow
+	for _, n := range lengths {
+		lengthCode.write(w, int(n))
+	}
+	return newPrefixCode(lengths)
+}
+
+// codeLengths returns Huffman code lengths of at most maxLength bits for a histogram with
+// at least two used symbols. Rare symbols are made more frequent until the longest code
+// fits.
+func codeLengths(histogram []int, maxLength int) []uint8 {
+	minCount := 1
+	for {
+		lengths := huffmanLengths(histogram, minCount)
+		longest := uint8(0)
+		for _, n := range lengths {
+			longest = max(longest, n)
+		}
+		if int(longest) <= maxLength {
+			return lengths
+		}
+		minCount *= 2
+	}
+}
+
+// huffmanNode is a node of a Huffman tree under construction
+type huffmanNode struct {
+	count       int
+	symbol      int // -1 for inner nodes
+	left, right *huffmanNode
+}
+
+// huffmanHeap orders nodes by count
+type huffmanHeap []*huffmanNode
+
+func (h huffmanHeap) Len() int            { return len(h) }
+func (h huffmanHeap) Less(i, j int) bool  { return h[i].count < h[j].count }
+func (h huffmanHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *huffmanHeap) Push(x interface{}) { *h = append(*h, x.(*huffmanNode)) }
+func (h *huffmanHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// huffmanLengths returns the Huffman code lengths of a histogram, counting used symbols at
+// least minCount times
+func huffmanLengths(histogram []int, minCount int) []uint8 {
+	h := &huffmanHeap{}
+	for symbol, count := range histogram {
+		if count > 0 {
+			*h = append(*h, &huffmanNode{count: max(count, minCount), symbol: symbol})
+		}
+	}
+	heap.Init(h)
+	for h.Len() > 1 {
+		a := heap.Pop(h).(*huffmanNode)
+		b := heap.Pop(h).(*huffmanNode)
+		heap.Push(h, &huffmanNode{count: a.count + b.count, symbol: -1, left: a, right: b})
+	}
+
+	lengths := make([]uint8, len(histogram))
+	var walk func(n *huffmanNode, depth uint8)
+	walk = func(n *huffmanNode, depth uint8) {
+		if n.symbol >= 0 {
+			lengths[n.symbol] = max(depth, 1)
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	if h.Len() == 1 {
+		walk((*h)[0], 0)
+	}
+	return lengths
+}
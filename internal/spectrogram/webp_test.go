@@ -0,0 +1,92 @@
+package spectrogram
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+// decodeTestWebP decodes an encoded image with the WebP decoder of golang.org/x/image,
+// independently of the encoder
+func decodeTestWebP(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := webp.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img
+}
+
+func TestEncodeWebP(t *testing.T) {
+	t.Parallel()
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 37, 21))
+	for y := 0; y < 21; y++ {
+		for x := 0; x < 37; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{uint8(x * 7), uint8(x * y), uint8(255 - y*3), uint8(200 + x%3)})
+		}
+	}
+	twoColors := image.NewNRGBA(image.Rect(0, 0, 8, 3))
+	for x := 0; x < 8; x++ {
+		twoColors.SetNRGBA(x, 1, color.NRGBA{1, 200, 0, 255})
+	}
+	single := image.NewUniform(color.NRGBA{10, 20, 30, 255})
+
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"gradient with alpha", gradient},
+		{"two colors", twoColors},
+		{"single color", &subImage{single, image.Rect(0, 0, 5, 4)}},
+		{"spectrogram", mustRender(t, sine(3000, 48000, 24000), 48000, Options{Width: 120, Axes: true, Colormap: "viridis"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, EncodeWebP(&buf, tt.img))
+			got := decodeTestWebP(t, buf.Bytes())
+
+			b := tt.img.Bounds()
+			require.Equal(t, b.Size(), got.Bounds().Size())
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					want := color.NRGBAModel.Convert(tt.img.At(b.Min.X+x, b.Min.Y+y))
+					assert.Equal(t, want, color.NRGBAModel.Convert(got.At(x, y)), "pixel %d,%d", x, y)
+				}
+			}
+		})
+	}
+
+	require.Error(t, EncodeWebP(&bytes.Buffer{}, image.NewRGBA(image.Rect(0, 0, 0, 10))))
+}
+
+// subImage gives an image bounds
+type subImage struct {
+	image.Image
+	bounds image.Rectangle
+}
+
+func (s *subImage) Bounds() image.Rectangle { return s.bounds }
+
+func TestCodeLengths(t *testing.T) {
+	t.Parallel()
+
+	// Skewed histograms are limited to the maximum code length and stay complete
+	histogram := make([]int, 40)
+	for i := range histogram {
+		histogram[i] = 1 << min(i, 30)
+	}
+	lengths := codeLengths(histogram, 7)
+	kraft := 0.0
+	for _, n := range lengths {
+		require.Positive(t, n)
+		require.LessOrEqual(t, n, uint8(7))
+		kraft += 1 / float64(uint(1)<<n)
+	}
+	assert.InDelta(t, 1.0, kraft, 1e-9)
+}
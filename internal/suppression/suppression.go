@@ -0,0 +1,173 @@
+// Package suppression matches detections against the suppression rules of the settings.
+// A rule suppresses a species only at one audio source, within a daily time window and up
+// to a confidence, so recurring false positives with a local cause can be silenced without
+// excluding the species everywhere.
+package suppression
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// DefaultWindow is the time window around a false positive covered by a rule created from it
+const DefaultWindow = 30 * time.Minute
+
+// minutesPerDay is the number of minutes in a day
+const minutesPerDay = 24 * 60
+
+// Detection is a detection checked against the suppression rules
+type Detection struct {
+	CommonName     string
+	ScientificName string
+	Source         string // Audio source ID
+	Confidence     float64
+	Time           time.Time
+}
+
+// RuleStats counts the detections suppressed by a rule since startup
+type RuleStats struct {
+	Suppressed     int64     `json:"suppressed"`
+	LastSuppressed time.Time `json:"lastSuppressed"`
+}
+
+// Engine matches detections against suppression rules and counts the suppressed detections
+// of each rule. A nil Engine matches without counting.
+type Engine struct {
+	mu    sync.Mutex
+	stats map[string]*RuleStats
+}
+
+// NewEngine creates a rule engine
+func NewEngine() *Engine {
+	return &Engine{stats: make(map[string]*RuleStats)}
+}
+
+// Match returns the first rule suppressing a detection, nil if the detection is kept
+func (e *Engine) Match(rules []conf.SuppressionRule, d *Detection) *conf.SuppressionRule {
+	for i := range rules {
+		if !Matches(&rules[i], d) {
+			continue
+		}
+		if e != nil {
+			e.mu.Lock()
+			stats, ok := e.stats[rules[i].ID]
+			if !ok {
+				stats = &RuleStats{}
+				e.stats[rules[i].ID] = stats
+			}
+			stats.Suppressed++
+			stats.LastSuppressed = d.Time
+			e.mu.Unlock()
+		}
+		return &rules[i]
+	}
+	return nil
+}
+
+// Stats returns the counters of the rules that suppressed detections
+func (e *Engine) Stats() map[string]RuleStats {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats := make(map[string]RuleStats, len(e.stats))
+	for id, s := range e.stats {
+		stats[id] = *s
+	}
+	return stats
+}
+
+// Matches reports whether a rule suppresses a detection
+func Matches(rule *conf.SuppressionRule, d *Detection) bool {
+	if !strings.EqualFold(rule.Species, d.CommonName) && !strings.EqualFold(rule.Species, d.ScientificName) {
+		return false
+	}
+	if rule.Source != "" && rule.Source != d.Source {
+		return false
+	}
+	if rule.MaxConfidence > 0 && d.Confidence > rule.MaxConfidence {
+		return false
+	}
+	return inWindow(rule.Start, rule.End, d.Time)
+}
+
+// inWindow reports whether the time of day of t is within a daily window, windows whose end
+// is before the start span midnight. Rules without a window apply all day, rules with an
+// invalid window never apply.
+func inWindow(start, end string, t time.Time) bool {
+	if start == "" && end == "" {
+		return true
+	}
+	from, err1 := parseClock(start)
+	to, err2 := parseClock(end)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if from <= to {
+		return minute >= from && minute <= to
+	}
+	return minute >= from || minute <= to
+}
+
+// parseClock returns the minute of the day of an HH:MM time
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// formatClock returns the HH:MM time of a minute of the day
+func formatClock(minute int) string {
+	minute = ((minute % minutesPerDay) + minutesPerDay) % minutesPerDay
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// NewRuleID returns a random rule ID
+func NewRuleID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return "rule-" + hex.EncodeToString(b)
+}
+
+// RuleFromNote creates a rule suppressing the species of a detection at an audio source,
+// within window before and after the time of day of the detection. Detections up to
+// maxConfidence are suppressed, all detections if it is 0.
+func RuleFromNote(note *datastore.Note, source string, window time.Duration, maxConfidence float64) (conf.SuppressionRule, error) {
+	detected, err := time.Parse("15:04:05", note.Time)
+	if err != nil {
+		return conf.SuppressionRule{}, fmt.Errorf("invalid detection time %q: %w", note.Time, err)
+	}
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	rule := conf.SuppressionRule{
+		ID:            NewRuleID(),
+		Species:       note.ScientificName,
+		Source:        source,
+		MaxConfidence: maxConfidence,
+		NoteID:        note.ID,
+		Comment:       fmt.Sprintf("Created from false positive detection %d (%s on %s at %s)", note.ID, note.CommonName, note.Date, note.Time),
+	}
+	if rule.Species == "" {
+		rule.Species = note.CommonName
+	}
+	// A window of a day or more covers the whole day
+	if minutes := int(window.Minutes()); 2*minutes < minutesPerDay {
+		minute := detected.Hour()*60 + detected.Minute()
+		rule.Start = formatClock(minute - minutes)
+		rule.End = formatClock(minute + minutes)
+	}
+	return rule, nil
+}
@@ -0,0 +1,106 @@
+package suppression
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// at returns a detection of a tawny owl at a source and time of day
+func at(source, clock string, confidence float64) *Detection {
+	t, _ := time.Parse("15:04", clock)
+	return &Detection{
+		CommonName:     "Tawny Owl",
+		ScientificName: "Strix aluco",
+		Source:         source,
+		Confidence:     confidence,
+		Time:           time.Date(2024, 5, 1, t.Hour(), t.Minute(), 0, 0, time.Local),
+	}
+}
+
+func TestMatches(t *testing.T) {
+	t.Parallel()
+
+	rule := &conf.SuppressionRule{ID: "rule-1", Species: "strix aluco", Source: "garden", Start: "22:30", End: "01:30", MaxConfidence: 0.9}
+
+	tests := []struct {
+		name      string
+		detection *Detection
+		want      bool
+	}{
+		{"before midnight", at("garden", "23:00", 0.8), true},
+		{"after midnight", at("garden", "01:30", 0.8), true},
+		{"outside window", at("garden", "02:00", 0.8), false},
+		{"other source", at("street", "23:00", 0.8), false},
+		{"above max confidence", at("garden", "23:00", 0.95), false},
+		{"other species", &Detection{CommonName: "Eurasian Wren", Source: "garden", Time: at("", "23:00", 0).Time}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, Matches(rule, tt.detection))
+		})
+	}
+
+	// Rules without source, window and confidence limit suppress the species everywhere
+	assert.True(t, Matches(&conf.SuppressionRule{Species: "Tawny Owl"}, at("street", "12:00", 0.99)))
+	// Rules with an invalid window never apply
+	assert.False(t, Matches(&conf.SuppressionRule{Species: "Tawny Owl", Start: "late", End: "01:00"}, at("street", "00:30", 0.5)))
+}
+
+func TestEngine_Stats(t *testing.T) {
+	t.Parallel()
+
+	rules := []conf.SuppressionRule{
+		{ID: "street", Species: "Tawny Owl", Source: "street"},
+		{ID: "garden", Species: "Tawny Owl", Source: "garden"},
+	}
+	e := NewEngine()
+
+	assert.Nil(t, e.Match(rules, at("porch", "23:00", 0.8)))
+	require.NotNil(t, e.Match(rules, at("garden", "23:00", 0.8)))
+	d := at("garden", "23:10", 0.8)
+	assert.Equal(t, "garden", e.Match(rules, d).ID)
+
+	stats := e.Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(2), stats["garden"].Suppressed)
+	assert.Equal(t, d.Time, stats["garden"].LastSuppressed)
+
+	// A nil engine matches without counting
+	var nilEngine *Engine
+	assert.NotNil(t, nilEngine.Match(rules, d))
+	assert.Nil(t, nilEngine.Stats())
+}
+
+func TestRuleFromNote(t *testing.T) {
+	t.Parallel()
+
+	note := &datastore.Note{ID: 42, Date: "2024-05-01", Time: "23:50:12", CommonName: "Tawny Owl", ScientificName: "Strix aluco"}
+
+	rule, err := RuleFromNote(note, "garden", 0, 0.85)
+	require.NoError(t, err)
+	assert.Regexp(t, `^rule-[0-9a-f]{8}$`, rule.ID)
+	assert.Equal(t, "Strix aluco", rule.Species)
+	assert.Equal(t, "garden", rule.Source)
+	assert.Equal(t, "23:20", rule.Start)
+	assert.Equal(t, "00:20", rule.End)
+	assert.InDelta(t, 0.85, rule.MaxConfidence, 1e-9)
+	assert.Equal(t, uint(42), rule.NoteID)
+	assert.Contains(t, rule.Comment, "detection 42")
+
+	// The rule suppresses the false positive it was created from
+	assert.True(t, Matches(&rule, at("garden", "23:50", 0.8)))
+
+	rule, err = RuleFromNote(note, "", 12*time.Hour, 0)
+	require.NoError(t, err)
+	assert.Empty(t, rule.Start)
+	assert.Empty(t, rule.End)
+
+	_, err = RuleFromNote(&datastore.Note{Time: "late"}, "", 0, 0)
+	assert.Error(t, err)
+}
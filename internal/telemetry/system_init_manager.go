@@ -149,6 +149,13 @@ func (m *SystemInitManager) initializeNotification() error {
 		}
 		
 		m.logger.Info("notification service initialized successfully", "debug", debug)
+
+		// Start external push delivery; failures are logged but not fatal
+		if settings != nil {
+			if err := notification.InitializePushDispatcher(&settings.Notification.Push); err != nil {
+				m.logger.Error("failed to initialize push notifications", "error", err)
+			}
+		}
 	})
 	
 	return m.notificationErr